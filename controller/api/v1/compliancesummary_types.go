@@ -0,0 +1,107 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ComplianceSummaryName is the name of the ComplianceSummary the controller maintains
+const ComplianceSummaryName = "cluster"
+
+// ComplianceSummaryStatus adds up the status of every ImagePolicy in the cluster
+type ComplianceSummaryStatus struct {
+	// TotalPolicies is the number of ImagePolicies in the cluster
+	// +optional
+	TotalPolicies int32 `json:"totalPolicies,omitempty"`
+
+	// CompliantPolicies is the number of policies whose ComplianceStatus is Compliant
+	// +optional
+	CompliantPolicies int32 `json:"compliantPolicies,omitempty"`
+
+	// NonCompliantPolicies is the number of policies whose ComplianceStatus is NonCompliant or Error
+	// +optional
+	NonCompliantPolicies int32 `json:"nonCompliantPolicies,omitempty"`
+
+	// TotalDeployments is the count of workloads monitored by every policy. A workload monitored by
+	// several policies is counted once per policy
+	// +optional
+	TotalDeployments int32 `json:"totalDeployments,omitempty"`
+
+	// CompliantDeployments is the count of those workloads using the latest digest
+	// +optional
+	CompliantDeployments int32 `json:"compliantDeployments,omitempty"`
+
+	// NonCompliantDeployments is the count of those workloads that aren't compliant
+	// +optional
+	NonCompliantDeployments int32 `json:"nonCompliantDeployments,omitempty"`
+
+	// CheckedAttestations is the count of monitored workloads whose attestations were checked
+	// +optional
+	CheckedAttestations int32 `json:"checkedAttestations,omitempty"`
+
+	// VerifiedAttestations is the count of those workloads with a valid attestation
+	// +optional
+	VerifiedAttestations int32 `json:"verifiedAttestations,omitempty"`
+
+	// AttestationCoverage is the share of checked workloads with a valid attestation (e.g., "75%"),
+	// or "N/A" when no policy requires attestations
+	// +optional
+	AttestationCoverage string `json:"attestationCoverage,omitempty"`
+
+	// LastUpdated is when the summary last changed
+	// +optional
+	LastUpdated *metav1.Time `json:"lastUpdated,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories=security
+// +kubebuilder:validation:XValidation:rule="self.metadata.name == 'cluster'",message="the ComplianceSummary must be named cluster"
+// +kubebuilder:printcolumn:name="Policies",type="integer",JSONPath=".status.totalPolicies"
+// +kubebuilder:printcolumn:name="Non-Compliant Policies",type="integer",JSONPath=".status.nonCompliantPolicies"
+// +kubebuilder:printcolumn:name="Total",type="integer",JSONPath=".status.totalDeployments"
+// +kubebuilder:printcolumn:name="Compliant",type="integer",JSONPath=".status.compliantDeployments"
+// +kubebuilder:printcolumn:name="Attestations",type="string",JSONPath=".status.attestationCoverage"
+// +kubebuilder:printcolumn:name="Last Updated",type="date",JSONPath=".status.lastUpdated"
+
+// ComplianceSummary is the cluster-wide compliance of every ImagePolicy. The controller creates and
+// updates a single ComplianceSummary named "cluster".
+type ComplianceSummary struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// status is the aggregated compliance of every ImagePolicy
+	// +optional
+	Status ComplianceSummaryStatus `json:"status,omitempty,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// ComplianceSummaryList contains a list of ComplianceSummary
+type ComplianceSummaryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ComplianceSummary `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ComplianceSummary{}, &ComplianceSummaryList{})
+}
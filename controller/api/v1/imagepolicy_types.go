@@ -17,6 +17,7 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -28,51 +29,501 @@ const (
 	ComplianceStatusError        = "Error"
 )
 
+// Workload kinds monitored by an ImagePolicy
+const (
+	WorkloadKindDeployment  = "Deployment"
+	WorkloadKindStatefulSet = "StatefulSet"
+	WorkloadKindDaemonSet   = "DaemonSet"
+	WorkloadKindCronJob     = "CronJob"
+	WorkloadKindJob         = "Job"
+)
+
+// Container kinds within a workload's pod template
+const (
+	ContainerKindContainer          = "Container"
+	ContainerKindInitContainer      = "InitContainer"
+	ContainerKindEphemeralContainer = "EphemeralContainer"
+)
+
+// ComplianceReasonCompliant is the Reason of a compliant workload
+const ComplianceReasonCompliant = "Compliant"
+
+// ComplianceReasonOverridden is the Reason of a workload forced compliant by its AnnotationComplianceOverride
+const ComplianceReasonOverridden = "Overridden"
+
+// ComplianceReasonUnknown is the Reason of a workload whose analysis didn't finish within its namespace's
+// NamespaceAnalysisTimeout; it is neither compliant nor remediated
+const ComplianceReasonUnknown = "Unknown"
+
+// Reasons a workload is non-compliant
+const (
+	NonComplianceReasonDeniedDigest        = "DeniedDigest"
+	NonComplianceReasonOutdatedDigest      = "OutdatedDigest"
+	NonComplianceReasonTagBased            = "TagBased"
+	NonComplianceReasonLatestDigestUnknown = "LatestDigestUnknown"
+	NonComplianceReasonAttestationFailed   = "AttestationFailed"
+	NonComplianceReasonDigestNotFound      = "DigestNotFound"
+	NonComplianceReasonDisallowedRegistry  = "DisallowedRegistry"
+
+	// NonComplianceReasonRemediationInProgress is a remediated workload whose controller hasn't finished
+	// rolling the remediated pod template out
+	NonComplianceReasonRemediationInProgress = "RemediationInProgress"
+)
+
+// Remediation modes
+const (
+	RemediationModeOff   = "Off"
+	RemediationModeAudit = "Audit"
+	RemediationModeAuto  = "Auto"
+)
+
+// Digest types
+const (
+	DigestTypeManifest = "Manifest"
+	DigestTypeConfig   = "Config"
+)
+
+// Automation gate sources
+const (
+	AutomationGateSourceLabel      = "Label"
+	AutomationGateSourceAnnotation = "Annotation"
+)
+
+// Attestation sources
+const (
+	AttestationSourceRekor     = "Rekor"
+	AttestationSourceReferrers = "Referrers"
+)
+
+// Attestation enforcement modes
+const (
+	AttestationEnforcementEnforce = "Enforce"
+	AttestationEnforcementWarn    = "Warn"
+)
+
+// Attestation failure modes
+const (
+	AttestationFailureModeOpen   = "Open"
+	AttestationFailureModeClosed = "Closed"
+)
+
+// Vulnerability severities a MaxVulnerabilitySeverity can be set to, from the lowest
+const (
+	VulnerabilitySeverityLow      = "Low"
+	VulnerabilitySeverityMedium   = "Medium"
+	VulnerabilitySeverityHigh     = "High"
+	VulnerabilitySeverityCritical = "Critical"
+)
+
+// Kinds of object a FulcioRootRef can reference
+const (
+	FulcioRootKindConfigMap = "ConfigMap"
+	FulcioRootKindSecret    = "Secret"
+)
+
+// Remediation strategies
+const (
+	RemediationStrategyInCluster = "InCluster"
+	RemediationStrategyGitOps    = "GitOps"
+)
+
+// Notification events sent to NotificationConfig webhooks
+const (
+	NotificationEventNonCompliant      = "NonCompliant"
+	NotificationEventAutoRemediated    = "AutoRemediated"
+	NotificationEventAttestationFailed = "AttestationFailed"
+)
+
+// Finalizer, labels and annotations used by the controller
+const (
+	// ImagePolicyFinalizer is added to ImagePolicies with RevertOnDelete so remediated workloads can be reverted
+	ImagePolicyFinalizer = "security.chainguard.dev/imagepolicy"
+
+	// AnnotationOriginalImage records the pre-remediation images of a workload as a JSON object of container name to image
+	AnnotationOriginalImage = "security.chainguard.dev/original-image"
+
+	// AnnotationRemediatedBy records the namespace/name of the ImagePolicy that remediated a workload
+	AnnotationRemediatedBy = "security.chainguard.dev/remediated-by"
+
+	// AnnotationOriginalPullPolicy records the pre-remediation imagePullPolicy of each container whose pull policy
+	// PinPullPolicy changed, as a JSON object of container name to pull policy
+	AnnotationOriginalPullPolicy = "security.chainguard.dev/original-pull-policy"
+
+	// LabelIgnore set to "true" on a workload opts it out of every ImagePolicy: it isn't monitored, counted,
+	// remediated or checked on admission
+	LabelIgnore = "security.chainguard.dev/ignore"
+
+	// AnnotationSkipContainers is a comma-separated list of container names excluded from compliance and remediation
+	AnnotationSkipContainers = "security.chainguard.dev/skip-containers"
+
+	// AnnotationPaused set to "true" on an ImagePolicy suspends its reconciliation until removed
+	AnnotationPaused = "security.chainguard.dev/paused"
+
+	// AnnotationComplianceOverride on a workload marks it compliant, whatever its images, so it isn't remediated
+	// during an incident. The value is the reason, reported in its status message
+	AnnotationComplianceOverride = "security.chainguard.dev/compliance-override"
+
+	// AnnotationComplianceOverrideExpires is an RFC 3339 time the AnnotationComplianceOverride lapses at
+	AnnotationComplianceOverrideExpires = "security.chainguard.dev/compliance-override-expires"
+)
+
+// Annotations on NonCompliantImage events describing the digest drift
+const (
+	// EventAnnotationCurrentDigest is the digest the workload is using
+	EventAnnotationCurrentDigest = "security.chainguard.dev/current-digest"
+
+	// EventAnnotationLatestDigest is the latest digest of the workload's repository
+	EventAnnotationLatestDigest = "security.chainguard.dev/latest-digest"
+
+	// EventAnnotationStaleSince is when the workload was first seen on its outdated digest (RFC 3339)
+	EventAnnotationStaleSince = "security.chainguard.dev/stale-since"
+
+	// EventAnnotationStaleForSeconds is how long the workload has been on its outdated digest, in seconds
+	EventAnnotationStaleForSeconds = "security.chainguard.dev/stale-for-seconds"
+)
+
 // Condition types
 const (
 	ConditionTypeReady       = "Ready"
 	ConditionTypeProgressing = "Progressing"
 	ConditionTypeDegraded    = "Degraded"
+
+	// ConditionTypeAttestationVerified summarizes attestation checks, set when attestations are required
+	ConditionTypeAttestationVerified = "AttestationVerified"
+
+	// ConditionTypeDriftSLOViolated is true while a workload is non-compliant for longer than MaxDriftDuration
+	ConditionTypeDriftSLOViolated = "DriftSLOViolated"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // ImagePolicySpec defines the desired state of ImagePolicy
+// +kubebuilder:validation:XValidation:rule="has(self.repository) || (has(self.repositories) && size(self.repositories) > 0) || has(self.repositoryPattern)",message="repository, repositories or repositoryPattern must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.remediationStrategy) || self.remediationStrategy != 'GitOps' || has(self.gitRepoRef)",message="gitRepoRef is required for the GitOps remediation strategy"
+// +kubebuilder:validation:XValidation:rule="!has(self.tags) || !has(self.tagSemverRange)",message="tags and tagSemverRange are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!has(self.goldenDigestRef) || (!has(self.tags) && !has(self.tagSemverRange))",message="goldenDigestRef can't be combined with tags or tagSemverRange"
+// +kubebuilder:validation:XValidation:rule="!has(self.mutateTagsToDigests) || !self.mutateTagsToDigests || !has(self.digestType) || self.digestType != 'Config'",message="mutateTagsToDigests can't pin images to Config digests"
 type ImagePolicySpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
-	// Repository specifies the DockerHub repository to monitor (e.g., "jonlimpw/demo-app")
-	// +kubebuilder:validation:Required
-	// +kubebuilder:validation:Pattern=`^[a-z0-9]+(?:[._-][a-z0-9]+)*\/[a-z0-9]+(?:[._-][a-z0-9]+)*$`
-	Repository string `json:"repository"`
+	// Repository specifies the DockerHub repository to monitor (e.g., "jonlimpw/demo-app", or "nginx" for library/nginx)
+	// Kept for backward compatibility; it is monitored alongside any Repositories
+	// +kubebuilder:validation:Pattern=`^(?:[a-z0-9]+(?:[._-][a-z0-9]+)*\/)?[a-z0-9]+(?:[._-][a-z0-9]+)*$`
+	// +optional
+	Repository string `json:"repository,omitempty"`
+
+	// Repositories specifies additional DockerHub repositories monitored with the same rules
+	// +kubebuilder:validation:items:Pattern=`^(?:[a-z0-9]+(?:[._-][a-z0-9]+)*\/)?[a-z0-9]+(?:[._-][a-z0-9]+)*$`
+	// +listType=set
+	// +optional
+	Repositories []string `json:"repositories,omitempty"`
+
+	// RepositoryPattern monitors every DockerHub repository matching the glob (e.g., "myorg/*") that a selected
+	// workload runs, each with its own latest digest. "*" doesn't match "/"; official images match as "library/<name>"
+	// +kubebuilder:validation:MaxLength=255
+	// +kubebuilder:validation:Pattern=`^[a-z0-9._*?\[\]^-]+(/[a-z0-9._*?\[\]^-]+)?$`
+	// +optional
+	RepositoryPattern string `json:"repositoryPattern,omitempty"`
+
+	// Tag specifies which tag of the repository to track for the latest digest (default: "latest")
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_][a-zA-Z0-9._-]{0,127}$`
+	// +kubebuilder:default=latest
+	// +optional
+	Tag string `json:"tag,omitempty"`
+
+	// Tags tracks several tags at once, taking precedence over Tag: a digest matching the latest digest of any
+	// of them is compliant. The first tag is the primary one, whose digest is LatestDigest and remediation applies
+	// +kubebuilder:validation:MaxItems=10
+	// +kubebuilder:validation:items:Pattern=`^[a-zA-Z0-9_][a-zA-Z0-9._-]{0,127}$`
+	// +listType=set
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+
+	// TagSemverRange tracks the highest tag within a semver range (e.g., ">=1.2.0 <2.0.0") instead of Tag.
+	// Tags are listed from the registry and pre-release tags are ignored
+	// +optional
+	TagSemverRange string `json:"tagSemverRange,omitempty"`
+
+	// GoldenDigestRef sources the target digest from a ConfigMap maintained by a promotion workflow instead of
+	// the registry: compliance is measured against the promoted digest and remediation pins to it. DockerHub
+	// isn't asked for the latest digest while it is set, so Tag and Platform are ignored, and Tags and
+	// TagSemverRange can't be set
+	// +optional
+	GoldenDigestRef *GoldenDigestRef `json:"goldenDigestRef,omitempty"`
+
+	// Platform selects the platform-specific digest from a multi-arch image (e.g., "linux/amd64")
+	// If empty, the top-level manifest list/index digest is used
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]+\/[a-z0-9_]+(?:\/[a-z0-9]+)?$`
+	// +optional
+	Platform string `json:"platform,omitempty"`
+
+	// PullSecretRef references a kubernetes.io/dockerconfigjson Secret with credentials for private repositories
+	// The namespace must be empty or the ImagePolicy namespace, which is used when empty
+	// +optional
+	PullSecretRef *corev1.SecretReference `json:"pullSecretRef,omitempty"`
+
+	// RegistryMirror resolves digests through a DockerHub mirror or pull-through cache instead of registry-1.docker.io,
+	// keeping the repository path (e.g., "harbor.example.com/dockerhub-proxy"). Overrides the manager's --registry-mirror
+	// It's queried anonymously: pullSecretRef credentials are only sent to the manager's --registry-mirror
+	// +kubebuilder:validation:Pattern=`^(https?://)?[a-zA-Z0-9.-]+(:[0-9]+)?(/[a-z0-9._-]+)*$`
+	// +optional
+	RegistryMirror string `json:"registryMirror,omitempty"`
+
+	// RegistryProxy is the HTTP(S) proxy registry requests go through (e.g., "http://proxy.example.com:3128"), for
+	// egress-restricted networks. Hosts in the manager's NO_PROXY are reached directly. Overrides the manager's --registry-proxy
+	// +kubebuilder:validation:Pattern=`^https?://([^:@/]+(:[^@/]*)?@)?[a-zA-Z0-9.-]+(:[0-9]+)?/?$`
+	// +optional
+	RegistryProxy string `json:"registryProxy,omitempty"`
 
 	// NamespaceSelector specifies which namespaces to monitor for deployments
 	// If empty, monitors all namespaces
 	// +optional
 	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
 
+	// ExcludeNamespaces lists namespaces never monitored, applied after NamespaceSelector
+	// +listType=set
+	// +optional
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
+
+	// ExcludeSystemNamespaces when true, also excludes kube-system, kube-public and kube-node-lease
+	// +kubebuilder:default=false
+	// +optional
+	ExcludeSystemNamespaces bool `json:"excludeSystemNamespaces,omitempty"`
+
 	// DeploymentSelector specifies which deployments to monitor within selected namespaces
 	// If empty, monitors all deployments
 	// +optional
 	DeploymentSelector *metav1.LabelSelector `json:"deploymentSelector,omitempty"`
 
-	// CheckIntervalSeconds defines how often to check for new image digests (default: 60)
+	// ContainerName restricts compliance and remediation to the container with this name in each
+	// selected workload; other containers are left untouched. If empty, every container using a
+	// monitored repository is governed
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +optional
+	ContainerName string `json:"containerName,omitempty"`
+
+	// CheckIntervalSeconds defines how often to check for new image digests (default: the manager's
+	// --default-check-interval, 60 unless set)
 	// +kubebuilder:validation:Minimum=10
 	// +kubebuilder:validation:Maximum=3600
-	// +kubebuilder:default=60
 	// +optional
 	CheckIntervalSeconds *int32 `json:"checkIntervalSeconds,omitempty"`
 
+	// MaxDriftDuration is how long a workload may stay non-compliant (e.g., "72h") before the policy reports
+	// the DriftSLOViolated condition and a warning event
+	// +optional
+	MaxDriftDuration *string `json:"maxDriftDuration,omitempty"`
+
+	// NamespaceAnalysisTimeout bounds the time (e.g., "30s") the workloads of each namespace may take to be
+	// analyzed, from when the first of them starts. Workloads of a namespace exceeding it are reported with
+	// reason Unknown instead of holding up the reconcile; namespaces are unbounded when unset
+	// +optional
+	NamespaceAnalysisTimeout *string `json:"namespaceAnalysisTimeout,omitempty"`
+
 	// EnforceLatestDigest when true, marks deployments as non-compliant if not using latest digest
-	// +kubebuilder:default=true
+	// (default: the manager's --default-enforce-latest, true unless set)
 	// +optional
 	EnforceLatestDigest *bool `json:"enforceLatestDigest,omitempty"`
 
+	// AllowedDigests lists approved digests (e.g. a frozen release) that are compliant even when they
+	// aren't the latest digest. The allowlist takes precedence over EnforceLatestDigest, but attestation
+	// requirements still apply, and auto-remediation never replaces an allowed digest
+	// +kubebuilder:validation:items:Pattern=`^(sha256:[a-f0-9]{64}|sha512:[a-f0-9]{128})$`
+	// +listType=set
+	// +optional
+	AllowedDigests []string `json:"allowedDigests,omitempty"`
+
+	// DeniedDigests lists known-vulnerable digests that are always non-compliant, even when they are the
+	// latest digest or in AllowedDigests. In Auto remediation mode they are replaced with the latest digest
+	// even if EnforceLatestDigest is false
+	// +kubebuilder:validation:items:Pattern=`^(sha256:[a-f0-9]{64}|sha512:[a-f0-9]{128})$`
+	// +listType=set
+	// +optional
+	DeniedDigests []string `json:"deniedDigests,omitempty"`
+
+	// AllowedRegistries lists the registry hosts (e.g. "docker.io", "cgr.dev", "registry.example.com:5000")
+	// the governed containers of monitored workloads may pull from. Workloads with a container from any other
+	// registry are non-compliant, whatever their digests; all registries are allowed when empty
+	// +kubebuilder:validation:items:Pattern=`^[a-z0-9]([a-z0-9.-]*[a-z0-9])?(:[0-9]+)?$`
+	// +listType=set
+	// +optional
+	AllowedRegistries []string `json:"allowedRegistries,omitempty"`
+
+	// VerifyDigestExists when true, checks that each workload's digest still exists in the registry and
+	// marks workloads whose digest was deleted or never existed as non-compliant (DigestNotFound)
+	// +optional
+	VerifyDigestExists *bool `json:"verifyDigestExists,omitempty"`
+
+	// MutateTagsToDigests when true, pins workloads using a monitored repository by tag to the digest the tag
+	// currently resolves to (the latest digest when enforcing it) instead of only flagging them. Tag-based
+	// workloads are non-compliant (TagBased) even when not enforcing the latest digest, and are only pinned
+	// when they pass the AutomationGate and the RemediationMode allows it
+	// +optional
+	MutateTagsToDigests *bool `json:"mutateTagsToDigests,omitempty"`
+
+	// PinPullPolicy when true, sets imagePullPolicy to IfNotPresent on the containers remediation pins to a
+	// digest. A digest is immutable, so Always only adds a registry round trip on every pod start; off by
+	// default for users who rely on Always, and containers remediation leaves alone are never changed
+	// +optional
+	PinPullPolicy *bool `json:"pinPullPolicy,omitempty"`
+
+	// DigestType selects the digest workloads are compared against: Manifest (default) compares the
+	// manifest digest, Config the config digest (image ID) read from the manifest of the tracked tag, for
+	// Platform if it is multi-arch. Images can't be pulled by their config digest, so workloads aren't
+	// remediated and VerifyDigestExists is ignored with Config
+	// +kubebuilder:validation:Enum=Manifest;Config
+	// +optional
+	DigestType string `json:"digestType,omitempty"`
+
+	// RemediationMode controls what happens to non-compliant workloads passing the AutomationGate:
+	// Off never remediates, Audit reports the digest it would apply without changing anything,
+	// and Auto updates the workload to the latest digest
+	// +kubebuilder:validation:Enum=Off;Audit;Auto
+	// +kubebuilder:default=Auto
+	// +optional
+	RemediationMode string `json:"remediationMode,omitempty"`
+
+	// AutomationGate selects the label or annotation that opts a workload into remediation
+	// (default: the label automation=true)
+	// +optional
+	AutomationGate *AutomationGate `json:"automationGate,omitempty"`
+
+	// RemediationStrategy controls how Auto remediation is applied: InCluster updates the live workload,
+	// GitOps opens a pull request bumping the image in GitRepoRef so ArgoCD/Flux roll it out
+	// +kubebuilder:validation:Enum=InCluster;GitOps
+	// +kubebuilder:default=InCluster
+	// +optional
+	RemediationStrategy string `json:"remediationStrategy,omitempty"`
+
+	// GitRepoRef is the Git repository holding the workload manifests, required for the GitOps strategy
+	// +optional
+	GitRepoRef *GitRepoRef `json:"gitRepoRef,omitempty"`
+
+	// RemediationHistoryLimit is the number of most recent remediations kept in the status (default: 20)
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default=20
+	// +optional
+	RemediationHistoryLimit *int32 `json:"remediationHistoryLimit,omitempty"`
+
+	// MaxMonitoredDeployments is the number of workloads whose status is listed in status.monitoredDeployments
+	// (default: 500). Non-compliant workloads are listed first; the totals still count every workload.
+	// The cap keeps the policy under the etcd object size limit (1.5MiB by default) when it covers thousands of workloads.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=500
+	// +optional
+	MaxMonitoredDeployments *int32 `json:"maxMonitoredDeployments,omitempty"`
+
+	// RemediationCooldown is the minimum time (e.g., "15m") between in-cluster remediations of the same
+	// workload, so a workload reverted by another controller isn't updated on every reconcile
+	// +optional
+	RemediationCooldown *string `json:"remediationCooldown,omitempty"`
+
+	// RemediationWindow restricts in-cluster remediation to a maintenance window. Outside it non-compliance
+	// is still reported, but remediation is deferred until the window opens
+	// +optional
+	RemediationWindow *RemediationWindow `json:"remediationWindow,omitempty"`
+
+	// MaxConcurrentRemediations bounds how many monitored workloads may be rolling out at once. Once that many
+	// are rolling out, in-cluster remediation of the others waits for their rollouts to complete; unlimited when unset
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConcurrentRemediations *int32 `json:"maxConcurrentRemediations,omitempty"`
+
+	// HonorPodDisruptionBudgets when true, defers in-cluster remediation of a workload whose pods are covered by
+	// a PodDisruptionBudget that allows no further disruptions. Otherwise the remediation goes ahead with a warning event
+	// +optional
+	HonorPodDisruptionBudgets *bool `json:"honorPodDisruptionBudgets,omitempty"`
+
+	// RevertOnDelete when true, reverts remediated workloads to their original image references when the policy is deleted
+	// +kubebuilder:default=false
+	// +optional
+	RevertOnDelete bool `json:"revertOnDelete,omitempty"`
+
+	// BlockOnAdmission when true, rejects Deployment creates and updates in selected namespaces whose image
+	// isn't the latest digest (or lacks a valid attestation when required). Admission is allowed when the
+	// latest digest is not yet known
+	// +kubebuilder:default=false
+	// +optional
+	BlockOnAdmission bool `json:"blockOnAdmission,omitempty"`
+
 	// AttestationPolicy defines requirements for cryptographic attestations
 	// +optional
 	AttestationPolicy *AttestationPolicy `json:"attestationPolicy,omitempty"`
+
+	// NotificationConfig sends a webhook notification when a workload's compliance state changes
+	// +optional
+	NotificationConfig *NotificationConfig `json:"notificationConfig,omitempty"`
+}
+
+// MonitoredRepositories returns Repository followed by Repositories, without duplicates
+func (s *ImagePolicySpec) MonitoredRepositories() []string {
+	var repositories []string
+	seen := map[string]bool{}
+	for _, repository := range append([]string{s.Repository}, s.Repositories...) {
+		if repository == "" || seen[repository] {
+			continue
+		}
+		seen[repository] = true
+		repositories = append(repositories, repository)
+	}
+	return repositories
+}
+
+// AutomationGate identifies workloads opted into remediation by a label or annotation
+type AutomationGate struct {
+	// Key of the label or annotation (e.g., "example.com/auto-update")
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=317
+	// +kubebuilder:validation:Pattern=`^([a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*/)?[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`
+	Key string `json:"key"`
+
+	// Value the label or annotation must have (default: "true")
+	// +kubebuilder:default="true"
+	// +optional
+	Value string `json:"value,omitempty"`
+
+	// Source is where Key is looked up: Label or Annotation
+	// +kubebuilder:validation:Enum=Label;Annotation
+	// +kubebuilder:default=Label
+	// +optional
+	Source string `json:"source,omitempty"`
+}
+
+// GitRepoRef references the manifest updated by GitOps remediation
+type GitRepoRef struct {
+	// URL of the GitHub or GitHub Enterprise repository (e.g., "https://github.com/org/deploy")
+	// +kubebuilder:validation:Pattern=`^https://[^/]+/[^/]+/[^/]+$`
+	URL string `json:"url"`
+
+	// Branch the pull requests target (default: "main")
+	// +kubebuilder:default=main
+	// +optional
+	Branch string `json:"branch,omitempty"`
+
+	// Path of the manifest file within the repository; every image of a monitored repository in it is bumped
+	// +kubebuilder:validation:MinLength=1
+	Path string `json:"path"`
+
+	// SecretRef references a Secret with an API token under the "token" key
+	// The namespace must be empty or the ImagePolicy namespace, which is used when empty
+	SecretRef corev1.SecretReference `json:"secretRef"`
+}
+
+// NotificationConfig configures webhook notifications (Slack incoming webhooks or any JSON endpoint)
+type NotificationConfig struct {
+	// SecretRef references a Secret with the webhook URL under the "address" key
+	// The namespace must be empty or the ImagePolicy namespace, which is used when empty
+	SecretRef corev1.SecretReference `json:"secretRef"`
+
+	// Events to notify on; all events are sent when empty
+	// +listType=set
+	// +kubebuilder:validation:items:Enum=NonCompliant;AutoRemediated;AttestationFailed
+	// +optional
+	Events []string `json:"events,omitempty"`
 }
 
 // AttestationPolicy defines the attestation verification requirements
@@ -82,10 +533,39 @@ type AttestationPolicy struct {
 	// +optional
 	RequireAttestation *bool `json:"requireAttestation,omitempty"`
 
+	// RequireSBOM when true, marks digest-pinned deployments as non-compliant unless an SPDX or CycloneDX
+	// attestation exists for their digest, from the same source and issuers as other attestations
+	// +optional
+	RequireSBOM bool `json:"requireSBOM,omitempty"`
+
+	// MaxVulnerabilitySeverity when set, marks digest-pinned deployments as non-compliant unless a vulnerability
+	// scan attestation (cosign's vuln predicate, e.g. from trivy or grype) exists for their digest, from the same
+	// source and issuers as other attestations, and reports no finding of this severity or higher
+	// +kubebuilder:validation:Enum=Low;Medium;High;Critical
+	// +optional
+	MaxVulnerabilitySeverity string `json:"maxVulnerabilitySeverity,omitempty"`
+
+	// ResolveTags when true, verifies the attestations of tag-based images against the digest their tag
+	// currently resolves to in the registry, instead of failing verification. Tag-based images remain
+	// non-compliant when EnforceLatestDigest is set
+	// +optional
+	ResolveTags bool `json:"resolveTags,omitempty"`
+
 	// AllowedIssuers specifies the allowed OIDC issuers for attestation certificates
 	// +optional
 	AllowedIssuers []string `json:"allowedIssuers,omitempty"`
 
+	// AllowedSANs specifies the accepted subject alternative names of attestation certificates, e.g. the
+	// GitHub workflow "https://github.com/org/repo/.github/workflows/release.yml@refs/heads/main". Entries
+	// ending in "*" match as a prefix, so "https://github.com/org/repo/*" accepts any workflow of the repository
+	// +optional
+	AllowedSANs []string `json:"allowedSANs,omitempty"`
+
+	// AllowedBuildConfigURIs specifies the accepted build configs (e.g. GitHub workflow at a ref) recorded in
+	// attestation certificates. Entries ending in "*" match as a prefix
+	// +optional
+	AllowedBuildConfigURIs []string `json:"allowedBuildConfigURIs,omitempty"`
+
 	// RequiredTypes specifies the required attestation types (e.g., "slsaprovenance")
 	// +optional
 	RequiredTypes []string `json:"requiredTypes,omitempty"`
@@ -93,6 +573,189 @@ type AttestationPolicy struct {
 	// MaxAge specifies the maximum age of attestations to accept (e.g., "24h")
 	// +optional
 	MaxAge *string `json:"maxAge,omitempty"`
+
+	// MinSLSALevel specifies the minimum SLSA build level (0-3) of the image's provenance.
+	// Level 1 requires SLSA provenance, level 2 a named builder with a Fulcio-signed attestation,
+	// and level 3 additionally a known isolated builder with all materials pinned by digest.
+	// Attestations that aren't SLSA provenance are treated as level 0.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=3
+	// +optional
+	MinSLSALevel int32 `json:"minSLSALevel,omitempty"`
+
+	// RekorURL overrides the controller's Rekor server for this policy (e.g., a private transparency log)
+	// +kubebuilder:validation:Pattern=`^https?://[^/]+`
+	// +optional
+	RekorURL string `json:"rekorURL,omitempty"`
+
+	// RekorPublicKeyRef references the PEM public key RekorURL's entries are verified against. It's required
+	// for a RekorURL other than the manager's --rekor-url and the public Sigstore instance
+	// +optional
+	RekorPublicKeyRef *RekorPublicKeyRef `json:"rekorPublicKeyRef,omitempty"`
+
+	// FulcioRootRef references a PEM bundle of Fulcio CA certificates (e.g. a private Sigstore deployment's)
+	// that attestation signing certificates must chain to. Unset, they must chain to the public-good Fulcio root
+	// +optional
+	FulcioRootRef *FulcioRootRef `json:"fulcioRootRef,omitempty"`
+
+	// TUFMirror fetches the Fulcio root bundle from a TUF repository instead of FulcioRootRef, e.g. a private
+	// Sigstore deployment's or a mirror of one reachable from an air-gapped cluster
+	// +optional
+	TUFMirror *TUFMirror `json:"tufMirror,omitempty"`
+
+	// AttestationSource selects where attestations are discovered: the Rekor transparency log, or the
+	// registry's OCI referrers API (/v2/<repo>/referrers/<digest>) for images whose attestations aren't in Rekor
+	// +kubebuilder:validation:Enum=Rekor;Referrers
+	// +kubebuilder:default=Rekor
+	// +optional
+	AttestationSource string `json:"attestationSource,omitempty"`
+
+	// Enforcement controls what failing attestations do: Enforce makes the workload non-compliant, Warn
+	// still verifies and reports them in the status but only emits an AttestationWarning event, to measure
+	// coverage before enforcing
+	// +kubebuilder:validation:Enum=Enforce;Warn
+	// +kubebuilder:default=Enforce
+	// +optional
+	Enforcement string `json:"enforcement,omitempty"`
+
+	// FailureMode controls what an attestation check that couldn't be completed, because Rekor (or the
+	// registry for the Referrers source) was unreachable or its client isn't up, does: Closed treats it as
+	// failed, Open leaves compliance to the digest checks and reports it in the AttestationVerified condition
+	// +kubebuilder:validation:Enum=Open;Closed
+	// +kubebuilder:default=Closed
+	// +optional
+	FailureMode string `json:"failureMode,omitempty"`
+}
+
+// RemediationWindow is a daily time range, optionally limited to some days of the week
+type RemediationWindow struct {
+	// Start is the time of day the window opens, as HH:MM
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+
+	// End is the time of day the window closes, as HH:MM. An end before the start closes it the next day,
+	// e.g. 22:00-04:00
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end"`
+
+	// Days are the days of the week the window opens on (Mon, Tue, Wed, Thu, Fri, Sat, Sun), every day when empty
+	// +kubebuilder:validation:items:Enum=Mon;Tue;Wed;Thu;Fri;Sat;Sun
+	// +optional
+	Days []string `json:"days,omitempty"`
+
+	// TimeZone is the IANA time zone of Start and End, e.g. "Europe/Berlin"
+	// +kubebuilder:default=UTC
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// FulcioRootRef references a PEM bundle of Fulcio root and intermediate certificates
+type FulcioRootRef struct {
+	// Kind is the kind of object holding the bundle
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	// +kubebuilder:default=ConfigMap
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Name is the name of the ConfigMap or Secret
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the ConfigMap or Secret
+	// It must be empty or the ImagePolicy namespace, which is used when empty
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Key is the data key holding the PEM bundle
+	// +kubebuilder:default="fulcio.crt.pem"
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// TUFMirror is a TUF repository serving a PEM bundle of Fulcio CA certificates as a target
+type TUFMirror struct {
+	// URL is the base URL of the repository metadata (root.json, timestamp.json, ...); targets are
+	// fetched from <url>/targets
+	// +kubebuilder:validation:Pattern=`^https?://[^/]+`
+	URL string `json:"url"`
+
+	// RootRef references the trusted root.json the repository metadata is verified from. Newer roots
+	// the repository publishes are verified against it and then trusted in its place
+	RootRef TUFRootRef `json:"rootRef"`
+
+	// Target is the name of the target holding the PEM bundle
+	// +kubebuilder:default="fulcio.crt.pem"
+	// +optional
+	Target string `json:"target,omitempty"`
+}
+
+// TUFRootRef references the ConfigMap key holding a TUF repository's trusted root.json
+type TUFRootRef struct {
+	// Name is the name of the ConfigMap
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the ConfigMap
+	// It must be empty or the ImagePolicy namespace, which is used when empty
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Key is the data key holding the root metadata
+	// +kubebuilder:default="root.json"
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// RekorPublicKeyRef references the ConfigMap key holding a Rekor log's PEM public key
+type RekorPublicKeyRef struct {
+	// Name is the name of the ConfigMap
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the ConfigMap
+	// It must be empty or the ImagePolicy namespace, which is used when empty
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Key is the data key holding the public key
+	// +kubebuilder:default="rekor.pub"
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// GoldenDigestRef references the ConfigMap key holding the promoted digests of a policy's repositories
+type GoldenDigestRef struct {
+	// Name is the name of the ConfigMap
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the ConfigMap
+	// It must be empty or the ImagePolicy namespace, which is used when empty
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Key is the data key holding the promoted digest. Its value is a digest (sha256:...) every monitored
+	// repository is held to, or one image reference per line ([docker.io/]<repository>@<digest>, e.g. a
+	// policy's status.resolvedImage) for policies monitoring several repositories
+	// +kubebuilder:default="digest"
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// RequiresVerification reports whether attestations must be verified: RequireAttestation, RequireSBOM or
+// MaxVulnerabilitySeverity is set
+func (p *AttestationPolicy) RequiresVerification() bool {
+	return p != nil && ((p.RequireAttestation != nil && *p.RequireAttestation) || p.RequireSBOM || p.MaxVulnerabilitySeverity != "")
+}
+
+// Enforced reports whether failing attestations make workloads non-compliant, i.e. Enforcement isn't Warn
+func (p *AttestationPolicy) Enforced() bool {
+	return p == nil || p.Enforcement != AttestationEnforcementWarn
+}
+
+// FailsOpen reports whether attestation checks that couldn't be completed are ignored, i.e. FailureMode is Open
+func (p *AttestationPolicy) FailsOpen() bool {
+	return p != nil && p.FailureMode == AttestationFailureModeOpen
 }
 
 // ImagePolicyStatus defines the observed state of ImagePolicy.
@@ -100,20 +763,51 @@ type ImagePolicyStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
-	// LatestDigest contains the most recent digest found for the monitored repository
+	// LatestDigest contains the most recent digest found for the first monitored repository
 	// +optional
 	LatestDigest string `json:"latestDigest,omitempty"`
 
-	// LastChecked timestamp of the last successful check against DockerHub
+	// LastChecked timestamp of the last successful check against DockerHub for the first monitored repository
 	// +optional
 	LastChecked *metav1.Time `json:"lastChecked,omitempty"`
 
+	// ResolvedTag is the tag chosen by TagSemverRange for the first monitored repository
+	// +optional
+	ResolvedTag string `json:"resolvedTag,omitempty"`
+
+	// ResolvedImage is the fully qualified image reference (docker.io/<repository>@<digest>) enforced for the
+	// first monitored repository. It is empty while the latest digest is unknown
+	// +optional
+	ResolvedImage string `json:"resolvedImage,omitempty"`
+
+	// Paused is true while reconciliation is suspended by the security.chainguard.dev/paused annotation
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// Repositories tracks the latest digest and compliance of each monitored repository
+	// +listType=map
+	// +listMapKey=repository
+	// +optional
+	Repositories []RepositoryStatus `json:"repositories,omitempty"`
+
 	// ComplianceStatus summarizes the overall compliance state
 	// +kubebuilder:validation:Enum=Compliant;NonCompliant;Unknown;Error
 	// +optional
 	ComplianceStatus string `json:"complianceStatus,omitempty"`
 
-	// MonitoredDeployments tracks deployments being monitored by this policy
+	// AttestationStatus summarizes attestation checks (e.g., "3/4 verified"), or "N/A" when attestations aren't required
+	// +optional
+	AttestationStatus string `json:"attestationStatus,omitempty"`
+
+	// CheckedAttestations is the count of monitored workloads whose attestations were checked
+	// +optional
+	CheckedAttestations int32 `json:"checkedAttestations,omitempty"`
+
+	// VerifiedAttestations is the count of those workloads with a valid attestation
+	// +optional
+	VerifiedAttestations int32 `json:"verifiedAttestations,omitempty"`
+
+	// MonitoredDeployments tracks workloads (Deployments, StatefulSets, DaemonSets, CronJobs and Jobs) being monitored by this policy
 	// +optional
 	MonitoredDeployments []DeploymentStatus `json:"monitoredDeployments,omitempty"`
 
@@ -125,6 +819,44 @@ type ImagePolicyStatus struct {
 	// +optional
 	CompliantDeployments int32 `json:"compliantDeployments,omitempty"`
 
+	// OmittedDeployments is the count of monitored workloads left out of MonitoredDeployments by MaxMonitoredDeployments
+	// +optional
+	OmittedDeployments int32 `json:"omittedDeployments,omitempty"`
+
+	// RemediationHistory records the most recent auto-remediations, newest first
+	// +optional
+	RemediationHistory []RemediationRecord `json:"remediationHistory,omitempty"`
+
+	// ConsecutiveFailures counts the reconciles in a row that failed to fetch a digest or write the status,
+	// reset by the next successful one
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+
+	// LastError is the error of the last failed reconcile, cleared with ConsecutiveFailures
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+
+	// RegistryRequestCount is the number of DockerHub requests (tokens, manifests, tags and referrers) reconciles
+	// of this policy made since RegistryRequestWindowStart. Digests served from the cache aren't requests
+	// +optional
+	RegistryRequestCount int32 `json:"registryRequestCount,omitempty"`
+
+	// RegistryRequestWindowStart is when RegistryRequestCount started counting; it restarts every hour
+	// +optional
+	RegistryRequestWindowStart *metav1.Time `json:"registryRequestWindowStart,omitempty"`
+
+	// RegistryLastLatencyMillis is how long the last DockerHub request of this policy took to respond, in milliseconds
+	// +optional
+	RegistryLastLatencyMillis int64 `json:"registryLastLatencyMillis,omitempty"`
+
+	// RegistryLast429 is when DockerHub last rate limited (HTTP 429) a request of this policy
+	// +optional
+	RegistryLast429 *metav1.Time `json:"registryLast429,omitempty"`
+
+	// ObservedGeneration is the metadata.generation of the spec the last complete reconcile applied
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
 	// conditions represent the current state of the ImagePolicy resource.
 	// +listType=map
 	// +listMapKey=type
@@ -132,20 +864,132 @@ type ImagePolicyStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
-// DeploymentStatus tracks the compliance status of a specific deployment
+// RemediationRecord records a workload updated by auto-remediation
+type RemediationRecord struct {
+	// Kind of the workload (Deployment, StatefulSet, DaemonSet or CronJob)
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Name of the workload
+	Name string `json:"name"`
+
+	// Namespace of the workload
+	Namespace string `json:"namespace"`
+
+	// OldDigest is the digest the workload was using before remediation
+	// +optional
+	OldDigest string `json:"oldDigest,omitempty"`
+
+	// NewDigest is the digest the workload was remediated to
+	NewDigest string `json:"newDigest"`
+
+	// PullRequestURL is the pull request proposing the change, for the GitOps strategy
+	// +optional
+	PullRequestURL string `json:"pullRequestURL,omitempty"`
+
+	// Timestamp when the remediation was applied
+	Timestamp metav1.Time `json:"timestamp"`
+}
+
+// RepositoryStatus tracks the latest digest and compliance of a monitored repository
+type RepositoryStatus struct {
+	// Repository is the monitored DockerHub repository
+	Repository string `json:"repository"`
+
+	// LatestDigest contains the most recent digest found for the repository
+	// +optional
+	LatestDigest string `json:"latestDigest,omitempty"`
+
+	// LastChecked timestamp of the last successful check against DockerHub
+	// +optional
+	LastChecked *metav1.Time `json:"lastChecked,omitempty"`
+
+	// ResolvedTag is the tag chosen by TagSemverRange whose digest is LatestDigest
+	// +optional
+	ResolvedTag string `json:"resolvedTag,omitempty"`
+
+	// ResolvedImage is the fully qualified image reference (docker.io/<repository>@<digest>) of LatestDigest
+	// +optional
+	ResolvedImage string `json:"resolvedImage,omitempty"`
+
+	// Tags lists the latest digest of each of the policy's Tags, primary first
+	// +optional
+	Tags []TagStatus `json:"tags,omitempty"`
+
+	// NotFound is set when DockerHub reported that the repository or tracked tag doesn't exist.
+	// Such repositories are checked again every 10 minutes, or CheckIntervalSeconds if longer
+	// +optional
+	NotFound bool `json:"notFound,omitempty"`
+
+	// TotalDeployments is the count of monitored workloads using the repository
+	// +optional
+	TotalDeployments int32 `json:"totalDeployments,omitempty"`
+
+	// CompliantDeployments is the count of those workloads using the latest digest
+	// +optional
+	CompliantDeployments int32 `json:"compliantDeployments,omitempty"`
+}
+
+// TagStatus tracks the latest digest of one of the tags a policy tracks
+type TagStatus struct {
+	// Tag is the tracked tag
+	Tag string `json:"tag"`
+
+	// LatestDigest is the digest the tag resolved to, empty if it couldn't be resolved
+	// +optional
+	LatestDigest string `json:"latestDigest,omitempty"`
+}
+
+// DeploymentStatus tracks the compliance status of a specific workload
 type DeploymentStatus struct {
+	// Kind of the workload (Deployment, StatefulSet, DaemonSet, CronJob or Job)
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet;DaemonSet;CronJob;Job
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
 	// Name of the deployment
 	Name string `json:"name"`
 
 	// Namespace of the deployment
 	Namespace string `json:"namespace"`
 
+	// Repository is the monitored repository the status was determined from
+	// +optional
+	Repository string `json:"repository,omitempty"`
+
 	// CurrentDigest is the digest currently used by the deployment
 	CurrentDigest string `json:"currentDigest"`
 
+	// ContainerName is the container the status was determined from (the first non-compliant one, if any)
+	// +optional
+	ContainerName string `json:"containerName,omitempty"`
+
+	// ContainerKind is the kind of ContainerName (Container, InitContainer or EphemeralContainer)
+	// +kubebuilder:validation:Enum=Container;InitContainer;EphemeralContainer
+	// +optional
+	ContainerKind string `json:"containerKind,omitempty"`
+
 	// IsCompliant indicates if the deployment is using the latest digest
 	IsCompliant bool `json:"isCompliant"`
 
+	// Reason is Compliant for a compliant deployment, Overridden for one forced compliant by the
+	// compliance-override annotation, or a machine-readable code for why it's non-compliant
+	// +kubebuilder:validation:Enum=Compliant;Overridden;DeniedDigest;OutdatedDigest;TagBased;LatestDigestUnknown;AttestationFailed;DigestNotFound;DisallowedRegistry;RemediationInProgress;Unknown
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message is a human-readable explanation of Reason
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// ProposedDigest is the digest the controller would remediate to in Audit mode
+	// +optional
+	ProposedDigest string `json:"proposedDigest,omitempty"`
+
+	// PinnedDigest is the digest a tag-based image is pinned to when the policy mutates tags to digests
+	// +optional
+	PinnedDigest string `json:"pinnedDigest,omitempty"`
+
 	// HasValidAttestation indicates if the deployment's image has valid attestations
 	// +optional
 	HasValidAttestation *bool `json:"hasValidAttestation,omitempty"`
@@ -154,10 +998,49 @@ type DeploymentStatus struct {
 	// +optional
 	AttestationDetails *AttestationDetails `json:"attestationDetails,omitempty"`
 
+	// LastNotified is the last non-compliant state a notification was sent for, so the same state
+	// isn't notified on every reconcile. It is cleared once the workload is compliant again
+	// +optional
+	LastNotified *NotifiedState `json:"lastNotified,omitempty"`
+
+	// StaleSince is when the workload was first seen on its current digest while a newer one was available.
+	// It is cleared once the workload uses the latest digest
+	// +optional
+	StaleSince *metav1.Time `json:"staleSince,omitempty"`
+
+	// NonCompliantSince is when the workload was first found non-compliant, for whatever reason.
+	// It is cleared once the workload is compliant again
+	// +optional
+	NonCompliantSince *metav1.Time `json:"nonCompliantSince,omitempty"`
+
+	// LastRemediated is when auto-remediation last updated the workload, for RemediationCooldown.
+	// It is cleared once the workload is compliant after the cooldown
+	// +optional
+	LastRemediated *metav1.Time `json:"lastRemediated,omitempty"`
+
+	// RemediationRollingOut is set by auto-remediation until the workload's controller has rolled the
+	// remediated pod template out (for Deployments, until the new ReplicaSet is available). Until then a
+	// workload on the remediated digests is RemediationInProgress rather than compliant
+	// +optional
+	RemediationRollingOut bool `json:"remediationRollingOut,omitempty"`
+
 	// LastUpdated timestamp when this status was last updated
 	LastUpdated *metav1.Time `json:"lastUpdated,omitempty"`
 }
 
+// NotifiedState identifies a workload state that has been notified
+type NotifiedState struct {
+	// Event is the notification event that was sent
+	Event string `json:"event"`
+
+	// Digest is the workload's digest when the notification was sent
+	// +optional
+	Digest string `json:"digest,omitempty"`
+
+	// Timestamp when the notification was sent
+	Timestamp metav1.Time `json:"timestamp"`
+}
+
 // AttestationDetails provides information about attestation verification results
 type AttestationDetails struct {
 	// Verified indicates if the attestation was successfully verified
@@ -167,14 +1050,51 @@ type AttestationDetails struct {
 	// +optional
 	AttestationType string `json:"attestationType,omitempty"`
 
+	// PredicateType is the in-toto predicate type URI of the attestation (e.g., "https://slsa.dev/provenance/v1")
+	// +optional
+	PredicateType string `json:"predicateType,omitempty"`
+
+	// BuilderID is the builder recorded in SLSA provenance (e.g., the slsa-github-generator workflow)
+	// +optional
+	BuilderID string `json:"builderID,omitempty"`
+
+	// Scanner is the scanner that produced a vulnerability report attestation, as URI@version
+	// +optional
+	Scanner string `json:"scanner,omitempty"`
+
 	// Issuer is the OIDC issuer of the attestation certificate
 	// +optional
 	Issuer string `json:"issuer,omitempty"`
 
+	// SAN is the subject alternative name of the attestation certificate, e.g. the GitHub workflow that signed it
+	// +optional
+	SAN string `json:"san,omitempty"`
+
+	// BuildConfigURI is the build config recorded in the attestation certificate
+	// +optional
+	BuildConfigURI string `json:"buildConfigURI,omitempty"`
+
 	// RekorLogIndex is the Rekor transparency log index for this attestation
 	// +optional
 	RekorLogIndex *int64 `json:"rekorLogIndex,omitempty"`
 
+	// ResolvedDigest is the digest a tag-based image was resolved to for verification, with ResolveTags
+	// +optional
+	ResolvedDigest string `json:"resolvedDigest,omitempty"`
+
+	// HasSBOM indicates whether an SPDX or CycloneDX attestation was found, when the policy requires one
+	// +optional
+	HasSBOM *bool `json:"hasSBOM,omitempty"`
+
+	// SLSALevel is the SLSA build level determined from the attestation (0 if not SLSA provenance)
+	// +optional
+	SLSALevel *int32 `json:"slsaLevel,omitempty"`
+
+	// VulnerabilityCount is the number of findings at or above MaxVulnerabilitySeverity in the vulnerability
+	// scan attestation, when the policy sets one and a scan was found
+	// +optional
+	VulnerabilityCount *int32 `json:"vulnerabilityCount,omitempty"`
+
 	// LastChecked timestamp when attestation was last verified
 	// +optional
 	LastChecked *metav1.Time `json:"lastChecked,omitempty"`
@@ -182,6 +1102,20 @@ type AttestationDetails struct {
 	// Error message if attestation verification failed
 	// +optional
 	Error string `json:"error,omitempty"`
+
+	// RekorUnavailable indicates verification failed because Rekor, or the registry for the Referrers
+	// attestation source, couldn't be queried
+	// +optional
+	RekorUnavailable bool `json:"rekorUnavailable,omitempty"`
+
+	// SubjectMismatch indicates the attestation found for the digest names another repository as its subject
+	// +optional
+	SubjectMismatch bool `json:"subjectMismatch,omitempty"`
+
+	// ProofVerified indicates the Rekor entry's inclusion proof and signed entry timestamp were verified
+	// against the log's public key
+	// +optional
+	ProofVerified bool `json:"proofVerified,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -191,6 +1125,11 @@ type AttestationDetails struct {
 // +kubebuilder:printcolumn:name="Compliance",type="string",JSONPath=".status.complianceStatus"
 // +kubebuilder:printcolumn:name="Total",type="integer",JSONPath=".status.totalDeployments"
 // +kubebuilder:printcolumn:name="Compliant",type="integer",JSONPath=".status.compliantDeployments"
+// +kubebuilder:printcolumn:name="Attestations",type="string",JSONPath=".status.attestationStatus"
+// +kubebuilder:printcolumn:name="Last Checked",type="date",JSONPath=".status.lastChecked"
+// +kubebuilder:printcolumn:name="Paused",type="boolean",JSONPath=".status.paused"
+// +kubebuilder:printcolumn:name="Failures",type="integer",JSONPath=".status.consecutiveFailures"
+// +kubebuilder:printcolumn:name="Last Remediation",type="date",JSONPath=".status.remediationHistory[0].timestamp"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // ImagePolicy is the Schema for the imagepolicies API
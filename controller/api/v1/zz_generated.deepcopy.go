@@ -21,6 +21,7 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
@@ -33,6 +34,21 @@ func (in *AttestationDetails) DeepCopyInto(out *AttestationDetails) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.HasSBOM != nil {
+		in, out := &in.HasSBOM, &out.HasSBOM
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SLSALevel != nil {
+		in, out := &in.SLSALevel, &out.SLSALevel
+		*out = new(int32)
+		**out = **in
+	}
+	if in.VulnerabilityCount != nil {
+		in, out := &in.VulnerabilityCount, &out.VulnerabilityCount
+		*out = new(int32)
+		**out = **in
+	}
 	if in.LastChecked != nil {
 		in, out := &in.LastChecked, &out.LastChecked
 		*out = (*in).DeepCopy()
@@ -62,6 +78,16 @@ func (in *AttestationPolicy) DeepCopyInto(out *AttestationPolicy) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.AllowedSANs != nil {
+		in, out := &in.AllowedSANs, &out.AllowedSANs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedBuildConfigURIs != nil {
+		in, out := &in.AllowedBuildConfigURIs, &out.AllowedBuildConfigURIs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.RequiredTypes != nil {
 		in, out := &in.RequiredTypes, &out.RequiredTypes
 		*out = make([]string, len(*in))
@@ -72,6 +98,21 @@ func (in *AttestationPolicy) DeepCopyInto(out *AttestationPolicy) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.RekorPublicKeyRef != nil {
+		in, out := &in.RekorPublicKeyRef, &out.RekorPublicKeyRef
+		*out = new(RekorPublicKeyRef)
+		**out = **in
+	}
+	if in.FulcioRootRef != nil {
+		in, out := &in.FulcioRootRef, &out.FulcioRootRef
+		*out = new(FulcioRootRef)
+		**out = **in
+	}
+	if in.TUFMirror != nil {
+		in, out := &in.TUFMirror, &out.TUFMirror
+		*out = new(TUFMirror)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AttestationPolicy.
@@ -84,6 +125,98 @@ func (in *AttestationPolicy) DeepCopy() *AttestationPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutomationGate) DeepCopyInto(out *AutomationGate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutomationGate.
+func (in *AutomationGate) DeepCopy() *AutomationGate {
+	if in == nil {
+		return nil
+	}
+	out := new(AutomationGate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceSummary) DeepCopyInto(out *ComplianceSummary) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceSummary.
+func (in *ComplianceSummary) DeepCopy() *ComplianceSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ComplianceSummary) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceSummaryList) DeepCopyInto(out *ComplianceSummaryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ComplianceSummary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceSummaryList.
+func (in *ComplianceSummaryList) DeepCopy() *ComplianceSummaryList {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceSummaryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ComplianceSummaryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceSummaryStatus) DeepCopyInto(out *ComplianceSummaryStatus) {
+	*out = *in
+	if in.LastUpdated != nil {
+		in, out := &in.LastUpdated, &out.LastUpdated
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceSummaryStatus.
+func (in *ComplianceSummaryStatus) DeepCopy() *ComplianceSummaryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceSummaryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeploymentStatus) DeepCopyInto(out *DeploymentStatus) {
 	*out = *in
@@ -97,6 +230,23 @@ func (in *DeploymentStatus) DeepCopyInto(out *DeploymentStatus) {
 		*out = new(AttestationDetails)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.LastNotified != nil {
+		in, out := &in.LastNotified, &out.LastNotified
+		*out = new(NotifiedState)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StaleSince != nil {
+		in, out := &in.StaleSince, &out.StaleSince
+		*out = (*in).DeepCopy()
+	}
+	if in.NonCompliantSince != nil {
+		in, out := &in.NonCompliantSince, &out.NonCompliantSince
+		*out = (*in).DeepCopy()
+	}
+	if in.LastRemediated != nil {
+		in, out := &in.LastRemediated, &out.LastRemediated
+		*out = (*in).DeepCopy()
+	}
 	if in.LastUpdated != nil {
 		in, out := &in.LastUpdated, &out.LastUpdated
 		*out = (*in).DeepCopy()
@@ -113,6 +263,52 @@ func (in *DeploymentStatus) DeepCopy() *DeploymentStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FulcioRootRef) DeepCopyInto(out *FulcioRootRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FulcioRootRef.
+func (in *FulcioRootRef) DeepCopy() *FulcioRootRef {
+	if in == nil {
+		return nil
+	}
+	out := new(FulcioRootRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitRepoRef) DeepCopyInto(out *GitRepoRef) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitRepoRef.
+func (in *GitRepoRef) DeepCopy() *GitRepoRef {
+	if in == nil {
+		return nil
+	}
+	out := new(GitRepoRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GoldenDigestRef) DeepCopyInto(out *GoldenDigestRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GoldenDigestRef.
+func (in *GoldenDigestRef) DeepCopy() *GoldenDigestRef {
+	if in == nil {
+		return nil
+	}
+	out := new(GoldenDigestRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImagePolicy) DeepCopyInto(out *ImagePolicy) {
 	*out = *in
@@ -175,11 +371,36 @@ func (in *ImagePolicyList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImagePolicySpec) DeepCopyInto(out *ImagePolicySpec) {
 	*out = *in
+	if in.Repositories != nil {
+		in, out := &in.Repositories, &out.Repositories
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.GoldenDigestRef != nil {
+		in, out := &in.GoldenDigestRef, &out.GoldenDigestRef
+		*out = new(GoldenDigestRef)
+		**out = **in
+	}
+	if in.PullSecretRef != nil {
+		in, out := &in.PullSecretRef, &out.PullSecretRef
+		*out = new(corev1.SecretReference)
+		**out = **in
+	}
 	if in.NamespaceSelector != nil {
 		in, out := &in.NamespaceSelector, &out.NamespaceSelector
 		*out = new(metav1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ExcludeNamespaces != nil {
+		in, out := &in.ExcludeNamespaces, &out.ExcludeNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.DeploymentSelector != nil {
 		in, out := &in.DeploymentSelector, &out.DeploymentSelector
 		*out = new(metav1.LabelSelector)
@@ -190,16 +411,101 @@ func (in *ImagePolicySpec) DeepCopyInto(out *ImagePolicySpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.MaxDriftDuration != nil {
+		in, out := &in.MaxDriftDuration, &out.MaxDriftDuration
+		*out = new(string)
+		**out = **in
+	}
+	if in.NamespaceAnalysisTimeout != nil {
+		in, out := &in.NamespaceAnalysisTimeout, &out.NamespaceAnalysisTimeout
+		*out = new(string)
+		**out = **in
+	}
 	if in.EnforceLatestDigest != nil {
 		in, out := &in.EnforceLatestDigest, &out.EnforceLatestDigest
 		*out = new(bool)
 		**out = **in
 	}
+	if in.AllowedDigests != nil {
+		in, out := &in.AllowedDigests, &out.AllowedDigests
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeniedDigests != nil {
+		in, out := &in.DeniedDigests, &out.DeniedDigests
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedRegistries != nil {
+		in, out := &in.AllowedRegistries, &out.AllowedRegistries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VerifyDigestExists != nil {
+		in, out := &in.VerifyDigestExists, &out.VerifyDigestExists
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MutateTagsToDigests != nil {
+		in, out := &in.MutateTagsToDigests, &out.MutateTagsToDigests
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PinPullPolicy != nil {
+		in, out := &in.PinPullPolicy, &out.PinPullPolicy
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AutomationGate != nil {
+		in, out := &in.AutomationGate, &out.AutomationGate
+		*out = new(AutomationGate)
+		**out = **in
+	}
+	if in.GitRepoRef != nil {
+		in, out := &in.GitRepoRef, &out.GitRepoRef
+		*out = new(GitRepoRef)
+		**out = **in
+	}
+	if in.RemediationHistoryLimit != nil {
+		in, out := &in.RemediationHistoryLimit, &out.RemediationHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxMonitoredDeployments != nil {
+		in, out := &in.MaxMonitoredDeployments, &out.MaxMonitoredDeployments
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RemediationCooldown != nil {
+		in, out := &in.RemediationCooldown, &out.RemediationCooldown
+		*out = new(string)
+		**out = **in
+	}
+	if in.RemediationWindow != nil {
+		in, out := &in.RemediationWindow, &out.RemediationWindow
+		*out = new(RemediationWindow)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxConcurrentRemediations != nil {
+		in, out := &in.MaxConcurrentRemediations, &out.MaxConcurrentRemediations
+		*out = new(int32)
+		**out = **in
+	}
+	if in.HonorPodDisruptionBudgets != nil {
+		in, out := &in.HonorPodDisruptionBudgets, &out.HonorPodDisruptionBudgets
+		*out = new(bool)
+		**out = **in
+	}
 	if in.AttestationPolicy != nil {
 		in, out := &in.AttestationPolicy, &out.AttestationPolicy
 		*out = new(AttestationPolicy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.NotificationConfig != nil {
+		in, out := &in.NotificationConfig, &out.NotificationConfig
+		*out = new(NotificationConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePolicySpec.
@@ -219,6 +525,13 @@ func (in *ImagePolicyStatus) DeepCopyInto(out *ImagePolicyStatus) {
 		in, out := &in.LastChecked, &out.LastChecked
 		*out = (*in).DeepCopy()
 	}
+	if in.Repositories != nil {
+		in, out := &in.Repositories, &out.Repositories
+		*out = make([]RepositoryStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.MonitoredDeployments != nil {
 		in, out := &in.MonitoredDeployments, &out.MonitoredDeployments
 		*out = make([]DeploymentStatus, len(*in))
@@ -226,6 +539,21 @@ func (in *ImagePolicyStatus) DeepCopyInto(out *ImagePolicyStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.RemediationHistory != nil {
+		in, out := &in.RemediationHistory, &out.RemediationHistory
+		*out = make([]RemediationRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RegistryRequestWindowStart != nil {
+		in, out := &in.RegistryRequestWindowStart, &out.RegistryRequestWindowStart
+		*out = (*in).DeepCopy()
+	}
+	if in.RegistryLast429 != nil {
+		in, out := &in.RegistryLast429, &out.RegistryLast429
+		*out = (*in).DeepCopy()
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]metav1.Condition, len(*in))
@@ -244,3 +572,161 @@ func (in *ImagePolicyStatus) DeepCopy() *ImagePolicyStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationConfig) DeepCopyInto(out *NotificationConfig) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationConfig.
+func (in *NotificationConfig) DeepCopy() *NotificationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotifiedState) DeepCopyInto(out *NotifiedState) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotifiedState.
+func (in *NotifiedState) DeepCopy() *NotifiedState {
+	if in == nil {
+		return nil
+	}
+	out := new(NotifiedState)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RekorPublicKeyRef) DeepCopyInto(out *RekorPublicKeyRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RekorPublicKeyRef.
+func (in *RekorPublicKeyRef) DeepCopy() *RekorPublicKeyRef {
+	if in == nil {
+		return nil
+	}
+	out := new(RekorPublicKeyRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationRecord) DeepCopyInto(out *RemediationRecord) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationRecord.
+func (in *RemediationRecord) DeepCopy() *RemediationRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationWindow) DeepCopyInto(out *RemediationWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationWindow.
+func (in *RemediationWindow) DeepCopy() *RemediationWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepositoryStatus) DeepCopyInto(out *RepositoryStatus) {
+	*out = *in
+	if in.LastChecked != nil {
+		in, out := &in.LastChecked, &out.LastChecked
+		*out = (*in).DeepCopy()
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]TagStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepositoryStatus.
+func (in *RepositoryStatus) DeepCopy() *RepositoryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RepositoryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TUFMirror) DeepCopyInto(out *TUFMirror) {
+	*out = *in
+	out.RootRef = in.RootRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TUFMirror.
+func (in *TUFMirror) DeepCopy() *TUFMirror {
+	if in == nil {
+		return nil
+	}
+	out := new(TUFMirror)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TUFRootRef) DeepCopyInto(out *TUFRootRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TUFRootRef.
+func (in *TUFRootRef) DeepCopy() *TUFRootRef {
+	if in == nil {
+		return nil
+	}
+	out := new(TUFRootRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TagStatus) DeepCopyInto(out *TagStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TagStatus.
+func (in *TagStatus) DeepCopy() *TagStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TagStatus)
+	in.DeepCopyInto(out)
+	return out
+}
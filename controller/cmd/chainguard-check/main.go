@@ -0,0 +1,152 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// chainguard-check resolves a repository's digest and verifies its attestations the way an ImagePolicy
+// would, printing the result as JSON, to debug a policy without deploying the controller.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+	"github.com/jonlimpw/chainguard-controller/internal/controller"
+	"github.com/jonlimpw/chainguard-controller/internal/rekor"
+)
+
+func main() {
+	var req controller.CheckRequest
+	var rekorURL, rekorPublicKeyPath, registryMirror, registryProxy string
+	var requireAttestation, verbose bool
+	var allowedIssuers, allowedSANs, allowedBuildConfigURIs, requiredTypes, maxAge string
+	var minSLSALevel int
+	attestationPolicy := &securityv1.AttestationPolicy{}
+	flag.StringVar(&req.Repository, "repository", "", "The DockerHub repository to check (e.g., \"jonlimpw/demo-app\", or \"nginx\").")
+	flag.StringVar(&req.Tag, "tag", "latest", "The tag to resolve to its latest digest.")
+	flag.StringVar(&req.Digest, "digest", "", "A sha256: or sha512: digest to verify instead of the tag's latest digest.")
+	flag.StringVar(&req.Platform, "platform", "", "The platform of multi-platform images to resolve, e.g. linux/arm64.")
+	flag.StringVar(&registryMirror, "registry-mirror", "", "A DockerHub mirror ([http(s)://]host[:port][/prefix]) to resolve digests through.")
+	flag.StringVar(&registryProxy, "registry-proxy", "", "An HTTP(S) proxy for registry requests. Defaults to the HTTPS_PROXY env var.")
+	flag.StringVar(&rekorURL, "rekor-url", rekor.DefaultURL, "The Rekor server used for attestation verification.")
+	flag.StringVar(&rekorPublicKeyPath, "rekor-public-key", "",
+		"A PEM file with the --rekor-url log's public key, required for a Rekor server other than the public Sigstore instance.")
+	flag.BoolVar(&requireAttestation, "require-attestation", false, "Verify the digest's attestations, as attestationPolicy.requireAttestation.")
+	flag.BoolVar(&attestationPolicy.RequireSBOM, "require-sbom", false, "Require an SPDX or CycloneDX attestation, as attestationPolicy.requireSBOM.")
+	flag.StringVar(&attestationPolicy.MaxVulnerabilitySeverity, "max-vulnerability-severity", "",
+		"Fail when the vulnerability scan attestation reports findings of this severity (Low, Medium, High or Critical) or higher.")
+	flag.StringVar(&allowedIssuers, "allowed-issuers", "", "Comma-separated OIDC issuers accepted for attestation certificates.")
+	flag.StringVar(&allowedSANs, "allowed-sans", "",
+		"Comma-separated certificate SANs (e.g. GitHub workflow URIs) accepted for attestations; a trailing * matches a prefix.")
+	flag.StringVar(&allowedBuildConfigURIs, "allowed-build-config-uris", "",
+		"Comma-separated certificate build config URIs accepted for attestations; a trailing * matches a prefix.")
+	flag.StringVar(&requiredTypes, "required-types", "", "Comma-separated attestation types required (e.g., \"slsaprovenance\").")
+	flag.StringVar(&maxAge, "max-age", "", "The maximum age of attestations to accept (e.g., \"24h\").")
+	flag.IntVar(&minSLSALevel, "min-slsa-level", 0, "The minimum SLSA build level (0-3) of the image's provenance.")
+	flag.StringVar(&attestationPolicy.AttestationSource, "attestation-source", securityv1.AttestationSourceRekor,
+		"Where attestations are discovered: Rekor or Referrers.")
+	flag.BoolVar(&verbose, "v", false, "Log registry and Rekor requests to stderr.")
+	flag.Parse()
+
+	// Keep stdout for the result
+	logOutput := io.Discard
+	if verbose {
+		logOutput = os.Stderr
+	}
+	ctrl.SetLogger(zap.New(zap.WriteTo(logOutput), zap.UseDevMode(true)))
+
+	if req.Repository == "" {
+		fmt.Fprintln(os.Stderr, "--repository is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	severities := []string{securityv1.VulnerabilitySeverityLow, securityv1.VulnerabilitySeverityMedium,
+		securityv1.VulnerabilitySeverityHigh, securityv1.VulnerabilitySeverityCritical}
+	if severity := attestationPolicy.MaxVulnerabilitySeverity; severity != "" && !slices.Contains(severities, severity) {
+		fmt.Fprintf(os.Stderr, "--max-vulnerability-severity must be one of %s\n", strings.Join(severities, ", "))
+		os.Exit(2)
+	}
+
+	attestationPolicy.RequireAttestation = &requireAttestation
+	attestationPolicy.MinSLSALevel = int32(min(max(minSLSALevel, 0), 3))
+	attestationPolicy.AllowedIssuers = splitList(allowedIssuers)
+	attestationPolicy.AllowedSANs = splitList(allowedSANs)
+	attestationPolicy.AllowedBuildConfigURIs = splitList(allowedBuildConfigURIs)
+	attestationPolicy.RequiredTypes = splitList(requiredTypes)
+	if maxAge != "" {
+		attestationPolicy.MaxAge = &maxAge
+	}
+	req.AttestationPolicy = attestationPolicy
+
+	reconciler := &controller.ImagePolicyReconciler{RegistryMirror: registryMirror, RegistryProxy: registryProxy}
+	if attestationPolicy.RequiresVerification() && attestationPolicy.AttestationSource != securityv1.AttestationSourceReferrers {
+		var rekorPublicKey []byte
+		if rekorPublicKeyPath != "" {
+			var err error
+			if rekorPublicKey, err = os.ReadFile(rekorPublicKeyPath); err != nil {
+				fmt.Fprintf(os.Stderr, "unable to read the Rekor public key: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		rekorClient, err := rekor.NewClient(rekorURL, rekorPublicKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "unable to create Rekor client for %s: %v\n", rekorURL, err)
+			os.Exit(1)
+		}
+		reconciler.RekorClient = rekorClient
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	result, err := reconciler.Check(ctx, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "check failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		fmt.Fprintf(os.Stderr, "unable to print the result: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Let scripts tell a failed verification apart from a digest that couldn't be checked
+	if result.Attestation != nil && !result.Attestation.Verified {
+		os.Exit(3)
+	}
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -17,20 +17,27 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
 	"os"
+	"strconv"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -38,6 +45,8 @@ import (
 	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
 	"github.com/jonlimpw/chainguard-controller/internal/controller"
 	"github.com/jonlimpw/chainguard-controller/internal/rekor"
+	"github.com/jonlimpw/chainguard-controller/internal/tracing"
+	webhookv1 "github.com/jonlimpw/chainguard-controller/internal/webhook/v1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -62,6 +71,21 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var rekorURL string
+	var rekorPublicKeyPath string
+	var dockerHubQPS float64
+	var dockerHubBurst int
+	var maxParallelRegistryRequests int
+	var registryMirror string
+	var registryProxy string
+	var maxConcurrentReconciles int
+	var maxConcurrentAnalyses int
+	var requeueJitter float64
+	var complianceAddr string
+	var defaultCheckInterval time.Duration
+	var errorRequeueInterval time.Duration
+	var defaultEnforceLatest bool
+	var tracingOptions tracing.Options
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -80,8 +104,49 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&rekorURL, "rekor-url", rekorURLFromEnv(),
+		"The Rekor server used for attestation verification. Defaults to the REKOR_URL env var or the public Sigstore instance.")
+	flag.StringVar(&rekorPublicKeyPath, "rekor-public-key", "",
+		"A PEM file with the public key of the --rekor-url log, which entries are verified against. "+
+			"Required for a Rekor server other than the public Sigstore instance, whose key is built in.")
+	flag.Float64Var(&dockerHubQPS, "dockerhub-qps", 5,
+		"The maximum DockerHub digest fetches per second across all ImagePolicies. Set to 0 to disable the limit.")
+	flag.IntVar(&dockerHubBurst, "dockerhub-burst", 10, "The number of DockerHub digest fetches allowed in a burst above --dockerhub-qps.")
+	flag.IntVar(&maxParallelRegistryRequests, "max-parallel-registry-requests", 16,
+		"The maximum registry requests in flight across all ImagePolicies; further requests wait for a slot. Set to 0 to disable the limit.")
+	flag.StringVar(&registryMirror, "registry-mirror", "",
+		"A DockerHub mirror or pull-through cache ([http(s)://]host[:port][/prefix]) to resolve digests through. "+
+			"ImagePolicies can override it with spec.registryMirror.")
+	flag.StringVar(&registryProxy, "registry-proxy", "",
+		"An HTTP(S) proxy (http(s)://host:port) for registry requests; hosts in NO_PROXY are reached directly. "+
+			"Defaults to the HTTPS_PROXY env var. ImagePolicies can override it with spec.registryProxy.")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 4,
+		"The number of ImagePolicies reconciled in parallel. DockerHub fetches stay bounded by --dockerhub-qps.")
+	flag.IntVar(&maxConcurrentAnalyses, "max-concurrent-analyses", 8,
+		"The number of workloads of an ImagePolicy whose compliance and attestations are checked in parallel.")
+	flag.Float64Var(&requeueJitter, "requeue-jitter", 0.1,
+		"The fraction (0 to 0.5) by which each ImagePolicy check interval is randomly shortened or lengthened, "+
+			"to spread DockerHub requests of policies created together.")
+	flag.StringVar(&complianceAddr, "compliance-bind-address", "",
+		"The address a read-only JSON summary of every ImagePolicy's compliance is served on at /compliance, e.g. :8082. "+
+			"It is unauthenticated and disabled when empty.")
+	flag.DurationVar(&defaultCheckInterval, "default-check-interval", time.Minute,
+		"The check interval (10s to 1h) of ImagePolicies that don't set spec.checkIntervalSeconds.")
+	flag.DurationVar(&errorRequeueInterval, "error-requeue-interval", 10*time.Second,
+		"The first retry of an ImagePolicy whose reconcile failed, doubled for every further failure up to an hour. "+
+			"Policies that succeed are requeued on their check interval.")
+	flag.BoolVar(&defaultEnforceLatest, "default-enforce-latest", true,
+		"Whether ImagePolicies that don't set spec.enforceLatestDigest require the latest digest.")
+	flag.StringVar(&tracingOptions.Endpoint, "otlp-endpoint", "",
+		"The OTLP/gRPC collector (host:port) reconcile traces are exported to. Tracing is disabled when empty.")
+	flag.BoolVar(&tracingOptions.Insecure, "otlp-insecure", false,
+		"If set, traces are sent to --otlp-endpoint without TLS.")
+	flag.Float64Var(&tracingOptions.SampleRatio, "otlp-sample-ratio", 1,
+		"The fraction (0 to 1) of reconciles traced when --otlp-endpoint is set.")
+	// Production defaults (JSON, info level) keep per-reconcile detail out of the logs. --zap-log-level
+	// accepts debug or a verbosity (2 for the most detail), and --zap-encoder=console reads better locally.
 	opts := zap.Options{
-		Development: true,
+		Development: false,
 	}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
@@ -173,31 +238,131 @@ func main() {
 		// if you are doing or is intended to do any operation such as perform cleanups
 		// after the manager stops then its usage might be unsafe.
 		// LeaderElectionReleaseOnCancel: true,
+
+		// Pull secrets and Fulcio root bundles are read directly from the API server so the
+		// controller only needs "get" on them rather than cluster-wide watches.
+		Client: client.Options{
+			Cache: &client.CacheOptions{
+				DisableFor: []client.Object{&corev1.Secret{}, &corev1.ConfigMap{}},
+			},
+		},
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
-	// Initialize Rekor client for attestation verification
-	rekorClient, err := rekor.NewClient()
-	if err != nil {
-		setupLog.Error(err, "unable to create Rekor client")
-		// Don't exit - controller can still work without attestation verification
-		rekorClient = nil
+	// Initialize Rekor client for attestation verification, pinned to the configured log key
+	var rekorPublicKey []byte
+	if rekorPublicKeyPath != "" {
+		if rekorPublicKey, err = os.ReadFile(rekorPublicKeyPath); err != nil {
+			setupLog.Error(err, "unable to read the Rekor public key", "path", rekorPublicKeyPath)
+			os.Exit(1)
+		}
+	}
+	rekorClient, rekorErr := rekor.NewClient(rekorURL, rekorPublicKey)
+	if rekorErr != nil {
+		// Don't exit - controller can still work without attestation verification, and retries below
+		setupLog.Error(rekorErr, "unable to create Rekor client, retrying in the background", "rekorURL", rekorURL)
 	} else {
-		setupLog.Info("Rekor client initialized successfully")
+		setupLog.Info("Rekor client initialized successfully", "rekorURL", rekorClient.URL())
+
+		// Surface an unreachable (e.g. misconfigured air-gapped) endpoint at startup
+		healthCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := rekorClient.HealthCheck(healthCtx); err != nil {
+			setupLog.Error(err, "Rekor health check failed, attestation verification will fail until it is reachable")
+		}
+		cancel()
 	}
 
-	if err := (&controller.ImagePolicyReconciler{
-		Client:      mgr.GetClient(),
-		Scheme:      mgr.GetScheme(),
-		Recorder:    mgr.GetEventRecorderFor("imagepolicy-controller"),
-		RekorClient: rekorClient,
-	}).SetupWithManager(mgr); err != nil {
+	// Export reconcile spans when a collector is configured, flushing the last ones on shutdown
+	shutdownTracing, err := tracing.Setup(context.Background(), tracingOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+	if tracingOptions.Endpoint != "" {
+		setupLog.Info("Exporting traces", "endpoint", tracingOptions.Endpoint, "sampleRatio", tracingOptions.SampleRatio)
+		if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return shutdownTracing(flushCtx)
+		})); err != nil {
+			setupLog.Error(err, "unable to set up tracing shutdown")
+			os.Exit(1)
+		}
+	}
+
+	// DockerHub retry budget per digest fetch, overridable for heavily rate-limited environments
+	var dockerHubMaxRetries int
+	if value := os.Getenv("DOCKERHUB_MAX_RETRIES"); value != "" {
+		dockerHubMaxRetries, err = strconv.Atoi(value)
+		if err != nil || dockerHubMaxRetries <= 0 {
+			setupLog.Info("ignoring invalid DOCKERHUB_MAX_RETRIES, using default", "value", value)
+			dockerHubMaxRetries = 0
+		}
+	}
+
+	// Shared DockerHub request budget; saturated reconciles requeue instead of blocking
+	var dockerHubRateLimiter *rate.Limiter
+	if dockerHubQPS > 0 {
+		dockerHubRateLimiter = rate.NewLimiter(rate.Limit(dockerHubQPS), max(dockerHubBurst, 1))
+	}
+
+	imagePolicyReconciler := &controller.ImagePolicyReconciler{
+		Client:                      mgr.GetClient(),
+		Scheme:                      mgr.GetScheme(),
+		Recorder:                    mgr.GetEventRecorderFor("imagepolicy-controller"),
+		APIReader:                   mgr.GetAPIReader(),
+		RekorClient:                 rekorClient,
+		DockerHubMaxRetries:         dockerHubMaxRetries,
+		DockerHubRateLimiter:        dockerHubRateLimiter,
+		MaxParallelRegistryRequests: maxParallelRegistryRequests,
+		RegistryMirror:              registryMirror,
+		RegistryProxy:               registryProxy,
+		MaxConcurrentReconciles:     maxConcurrentReconciles,
+		MaxConcurrentAnalyses:       maxConcurrentAnalyses,
+		RequeueJitter:               requeueJitter,
+		ErrorRequeueInterval:        errorRequeueInterval,
+		// Round to whole seconds within the CRD's checkIntervalSeconds bounds
+		DefaultCheckIntervalSeconds: int32(min(max(defaultCheckInterval, 10*time.Second), time.Hour) / time.Second),
+		DefaultEnforceLatest:        &defaultEnforceLatest,
+	}
+	if err := imagePolicyReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ImagePolicy")
 		os.Exit(1)
 	}
+	if err := (&controller.ComplianceSummaryReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ComplianceSummary")
+		os.Exit(1)
+	}
+	if rekorClient == nil {
+		if err := mgr.Add(imagePolicyReconciler.RetryRekorClient(rekorURL, rekorPublicKey, rekorErr)); err != nil {
+			setupLog.Error(err, "unable to set up Rekor client retries")
+			os.Exit(1)
+		}
+	}
+	if complianceAddr != "" {
+		if err := mgr.Add(imagePolicyReconciler.ComplianceServer(complianceAddr)); err != nil {
+			setupLog.Error(err, "unable to set up the compliance server")
+			os.Exit(1)
+		}
+	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err := webhookv1.SetupDeploymentWebhookWithManager(mgr, imagePolicyReconciler); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Deployment")
+			os.Exit(1)
+		}
+		if err := webhookv1.SetupImagePolicyWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ImagePolicy")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -208,6 +373,11 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	// Readiness rather than liveness: restarting the manager doesn't fix an unreachable Rekor
+	if err := mgr.AddReadyzCheck("rekor", imagePolicyReconciler.RekorHealthCheck); err != nil {
+		setupLog.Error(err, "unable to set up Rekor ready check")
+		os.Exit(1)
+	}
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
@@ -215,3 +385,11 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// rekorURLFromEnv returns the REKOR_URL env var, or the public Sigstore Rekor instance when unset
+func rekorURLFromEnv() string {
+	if value := os.Getenv("REKOR_URL"); value != "" {
+		return value
+	}
+	return rekor.DefaultURL
+}
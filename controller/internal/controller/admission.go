@@ -0,0 +1,187 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+// ValidateWorkloadAdmission checks a workload against every ImagePolicy with BlockOnAdmission that selects it
+// and returns the reasons it should be rejected. Latest digests come from the digest cache or the policy
+// status and are never fetched from DockerHub here; repositories with an unknown latest digest are allowed.
+func (r *ImagePolicyReconciler) ValidateWorkloadAdmission(ctx context.Context, obj client.Object) ([]string, error) {
+	deployment, ok := newWorkload(obj)
+	if !ok {
+		return nil, nil
+	}
+	return r.validateAdmission(ctx, deployment, nil)
+}
+
+// ValidateWorkloadUpdate is ValidateWorkloadAdmission for updates: only repositories whose governed container
+// images differ between oldObj and newObj are checked, so scaling or relabelling a workload that already runs
+// a non-compliant image isn't rejected.
+func (r *ImagePolicyReconciler) ValidateWorkloadUpdate(ctx context.Context, oldObj, newObj client.Object) ([]string, error) {
+	deployment, ok := newWorkload(newObj)
+	if !ok {
+		return nil, nil
+	}
+	previous, ok := newWorkload(oldObj)
+	if !ok {
+		return r.validateAdmission(ctx, deployment, nil)
+	}
+	return r.validateAdmission(ctx, deployment, &previous)
+}
+
+// validateAdmission returns the reasons deployment should be rejected. When previous is set, repositories
+// whose governed images are unchanged from it are allowed.
+func (r *ImagePolicyReconciler) validateAdmission(ctx context.Context, deployment workload, previous *workload) ([]string, error) {
+	log := logf.FromContext(ctx)
+
+	policies := &securityv1.ImagePolicyList{}
+	if err := r.List(ctx, policies); err != nil {
+		return nil, fmt.Errorf("failed to list image policies: %w", err)
+	}
+
+	var denials []string
+	for i := range policies.Items {
+		policy := &policies.Items[i]
+		if !policy.Spec.BlockOnAdmission || !policy.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		selected, err := r.policySelectsWorkload(ctx, policy, deployment)
+		if err != nil {
+			return nil, err
+		}
+		if !selected {
+			continue
+		}
+
+		rules := r.rulesFor(policy)
+
+		for _, repository := range monitoredRepositories(policy, []workload{deployment}) {
+			if !governsRepository(deployment, repository, rules) {
+				continue
+			}
+			if previous != nil && maps.Equal(governedImages(*previous, repository, rules), governedImages(deployment, repository, rules)) {
+				continue
+			}
+
+			latestDigest := r.knownLatestDigest(policy, repository)
+			if rules.EnforceLatest && policy.Spec.DigestType != securityv1.DigestTypeConfig && policy.Spec.GoldenDigestRef == nil {
+				rules.MultiArchDigests = r.findMultiArchDigests(ctx, policy, []workload{deployment}, []string{repository},
+					map[string]string{repository: latestDigest}, rules.TrackedDigests, time.Duration(r.checkInterval(policy))*time.Second)
+			}
+			status := r.analyzeDeploymentCompliance(ctx, deployment, repository, latestDigest, rules)
+			if status.IsCompliant {
+				continue
+			}
+
+			// Fail open so a DockerHub outage doesn't block every deploy, unless the digest is denied outright
+			if latestDigest == "" && status.Reason != securityv1.NonComplianceReasonDeniedDigest {
+				log.Info("Latest digest unknown, allowing admission", "policy", policy.Name, "repository", repository,
+					"kind", deployment.Kind, "deployment", deployment.GetName(), "namespace", deployment.GetNamespace())
+				continue
+			}
+
+			// Tags are let through when remediation will pin them to a digest right after
+			if status.Reason == securityv1.NonComplianceReasonTagBased && r.pinsTagsOf(policy, deployment) {
+				log.Info("Tag-based image will be pinned to a digest, allowing admission", "policy", policy.Name, "repository", repository,
+					"kind", deployment.Kind, "deployment", deployment.GetName(), "namespace", deployment.GetNamespace())
+				continue
+			}
+
+			denials = append(denials, fmt.Sprintf("%s %s (%s): %s, required by ImagePolicy %s/%s",
+				status.ContainerKind, status.ContainerName, repository, status.Message, policy.Namespace, policy.Name))
+		}
+	}
+
+	return denials, nil
+}
+
+// governedImages maps the name of each governed container using repository to its image
+func governedImages(deployment workload, repository string, rules complianceRules) map[string]string {
+	images := map[string]string{}
+	for _, container := range deployment.governedContainers(rules.ContainerName) {
+		if imageUsesRepository(*container.Image, repository) {
+			images[container.Name] = *container.Image
+		}
+	}
+	return images
+}
+
+// policySelectsWorkload checks if a workload is within the policy's namespace and deployment selectors,
+// not in an excluded namespace and not ignored
+func (r *ImagePolicyReconciler) policySelectsWorkload(ctx context.Context, policy *securityv1.ImagePolicy, deployment workload) (bool, error) {
+	if deployment.ignored() || namespaceExcluded(policy, deployment.GetNamespace()) {
+		return false, nil
+	}
+
+	if policy.Spec.DeploymentSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(policy.Spec.DeploymentSelector)
+		if err != nil {
+			return false, fmt.Errorf("invalid deployment selector on ImagePolicy %s/%s: %w", policy.Namespace, policy.Name, err)
+		}
+		if !selector.Matches(labels.Set(deployment.GetLabels())) {
+			return false, nil
+		}
+	}
+
+	if policy.Spec.NamespaceSelector == nil {
+		return true, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(policy.Spec.NamespaceSelector)
+	if err != nil {
+		return false, fmt.Errorf("invalid namespace selector on ImagePolicy %s/%s: %w", policy.Namespace, policy.Name, err)
+	}
+
+	namespace := &corev1.Namespace{}
+	if err := r.Get(ctx, client.ObjectKey{Name: deployment.GetNamespace()}, namespace); err != nil {
+		return false, fmt.Errorf("failed to get namespace %s: %w", deployment.GetNamespace(), err)
+	}
+	return selector.Matches(labels.Set(namespace.Labels)), nil
+}
+
+// knownLatestDigest returns the latest digest of a repository from the digest cache, falling back to
+// the digest recorded in the policy status by the last reconcile. The cache holds DockerHub's digests,
+// so policies with a GoldenDigestRef only use the status. The lookup is anonymous, so private repositories
+// (cached under their credentials) also fall back to the status.
+func (r *ImagePolicyReconciler) knownLatestDigest(policy *securityv1.ImagePolicy, repository string) string {
+	checkInterval := r.checkInterval(policy)
+
+	digestReq := digestRequest{Repository: repository, Tag: trackedTags(policy)[0], Platform: policy.Spec.Platform, DigestType: policy.Spec.DigestType}
+	if digest, ok := r.getDigestCache().get(digestReq.cacheKey(), time.Duration(checkInterval)*time.Second); ok && policy.Spec.GoldenDigestRef == nil {
+		return digest
+	}
+
+	if status := findRepositoryStatus(policy, repository); status != nil {
+		return status.LatestDigest
+	}
+	return ""
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Workload admission", func() {
+	const (
+		repository   = "jonlimpw/cg-demo"
+		latestDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		staleDigest  = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	)
+
+	ctx := context.Background()
+
+	newPolicy := func(block bool, knownDigest string) *securityv1.ImagePolicy {
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+			Spec: securityv1.ImagePolicySpec{
+				Repository:       repository,
+				BlockOnAdmission: block,
+			},
+		}
+		if knownDigest != "" {
+			policy.Status.Repositories = []securityv1.RepositoryStatus{{Repository: repository, LatestDigest: knownDigest}}
+		}
+		return policy
+	}
+
+	newDeployment := func(image string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: image}}},
+				},
+			},
+		}
+	}
+
+	newReconciler := func(policy *securityv1.ImagePolicy) *ImagePolicyReconciler {
+		return &ImagePolicyReconciler{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(policy).Build()}
+	}
+
+	It("should reject a deployment using an outdated digest", func() {
+		reconciler := newReconciler(newPolicy(true, latestDigest))
+
+		denials, err := reconciler.ValidateWorkloadAdmission(ctx, newDeployment(repository+"@"+staleDigest))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(denials).To(HaveLen(1))
+		Expect(denials[0]).To(ContainSubstring(staleDigest))
+	})
+
+	It("should allow a deployment using the latest digest", func() {
+		reconciler := newReconciler(newPolicy(true, latestDigest))
+
+		denials, err := reconciler.ValidateWorkloadAdmission(ctx, newDeployment(repository+"@"+latestDigest))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(denials).To(BeEmpty())
+	})
+
+	It("should prefer the cached digest over the policy status", func() {
+		reconciler := newReconciler(newPolicy(true, staleDigest))
+		reconciler.getDigestCache().set(digestRequest{Repository: repository, Tag: "latest"}.cacheKey(), latestDigest)
+
+		denials, err := reconciler.ValidateWorkloadAdmission(ctx, newDeployment(repository+"@"+staleDigest))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(denials).To(HaveLen(1))
+	})
+
+	It("should allow a deployment using a platform manifest of the latest index", func() {
+		const platformDigest = "sha256:3333333333333333333333333333333333333333333333333333333333333333"
+		reconciler := newReconciler(newPolicy(true, latestDigest))
+		reconciler.getDigestCache().set(digestRequest{Repository: repository, Tag: latestDigest}.cacheKey()+"#manifests", platformDigest)
+
+		denials, err := reconciler.ValidateWorkloadAdmission(ctx, newDeployment(repository+"@"+platformDigest))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(denials).To(BeEmpty())
+
+		denials, err = reconciler.ValidateWorkloadAdmission(ctx, newDeployment(repository+"@"+staleDigest))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(denials).To(HaveLen(1))
+	})
+
+	It("should fail open when the latest digest is unknown", func() {
+		reconciler := newReconciler(newPolicy(true, ""))
+
+		denials, err := reconciler.ValidateWorkloadAdmission(ctx, newDeployment(repository+"@"+staleDigest))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(denials).To(BeEmpty())
+	})
+
+	It("should ignore policies without BlockOnAdmission", func() {
+		reconciler := newReconciler(newPolicy(false, latestDigest))
+
+		denials, err := reconciler.ValidateWorkloadAdmission(ctx, newDeployment(repository+"@"+staleDigest))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(denials).To(BeEmpty())
+	})
+
+	It("should ignore policies excluding the deployment's namespace", func() {
+		policy := newPolicy(true, latestDigest)
+		policy.Spec.ExcludeNamespaces = []string{"default"}
+		reconciler := newReconciler(policy)
+
+		denials, err := reconciler.ValidateWorkloadAdmission(ctx, newDeployment(repository+"@"+staleDigest))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(denials).To(BeEmpty())
+	})
+
+	It("should allow updates that leave a non-compliant image unchanged", func() {
+		reconciler := newReconciler(newPolicy(true, latestDigest))
+		oldDeployment := newDeployment(repository + "@" + staleDigest)
+		scaled := oldDeployment.DeepCopy()
+		scaled.Spec.Replicas = ptr.To(int32(3))
+		scaled.Spec.Template.Labels = map[string]string{"rollout": "2"}
+
+		denials, err := reconciler.ValidateWorkloadUpdate(ctx, oldDeployment, scaled)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(denials).To(BeEmpty())
+	})
+
+	It("should reject updates that change a governed image to a non-compliant one", func() {
+		reconciler := newReconciler(newPolicy(true, latestDigest))
+		oldDeployment := newDeployment(repository + "@" + latestDigest)
+
+		denials, err := reconciler.ValidateWorkloadUpdate(ctx, oldDeployment, newDeployment(repository+"@"+staleDigest))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(denials).To(HaveLen(1))
+		Expect(denials[0]).To(ContainSubstring(staleDigest))
+	})
+
+	It("should only check the governed images an update changed", func() {
+		policy := newPolicy(true, latestDigest)
+		policy.Spec.ContainerName = "app"
+		reconciler := newReconciler(policy)
+		oldDeployment := newDeployment(repository + "@" + staleDigest)
+		oldDeployment.Spec.Template.Spec.Containers = append(oldDeployment.Spec.Template.Spec.Containers,
+			corev1.Container{Name: "sidecar", Image: repository + "@" + latestDigest})
+		updated := oldDeployment.DeepCopy()
+		updated.Spec.Template.Spec.Containers[1].Image = repository + "@" + staleDigest
+
+		denials, err := reconciler.ValidateWorkloadUpdate(ctx, oldDeployment, updated)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(denials).To(BeEmpty())
+	})
+})
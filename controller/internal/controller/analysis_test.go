@@ -0,0 +1,178 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+const analysisRepository = "jonlimpw/cg-demo"
+
+// newAnalysisWorkloads returns n Deployments each pinned to its own digest of analysisRepository
+func newAnalysisWorkloads(n int) []workload {
+	workloads := make([]workload, n)
+	for i := range workloads {
+		workloads[i], _ = newWorkload(&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("demo-%d", i), Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "app", Image: fmt.Sprintf("%s@sha256:%064d", analysisRepository, i)}},
+			}}},
+		})
+	}
+	return workloads
+}
+
+// serveSlowReferrers points DockerHub at a registry answering every referrers request after latency with no
+// attestations, tracking the most requests in flight at once. It returns a function restoring DockerHub.
+func serveSlowReferrers(latency time.Duration, maxInFlight *atomic.Int32) func() {
+	var inFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			_, _ = w.Write([]byte(`{"token":"test"}`))
+			return
+		}
+		if !strings.Contains(r.URL.Path, "/referrers/") {
+			http.NotFound(w, r)
+			return
+		}
+
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for previous := maxInFlight.Load(); current > previous && !maxInFlight.CompareAndSwap(previous, current); previous = maxInFlight.Load() {
+		}
+		time.Sleep(latency)
+		_, _ = w.Write([]byte(`{"manifests":[]}`))
+	}))
+
+	previousRegistryURL, previousAuthURL := dockerHubRegistryURL, dockerHubAuthURL
+	dockerHubRegistryURL, dockerHubAuthURL = server.URL, server.URL
+	return func() {
+		server.Close()
+		dockerHubRegistryURL, dockerHubAuthURL = previousRegistryURL, previousAuthURL
+	}
+}
+
+// analysisRules require a Referrers attestation of every workload, so each analysis waits on the registry
+var analysisRules = complianceRules{
+	AttestationPolicy: &securityv1.AttestationPolicy{
+		RequireSBOM:       true,
+		AttestationSource: securityv1.AttestationSourceReferrers,
+	},
+}
+
+var _ = Describe("Workload analysis", func() {
+	It("should analyze workloads in parallel up to the limit, keeping their order", func() {
+		var maxInFlight atomic.Int32
+		DeferCleanup(serveSlowReferrers(20*time.Millisecond, &maxInFlight))
+
+		deployments := newAnalysisWorkloads(20)
+		reconciler := &ImagePolicyReconciler{MaxConcurrentAnalyses: 4}
+		analyses := reconciler.analyzeWorkloads(context.Background(), deployments, []string{analysisRepository}, map[string]string{}, analysisRules)
+
+		Expect(analyses).To(HaveLen(len(deployments)))
+		for i, analysis := range analyses {
+			Expect(analysis.Status.Name).To(Equal(deployments[i].GetName()))
+			Expect(analysis.Status.CurrentDigest).To(Equal(fmt.Sprintf("sha256:%064d", i)))
+			Expect(analysis.Status.IsCompliant).To(BeFalse())
+			Expect(analysis.RepositoryCompliance).To(Equal(map[string]bool{analysisRepository: false}))
+		}
+		Expect(maxInFlight.Load()).To(BeNumerically(">", 1))
+		Expect(maxInFlight.Load()).To(BeNumerically("<=", 4))
+	})
+
+	It("should take one workload of each namespace in turn", func() {
+		deployments := newAnalysisWorkloads(6)
+		for i, namespace := range []string{"busy", "busy", "busy", "busy", "quiet", "other"} {
+			deployments[i].SetNamespace(namespace)
+		}
+		Expect(interleavedByNamespace(deployments)).To(Equal([]int{0, 4, 5, 1, 2, 3}))
+	})
+
+	It("should report the workloads of a namespace exceeding its timeout as unknown without holding up the others", func() {
+		var maxInFlight atomic.Int32
+		DeferCleanup(serveSlowReferrers(20*time.Millisecond, &maxInFlight))
+
+		// One at a time, the busy namespace needs 400ms, far over its timeout
+		deployments := newAnalysisWorkloads(21)
+		for i := range deployments {
+			deployments[i].SetNamespace("busy")
+		}
+		deployments[20].SetNamespace("quiet")
+		rules := analysisRules
+		rules.NamespaceAnalysisTimeout = 150 * time.Millisecond
+		reconciler := &ImagePolicyReconciler{MaxConcurrentAnalyses: 1}
+
+		started := time.Now()
+		analyses := reconciler.analyzeWorkloads(context.Background(), deployments, []string{analysisRepository}, map[string]string{}, rules)
+		Expect(time.Since(started)).To(BeNumerically("<", 300*time.Millisecond))
+
+		Expect(analyses[0].Status.Reason).To(Equal(securityv1.NonComplianceReasonAttestationFailed))
+		Expect(analyses[20].Status.Reason).To(Equal(securityv1.NonComplianceReasonAttestationFailed))
+		Expect(analyses[19].Status.Reason).To(Equal(securityv1.ComplianceReasonUnknown))
+		Expect(analyses[19].Status.Message).To(ContainSubstring("namespace busy didn't finish within the namespaceAnalysisTimeout of 150ms"))
+		Expect(analyses[19].RepositoryCompliance).To(BeNil())
+	})
+
+	It("should keep what the last reconcile recorded about a workload of unknown compliance", func() {
+		deployment := newAnalysisWorkloads(1)[0]
+		lastRemediated := metav1.NewTime(time.Now().Add(-time.Hour))
+		policy := &securityv1.ImagePolicy{Status: securityv1.ImagePolicyStatus{MonitoredDeployments: []securityv1.DeploymentStatus{{
+			Kind: deployment.Kind, Name: deployment.GetName(), Namespace: deployment.GetNamespace(),
+			IsCompliant: true, Reason: securityv1.ComplianceReasonCompliant, CurrentDigest: "sha256:previous", LastRemediated: &lastRemediated,
+		}}}}
+
+		status := timedOutAnalysis(deployment, time.Second).Status
+		keepUnknownStatus(policy, deployment, &status)
+		Expect(status.IsCompliant).To(BeFalse())
+		Expect(status.Reason).To(Equal(securityv1.ComplianceReasonUnknown))
+		Expect(status.CurrentDigest).To(Equal("sha256:previous"))
+		Expect(status.LastRemediated).To(Equal(&lastRemediated))
+	})
+})
+
+// BenchmarkAnalyzeWorkloads measures a policy over 50 workloads whose attestation checks take 10ms each:
+// about 540ms analyzed one at a time, 150ms four at a time and 85ms with the default of 8
+func BenchmarkAnalyzeWorkloads(b *testing.B) {
+	var maxInFlight atomic.Int32
+	b.Cleanup(serveSlowReferrers(10*time.Millisecond, &maxInFlight))
+
+	ctx := context.Background()
+	deployments := newAnalysisWorkloads(50)
+	for _, concurrency := range []int{1, 4, defaultMaxConcurrentAnalyses, 16} {
+		reconciler := &ImagePolicyReconciler{MaxConcurrentAnalyses: concurrency}
+		b.Run(fmt.Sprintf("concurrency/%d", concurrency), func(b *testing.B) {
+			for b.Loop() {
+				reconciler.analyzeWorkloads(ctx, deployments, []string{analysisRepository}, map[string]string{}, analysisRules)
+			}
+		})
+	}
+}
@@ -0,0 +1,483 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+	"github.com/jonlimpw/chainguard-controller/internal/rekor"
+)
+
+var _ = Describe("Attestation status", func() {
+	reconciler := &ImagePolicyReconciler{}
+	required := &securityv1.AttestationPolicy{RequireAttestation: ptr.To(true)}
+
+	verified := securityv1.DeploymentStatus{HasValidAttestation: ptr.To(true)}
+	unverified := securityv1.DeploymentStatus{
+		HasValidAttestation: ptr.To(false),
+		AttestationDetails:  &securityv1.AttestationDetails{Error: "issuer not allowed"},
+	}
+	rekorDown := securityv1.DeploymentStatus{
+		HasValidAttestation: ptr.To(false),
+		AttestationDetails:  &securityv1.AttestationDetails{Error: "Rekor verification failed", RekorUnavailable: true},
+	}
+
+	var policy *securityv1.ImagePolicy
+	conditionFor := func(attestationPolicy *securityv1.AttestationPolicy, statuses ...securityv1.DeploymentStatus) *metav1.Condition {
+		policy = &securityv1.ImagePolicy{}
+		reconciler.updateAttestationCondition(policy, attestationPolicy, statuses)
+		return meta.FindStatusCondition(policy.Status.Conditions, securityv1.ConditionTypeAttestationVerified)
+	}
+
+	It("should be true when every checked deployment is verified", func() {
+		condition := conditionFor(required, verified, securityv1.DeploymentStatus{})
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Reason).To(Equal("AllVerified"))
+	})
+
+	It("should report unverified deployments", func() {
+		condition := conditionFor(required, verified, unverified)
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("SomeUnverified"))
+		Expect(condition.Message).To(Equal("1 of 2 deployments lack a valid attestation"))
+		Expect(policy.Status.AttestationStatus).To(Equal("1/2 verified"))
+	})
+
+	It("should report Rekor outages over unverified deployments", func() {
+		condition := conditionFor(required, unverified, rekorDown)
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("RekorUnavailable"))
+	})
+
+	It("should not be set when attestations aren't required", func() {
+		Expect(conditionFor(nil, unverified)).To(BeNil())
+		Expect(policy.Status.AttestationStatus).To(Equal("N/A"))
+		Expect(conditionFor(&securityv1.AttestationPolicy{RequireAttestation: ptr.To(false)}, unverified)).To(BeNil())
+	})
+})
+
+var _ = Describe("SBOM and vulnerability requirements", func() {
+	const (
+		repository = "jonlimpw/cg-demo"
+		digest     = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	)
+
+	deployment := func() workload {
+		w, _ := newWorkload(&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "app", Image: repository + "@" + digest}},
+			}}},
+		})
+		return w
+	}
+
+	// serveStatementBy points DockerHub at a registry serving one DSSE attestation of the given predicate signed by signer
+	serveStatementBy := func(signer *attestationSigner, predicateType, predicate string) {
+		statement := `{"_type":"https://in-toto.io/Statement/v1","subject":[{"name":"docker.io/` + repository + `","digest":{"sha256":"` + strings.TrimPrefix(digest, "sha256:") + `"}}],"predicateType":"` + predicateType + `","predicate":` + predicate + `}`
+		served := newServedReferrerBy(signer, statement)
+
+		base := "/v2/" + repository
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/token":
+				_, _ = w.Write([]byte(`{"token":"test"}`))
+			default:
+				if !served.serve(w, r, base, digest) {
+					http.NotFound(w, r)
+				}
+			}
+		}))
+		previousRegistryURL, previousAuthURL := dockerHubRegistryURL, dockerHubAuthURL
+		dockerHubRegistryURL, dockerHubAuthURL = server.URL, server.URL
+		DeferCleanup(func() {
+			server.Close()
+			dockerHubRegistryURL, dockerHubAuthURL = previousRegistryURL, previousAuthURL
+		})
+	}
+
+	// serveStatement serves one attestation of the given predicate signed by a certificate of the test Fulcio
+	serveStatement := func(predicateType, predicate string) {
+		serveStatementBy(newAttestationSigner(), predicateType, predicate)
+	}
+
+	// serveReferrers serves one attestation of the given predicate type with an empty predicate
+	serveReferrers := func(predicateType string) {
+		serveStatement(predicateType, "{}")
+	}
+
+	analyze := func(reconciler *ImagePolicyReconciler, attestationPolicy *securityv1.AttestationPolicy) securityv1.DeploymentStatus {
+		return reconciler.analyzeDeploymentCompliance(context.Background(), deployment(), repository, digest,
+			complianceRules{EnforceLatest: true, AttestationPolicy: attestationPolicy})
+	}
+
+	referrers := &securityv1.AttestationPolicy{RequireSBOM: true, AttestationSource: securityv1.AttestationSourceReferrers}
+
+	It("should accept an SPDX or CycloneDX attestation", func() {
+		for _, predicateType := range []string{"https://spdx.dev/Document", "https://cyclonedx.org/bom"} {
+			serveReferrers(predicateType)
+
+			status := analyze(&ImagePolicyReconciler{}, referrers)
+			Expect(status.IsCompliant).To(BeTrue(), predicateType)
+			Expect(status.AttestationDetails.HasSBOM).To(Equal(ptr.To(true)), predicateType)
+			Expect(status.AttestationDetails.PredicateType).To(Equal(predicateType))
+		}
+	})
+
+	It("should reject a self-signed certificate against the public-good Fulcio root without a fulcioRootRef", func() {
+		previous := defaultFulcioRoot
+		defaultFulcioRoot = rekor.PublicGoodTrustRoot
+		DeferCleanup(func() { defaultFulcioRoot = previous })
+		serveStatementBy(newSelfSignedAttestationSigner(), "https://spdx.dev/Document", "{}")
+
+		status := analyze(&ImagePolicyReconciler{}, referrers)
+		Expect(status.IsCompliant).To(BeFalse())
+		Expect(status.Reason).To(Equal(securityv1.NonComplianceReasonAttestationFailed))
+		Expect(status.AttestationDetails.Error).To(ContainSubstring("doesn't chain to the Fulcio root"))
+	})
+
+	It("should mark a digest without an SBOM attestation as non-compliant", func() {
+		serveReferrers("https://slsa.dev/provenance/v1")
+
+		status := analyze(&ImagePolicyReconciler{}, referrers)
+		Expect(status.IsCompliant).To(BeFalse())
+		Expect(status.Reason).To(Equal(securityv1.NonComplianceReasonAttestationFailed))
+		Expect(status.AttestationDetails.HasSBOM).To(Equal(ptr.To(false)))
+		Expect(status.AttestationDetails.Error).To(HavePrefix("no SBOM attestation found"))
+		Expect(status.AttestationDetails.RekorUnavailable).To(BeFalse())
+	})
+
+	It("should only report failing attestations in Warn mode", func() {
+		serveReferrers("https://slsa.dev/provenance/v1")
+
+		attestationPolicy := referrers.DeepCopy()
+		attestationPolicy.Enforcement = securityv1.AttestationEnforcementWarn
+		status := analyze(&ImagePolicyReconciler{}, attestationPolicy)
+		Expect(status.IsCompliant).To(BeTrue())
+		Expect(status.Reason).To(Equal(securityv1.ComplianceReasonCompliant))
+		Expect(status.HasValidAttestation).To(Equal(ptr.To(false)))
+		Expect(status.AttestationDetails.HasSBOM).To(Equal(ptr.To(false)))
+		Expect(status.AttestationDetails.Error).To(HavePrefix("no SBOM attestation found"))
+	})
+
+	It("should emit an AttestationWarning event instead of flagging the workload in Warn mode", func() {
+		serveReferrers("https://slsa.dev/provenance/v1")
+
+		key := types.NamespacedName{Name: "warn", Namespace: "default"}
+		attestationPolicy := referrers.DeepCopy()
+		attestationPolicy.Enforcement = securityv1.AttestationEnforcementWarn
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Spec: securityv1.ImagePolicySpec{
+				Repository:          repository,
+				EnforceLatestDigest: ptr.To(false),
+				AttestationPolicy:   attestationPolicy,
+			},
+		}
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(policy, deployment().Object, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}).
+			WithStatusSubresource(policy).Build()
+		recorder := record.NewFakeRecorder(10)
+		reconciler := &ImagePolicyReconciler{Client: fakeClient, Recorder: recorder, DockerHubMaxRetries: 1}
+
+		_, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		updated := &securityv1.ImagePolicy{}
+		Expect(fakeClient.Get(context.Background(), key, updated)).To(Succeed())
+		Expect(updated.Status.MonitoredDeployments).To(HaveLen(1))
+		Expect(updated.Status.MonitoredDeployments[0].IsCompliant).To(BeTrue())
+		Expect(updated.Status.AttestationStatus).To(Equal("0/1 verified"))
+		Expect(recorder.Events).To(Receive(And(ContainSubstring("AttestationWarning"), ContainSubstring("Deployment default/demo"))))
+	})
+
+	It("should only remediate to a latest digest that passes the attestation policy itself", func() {
+		serveReferrers("https://spdx.dev/Document")
+
+		const outdated = "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+		const unattested = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+		running := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default", Labels: map[string]string{"automation": "true"}},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "app", Image: repository + "@" + outdated}},
+			}}},
+		}
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "target", Namespace: "default"},
+			Spec:       securityv1.ImagePolicySpec{Repository: repository, AttestationPolicy: referrers},
+		}
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(running).Build()
+		recorder := record.NewFakeRecorder(10)
+		reconciler := &ImagePolicyReconciler{Client: fakeClient, Recorder: recorder}
+
+		remediate := func(latestDigest string) string {
+			w, _ := newWorkload(running.DeepCopy())
+			status := &securityv1.DeploymentStatus{Repository: repository, CurrentDigest: outdated}
+			reconciler.handleRemediation(context.Background(), policy, w, status, reconciler.rulesFor(policy), map[string]string{repository: latestDigest}, securityv1.RemediationModeAuto, nil)
+
+			updated := &appsv1.Deployment{}
+			Expect(fakeClient.Get(context.Background(), types.NamespacedName{Name: "demo", Namespace: "default"}, updated)).To(Succeed())
+			return updated.Spec.Template.Spec.Containers[0].Image
+		}
+
+		Expect(remediate(unattested)).To(Equal(repository + "@" + outdated))
+		Expect(recorder.Events).To(Receive(And(ContainSubstring("RemediationBlockedUnverifiedTarget"), ContainSubstring(unattested))))
+
+		Expect(remediate(digest)).To(Equal(repository + "@" + digest))
+		Expect(recorder.Events).To(Receive(ContainSubstring("AutoRemediated")))
+	})
+
+	It("should remediate to unverified digests when attestations are only reported", func() {
+		const outdated = "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+		attestationPolicy := referrers.DeepCopy()
+		attestationPolicy.Enforcement = securityv1.AttestationEnforcementWarn
+		policy := &securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{Repository: repository, AttestationPolicy: attestationPolicy}}
+		w, _ := newWorkload(&appsv1.Deployment{Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Image: repository + "@" + outdated}},
+		}}}})
+
+		reconciler := &ImagePolicyReconciler{}
+		_, _, result := reconciler.unverifiedRemediationTarget(context.Background(), w, reconciler.rulesFor(policy), map[string]string{repository: digest})
+		Expect(result).To(BeNil())
+	})
+
+	It("should fail the policy's other attestation requirements when only the SBOM is present", func() {
+		serveReferrers("https://spdx.dev/Document")
+
+		attestationPolicy := referrers.DeepCopy()
+		attestationPolicy.RequireAttestation = ptr.To(true)
+		attestationPolicy.RequiredTypes = []string{"slsaprovenance1"}
+		status := analyze(&ImagePolicyReconciler{}, attestationPolicy)
+		Expect(status.IsCompliant).To(BeFalse())
+		Expect(status.AttestationDetails.HasSBOM).To(Equal(ptr.To(true)))
+		Expect(status.AttestationDetails.Error).To(ContainSubstring("not in required list"))
+	})
+
+	It("should tell an unreachable Rekor apart from a missing SBOM", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		DeferCleanup(server.Close)
+		rekorClient, err := rekor.NewClient(server.URL, testRekorPublicKey())
+		Expect(err).NotTo(HaveOccurred())
+
+		status := analyze(&ImagePolicyReconciler{RekorClient: rekorClient}, &securityv1.AttestationPolicy{RequireSBOM: true})
+		Expect(status.IsCompliant).To(BeFalse())
+		Expect(status.AttestationDetails.HasSBOM).To(Equal(ptr.To(false)))
+		Expect(status.AttestationDetails.Error).To(HavePrefix("SBOM could not be checked, attestation source unreachable"))
+		Expect(status.AttestationDetails.RekorUnavailable).To(BeTrue())
+	})
+
+	Context("with a maximum vulnerability severity", func() {
+		const vulnPredicateType = "https://cosign.sigstore.dev/attestation/vuln/v1"
+		scanPolicy := &securityv1.AttestationPolicy{
+			MaxVulnerabilitySeverity: securityv1.VulnerabilitySeverityHigh,
+			AttestationSource:        securityv1.AttestationSourceReferrers,
+		}
+
+		It("should mark a digest whose trivy scan reports findings at the threshold or above as non-compliant", func() {
+			serveStatement(vulnPredicateType, `{"scanner":{"uri":"pkg:github/aquasecurity/trivy","version":"0.50.0","result":`+
+				`{"Results":[{"Vulnerabilities":[{"VulnerabilityID":"CVE-2024-0001","Severity":"CRITICAL"},`+
+				`{"VulnerabilityID":"CVE-2024-0002","Severity":"HIGH"},{"VulnerabilityID":"CVE-2024-0003","Severity":"LOW"}]}]}}}`)
+
+			status := analyze(&ImagePolicyReconciler{}, scanPolicy)
+			Expect(status.IsCompliant).To(BeFalse())
+			Expect(status.Reason).To(Equal(securityv1.NonComplianceReasonAttestationFailed))
+			Expect(status.AttestationDetails.VulnerabilityCount).To(Equal(ptr.To(int32(2))))
+			Expect(status.AttestationDetails.Error).To(Equal(
+				"vulnerability scan by pkg:github/aquasecurity/trivy@0.50.0 reports 2 finding(s) of severity High or higher"))
+		})
+
+		It("should accept a digest whose grype scan only reports findings below the threshold", func() {
+			serveStatement(vulnPredicateType, `{"scanner":{"uri":"pkg:github/anchore/grype","version":"0.74.0","result":`+
+				`{"matches":[{"vulnerability":{"id":"CVE-2024-0004","severity":"Medium"}},{"vulnerability":{"id":"CVE-2024-0005","severity":"Negligible"}}]}}}`)
+
+			status := analyze(&ImagePolicyReconciler{}, scanPolicy)
+			Expect(status.IsCompliant).To(BeTrue())
+			Expect(status.AttestationDetails.Verified).To(BeTrue())
+			Expect(status.AttestationDetails.VulnerabilityCount).To(Equal(ptr.To(int32(0))))
+			Expect(status.AttestationDetails.Scanner).To(Equal("pkg:github/anchore/grype@0.74.0"))
+		})
+
+		It("should mark a digest without a vulnerability scan attestation as non-compliant", func() {
+			serveReferrers("https://slsa.dev/provenance/v1")
+
+			status := analyze(&ImagePolicyReconciler{}, scanPolicy)
+			Expect(status.IsCompliant).To(BeFalse())
+			Expect(status.AttestationDetails.VulnerabilityCount).To(BeNil())
+			Expect(status.AttestationDetails.Error).To(HavePrefix("no vulnerability scan attestation found"))
+		})
+	})
+})
+
+var _ = Describe("Tag resolution for attestations", func() {
+	const (
+		repository = "jonlimpw/cg-demo"
+		digest     = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	)
+
+	deployment := func() workload {
+		w, _ := newWorkload(&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "app", Image: repository + ":v1"}},
+			}}},
+		})
+		return w
+	}
+
+	BeforeEach(func() {
+		statement := `{"_type":"https://in-toto.io/Statement/v1","subject":[{"name":"docker.io/` + repository + `","digest":{"sha256":"` + strings.TrimPrefix(digest, "sha256:") + `"}}],"predicateType":"https://spdx.dev/Document","predicate":{}}`
+		served := newServedReferrer(statement)
+
+		base := "/v2/" + repository
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/token":
+				_, _ = w.Write([]byte(`{"token":"test"}`))
+			case base + "/manifests/v1":
+				w.Header().Set("Docker-Content-Digest", digest)
+			default:
+				if !served.serve(w, r, base, digest) {
+					http.NotFound(w, r)
+				}
+			}
+		}))
+		previousRegistryURL, previousAuthURL := dockerHubRegistryURL, dockerHubAuthURL
+		dockerHubRegistryURL, dockerHubAuthURL = server.URL, server.URL
+		DeferCleanup(func() {
+			server.Close()
+			dockerHubRegistryURL, dockerHubAuthURL = previousRegistryURL, previousAuthURL
+		})
+	})
+
+	attestationPolicy := &securityv1.AttestationPolicy{RequireSBOM: true, ResolveTags: true, AttestationSource: securityv1.AttestationSourceReferrers}
+
+	It("should verify a tag-based image against the digest its tag resolves to", func() {
+		reconciler := &ImagePolicyReconciler{}
+		policy := &securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{Repository: repository, AttestationPolicy: attestationPolicy}}
+		tagDigests := reconciler.resolveTagDigests(context.Background(), policy, []workload{deployment()}, []string{repository}, time.Minute)
+		Expect(tagDigests).To(Equal(map[string]string{repository + ":v1": digest}))
+
+		status := reconciler.analyzeDeploymentCompliance(context.Background(), deployment(), repository, digest,
+			complianceRules{EnforceLatest: true, AttestationPolicy: attestationPolicy, TagDigests: tagDigests})
+		Expect(status.HasValidAttestation).To(Equal(ptr.To(true)))
+		Expect(status.AttestationDetails.ResolvedDigest).To(Equal(digest))
+		Expect(status.AttestationDetails.HasSBOM).To(Equal(ptr.To(true)))
+
+		// Verified attestations don't make a tag compliant with enforceLatestDigest
+		Expect(status.IsCompliant).To(BeFalse())
+		Expect(status.Reason).To(Equal(securityv1.NonComplianceReasonTagBased))
+	})
+
+	It("should report a tag that couldn't be resolved", func() {
+		status := (&ImagePolicyReconciler{}).analyzeDeploymentCompliance(context.Background(), deployment(), repository, digest,
+			complianceRules{AttestationPolicy: attestationPolicy})
+		Expect(status.IsCompliant).To(BeFalse())
+		Expect(status.Reason).To(Equal(securityv1.NonComplianceReasonAttestationFailed))
+		Expect(status.AttestationDetails.Error).To(ContainSubstring("failed to resolve its tag"))
+	})
+
+	It("should still require a digest without resolveTags", func() {
+		status := (&ImagePolicyReconciler{}).analyzeDeploymentCompliance(context.Background(), deployment(), repository, digest,
+			complianceRules{AttestationPolicy: &securityv1.AttestationPolicy{RequireSBOM: true, AttestationSource: securityv1.AttestationSourceReferrers},
+				TagDigests: map[string]string{repository + ":v1": digest}})
+		Expect(status.IsCompliant).To(BeFalse())
+		Expect(status.AttestationDetails.Error).To(ContainSubstring("digest required"))
+	})
+})
+
+var _ = Describe("Attestation failure modes", func() {
+	const (
+		repository = "jonlimpw/cg-demo"
+		digest     = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	)
+
+	analyze := func(reconciler *ImagePolicyReconciler, attestationPolicy *securityv1.AttestationPolicy) securityv1.DeploymentStatus {
+		w, _ := newWorkload(&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "app", Image: repository + "@" + digest}},
+			}}},
+		})
+		return reconciler.analyzeDeploymentCompliance(context.Background(), w, repository, digest,
+			complianceRules{EnforceLatest: true, AttestationPolicy: attestationPolicy})
+	}
+
+	It("should fail closed by default while Rekor is unavailable", func() {
+		status := analyze(&ImagePolicyReconciler{}, &securityv1.AttestationPolicy{RequireAttestation: ptr.To(true)})
+		Expect(status.IsCompliant).To(BeFalse())
+		Expect(status.Reason).To(Equal(securityv1.NonComplianceReasonAttestationFailed))
+		Expect(status.AttestationDetails.RekorUnavailable).To(BeTrue())
+	})
+
+	It("should leave compliance to digests while Rekor is unavailable when failing open", func() {
+		attestationPolicy := &securityv1.AttestationPolicy{RequireAttestation: ptr.To(true), FailureMode: securityv1.AttestationFailureModeOpen}
+		reconciler := &ImagePolicyReconciler{}
+		status := analyze(reconciler, attestationPolicy)
+		Expect(status.IsCompliant).To(BeTrue())
+		Expect(status.Reason).To(Equal(securityv1.ComplianceReasonCompliant))
+		Expect(status.HasValidAttestation).To(Equal(ptr.To(false)))
+		Expect(status.AttestationDetails.RekorUnavailable).To(BeTrue())
+
+		policy := &securityv1.ImagePolicy{}
+		reconciler.updateAttestationCondition(policy, attestationPolicy, []securityv1.DeploymentStatus{status})
+		condition := meta.FindStatusCondition(policy.Status.Conditions, securityv1.ConditionTypeAttestationVerified)
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("RekorUnavailable"))
+		Expect(condition.Message).To(ContainSubstring("failing open"))
+	})
+
+	It("should still fail attestations that were checked when failing open", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/token":
+				_, _ = w.Write([]byte(`{"token":"test"}`))
+			case strings.Contains(r.URL.Path, "/referrers/"):
+				_, _ = w.Write([]byte(`{"manifests":[]}`))
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		DeferCleanup(server.Close)
+		reconciler := &ImagePolicyReconciler{DockerHubRegistryURL: server.URL, DockerHubAuthURL: server.URL}
+
+		status := analyze(reconciler, &securityv1.AttestationPolicy{RequireAttestation: ptr.To(true),
+			AttestationSource: securityv1.AttestationSourceReferrers, FailureMode: securityv1.AttestationFailureModeOpen})
+		Expect(status.IsCompliant).To(BeFalse())
+		Expect(status.Reason).To(Equal(securityv1.NonComplianceReasonAttestationFailed))
+		Expect(status.AttestationDetails.RekorUnavailable).To(BeFalse())
+	})
+})
@@ -0,0 +1,168 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/jonlimpw/chainguard-controller/internal/registry"
+)
+
+const (
+	// defaultDockerHubMaxRetries is the number of DockerHub attempts per digest fetch when not configured
+	defaultDockerHubMaxRetries = 3
+
+	// dockerHubBackoffBase and dockerHubBackoffCap bound the exponential retry delay
+	dockerHubBackoffBase = 1 * time.Second
+	dockerHubBackoffCap  = 30 * time.Second
+)
+
+// retryableError marks a transient network failure talking to DockerHub as worth retrying
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+
+func (e *retryableError) Unwrap() error { return e.err }
+
+// rateLimitedError is returned when DockerHub keeps answering 429 through every attempt, or asks us
+// to wait longer than we are willing to block a reconcile for; the caller should requeue instead
+type rateLimitedError struct {
+	RetryAfter time.Duration
+	Attempts   int
+	Err        error
+}
+
+func (e *rateLimitedError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("rate limited by DockerHub after %d attempts, retry after %s", e.Attempts, e.RetryAfter)
+	}
+	return fmt.Sprintf("rate limited by DockerHub after %d attempts", e.Attempts)
+}
+
+func (e *rateLimitedError) Unwrap() error { return e.Err }
+
+// throttledError is returned when the controller's own DockerHub rate limit is exhausted; the caller
+// should requeue after RetryAfter instead of waiting for a token
+type throttledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *throttledError) Error() string {
+	return fmt.Sprintf("DockerHub request rate limit reached, retry after %s", e.RetryAfter)
+}
+
+// retryDelay reports whether a DockerHub failure is worth retrying (429, 5xx or a transient network
+// error), and the delay the registry asked for with Retry-After, if any
+func retryDelay(err error) (time.Duration, bool) {
+	var rateLimit *registry.RateLimitError
+	var server *registry.ServerError
+	var network *retryableError
+	switch {
+	case errors.As(err, &rateLimit):
+		return rateLimit.RetryAfter, true
+	case errors.As(err, &server):
+		return server.RetryAfter, true
+	case errors.As(err, &network):
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// backoffDelay returns a full-jitter exponential delay for the given retry attempt (1-based)
+func backoffDelay(attempt int) time.Duration {
+	ceiling := dockerHubBackoffCap
+	if shift := attempt - 1; shift < 30 {
+		if exp := dockerHubBackoffBase << shift; exp < ceiling {
+			ceiling = exp
+		}
+	}
+	return rand.N(ceiling) + 1
+}
+
+// networkError marks a transport failure as retryable unless the reconcile context itself is done
+func networkError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return err
+	}
+	return &retryableError{err: err}
+}
+
+// retryDockerHub calls fetch up to maxRetries times (defaultDockerHubMaxRetries when not positive),
+// backing off between transient failures. Retry-After windows longer than dockerHubBackoffCap, and
+// 429s on every attempt, return a rateLimitedError so the reconcile requeues instead of holding the worker.
+func retryDockerHub[T any](ctx context.Context, maxRetries int, fetch func() (T, error)) (T, error) {
+	log := logf.FromContext(ctx)
+	var zero T
+
+	if maxRetries <= 0 {
+		maxRetries = defaultDockerHubMaxRetries
+	}
+
+	var lastErr error
+	var retryAfter time.Duration
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		if attempt > 0 {
+			delay := backoffDelay(attempt)
+			if retryAfter > 0 {
+				delay = retryAfter
+			}
+			log.Info("Retrying DockerHub API request", "attempt", attempt+1, "delay", delay)
+			select {
+			case <-ctx.Done():
+				return zero, ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		result, err := fetch()
+		if err != nil {
+			delay, retryable := retryDelay(err)
+			if !retryable {
+				// For non-transient errors, return immediately
+				return zero, err
+			}
+
+			// Don't hold the worker for long Retry-After windows, let the reconcile requeue instead
+			if delay > dockerHubBackoffCap {
+				log.Info("DockerHub requested a long retry delay, requeueing", "retryAfter", delay)
+				return zero, &rateLimitedError{RetryAfter: delay, Attempts: attempt + 1, Err: err}
+			}
+
+			var rateLimit *registry.RateLimitError
+			log.Info("Transient DockerHub error, will retry", "attempt", attempt+1, "rateLimited", errors.As(err, &rateLimit), "error", err.Error())
+			lastErr = err
+			retryAfter = delay
+			continue
+		}
+		return result, nil
+	}
+
+	var rateLimit *registry.RateLimitError
+	if errors.As(lastErr, &rateLimit) {
+		return zero, &rateLimitedError{RetryAfter: retryAfter, Attempts: maxRetries, Err: lastErr}
+	}
+	return zero, fmt.Errorf("failed to fetch from DockerHub after %d attempts: %w", maxRetries, lastErr)
+}
@@ -0,0 +1,270 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/record"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+	"github.com/jonlimpw/chainguard-controller/internal/registry"
+	"github.com/jonlimpw/chainguard-controller/internal/registry/registrytest"
+)
+
+var _ = Describe("DockerHub requests", func() {
+	It("should abort the token request when the reconcile context is cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		start := time.Now()
+		_, err := (&ImagePolicyReconciler{}).fetchDockerHubToken(ctx, "library/nginx", nil, "")
+		Expect(err).To(MatchError(context.Canceled))
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+
+		// A cancelled reconcile must not be retried
+		var retryable *retryableError
+		Expect(errors.As(err, &retryable)).To(BeFalse())
+	})
+	It("should requeue instead of blocking when the shared rate limit is exhausted", func() {
+		limiter := rate.NewLimiter(rate.Every(time.Minute), 1)
+		Expect(limiter.Allow()).To(BeTrue())
+		reconciler := &ImagePolicyReconciler{DockerHubRateLimiter: limiter}
+
+		start := time.Now()
+		_, err := reconciler.getLatestDigestFromDockerHub(context.Background(), digestRequest{Repository: "library/nginx", Tag: "latest"}, time.Minute)
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+
+		var throttled *throttledError
+		Expect(errors.As(err, &throttled)).To(BeTrue())
+		Expect(throttled.RetryAfter).To(BeNumerically("~", time.Minute, time.Second))
+
+		// The rejected request must not consume the next token
+		Expect(limiter.Tokens()).To(BeNumerically("<", 0.1))
+		Expect(limiter.Tokens()).To(BeNumerically(">", -0.1))
+	})
+
+	It("should serve cached digests without taking a token", func() {
+		limiter := rate.NewLimiter(rate.Every(time.Minute), 1)
+		Expect(limiter.Allow()).To(BeTrue())
+		reconciler := &ImagePolicyReconciler{DockerHubRateLimiter: limiter}
+		digestReq := digestRequest{Repository: "library/nginx", Tag: "latest"}
+		reconciler.getDigestCache().set(digestReq.cacheKey(), "sha256:1111111111111111111111111111111111111111111111111111111111111111")
+
+		digest, err := reconciler.getLatestDigestFromDockerHub(context.Background(), digestReq, time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(digest).To(HavePrefix("sha256:"))
+	})
+	It("should cap the registry requests in flight across concurrent reconciles", func() {
+		var inFlight, maxInFlight atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for previous := maxInFlight.Load(); current > previous && !maxInFlight.CompareAndSwap(previous, current); previous = maxInFlight.Load() {
+			}
+			time.Sleep(20 * time.Millisecond)
+
+			if r.URL.Path == "/token" {
+				_, _ = w.Write([]byte(`{"token":"test"}`))
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", "sha256:1111111111111111111111111111111111111111111111111111111111111111")
+		}))
+		DeferCleanup(server.Close)
+		reconciler := &ImagePolicyReconciler{DockerHubRegistryURL: server.URL, DockerHubAuthURL: server.URL, MaxParallelRegistryRequests: 2}
+
+		var wg sync.WaitGroup
+		for i := range 8 {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				repository := fmt.Sprintf("jonlimpw/app-%d", i)
+				policy := &securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{Repository: repository}}
+				latestDigests, _, _, err := reconciler.fetchLatestDigests(context.Background(), policy, []string{repository}, []string{"latest"}, 60)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(latestDigests[repository]).To(HavePrefix("sha256:1111"))
+			}()
+		}
+		wg.Wait()
+
+		Expect(maxInFlight.Load()).To(BeNumerically("<=", 2))
+		Expect(maxInFlight.Load()).To(BeNumerically(">", 1), "requests should still run in parallel up to the cap")
+	})
+
+	Context("with a mock registry", func() {
+		var manifestRequests int
+
+		BeforeEach(func() {
+			manifestRequests = 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/token":
+					_, _ = w.Write([]byte(`{"token":"test"}`))
+				case strings.HasPrefix(r.URL.Path, "/v2/jonlimpw/shared/manifests/"):
+					manifestRequests++
+					// Hold the response so concurrent reconciles pile up behind the first fetch
+					time.Sleep(200 * time.Millisecond)
+					w.Header().Set("Docker-Content-Digest", "sha256:1111111111111111111111111111111111111111111111111111111111111111")
+				case strings.HasPrefix(r.URL.Path, "/v2/jonlimpw/throttled/manifests/"):
+					manifestRequests++
+					w.Header().Set("Retry-After", "0")
+					http.Error(w, `{"errors":[{"code":"TOOMANYREQUESTS"}]}`, http.StatusTooManyRequests)
+				case strings.HasPrefix(r.URL.Path, "/v2/jonlimpw/missing/manifests/"):
+					manifestRequests++
+					http.Error(w, `{"errors":[{"code":"MANIFEST_UNKNOWN"}]}`, http.StatusNotFound)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			previousRegistryURL, previousAuthURL := dockerHubRegistryURL, dockerHubAuthURL
+			dockerHubRegistryURL, dockerHubAuthURL = server.URL, server.URL
+			DeferCleanup(func() {
+				server.Close()
+				dockerHubRegistryURL, dockerHubAuthURL = previousRegistryURL, previousAuthURL
+			})
+		})
+
+		It("should share a single fetch between concurrent reconciles", func() {
+			reconciler := &ImagePolicyReconciler{}
+			digestReq := digestRequest{Repository: "jonlimpw/shared", Tag: "latest"}
+
+			var wg sync.WaitGroup
+			digests := make([]string, 5)
+			for i := range digests {
+				wg.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+					digest, err := reconciler.getLatestDigestFromDockerHub(context.Background(), digestReq, time.Minute)
+					Expect(err).NotTo(HaveOccurred())
+					digests[i] = digest
+				}()
+			}
+			wg.Wait()
+
+			Expect(manifestRequests).To(Equal(1))
+			for _, digest := range digests {
+				Expect(digest).To(HavePrefix("sha256:1111"))
+			}
+		})
+
+		It("should flag a policy DockerHub keeps rate limiting and back off", func() {
+			recorder := record.NewFakeRecorder(1)
+			reconciler := &ImagePolicyReconciler{Recorder: recorder, DockerHubMaxRetries: 2}
+			policy := &securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{Repository: "jonlimpw/throttled"}}
+
+			latestDigests, _, requeueAfter, err := reconciler.fetchLatestDigests(context.Background(), policy, []string{"jonlimpw/throttled"}, []string{"latest"}, 60)
+			Expect(err).To(MatchError(ContainSubstring("rate limited by DockerHub")))
+			Expect(manifestRequests).To(Equal(2))
+			Expect(latestDigests).To(HaveKeyWithValue("jonlimpw/throttled", ""))
+			Expect(requeueAfter).To(Equal(time.Minute), "a throttled policy waits for the next interval")
+			Expect(policy.Status.ComplianceStatus).To(Equal(securityv1.ComplianceStatusError))
+
+			degraded := meta.FindStatusCondition(policy.Status.Conditions, securityv1.ConditionTypeDegraded)
+			Expect(degraded).NotTo(BeNil())
+			Expect(degraded.Reason).To(Equal("RateLimited"))
+			Expect(recorder.Events).To(Receive(And(ContainSubstring("DockerHubRateLimited"),
+				ContainSubstring("jonlimpw/throttled"), ContainSubstring("after 2 attempts"))))
+
+			// The condition clears once DockerHub answers again
+			_, _, _, err = reconciler.fetchLatestDigests(context.Background(), policy, []string{"jonlimpw/shared"}, []string{"latest"}, 60)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(meta.FindStatusCondition(policy.Status.Conditions, securityv1.ConditionTypeDegraded)).To(BeNil())
+		})
+
+		It("should report a repository that doesn't exist and back off", func() {
+			reconciler := &ImagePolicyReconciler{}
+			policy := &securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{Repository: "jonlimpw/missing"}}
+
+			latestDigests, statuses, requeueAfter, err := reconciler.fetchLatestDigests(context.Background(), policy, []string{"jonlimpw/missing"}, []string{"latest"}, 60)
+			Expect(err).NotTo(HaveOccurred(), "a missing repository isn't a reconcile failure")
+			Expect(manifestRequests).To(Equal(1), "a 404 must not be retried")
+			Expect(latestDigests).To(HaveKeyWithValue("jonlimpw/missing", ""))
+			Expect(statuses).To(HaveLen(1))
+			Expect(statuses[0].NotFound).To(BeTrue())
+			Expect(requeueAfter).To(Equal(notFoundCheckInterval))
+			Expect(policy.Status.ComplianceStatus).To(Equal(securityv1.ComplianceStatusError))
+
+			degraded := meta.FindStatusCondition(policy.Status.Conditions, securityv1.ConditionTypeDegraded)
+			Expect(degraded).NotTo(BeNil())
+			Expect(degraded.Reason).To(Equal("RepositoryNotFound"))
+
+			// The next reconcile within the back-off window doesn't ask DockerHub again
+			policy.Status.Repositories = statuses
+			_, statuses, _, _ = reconciler.fetchLatestDigests(context.Background(), policy, []string{"jonlimpw/missing"}, []string{"latest"}, 60)
+			Expect(manifestRequests).To(Equal(1))
+			Expect(statuses[0].NotFound).To(BeTrue())
+		})
+	})
+
+	Context("with a fake registry", func() {
+		var fakeRegistry *registrytest.Registry
+		var reconciler *ImagePolicyReconciler
+
+		BeforeEach(func() {
+			fakeRegistry = registrytest.NewRegistry()
+			DeferCleanup(fakeRegistry.Close)
+			reconciler = &ImagePolicyReconciler{DockerHubRegistryURL: fakeRegistry.URL, DockerHubAuthURL: fakeRegistry.URL}
+		})
+
+		It("should retry rate limited and failed requests until the digest is served", func() {
+			digest := fakeRegistry.PutImage("jonlimpw/cg-demo", "latest")
+			fakeRegistry.FailToken(registrytest.Failure{StatusCode: http.StatusTooManyRequests, RetryAfter: "0"})
+			fakeRegistry.FailManifest("jonlimpw/cg-demo", "latest", registrytest.Failure{StatusCode: http.StatusServiceUnavailable})
+
+			fetched, err := reconciler.getLatestDigestFromDockerHub(context.Background(), digestRequest{Repository: "jonlimpw/cg-demo", Tag: "latest"}, time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fetched).To(Equal(digest))
+			Expect(fakeRegistry.TokenRequests()).To(Equal(3))
+			Expect(fakeRegistry.ManifestRequests("jonlimpw/cg-demo", "latest")).To(Equal(2))
+		})
+
+		It("should give up on a repository rate limited on every attempt", func() {
+			reconciler.DockerHubMaxRetries = 2
+			fakeRegistry.PutImage("jonlimpw/cg-demo", "latest")
+			fakeRegistry.FailManifest("jonlimpw/cg-demo", "latest",
+				registrytest.Failure{StatusCode: http.StatusTooManyRequests, RetryAfter: "0"},
+				registrytest.Failure{StatusCode: http.StatusTooManyRequests, RetryAfter: "0"})
+
+			_, err := reconciler.getLatestDigestFromDockerHub(context.Background(), digestRequest{Repository: "jonlimpw/cg-demo", Tag: "latest"}, time.Minute)
+			var rateLimited *rateLimitedError
+			Expect(errors.As(err, &rateLimited)).To(BeTrue())
+			Expect(rateLimited.Attempts).To(Equal(2))
+			Expect(fakeRegistry.ManifestRequests("jonlimpw/cg-demo", "latest")).To(Equal(2))
+		})
+
+		It("should not retry a tag that doesn't exist", func() {
+			_, err := reconciler.getLatestDigestFromDockerHub(context.Background(), digestRequest{Repository: "nginx", Tag: "missing"}, time.Minute)
+			var notFound *registry.NotFoundError
+			Expect(errors.As(err, &notFound)).To(BeTrue())
+			Expect(fakeRegistry.ManifestRequests("library/nginx", "missing")).To(Equal(1))
+		})
+	})
+})
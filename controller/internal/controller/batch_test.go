@@ -0,0 +1,147 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Batch workloads", func() {
+	const (
+		repository   = "jonlimpw/cg-demo"
+		latestDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		staleDigest  = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	)
+
+	ctx := context.Background()
+	var recorder *record.FakeRecorder
+	var reconciler *ImagePolicyReconciler
+	policy := &securityv1.ImagePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+		Spec:       securityv1.ImagePolicySpec{Repository: repository},
+	}
+
+	podTemplate := corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+		Containers: []corev1.Container{{Name: "batch", Image: repository + "@" + staleDigest}},
+	}}
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"automation": "true"}}
+	}
+
+	BeforeEach(func() {
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &ImagePolicyReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+				&batchv1.CronJob{ObjectMeta: meta("nightly"), Spec: batchv1.CronJobSpec{Schedule: "@daily",
+					JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: *podTemplate.DeepCopy()}}}},
+				&batchv1.Job{ObjectMeta: meta("migrate"), Spec: batchv1.JobSpec{Template: *podTemplate.DeepCopy()}},
+			).Build(),
+			Recorder: recorder,
+		}
+	})
+
+	// listBatchWorkload returns the listed workload of the given kind
+	listBatchWorkload := func(kind string) workload {
+		workloads, err := reconciler.listWorkloads(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		for _, w := range workloads {
+			if w.Kind == kind {
+				return w
+			}
+		}
+		Fail("no " + kind + " listed")
+		return workload{}
+	}
+
+	It("should analyze the containers of job templates", func() {
+		for _, kind := range []string{securityv1.WorkloadKindCronJob, securityv1.WorkloadKindJob} {
+			w := listBatchWorkload(kind)
+			Expect(reconciler.deploymentUsesRepository(w, repository)).To(BeTrue())
+
+			status := reconciler.analyzeDeploymentCompliance(ctx, w, repository, latestDigest, complianceRules{EnforceLatest: true})
+			Expect(status.Kind).To(Equal(kind))
+			Expect(status.IsCompliant).To(BeFalse())
+			Expect(status.Reason).To(Equal(securityv1.NonComplianceReasonOutdatedDigest))
+			Expect(status.ContainerName).To(Equal("batch"))
+		}
+	})
+
+	It("should patch the job template of a CronJob", func() {
+		w := listBatchWorkload(securityv1.WorkloadKindCronJob)
+		status := &securityv1.DeploymentStatus{Kind: w.Kind, Name: "nightly", Namespace: "default", Repository: repository, CurrentDigest: staleDigest}
+		reconciler.handleRemediation(ctx, policy.DeepCopy(), w, status, reconciler.rulesFor(policy), map[string]string{repository: latestDigest}, securityv1.RemediationModeAuto, nil)
+		Expect(recorder.Events).To(Receive(ContainSubstring("AutoRemediated")))
+
+		cronJob := &batchv1.CronJob{}
+		Expect(reconciler.Get(ctx, types.NamespacedName{Name: "nightly", Namespace: "default"}, cronJob)).To(Succeed())
+		Expect(cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Image).To(Equal(repository + "@" + latestDigest))
+	})
+
+	It("should only report remediation of a Job", func() {
+		w := listBatchWorkload(securityv1.WorkloadKindJob)
+		status := &securityv1.DeploymentStatus{Kind: w.Kind, Name: "migrate", Namespace: "default", Repository: repository, CurrentDigest: staleDigest}
+		reconciler.handleRemediation(ctx, policy.DeepCopy(), w, status, reconciler.rulesFor(policy), map[string]string{repository: latestDigest}, securityv1.RemediationModeAuto, nil)
+		Expect(recorder.Events).To(Receive(And(ContainSubstring("WouldRemediate"), ContainSubstring("Jobs are immutable"))))
+		Expect(status.ProposedDigest).To(Equal(latestDigest))
+
+		job := &batchv1.Job{}
+		Expect(reconciler.Get(ctx, types.NamespacedName{Name: "migrate", Namespace: "default"}, job)).To(Succeed())
+		Expect(job.Spec.Template.Spec.Containers[0].Image).To(Equal(repository + "@" + staleDigest))
+	})
+
+	It("should skip Jobs created by a CronJob and finished Jobs", func() {
+		owned := &batchv1.Job{ObjectMeta: meta("nightly-28000000"), Spec: batchv1.JobSpec{Template: *podTemplate.DeepCopy()}}
+		owned.OwnerReferences = []metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "CronJob", Name: "nightly", UID: "uid", Controller: ptr.To(true)}}
+		finished := func(name string, condition batchv1.JobConditionType) *batchv1.Job {
+			return &batchv1.Job{ObjectMeta: meta(name), Spec: batchv1.JobSpec{Template: *podTemplate.DeepCopy()},
+				Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{{Type: condition, Status: corev1.ConditionTrue}}}}
+		}
+		completed, failed := finished("backfill", batchv1.JobComplete), finished("seed", batchv1.JobFailed)
+		for _, job := range []*batchv1.Job{owned, completed, failed} {
+			Expect(reconciler.Create(ctx, job)).To(Succeed())
+		}
+
+		workloads, err := reconciler.listWorkloads(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		var jobs []string
+		for _, w := range workloads {
+			if w.Kind == securityv1.WorkloadKindJob {
+				jobs = append(jobs, w.GetName())
+			}
+		}
+		Expect(jobs).To(ConsistOf("migrate"))
+
+		w, _ := newWorkload(completed)
+		status := &securityv1.DeploymentStatus{Kind: w.Kind, Name: "backfill", Namespace: "default", Repository: repository, CurrentDigest: staleDigest}
+		reconciler.handleRemediation(ctx, policy.DeepCopy(), w, status, reconciler.rulesFor(policy), map[string]string{repository: latestDigest}, securityv1.RemediationModeAuto, nil)
+		Expect(recorder.Events).NotTo(Receive())
+		Expect(status.ProposedDigest).To(BeEmpty())
+	})
+})
@@ -0,0 +1,93 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+// CheckRequest describes an ad-hoc check of a repository, as an ImagePolicy would run it
+type CheckRequest struct {
+	// Repository is the DockerHub repository to check
+	Repository string
+
+	// Tag is resolved to the latest digest (default: "latest")
+	Tag string
+
+	// Digest is verified instead of the latest digest, if set
+	Digest string
+
+	// Platform selects the platform manifest of multi-platform images, e.g. "linux/arm64"
+	Platform string
+
+	// AttestationPolicy is the attestation policy to verify the digest against; nil skips verification
+	AttestationPolicy *securityv1.AttestationPolicy
+}
+
+// CheckResult is the outcome of a CheckRequest
+type CheckResult struct {
+	Repository string `json:"repository"`
+	Tag        string `json:"tag"`
+
+	// LatestDigest is the digest Tag resolves to, empty when a digest was given
+	LatestDigest string `json:"latestDigest,omitempty"`
+
+	// Digest is the digest attestations were verified for
+	Digest string `json:"digest"`
+
+	// Attestation is the verification result, when the attestation policy requires one
+	Attestation *securityv1.AttestationDetails `json:"attestation,omitempty"`
+}
+
+// Check resolves and verifies a repository the way reconciles do, without a cluster: the latest digest is
+// fetched anonymously through the shared rate limit, retries and RegistryMirror, and attestations are
+// verified with the same Rekor or referrers code path.
+func (r *ImagePolicyReconciler) Check(ctx context.Context, req CheckRequest) (*CheckResult, error) {
+	policy := &securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{
+		Repository:        req.Repository,
+		Tag:               req.Tag,
+		Platform:          req.Platform,
+		AttestationPolicy: req.AttestationPolicy,
+	}}
+	if policy.Spec.Tag == "" {
+		policy.Spec.Tag = "latest"
+	}
+
+	result := &CheckResult{Repository: req.Repository, Tag: policy.Spec.Tag, Digest: req.Digest}
+	if result.Digest == "" {
+		digest, err := r.getLatestDigestFromDockerHub(ctx, digestRequest{
+			Repository: req.Repository,
+			Tag:        policy.Spec.Tag,
+			Platform:   req.Platform,
+			Mirror:     r.registryMirror(policy),
+			Proxy:      r.registryProxy(policy),
+			DigestType: policy.Spec.DigestType,
+		}, 0)
+		if err != nil {
+			return nil, err
+		}
+		result.LatestDigest, result.Digest = digest, digest
+	}
+
+	if req.AttestationPolicy.RequiresVerification() {
+		attestationResult, checks := r.verifyAttestationPolicy(ctx, req.Repository, result.Digest, req.AttestationPolicy, r.registryProxy(policy))
+		result.Attestation = newAttestationDetails(attestationResult, checks)
+	}
+	return result, nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Ad-hoc checks", func() {
+	const (
+		repository = "jonlimpw/cg-demo"
+		digest     = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	)
+
+	BeforeEach(func() {
+		statement := `{"_type":"https://in-toto.io/Statement/v1","subject":[{"name":"docker.io/` + repository + `","digest":{"sha256":"` + strings.TrimPrefix(digest, "sha256:") + `"}}],"predicateType":"https://cyclonedx.org/bom","predicate":{}}`
+		served := newServedReferrer(statement)
+
+		base := "/v2/" + repository
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/token":
+				_, _ = w.Write([]byte(`{"token":"test"}`))
+			case base + "/manifests/stable":
+				w.Header().Set("Docker-Content-Digest", digest)
+			default:
+				if !served.serve(w, r, base, digest) {
+					http.NotFound(w, r)
+				}
+			}
+		}))
+		previousRegistryURL, previousAuthURL := dockerHubRegistryURL, dockerHubAuthURL
+		dockerHubRegistryURL, dockerHubAuthURL = server.URL, server.URL
+		DeferCleanup(func() {
+			server.Close()
+			dockerHubRegistryURL, dockerHubAuthURL = previousRegistryURL, previousAuthURL
+		})
+	})
+
+	It("should resolve the latest digest without verifying when no attestation is required", func() {
+		result, err := (&ImagePolicyReconciler{}).Check(context.Background(), CheckRequest{Repository: repository, Tag: "stable"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(&CheckResult{Repository: repository, Tag: "stable", LatestDigest: digest, Digest: digest}))
+	})
+
+	It("should verify the resolved digest like a policy would", func() {
+		result, err := (&ImagePolicyReconciler{}).Check(context.Background(), CheckRequest{
+			Repository:        repository,
+			Tag:               "stable",
+			AttestationPolicy: &securityv1.AttestationPolicy{RequireSBOM: true, AttestationSource: securityv1.AttestationSourceReferrers},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Digest).To(Equal(digest))
+		Expect(result.Attestation).NotTo(BeNil())
+		Expect(result.Attestation.Verified).To(BeTrue())
+		Expect(result.Attestation.HasSBOM).To(Equal(ptr.To(true)))
+		Expect(result.Attestation.PredicateType).To(Equal("https://cyclonedx.org/bom"))
+	})
+
+	It("should report a tag that doesn't exist", func() {
+		_, err := (&ImagePolicyReconciler{}).Check(context.Background(), CheckRequest{Repository: repository, Tag: "missing"})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,122 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+// ComplianceSummaryReconciler keeps the cluster's ComplianceSummary in line with the status of every ImagePolicy
+type ComplianceSummaryReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=security.chainguard.dev,resources=compliancesummaries,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=security.chainguard.dev,resources=compliancesummaries/status,verbs=get;update;patch
+
+// Reconcile recomputes the ComplianceSummary from every ImagePolicy, creating it if it doesn't exist
+func (r *ComplianceSummaryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+	if req.Name != securityv1.ComplianceSummaryName {
+		return ctrl.Result{}, nil
+	}
+
+	policies := &securityv1.ImagePolicyList{}
+	if err := r.List(ctx, policies); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list image policies: %w", err)
+	}
+
+	summary := &securityv1.ComplianceSummary{}
+	if err := r.Get(ctx, types.NamespacedName{Name: securityv1.ComplianceSummaryName}, summary); errors.IsNotFound(err) {
+		summary.Name = securityv1.ComplianceSummaryName
+		if err := r.Create(ctx, summary); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to create the compliance summary: %w", err)
+		}
+		log.Info("Created the compliance summary", "name", summary.Name)
+	} else if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get the compliance summary: %w", err)
+	}
+
+	status := summarizePolicies(policies.Items)
+	previous := summary.Status.DeepCopy()
+	previous.LastUpdated = nil
+	if equality.Semantic.DeepEqual(*previous, status) {
+		return ctrl.Result{}, nil
+	}
+
+	now := metav1.Now()
+	status.LastUpdated = &now
+	summary.Status = status
+	if err := r.Status().Update(ctx, summary); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to update the compliance summary: %w", err)
+	}
+	log.V(1).Info("Updated the compliance summary", "policies", status.TotalPolicies, "deployments", status.TotalDeployments)
+	return ctrl.Result{}, nil
+}
+
+// summarizePolicies adds up the status of the policies, as their last reconciles recorded it
+func summarizePolicies(policies []securityv1.ImagePolicy) securityv1.ComplianceSummaryStatus {
+	status := securityv1.ComplianceSummaryStatus{TotalPolicies: int32(len(policies))}
+	for _, policy := range policies {
+		switch policy.Status.ComplianceStatus {
+		case securityv1.ComplianceStatusCompliant:
+			status.CompliantPolicies++
+		case securityv1.ComplianceStatusNonCompliant, securityv1.ComplianceStatusError:
+			status.NonCompliantPolicies++
+		}
+		status.TotalDeployments += policy.Status.TotalDeployments
+		status.CompliantDeployments += policy.Status.CompliantDeployments
+		status.CheckedAttestations += policy.Status.CheckedAttestations
+		status.VerifiedAttestations += policy.Status.VerifiedAttestations
+	}
+	status.NonCompliantDeployments = status.TotalDeployments - status.CompliantDeployments
+
+	status.AttestationCoverage = "N/A"
+	if status.CheckedAttestations > 0 {
+		status.AttestationCoverage = fmt.Sprintf("%d%%", status.VerifiedAttestations*100/status.CheckedAttestations)
+	}
+	return status
+}
+
+// SetupWithManager sets up the controller with the Manager. Every ImagePolicy change, including status
+// updates, maps to the single ComplianceSummary, so concurrent policy reconciles are merged into one update.
+func (r *ComplianceSummaryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	enqueueSummary := handler.EnqueueRequestsFromMapFunc(func(context.Context, client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: securityv1.ComplianceSummaryName}}}
+	})
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&securityv1.ComplianceSummary{}).
+		Watches(&securityv1.ImagePolicy{}, enqueueSummary).
+		Named("compliancesummary").
+		Complete(r)
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("ComplianceSummary controller", func() {
+	newPolicy := func(namespace, name, complianceStatus string, total, compliant, checked, verified int32) *securityv1.ImagePolicy {
+		return &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       securityv1.ImagePolicySpec{Repository: "jonlimpw/cg-demo"},
+			Status: securityv1.ImagePolicyStatus{
+				ComplianceStatus:     complianceStatus,
+				TotalDeployments:     total,
+				CompliantDeployments: compliant,
+				CheckedAttestations:  checked,
+				VerifiedAttestations: verified,
+			},
+		}
+	}
+
+	var fakeClient client.Client
+	var reconciler *ComplianceSummaryReconciler
+	request := ctrl.Request{NamespacedName: types.NamespacedName{Name: securityv1.ComplianceSummaryName}}
+
+	getSummary := func() *securityv1.ComplianceSummary {
+		summary := &securityv1.ComplianceSummary{}
+		Expect(fakeClient.Get(context.Background(), request.NamespacedName, summary)).To(Succeed())
+		return summary
+	}
+
+	BeforeEach(func() {
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithStatusSubresource(&securityv1.ComplianceSummary{}).
+			WithObjects(
+				newPolicy("team-a", "web", securityv1.ComplianceStatusCompliant, 3, 3, 3, 3),
+				newPolicy("team-b", "demo", securityv1.ComplianceStatusNonCompliant, 2, 1, 2, 0),
+				newPolicy("team-b", "api", securityv1.ComplianceStatusError, 0, 0, 0, 0),
+			).Build()
+		reconciler = &ComplianceSummaryReconciler{Client: fakeClient, Scheme: scheme.Scheme}
+	})
+
+	It("should create the summary with the totals of every policy", func() {
+		_, err := reconciler.Reconcile(context.Background(), request)
+		Expect(err).NotTo(HaveOccurred())
+
+		status := getSummary().Status
+		Expect(status.TotalPolicies).To(Equal(int32(3)))
+		Expect(status.CompliantPolicies).To(Equal(int32(1)))
+		Expect(status.NonCompliantPolicies).To(Equal(int32(2)))
+		Expect(status.TotalDeployments).To(Equal(int32(5)))
+		Expect(status.CompliantDeployments).To(Equal(int32(4)))
+		Expect(status.NonCompliantDeployments).To(Equal(int32(1)))
+		Expect(status.CheckedAttestations).To(Equal(int32(5)))
+		Expect(status.VerifiedAttestations).To(Equal(int32(3)))
+		Expect(status.AttestationCoverage).To(Equal("60%"))
+		Expect(status.LastUpdated).NotTo(BeNil())
+	})
+
+	It("should only update the summary when the totals change", func() {
+		_, err := reconciler.Reconcile(context.Background(), request)
+		Expect(err).NotTo(HaveOccurred())
+		first := getSummary()
+
+		_, err = reconciler.Reconcile(context.Background(), request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getSummary().ResourceVersion).To(Equal(first.ResourceVersion))
+
+		Expect(fakeClient.Delete(context.Background(), newPolicy("team-a", "web", "", 0, 0, 0, 0))).To(Succeed())
+		_, err = reconciler.Reconcile(context.Background(), request)
+		Expect(err).NotTo(HaveOccurred())
+		status := getSummary().Status
+		Expect(status.TotalPolicies).To(Equal(int32(2)))
+		Expect(status.AttestationCoverage).To(Equal("0%"))
+	})
+
+	It("should report no attestation coverage when no policy checks attestations", func() {
+		Expect(summarizePolicies(nil).AttestationCoverage).To(Equal("N/A"))
+	})
+
+	It("should ignore summaries with another name", func() {
+		_, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "other"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeClient.Get(context.Background(), request.NamespacedName, &securityv1.ComplianceSummary{})).NotTo(Succeed())
+	})
+})
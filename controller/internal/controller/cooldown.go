@@ -0,0 +1,62 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+// remediationCooldown returns a policy's RemediationCooldown, 0 when unset
+func remediationCooldown(policy *securityv1.ImagePolicy) (time.Duration, error) {
+	if policy.Spec.RemediationCooldown == nil || *policy.Spec.RemediationCooldown == "" {
+		return 0, nil
+	}
+
+	cooldown, err := time.ParseDuration(*policy.Spec.RemediationCooldown)
+	if err != nil {
+		return 0, fmt.Errorf("invalid RemediationCooldown %q: %w", *policy.Spec.RemediationCooldown, err)
+	}
+	if cooldown < 0 {
+		return 0, fmt.Errorf("invalid RemediationCooldown %q: must not be negative", *policy.Spec.RemediationCooldown)
+	}
+	return cooldown, nil
+}
+
+// trackRemediation keeps LastRemediated across reconciles, and clears it once the workload is observed
+// compliant after the cooldown. Right after a remediation the workload is compliant, so clearing it any
+// sooner would let a controller reverting the workload restart the update loop.
+func trackRemediation(policy *securityv1.ImagePolicy, deployment workload, status *securityv1.DeploymentStatus) {
+	if previous := findDeploymentStatus(policy, deployment); previous != nil {
+		status.LastRemediated = previous.LastRemediated
+	}
+	if status.IsCompliant && cooldownRemaining(policy, status, time.Now()) == 0 {
+		status.LastRemediated = nil
+	}
+}
+
+// cooldownRemaining returns how long remediation of a workload is still held off by the policy's
+// RemediationCooldown, 0 when it may be remediated. An invalid cooldown doesn't hold anything off.
+func cooldownRemaining(policy *securityv1.ImagePolicy, status *securityv1.DeploymentStatus, now time.Time) time.Duration {
+	cooldown, err := remediationCooldown(policy)
+	if err != nil || cooldown == 0 || status.LastRemediated == nil {
+		return 0
+	}
+	return max(status.LastRemediated.Add(cooldown).Sub(now), 0)
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Remediation cooldown", func() {
+	const (
+		repository   = "jonlimpw/cg-demo"
+		latestDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		staleDigest  = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	)
+
+	var (
+		fakeClient client.Client
+		recorder   *record.FakeRecorder
+		reconciler *ImagePolicyReconciler
+		policy     *securityv1.ImagePolicy
+	)
+
+	BeforeEach(func() {
+		running := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default", Labels: map[string]string{"automation": "true"}},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "app", Image: repository + "@" + staleDigest}},
+			}}},
+		}
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(running).Build()
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &ImagePolicyReconciler{Client: fakeClient, Recorder: recorder}
+		policy = &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+			Spec:       securityv1.ImagePolicySpec{Repository: repository, RemediationCooldown: ptr.To("15m")},
+		}
+	})
+
+	// remediate runs remediation of the outdated workload and returns the image it ends up with
+	remediate := func(status *securityv1.DeploymentStatus) string {
+		deployment := &appsv1.Deployment{}
+		Expect(fakeClient.Get(context.Background(), types.NamespacedName{Name: "demo", Namespace: "default"}, deployment)).To(Succeed())
+		// Simulate another controller reverting the last remediation
+		deployment.Spec.Template.Spec.Containers[0].Image = repository + "@" + staleDigest
+		Expect(fakeClient.Update(context.Background(), deployment)).To(Succeed())
+
+		w, _ := newWorkload(deployment)
+		reconciler.handleRemediation(context.Background(), policy, w, status, reconciler.rulesFor(policy), map[string]string{repository: latestDigest}, securityv1.RemediationModeAuto, nil)
+
+		Expect(fakeClient.Get(context.Background(), types.NamespacedName{Name: "demo", Namespace: "default"}, deployment)).To(Succeed())
+		return deployment.Spec.Template.Spec.Containers[0].Image
+	}
+
+	It("should throttle re-remediating a reverted workload within the cooldown", func() {
+		status := &securityv1.DeploymentStatus{Name: "demo", Namespace: "default", Repository: repository, CurrentDigest: staleDigest}
+		Expect(remediate(status)).To(Equal(repository + "@" + latestDigest))
+		Expect(status.LastRemediated).NotTo(BeNil())
+		Expect(recorder.Events).To(Receive(ContainSubstring("AutoRemediated")))
+		Expect(recorder.Events).To(Receive(ContainSubstring("Auto-remediated by ImagePolicy default/policy")))
+
+		Expect(remediate(status)).To(Equal(repository + "@" + staleDigest))
+		Expect(recorder.Events).To(Receive(And(ContainSubstring("Warning RemediationThrottled"), ContainSubstring("Deployment default/demo"))))
+	})
+
+	It("should remediate again once the cooldown has passed", func() {
+		status := &securityv1.DeploymentStatus{Name: "demo", Namespace: "default", Repository: repository, CurrentDigest: staleDigest,
+			LastRemediated: ptr.To(metav1.NewTime(time.Now().Add(-20 * time.Minute)))}
+		Expect(remediate(status)).To(Equal(repository + "@" + latestDigest))
+	})
+
+	It("should not throttle without a cooldown", func() {
+		policy.Spec.RemediationCooldown = nil
+		status := &securityv1.DeploymentStatus{Name: "demo", Namespace: "default", Repository: repository, CurrentDigest: staleDigest,
+			LastRemediated: ptr.To(metav1.Now())}
+		Expect(remediate(status)).To(Equal(repository + "@" + latestDigest))
+	})
+
+	It("should keep the last remediation across reconciles until the workload settles compliant", func() {
+		deployment, _ := newWorkload(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"}})
+		remediatedAt := metav1.NewTime(time.Now().Add(-5 * time.Minute))
+		policy.Status.MonitoredDeployments = []securityv1.DeploymentStatus{
+			{Kind: securityv1.WorkloadKindDeployment, Name: "demo", Namespace: "default", LastRemediated: &remediatedAt},
+		}
+
+		status := securityv1.DeploymentStatus{Kind: securityv1.WorkloadKindDeployment, Name: "demo", Namespace: "default", IsCompliant: true}
+		trackRemediation(policy, deployment, &status)
+		Expect(status.LastRemediated).To(Equal(&remediatedAt))
+
+		policy.Spec.RemediationCooldown = ptr.To("1m")
+		trackRemediation(policy, deployment, &status)
+		Expect(status.LastRemediated).To(BeNil())
+	})
+
+	It("should reject malformed and negative cooldowns", func() {
+		for _, value := range []string{"soon", "-1m"} {
+			policy.Spec.RemediationCooldown = ptr.To(value)
+			_, err := remediationCooldown(policy)
+			Expect(err).To(MatchError(ContainSubstring("invalid RemediationCooldown")), value)
+		}
+	})
+})
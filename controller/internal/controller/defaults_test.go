@@ -0,0 +1,80 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Manager-level policy defaults", func() {
+	unset := &securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{Repository: "jonlimpw/cg-demo"}}
+	explicit := &securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{
+		Repository:           "jonlimpw/cg-demo",
+		CheckIntervalSeconds: ptr.To[int32](300),
+		EnforceLatestDigest:  ptr.To(true),
+	}}
+
+	It("should fall back to the builtin defaults when the manager sets none", func() {
+		reconciler := &ImagePolicyReconciler{}
+		Expect(reconciler.checkInterval(unset)).To(Equal(int32(60)))
+		Expect(reconciler.rulesFor(unset).EnforceLatest).To(BeTrue())
+	})
+
+	It("should apply the manager defaults to policies that leave the fields unset", func() {
+		reconciler := &ImagePolicyReconciler{DefaultCheckIntervalSeconds: 900, DefaultEnforceLatest: ptr.To(false)}
+		Expect(reconciler.checkInterval(unset)).To(Equal(int32(900)))
+		Expect(reconciler.rulesFor(unset).EnforceLatest).To(BeFalse())
+	})
+
+	It("should prefer the policy's own values over the manager defaults", func() {
+		reconciler := &ImagePolicyReconciler{DefaultCheckIntervalSeconds: 900, DefaultEnforceLatest: ptr.To(false)}
+		Expect(reconciler.checkInterval(explicit)).To(Equal(int32(300)))
+		Expect(reconciler.rulesFor(explicit).EnforceLatest).To(BeTrue())
+	})
+})
+
+var _ = Describe("Requeue jitter", func() {
+	const interval = time.Minute
+
+	It("should requeue exactly on the check interval without jitter", func() {
+		Expect((&ImagePolicyReconciler{}).jitteredInterval(interval)).To(Equal(interval))
+	})
+
+	It("should spread requeues within the jitter fraction either way", func() {
+		reconciler := &ImagePolicyReconciler{RequeueJitter: 0.1}
+		seen := map[time.Duration]bool{}
+		for range 100 {
+			requeue := reconciler.jitteredInterval(interval)
+			Expect(requeue).To(BeNumerically("~", interval, interval/10))
+			seen[requeue] = true
+		}
+		Expect(len(seen)).To(BeNumerically(">", 1))
+	})
+
+	It("should cap the jitter so requeues never get close to zero", func() {
+		reconciler := &ImagePolicyReconciler{RequeueJitter: 5}
+		for range 100 {
+			Expect(reconciler.jitteredInterval(interval)).To(BeNumerically(">=", interval/2))
+		}
+	})
+})
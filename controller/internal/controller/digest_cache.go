@@ -0,0 +1,96 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// digestCacheEntry is a digest resolved from the registry and when it was fetched
+type digestCacheEntry struct {
+	digest    string
+	fetchedAt time.Time
+}
+
+// digestCache is an in-memory cache of resolved digests shared by all ImagePolicies,
+// so policies watching the same image don't each hit the registry on every check
+type digestCache struct {
+	mu      sync.Mutex
+	entries map[string]digestCacheEntry
+
+	// maxTTL is the longest TTL entries were read with; older entries can't be returned and are pruned
+	// by set at most once per maxTTL
+	maxTTL time.Duration
+	pruned time.Time
+
+	// fetches deduplicates concurrent misses for the same key across reconcile workers
+	fetches singleflight.Group
+}
+
+// newDigestCache creates an empty digest cache
+func newDigestCache() *digestCache {
+	return &digestCache{entries: map[string]digestCacheEntry{}}
+}
+
+// get returns the cached digest for key if it was fetched within ttl
+func (c *digestCache) get(key string, ttl time.Duration) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxTTL = max(c.maxTTL, ttl)
+	entry, ok := c.entries[key]
+	if !ok || time.Since(entry.fetchedAt) > ttl {
+		return "", false
+	}
+	return entry.digest, true
+}
+
+// set stores a freshly fetched digest, replacing any older value for key, and drops the entries that
+// expired for every reader so keys no policy checks anymore don't accumulate
+func (c *digestCache) set(key, digest string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.entries[key] = digestCacheEntry{digest: digest, fetchedAt: now}
+	if c.maxTTL == 0 || now.Sub(c.pruned) < c.maxTTL {
+		return
+	}
+	for cached, entry := range c.entries {
+		if now.Sub(entry.fetchedAt) > c.maxTTL {
+			delete(c.entries, cached)
+		}
+	}
+	c.pruned = now
+}
+
+// fetch calls fn to resolve a missing digest, caching the result on success. Concurrent calls for the
+// same key wait for the in-flight fetch and share its result instead of each querying the registry.
+func (c *digestCache) fetch(key string, fn func() (string, error)) (string, error) {
+	digest, err, _ := c.fetches.Do(key, func() (any, error) {
+		digest, err := fn()
+		if err != nil {
+			return "", err
+		}
+		c.set(key, digest)
+		return digest, nil
+	})
+	return digest.(string), err
+}
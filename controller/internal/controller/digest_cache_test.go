@@ -0,0 +1,139 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Digest cache", func() {
+	const (
+		key         = "registry-1.docker.io/jonlimpw/cg-demo:latest"
+		firstDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		newDigest   = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	)
+
+	It("should expire entries older than the TTL", func() {
+		cache := newDigestCache()
+		cache.set(key, firstDigest)
+
+		digest, ok := cache.get(key, time.Minute)
+		Expect(ok).To(BeTrue())
+		Expect(digest).To(Equal(firstDigest))
+
+		cache.entries[key] = digestCacheEntry{digest: firstDigest, fetchedAt: time.Now().Add(-2 * time.Minute)}
+		_, ok = cache.get(key, time.Minute)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should replace an older digest and refresh its fetch time", func() {
+		cache := newDigestCache()
+		cache.entries[key] = digestCacheEntry{digest: firstDigest, fetchedAt: time.Now().Add(-2 * time.Minute)}
+
+		cache.set(key, newDigest)
+
+		digest, ok := cache.get(key, time.Minute)
+		Expect(ok).To(BeTrue())
+		Expect(digest).To(Equal(newDigest))
+	})
+
+	It("should prune entries older than the longest TTL they were read with", func() {
+		const (
+			expiredKey = "registry-1.docker.io/jonlimpw/cg-demo:1.0"
+			recentKey  = "registry-1.docker.io/jonlimpw/cg-demo:2.0"
+		)
+		cache := newDigestCache()
+		cache.get(expiredKey, time.Minute)
+		cache.get(recentKey, 10*time.Minute)
+		cache.entries[expiredKey] = digestCacheEntry{digest: firstDigest, fetchedAt: time.Now().Add(-20 * time.Minute)}
+		cache.entries[recentKey] = digestCacheEntry{digest: firstDigest, fetchedAt: time.Now().Add(-5 * time.Minute)}
+
+		cache.set(key, newDigest)
+		Expect(cache.entries).To(HaveKey(key))
+		Expect(cache.entries).To(HaveKey(recentKey))
+		Expect(cache.entries).NotTo(HaveKey(expiredKey))
+
+		// Pruning runs at most once per TTL
+		cache.entries[expiredKey] = digestCacheEntry{digest: firstDigest, fetchedAt: time.Now().Add(-20 * time.Minute)}
+		cache.set(key, newDigest)
+		Expect(cache.entries).To(HaveKey(expiredKey))
+	})
+
+	It("should share one in-flight fetch between concurrent callers", func() {
+		cache := newDigestCache()
+		var calls atomic.Int32
+		release := make(chan struct{})
+
+		var wg sync.WaitGroup
+		digests := make([]string, 5)
+		for i := range digests {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				digest, err := cache.fetch(key, func() (string, error) {
+					calls.Add(1)
+					<-release
+					return firstDigest, nil
+				})
+				Expect(err).NotTo(HaveOccurred())
+				digests[i] = digest
+			}()
+		}
+		Eventually(calls.Load).Should(Equal(int32(1)))
+		// give the other callers time to join the in-flight fetch before it completes
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		Expect(calls.Load()).To(Equal(int32(1)))
+		Expect(digests).To(HaveEach(firstDigest))
+		digest, ok := cache.get(key, time.Minute)
+		Expect(ok).To(BeTrue())
+		Expect(digest).To(Equal(firstDigest))
+	})
+
+	It("should not cache failed fetches", func() {
+		cache := newDigestCache()
+		_, err := cache.fetch(key, func() (string, error) { return "", errors.New("registry unavailable") })
+		Expect(err).To(HaveOccurred())
+
+		_, ok := cache.get(key, time.Minute)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should key authenticated requests by their credentials", func() {
+		anonymous := digestRequest{Repository: "jonlimpw/private", Tag: "latest"}
+		withSecret := anonymous
+		withSecret.Credentials = &registryCredentials{Username: "ci", Password: "first"}
+		withOtherSecret := anonymous
+		withOtherSecret.Credentials = &registryCredentials{Username: "ci", Password: "second"}
+
+		Expect(withSecret.cacheKey()).NotTo(Equal(anonymous.cacheKey()))
+		Expect(withSecret.cacheKey()).NotTo(Equal(withOtherSecret.cacheKey()))
+		Expect(withSecret.cacheKey()).NotTo(ContainSubstring("first"))
+		sameSecret := anonymous
+		sameSecret.Credentials = &registryCredentials{Username: "ci", Password: "first"}
+		Expect(sameSecret.cacheKey()).To(Equal(withSecret.cacheKey()))
+	})
+})
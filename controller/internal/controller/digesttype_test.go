@@ -0,0 +1,162 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Digest types", func() {
+	const (
+		indexDigest    = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		amd64Digest    = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+		singleDigest   = "sha256:3333333333333333333333333333333333333333333333333333333333333333"
+		amd64Config    = "sha256:4444444444444444444444444444444444444444444444444444444444444444"
+		singleConfig   = "sha256:5555555555555555555555555555555555555555555555555555555555555555"
+		outdatedConfig = "sha256:6666666666666666666666666666666666666666666666666666666666666666"
+	)
+
+	ctx := context.Background()
+
+	// The multi tag is an index of an amd64 manifest, the single tag a single-platform manifest
+	var manifestRequests int
+	BeforeEach(func() {
+		manifestRequests = 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/token":
+				_, _ = w.Write([]byte(`{"token":"test"}`))
+				return
+			case "/v2/jonlimpw/cg-demo/manifests/multi":
+				w.Header().Set("Content-Type", mediaTypeOCIImageIndex)
+				w.Header().Set("Docker-Content-Digest", indexDigest)
+				_, _ = w.Write([]byte(`{"mediaType":"` + mediaTypeOCIImageIndex + `","manifests":[{"mediaType":"` + mediaTypeOCIImageManifest +
+					`","digest":"` + amd64Digest + `","platform":{"os":"linux","architecture":"amd64"}}]}`))
+			case "/v2/jonlimpw/cg-demo/manifests/" + amd64Digest:
+				Expect(r.Header.Get("Accept")).To(ContainSubstring(mediaTypeOCIImageManifest))
+				w.Header().Set("Content-Type", mediaTypeOCIImageManifest)
+				w.Header().Set("Docker-Content-Digest", amd64Digest)
+				_, _ = w.Write([]byte(`{"mediaType":"` + mediaTypeOCIImageManifest + `","config":{"digest":"` + amd64Config + `"}}`))
+			case "/v2/jonlimpw/cg-demo/manifests/single":
+				w.Header().Set("Content-Type", mediaTypeDockerManifest)
+				w.Header().Set("Docker-Content-Digest", singleDigest)
+				_, _ = w.Write([]byte(`{"mediaType":"` + mediaTypeDockerManifest + `","config":{"digest":"` + singleConfig + `"}}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			manifestRequests++
+		}))
+		previousRegistryURL, previousAuthURL := dockerHubRegistryURL, dockerHubAuthURL
+		dockerHubRegistryURL, dockerHubAuthURL = server.URL, server.URL
+		DeferCleanup(func() {
+			server.Close()
+			dockerHubRegistryURL, dockerHubAuthURL = previousRegistryURL, previousAuthURL
+		})
+	})
+
+	fetch := func(tag, platform, digestType string) (string, error) {
+		return (&ImagePolicyReconciler{}).fetchDigestFromDockerHub(ctx, digestRequest{
+			Repository: "jonlimpw/cg-demo",
+			Tag:        tag,
+			Platform:   platform,
+			DigestType: digestType,
+		})
+	}
+
+	It("should compare manifest digests by default", func() {
+		Expect(fetch("single", "", "")).To(Equal(singleDigest))
+		Expect(fetch("single", "", securityv1.DigestTypeManifest)).To(Equal(singleDigest))
+		Expect(fetch("multi", "", "")).To(Equal(indexDigest))
+		Expect(fetch("multi", "linux/amd64", "")).To(Equal(amd64Digest))
+	})
+
+	It("should read the config digest from the manifest body", func() {
+		Expect(fetch("single", "", securityv1.DigestTypeConfig)).To(Equal(singleConfig))
+		Expect(manifestRequests).To(Equal(1))
+	})
+
+	It("should read the config digest of a multi-arch image from the platform's manifest", func() {
+		Expect(fetch("multi", "linux/amd64", securityv1.DigestTypeConfig)).To(Equal(amd64Config))
+		Expect(manifestRequests).To(Equal(2))
+
+		_, err := fetch("multi", "", securityv1.DigestTypeConfig)
+		Expect(err).To(MatchError(ContainSubstring("needs a platform")))
+		_, err = fetch("multi", "linux/arm64", securityv1.DigestTypeConfig)
+		Expect(err).To(MatchError(ContainSubstring("no manifest found for platform linux/arm64")))
+	})
+
+	It("should cache config and manifest digests separately", func() {
+		manifest := digestRequest{Repository: "jonlimpw/cg-demo", Tag: "single"}
+		config := digestRequest{Repository: "jonlimpw/cg-demo", Tag: "single", DigestType: securityv1.DigestTypeConfig}
+		Expect(config.cacheKey()).NotTo(Equal(manifest.cacheKey()))
+		Expect(manifest.cacheKey()).To(Equal(digestRequest{Repository: "jonlimpw/cg-demo", Tag: "single", DigestType: securityv1.DigestTypeManifest}.cacheKey()))
+	})
+
+	It("should check compliance against the config digest without remediating", func() {
+		key := types.NamespacedName{Name: "config", Namespace: "default"}
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Spec: securityv1.ImagePolicySpec{
+				Repository: "jonlimpw/cg-demo",
+				Tag:        "single",
+				DigestType: securityv1.DigestTypeConfig,
+			},
+		}
+		newDeployment := func(name, digest string) *appsv1.Deployment {
+			return &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"automation": "true"}},
+				Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "app", Image: "jonlimpw/cg-demo@" + digest}},
+				}}},
+			}
+		}
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(policy, newDeployment("current", singleConfig), newDeployment("outdated", outdatedConfig),
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}).
+			WithStatusSubresource(policy).Build()
+		reconciler := &ImagePolicyReconciler{Client: fakeClient, Recorder: record.NewFakeRecorder(10), DockerHubMaxRetries: 1}
+
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		updated := &securityv1.ImagePolicy{}
+		Expect(fakeClient.Get(ctx, key, updated)).To(Succeed())
+		Expect(updated.Status.LatestDigest).To(Equal(singleConfig))
+		Expect(updated.Status.TotalDeployments).To(Equal(int32(2)))
+		Expect(updated.Status.CompliantDeployments).To(Equal(int32(1)))
+
+		outdated := &appsv1.Deployment{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "outdated", Namespace: "default"}, outdated)).To(Succeed())
+		Expect(outdated.Spec.Template.Spec.Containers[0].Image).To(Equal("jonlimpw/cg-demo@" + outdatedConfig))
+	})
+})
@@ -0,0 +1,156 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+const discoveryRepository = "jonlimpw/cg-demo"
+
+// newDiscoveryClient builds a fake client with one monitored Deployment in each of n labelled namespaces,
+// counting workload List calls
+func newDiscoveryClient(n int, listCalls *int) client.Client {
+	var objects []client.Object
+	for i := range n {
+		namespace := fmt.Sprintf("team-%d", i)
+		objects = append(objects,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace, Labels: map[string]string{"team": namespace}}},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: namespace},
+				Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "app", Image: discoveryRepository + ":latest"}},
+				}}},
+			})
+	}
+
+	return fake.NewClientBuilder().WithObjects(objects...).WithInterceptorFuncs(interceptor.Funcs{
+		List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			if _, ok := list.(*corev1.NamespaceList); !ok {
+				*listCalls++
+			}
+			return c.List(ctx, list, opts...)
+		},
+	}).Build()
+}
+
+var _ = Describe("Workload discovery", func() {
+	ctx := context.Background()
+
+	It("should list workloads cluster-wide without a namespace selector", func() {
+		var listCalls int
+		reconciler := &ImagePolicyReconciler{Client: newDiscoveryClient(20, &listCalls)}
+		policy := &securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{
+			Repository:        discoveryRepository,
+			ExcludeNamespaces: []string{"team-0"},
+		}}
+
+		deployments, err := reconciler.findDeploymentsToMonitor(ctx, policy)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deployments).To(HaveLen(19))
+		Expect(listCalls).To(Equal(5)) // one per workload kind
+	})
+
+	It("should list workloads per namespace when the selector matches few namespaces", func() {
+		var listCalls int
+		reconciler := &ImagePolicyReconciler{Client: newDiscoveryClient(20, &listCalls)}
+		policy := &securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{
+			Repository:        discoveryRepository,
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "team-3"}},
+		}}
+
+		deployments, err := reconciler.findDeploymentsToMonitor(ctx, policy)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deployments).To(HaveLen(1))
+		Expect(deployments[0].GetNamespace()).To(Equal("team-3"))
+		Expect(listCalls).To(Equal(5))
+	})
+
+	It("should return each workload once", func() {
+		// Overlapping listings, e.g. a namespace matched twice, must not double-count compliance
+		var listCalls int
+		c := newDiscoveryClient(1, &listCalls)
+		reconciler := &ImagePolicyReconciler{Client: c}
+		workloads, err := reconciler.listWorkloadsPerNamespace(ctx, []string{"team-0", "team-0"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(workloads).To(HaveLen(2))
+
+		statefulSet, _ := newWorkload(&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "team-0"}})
+		unique := uniqueWorkloads(append(workloads, statefulSet))
+		Expect(unique).To(HaveLen(2))
+		Expect(unique[0].Kind).To(Equal(securityv1.WorkloadKindDeployment))
+		Expect(unique[1].Kind).To(Equal(securityv1.WorkloadKindStatefulSet))
+	})
+
+	It("should count a workload using several monitored repositories once", func() {
+		var listCalls int
+		reconciler := &ImagePolicyReconciler{Client: newDiscoveryClient(1, &listCalls)}
+		policy := &securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{
+			Repository:   discoveryRepository,
+			Repositories: []string{discoveryRepository, "docker.io/" + discoveryRepository},
+		}}
+
+		deployments, err := reconciler.findDeploymentsToMonitor(ctx, policy)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deployments).To(HaveLen(1))
+	})
+})
+
+// BenchmarkListWorkloads compares listing per namespace with listing cluster-wide across
+// 500 namespaces, for increasingly large sets of monitored namespaces. Run with:
+//
+//	go test ./internal/controller -run '^$' -bench ListWorkloads
+func BenchmarkListWorkloads(b *testing.B) {
+	ctx := context.Background()
+	var listCalls int
+	reconciler := &ImagePolicyReconciler{Client: newDiscoveryClient(500, &listCalls)}
+
+	for _, selected := range []int{1, perNamespaceListThreshold, 50, 500} {
+		namespaces := make([]string, selected)
+		for i := range namespaces {
+			namespaces[i] = fmt.Sprintf("team-%d", i)
+		}
+
+		b.Run(fmt.Sprintf("per-namespace/%d", selected), func(b *testing.B) {
+			for b.Loop() {
+				if _, err := reconciler.listWorkloadsPerNamespace(ctx, namespaces); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("cluster-wide/%d", selected), func(b *testing.B) {
+			for b.Loop() {
+				if _, err := reconciler.listWorkloadsInNamespaces(ctx, namespaces); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+// rolloutRequeueInterval is how soon a policy is reconciled again when remediations wait for rollouts or PodDisruptionBudgets
+const rolloutRequeueInterval = 30 * time.Second
+
+// remediationBudget tracks, over one reconcile, the rollouts MaxConcurrentRemediations allows and the disruptions
+// remediations took from each PodDisruptionBudget, so remediating many workloads at once is staggered.
+// A nil budget allows every remediation.
+type remediationBudget struct {
+	// limit is MaxConcurrentRemediations, 0 when unlimited
+	limit int
+
+	// rollingOut counts the monitored workloads rolling out, including those remediated so far
+	rollingOut int
+
+	// disruptions counts the remediations of workloads covered by each PodDisruptionBudget, keyed by namespace/name
+	disruptions map[string]int32
+
+	// deferred is set once a remediation waited on the budget, so the policy is reconciled again soon
+	deferred bool
+}
+
+// newRemediationBudget starts a policy's remediation budget, counting the workloads already rolling out
+func newRemediationBudget(policy *securityv1.ImagePolicy, workloads []workload) *remediationBudget {
+	budget := &remediationBudget{disruptions: map[string]int32{}}
+	if policy.Spec.MaxConcurrentRemediations != nil {
+		budget.limit = int(*policy.Spec.MaxConcurrentRemediations)
+		for _, w := range workloads {
+			if rolloutInProgress(w) {
+				budget.rollingOut++
+			}
+		}
+	}
+	return budget
+}
+
+// exhausted checks if another rollout would exceed MaxConcurrentRemediations
+func (b *remediationBudget) exhausted() bool {
+	return b != nil && b.limit > 0 && b.rollingOut >= b.limit
+}
+
+// markDeferred records that a remediation waited on the budget
+func (b *remediationBudget) markDeferred() {
+	if b != nil {
+		b.deferred = true
+	}
+}
+
+// take records the remediation of a workload whose pods the PodDisruptionBudgets cover
+func (b *remediationBudget) take(disruptionBudgets []policyv1.PodDisruptionBudget) {
+	if b == nil {
+		return
+	}
+	b.rollingOut++
+	for _, pdb := range disruptionBudgets {
+		b.disruptions[client.ObjectKeyFromObject(&pdb).String()]++
+	}
+}
+
+// blocking returns the first PodDisruptionBudget that allows no more disruptions, less those remediations
+// took from it this reconcile, or nil
+func (b *remediationBudget) blocking(disruptionBudgets []policyv1.PodDisruptionBudget) *policyv1.PodDisruptionBudget {
+	for i, pdb := range disruptionBudgets {
+		allowed := pdb.Status.DisruptionsAllowed
+		if b != nil {
+			allowed -= b.disruptions[client.ObjectKeyFromObject(&pdb).String()]
+		}
+		if allowed < 1 {
+			return &disruptionBudgets[i]
+		}
+	}
+	return nil
+}
+
+// rolloutInProgress checks if a workload's controller hasn't finished rolling out its pod template.
+// StatefulSets and DaemonSets updated OnDelete only roll out when their pods are deleted, so they never are.
+func rolloutInProgress(w workload) bool {
+	switch o := w.Object.(type) {
+	case *appsv1.Deployment:
+		replicas := ptr.Deref(o.Spec.Replicas, 1)
+		return o.Status.ObservedGeneration < o.Generation || o.Status.UpdatedReplicas < replicas ||
+			o.Status.Replicas > o.Status.UpdatedReplicas || o.Status.AvailableReplicas < o.Status.UpdatedReplicas
+	case *appsv1.StatefulSet:
+		if o.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+			return false
+		}
+		replicas := ptr.Deref(o.Spec.Replicas, 1)
+		return o.Status.ObservedGeneration < o.Generation || o.Status.UpdatedReplicas < replicas || o.Status.ReadyReplicas < replicas
+	case *appsv1.DaemonSet:
+		if o.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType {
+			return false
+		}
+		return o.Status.ObservedGeneration < o.Generation || o.Status.UpdatedNumberScheduled < o.Status.DesiredNumberScheduled ||
+			o.Status.NumberAvailable < o.Status.DesiredNumberScheduled
+	default:
+		return false
+	}
+}
+
+// matchingDisruptionBudgets lists the PodDisruptionBudgets in a workload's namespace that select its pods
+func (r *ImagePolicyReconciler) matchingDisruptionBudgets(ctx context.Context, w workload) ([]policyv1.PodDisruptionBudget, error) {
+	// Remediating a CronJob only changes the Jobs it creates next, so it disrupts no running pods
+	if w.Kind == securityv1.WorkloadKindCronJob {
+		return nil, nil
+	}
+
+	pdbList := &policyv1.PodDisruptionBudgetList{}
+	if err := r.List(ctx, pdbList, client.InNamespace(w.GetNamespace())); err != nil {
+		return nil, fmt.Errorf("failed to list pod disruption budgets: %w", err)
+	}
+
+	podLabels := labels.Set(w.Template.Labels)
+	var matching []policyv1.PodDisruptionBudget
+	for _, pdb := range pdbList.Items {
+		// A nil selector selects no pods, an empty one every pod in the namespace
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || !selector.Matches(podLabels) {
+			continue
+		}
+		matching = append(matching, pdb)
+	}
+	return matching, nil
+}
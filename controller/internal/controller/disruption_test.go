@@ -0,0 +1,188 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Remediation disruption budgets", func() {
+	const (
+		repository   = "jonlimpw/cg-demo"
+		latestDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		staleDigest  = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	)
+
+	var (
+		recorder   *record.FakeRecorder
+		policy     *securityv1.ImagePolicy
+		reconciler *ImagePolicyReconciler
+	)
+
+	newDeployment := func(name string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"automation": "true"}},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "demo"}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "app", Image: repository + "@" + staleDigest}},
+				},
+			}},
+		}
+	}
+
+	newPDB := func(selector map[string]string, disruptionsAllowed int32) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo-pdb", Namespace: "default"},
+			Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: selector}},
+			Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: disruptionsAllowed},
+		}
+	}
+
+	setup := func(objects ...client.Object) {
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &ImagePolicyReconciler{
+			Client:   fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objects...).Build(),
+			Recorder: recorder,
+		}
+	}
+
+	// remediate runs remediation of the named outdated deployment and returns the image it ends up with
+	remediate := func(name string, budget *remediationBudget) string {
+		deployment := &appsv1.Deployment{}
+		Expect(reconciler.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "default"}, deployment)).To(Succeed())
+
+		w, _ := newWorkload(deployment)
+		status := &securityv1.DeploymentStatus{Name: name, Namespace: "default", Repository: repository, CurrentDigest: staleDigest}
+		reconciler.handleRemediation(context.Background(), policy, w, status, reconciler.rulesFor(policy), map[string]string{repository: latestDigest}, securityv1.RemediationModeAuto, budget)
+
+		Expect(reconciler.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "default"}, deployment)).To(Succeed())
+		return deployment.Spec.Template.Spec.Containers[0].Image
+	}
+
+	BeforeEach(func() {
+		policy = &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+			Spec:       securityv1.ImagePolicySpec{Repository: repository, HonorPodDisruptionBudgets: ptr.To(true)},
+		}
+	})
+
+	It("should defer remediation while a matching PodDisruptionBudget allows no disruptions", func() {
+		setup(newDeployment("demo"), newPDB(map[string]string{"app": "demo"}, 0))
+		budget := newRemediationBudget(policy, nil)
+
+		Expect(remediate("demo", budget)).To(Equal(repository + "@" + staleDigest))
+		Expect(recorder.Events).To(Receive(And(ContainSubstring("Warning RemediationBlockedByPDB"), ContainSubstring("PodDisruptionBudget demo-pdb"))))
+		Expect(budget.deferred).To(BeTrue())
+	})
+
+	It("should remediate with a warning when PodDisruptionBudgets aren't honored", func() {
+		policy.Spec.HonorPodDisruptionBudgets = nil
+		setup(newDeployment("demo"), newPDB(map[string]string{"app": "demo"}, 0))
+
+		Expect(remediate("demo", nil)).To(Equal(repository + "@" + latestDigest))
+		Expect(recorder.Events).To(Receive(ContainSubstring("Warning PodDisruptionBudgetConflict")))
+		Expect(recorder.Events).To(Receive(ContainSubstring("AutoRemediated")))
+	})
+
+	It("should ignore PodDisruptionBudgets selecting other pods", func() {
+		setup(newDeployment("demo"), newPDB(map[string]string{"app": "other"}, 0))
+
+		Expect(remediate("demo", nil)).To(Equal(repository + "@" + latestDigest))
+		Expect(recorder.Events).To(Receive(ContainSubstring("AutoRemediated")))
+	})
+
+	It("should stagger remediations sharing a PodDisruptionBudget", func() {
+		setup(newDeployment("first"), newDeployment("second"), newPDB(map[string]string{"app": "demo"}, 1))
+		budget := newRemediationBudget(policy, nil)
+
+		Expect(remediate("first", budget)).To(Equal(repository + "@" + latestDigest))
+		Expect(remediate("second", budget)).To(Equal(repository + "@" + staleDigest))
+		Expect(recorder.Events).To(Receive(ContainSubstring("AutoRemediated")))
+		Expect(recorder.Events).To(Receive(ContainSubstring("Auto-remediated by ImagePolicy")))
+		Expect(recorder.Events).To(Receive(And(ContainSubstring("Warning RemediationBlockedByPDB"), ContainSubstring("Deployment default/second"))))
+	})
+
+	It("should bound concurrent rollouts by maxConcurrentRemediations", func() {
+		policy.Spec.MaxConcurrentRemediations = ptr.To(int32(2))
+		rollingOut, _ := newWorkload(&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "rolling", Namespace: "default", Generation: 2},
+			Status:     appsv1.DeploymentStatus{ObservedGeneration: 1},
+		})
+		setup(newDeployment("first"), newDeployment("second"))
+		budget := newRemediationBudget(policy, []workload{rollingOut})
+		Expect(budget.rollingOut).To(Equal(1))
+
+		Expect(remediate("first", budget)).To(Equal(repository + "@" + latestDigest))
+		Expect(remediate("second", budget)).To(Equal(repository + "@" + staleDigest))
+		Expect(recorder.Events).To(Receive(ContainSubstring("AutoRemediated")))
+		Expect(recorder.Events).To(Receive(ContainSubstring("Auto-remediated by ImagePolicy")))
+		Expect(recorder.Events).To(Receive(And(ContainSubstring("Normal RemediationDeferred"), ContainSubstring("maxConcurrentRemediations of 2"))))
+		Expect(budget.deferred).To(BeTrue())
+	})
+
+	DescribeTable("detecting rollouts in progress",
+		func(obj client.Object, expected bool) {
+			w, _ := newWorkload(obj)
+			Expect(rolloutInProgress(w)).To(Equal(expected))
+		},
+		Entry("a rolled out deployment", &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Generation: 1},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(int32(2))},
+			Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+		}, false),
+		Entry("a deployment with old replicas left", &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Generation: 1},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(int32(2))},
+			Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 3, UpdatedReplicas: 2, AvailableReplicas: 2},
+		}, true),
+		Entry("a deployment whose new spec isn't observed yet", &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Generation: 2},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(int32(0))},
+			Status:     appsv1.DeploymentStatus{ObservedGeneration: 1},
+		}, true),
+		Entry("a statefulset with unready replicas", &appsv1.StatefulSet{
+			Spec:   appsv1.StatefulSetSpec{Replicas: ptr.To(int32(3))},
+			Status: appsv1.StatefulSetStatus{UpdatedReplicas: 3, ReadyReplicas: 2},
+		}, true),
+		Entry("a statefulset updated on delete", &appsv1.StatefulSet{
+			Spec: appsv1.StatefulSetSpec{Replicas: ptr.To(int32(3)),
+				UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}},
+		}, false),
+		Entry("a daemonset not yet updated on every node", &appsv1.DaemonSet{
+			Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, UpdatedNumberScheduled: 2, NumberAvailable: 3},
+		}, true),
+		Entry("a rolled out daemonset", &appsv1.DaemonSet{
+			Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberAvailable: 3},
+		}, false),
+	)
+})
@@ -0,0 +1,109 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+// maxDriftDuration returns a policy's MaxDriftDuration, 0 when unset
+func maxDriftDuration(policy *securityv1.ImagePolicy) (time.Duration, error) {
+	if policy.Spec.MaxDriftDuration == nil || *policy.Spec.MaxDriftDuration == "" {
+		return 0, nil
+	}
+
+	maxDrift, err := time.ParseDuration(*policy.Spec.MaxDriftDuration)
+	if err != nil {
+		return 0, fmt.Errorf("invalid MaxDriftDuration %q: %w", *policy.Spec.MaxDriftDuration, err)
+	}
+	if maxDrift <= 0 {
+		return 0, fmt.Errorf("invalid MaxDriftDuration %q: must be positive", *policy.Spec.MaxDriftDuration)
+	}
+	return maxDrift, nil
+}
+
+// trackDrift sets NonCompliantSince while a workload is non-compliant, keeping the time it was first found
+// non-compliant across reconciles, and clears it once the workload is compliant again
+func trackDrift(policy *securityv1.ImagePolicy, deployment workload, status *securityv1.DeploymentStatus) {
+	if status.IsCompliant {
+		status.NonCompliantSince = nil
+		return
+	}
+
+	if previous := findDeploymentStatus(policy, deployment); previous != nil && previous.NonCompliantSince != nil {
+		status.NonCompliantSince = previous.NonCompliantSince
+		return
+	}
+
+	nonCompliantSince := metav1.Now()
+	if status.LastUpdated != nil {
+		nonCompliantSince = *status.LastUpdated
+	}
+	status.NonCompliantSince = &nonCompliantSince
+}
+
+// driftExceeded reports whether a workload had been non-compliant for longer than maxDrift when its status was taken
+func driftExceeded(status *securityv1.DeploymentStatus, maxDrift time.Duration) bool {
+	return maxDrift > 0 && status.NonCompliantSince != nil && status.LastUpdated != nil &&
+		status.LastUpdated.Sub(status.NonCompliantSince.Time) > maxDrift
+}
+
+// recordDriftViolation emits a DriftSLOViolated event on the first reconcile that finds a workload
+// non-compliant for longer than maxDrift
+func (r *ImagePolicyReconciler) recordDriftViolation(policy *securityv1.ImagePolicy, deployment workload, status *securityv1.DeploymentStatus, maxDrift time.Duration) {
+	if !driftExceeded(status, maxDrift) {
+		return
+	}
+	if previous := findDeploymentStatus(policy, deployment); previous != nil && driftExceeded(previous, maxDrift) {
+		return
+	}
+
+	drift := status.LastUpdated.Sub(status.NonCompliantSince.Time)
+	r.Recorder.Event(policy, corev1.EventTypeWarning, "DriftSLOViolated",
+		fmt.Sprintf("%s %s/%s has been non-compliant for %s, over the %s maxDriftDuration: %s", deployment.Kind, deployment.GetNamespace(), deployment.GetName(),
+			drift.Round(time.Minute), maxDrift, status.Message))
+}
+
+// updateDriftCondition sets DriftSLOViolated from the workloads over maxDrift, and removes it when the policy has no MaxDriftDuration
+func (r *ImagePolicyReconciler) updateDriftCondition(policy *securityv1.ImagePolicy, maxDrift time.Duration, statuses []securityv1.DeploymentStatus) {
+	if maxDrift == 0 {
+		meta.RemoveStatusCondition(&policy.Status.Conditions, securityv1.ConditionTypeDriftSLOViolated)
+		return
+	}
+
+	violating := 0
+	for i := range statuses {
+		if driftExceeded(&statuses[i], maxDrift) {
+			violating++
+		}
+	}
+
+	if violating > 0 {
+		r.updateCondition(policy, securityv1.ConditionTypeDriftSLOViolated, metav1.ConditionTrue,
+			"MaxDriftDurationExceeded", fmt.Sprintf("%d of %d deployments have been non-compliant for longer than %s", violating, len(statuses), maxDrift))
+		return
+	}
+	r.updateCondition(policy, securityv1.ConditionTypeDriftSLOViolated, metav1.ConditionFalse,
+		"WithinMaxDriftDuration", fmt.Sprintf("No deployment has been non-compliant for longer than %s", maxDrift))
+}
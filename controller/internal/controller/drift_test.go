@@ -0,0 +1,166 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Compliance drift", func() {
+	deployment, _ := newWorkload(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"}})
+
+	newStatus := func(compliant bool) securityv1.DeploymentStatus {
+		now := metav1.Now()
+		return securityv1.DeploymentStatus{
+			Kind:        securityv1.WorkloadKindDeployment,
+			Name:        "demo",
+			Namespace:   "default",
+			IsCompliant: compliant,
+			Message:     "digest is outdated",
+			LastUpdated: &now,
+		}
+	}
+
+	policyWithPrevious := func(previous securityv1.DeploymentStatus) *securityv1.ImagePolicy {
+		policy := &securityv1.ImagePolicy{}
+		policy.Status.MonitoredDeployments = []securityv1.DeploymentStatus{previous}
+		return policy
+	}
+
+	Describe("maxDriftDuration", func() {
+		It("should be disabled when unset", func() {
+			maxDrift, err := maxDriftDuration(&securityv1.ImagePolicy{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(maxDrift).To(BeZero())
+		})
+
+		It("should parse a Go duration", func() {
+			policy := &securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{MaxDriftDuration: ptr.To("72h")}}
+			maxDrift, err := maxDriftDuration(policy)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(maxDrift).To(Equal(72 * time.Hour))
+		})
+
+		It("should reject malformed and non-positive durations", func() {
+			for _, value := range []string{"3 days", "0s", "-1h"} {
+				policy := &securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{MaxDriftDuration: ptr.To(value)}}
+				_, err := maxDriftDuration(policy)
+				Expect(err).To(MatchError(ContainSubstring("invalid MaxDriftDuration")), value)
+			}
+		})
+	})
+
+	It("should start tracking a workload first seen non-compliant", func() {
+		status := newStatus(false)
+		trackDrift(&securityv1.ImagePolicy{}, deployment, &status)
+		Expect(status.NonCompliantSince).To(Equal(status.LastUpdated))
+	})
+
+	It("should keep the first non-compliant time across reconciles", func() {
+		previous := newStatus(false)
+		previous.NonCompliantSince = ptr.To(metav1.NewTime(time.Now().Add(-2 * time.Hour)))
+
+		status := newStatus(false)
+		trackDrift(policyWithPrevious(previous), deployment, &status)
+		Expect(status.NonCompliantSince).To(Equal(previous.NonCompliantSince))
+	})
+
+	It("should clear tracking once the workload is compliant", func() {
+		previous := newStatus(false)
+		previous.NonCompliantSince = ptr.To(metav1.NewTime(time.Now().Add(-2 * time.Hour)))
+
+		status := newStatus(true)
+		trackDrift(policyWithPrevious(previous), deployment, &status)
+		Expect(status.NonCompliantSince).To(BeNil())
+	})
+
+	It("should emit DriftSLOViolated only when a workload first exceeds the maximum", func() {
+		recorder := record.NewFakeRecorder(2)
+		reconciler := &ImagePolicyReconciler{Recorder: recorder}
+
+		status := newStatus(false)
+		status.NonCompliantSince = ptr.To(metav1.NewTime(status.LastUpdated.Add(-2 * time.Hour)))
+		reconciler.recordDriftViolation(&securityv1.ImagePolicy{}, deployment, &status, time.Hour)
+
+		var event string
+		Eventually(recorder.Events).Should(Receive(&event))
+		Expect(event).To(Equal("Warning DriftSLOViolated Deployment default/demo has been non-compliant for 2h0m0s, over the 1h0m0s maxDriftDuration: digest is outdated"))
+
+		// Still over the maximum on the next reconcile
+		next := newStatus(false)
+		next.NonCompliantSince = status.NonCompliantSince
+		reconciler.recordDriftViolation(policyWithPrevious(status), deployment, &next, time.Hour)
+		Consistently(recorder.Events).ShouldNot(Receive())
+	})
+
+	It("should not emit DriftSLOViolated within the maximum", func() {
+		recorder := record.NewFakeRecorder(1)
+		reconciler := &ImagePolicyReconciler{Recorder: recorder}
+
+		status := newStatus(false)
+		status.NonCompliantSince = ptr.To(metav1.NewTime(status.LastUpdated.Add(-30 * time.Minute)))
+		reconciler.recordDriftViolation(&securityv1.ImagePolicy{}, deployment, &status, time.Hour)
+		Consistently(recorder.Events).ShouldNot(Receive())
+	})
+
+	Describe("DriftSLOViolated condition", func() {
+		reconciler := &ImagePolicyReconciler{}
+
+		It("should count the workloads over the maximum", func() {
+			over := newStatus(false)
+			over.NonCompliantSince = ptr.To(metav1.NewTime(over.LastUpdated.Add(-2 * time.Hour)))
+			within := newStatus(false)
+			within.NonCompliantSince = ptr.To(metav1.NewTime(within.LastUpdated.Add(-time.Minute)))
+
+			policy := &securityv1.ImagePolicy{}
+			reconciler.updateDriftCondition(policy, time.Hour, []securityv1.DeploymentStatus{over, within, newStatus(true)})
+
+			condition := meta.FindStatusCondition(policy.Status.Conditions, securityv1.ConditionTypeDriftSLOViolated)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal("MaxDriftDurationExceeded"))
+			Expect(condition.Message).To(Equal("1 of 3 deployments have been non-compliant for longer than 1h0m0s"))
+		})
+
+		It("should be False when every workload is within the maximum", func() {
+			policy := &securityv1.ImagePolicy{}
+			reconciler.updateDriftCondition(policy, time.Hour, []securityv1.DeploymentStatus{newStatus(true)})
+
+			condition := meta.FindStatusCondition(policy.Status.Conditions, securityv1.ConditionTypeDriftSLOViolated)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal("WithinMaxDriftDuration"))
+		})
+
+		It("should be removed when the policy has no maximum", func() {
+			policy := &securityv1.ImagePolicy{}
+			reconciler.updateDriftCondition(policy, time.Hour, nil)
+			reconciler.updateDriftCondition(policy, 0, nil)
+			Expect(meta.FindStatusCondition(policy.Status.Conditions, securityv1.ConditionTypeDriftSLOViolated)).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,149 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+	"github.com/jonlimpw/chainguard-controller/internal/registry"
+)
+
+// findMissingDigests returns the digests of monitored repositories that workloads run but the registry doesn't
+// have, keyed by repository@digest. Digests that couldn't be checked (network errors, rate limit) are assumed
+// to exist until the next reconcile. A policy whose pull secret can't be loaded skips the check.
+func (r *ImagePolicyReconciler) findMissingDigests(ctx context.Context, policy *securityv1.ImagePolicy, deployments []workload, repositories []string, cacheTTL time.Duration) map[string]bool {
+	log := logf.FromContext(ctx)
+
+	credentials, err := r.loadRegistryCredentials(ctx, policy)
+	if err != nil {
+		log.Error(err, "Skipping digest existence check, failed to load registry credentials")
+		return nil
+	}
+
+	missing := map[string]bool{}
+	checked := map[string]bool{}
+	for _, deployment := range deployments {
+		for _, container := range deployment.governedContainers(policy.Spec.ContainerName) {
+			digest := imageDigest(*container.Image)
+			if digest == "" {
+				continue
+			}
+			for _, repository := range repositories {
+				key := repository + "@" + digest
+				if !imageUsesRepository(*container.Image, repository) || checked[key] {
+					continue
+				}
+				checked[key] = true
+
+				exists, err := r.digestExists(ctx, digestRequest{
+					Repository:  repository,
+					Tag:         digest,
+					Credentials: credentials,
+					Mirror:      r.registryMirror(policy),
+					Proxy:       r.registryProxy(policy),
+				}, cacheTTL)
+				if err != nil {
+					log.Error(err, "Failed to check digest existence", "repository", repository, "digest", digest)
+					continue
+				}
+				if !exists {
+					missing[key] = true
+				}
+			}
+		}
+	}
+	return missing
+}
+
+// digestExists checks whether the registry has the manifest of a digest, given as the request's Tag.
+// Results are shared through the digest cache like latest digests: an existing digest is cached as itself,
+// a missing one as an empty string.
+func (r *ImagePolicyReconciler) digestExists(ctx context.Context, digestReq digestRequest, cacheTTL time.Duration) (bool, error) {
+	cache := r.getDigestCache()
+	cacheKey := digestReq.cacheKey()
+	if digest, ok := cache.get(cacheKey, cacheTTL); ok {
+		return digest != "", nil
+	}
+
+	digest, err := cache.fetch(cacheKey, func() (string, error) {
+		if err := r.reserveDockerHubRequest(); err != nil {
+			return "", err
+		}
+
+		_, err := retryDockerHub(ctx, r.DockerHubMaxRetries, func() (struct{}, error) {
+			release, err := r.acquireRegistryRequest(ctx)
+			if err != nil {
+				return struct{}{}, err
+			}
+			defer release()
+			return struct{}{}, r.headManifest(ctx, digestReq)
+		})
+		if err != nil {
+			var notFound *registry.NotFoundError
+			if errors.As(err, &notFound) {
+				return "", nil
+			}
+			return "", err
+		}
+		return digestReq.Tag, nil
+	})
+	return digest != "", err
+}
+
+// headManifest performs a single HEAD request for a manifest, returning a registry.NotFoundError on a 404.
+// Mirrors are queried through their own auth flow, which issues a GET instead.
+func (r *ImagePolicyReconciler) headManifest(ctx context.Context, digestReq digestRequest) error {
+	if digestReq.Mirror != "" {
+		_, err := fetchDigestFromMirror(ctx, r.mirrorRequest(digestReq))
+		return err
+	}
+
+	token, err := r.fetchDockerHubToken(ctx, digestReq.Repository, digestReq.Credentials, digestReq.Proxy)
+	if err != nil {
+		return err
+	}
+
+	manifestURL := fmt.Sprintf("%s/v2/%s/manifests/%s", r.registryURL(), dockerHubRepository(digestReq.Repository), digestReq.Tag)
+	req, err := http.NewRequestWithContext(ctx, "HEAD", manifestURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create manifest request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", manifestAccept)
+
+	client := registryHTTPClient(digestReq.Proxy)
+	sent := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		observeRegistryRequest(ctx, sent, nil)
+		return networkError(ctx, fmt.Errorf("failed to check manifest: %w", err))
+	}
+	defer resp.Body.Close()
+	observeRegistryRequest(ctx, sent, resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return registry.NewStatusError(resp, "registry")
+	}
+	return nil
+}
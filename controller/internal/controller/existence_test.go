@@ -0,0 +1,108 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Digest existence", func() {
+	const (
+		repository    = "jonlimpw/cg-demo"
+		existing      = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		deleted       = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+		existingImage = repository + "@" + existing
+		deletedImage  = repository + "@" + deleted
+	)
+
+	var headRequests int
+
+	BeforeEach(func() {
+		headRequests = 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/token":
+				_, _ = w.Write([]byte(`{"token":"test"}`))
+			case "/v2/" + repository + "/manifests/" + existing:
+				Expect(r.Method).To(Equal(http.MethodHead))
+				headRequests++
+				w.Header().Set("Docker-Content-Digest", existing)
+			default:
+				headRequests++
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		previousRegistryURL, previousAuthURL := dockerHubRegistryURL, dockerHubAuthURL
+		dockerHubRegistryURL, dockerHubAuthURL = server.URL, server.URL
+		DeferCleanup(func() {
+			server.Close()
+			dockerHubRegistryURL, dockerHubAuthURL = previousRegistryURL, previousAuthURL
+		})
+	})
+
+	newDeployment := func(images ...string) workload {
+		deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"}}
+		for i, image := range images {
+			deployment.Spec.Template.Spec.Containers = append(deployment.Spec.Template.Spec.Containers,
+				corev1.Container{Name: string(rune('a' + i)), Image: image})
+		}
+		w, _ := newWorkload(deployment)
+		return w
+	}
+
+	It("should report digests the registry doesn't have and mark the workload non-compliant", func() {
+		reconciler := &ImagePolicyReconciler{}
+		policy := &securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{Repository: repository}}
+		deployments := []workload{newDeployment(existingImage, deletedImage), newDeployment(deletedImage, "nginx:latest")}
+
+		missing := reconciler.findMissingDigests(context.Background(), policy, deployments, []string{repository}, time.Minute)
+		Expect(missing).To(Equal(map[string]bool{deletedImage: true}))
+		Expect(headRequests).To(Equal(2), "each digest is checked once")
+
+		rules := complianceRules{MissingDigests: missing}
+		status := reconciler.analyzeDeploymentCompliance(context.Background(), deployments[0], repository, existing, rules)
+		Expect(status.IsCompliant).To(BeFalse())
+		Expect(status.Reason).To(Equal(securityv1.NonComplianceReasonDigestNotFound))
+		Expect(status.CurrentDigest).To(Equal(deleted))
+
+		status = reconciler.analyzeDeploymentCompliance(context.Background(), newDeployment(existingImage), repository, existing, rules)
+		Expect(status.IsCompliant).To(BeTrue())
+	})
+
+	It("should serve existence checks from the digest cache", func() {
+		reconciler := &ImagePolicyReconciler{}
+		digestReq := digestRequest{Repository: repository, Tag: deleted}
+
+		for range 2 {
+			exists, err := reconciler.digestExists(context.Background(), digestReq, time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeFalse())
+		}
+		Expect(headRequests).To(Equal(1))
+	})
+})
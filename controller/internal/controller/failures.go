@@ -0,0 +1,110 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+const (
+	// failureEventThreshold is the number of reconciles in a row that must fail before a ReconcileFailing
+	// event is emitted
+	failureEventThreshold = 3
+
+	// defaultErrorRequeueInterval is the first retry of a failing policy when ErrorRequeueInterval is unset
+	defaultErrorRequeueInterval = 10 * time.Second
+
+	// maxFailureBackoff caps the requeue of failing policies
+	maxFailureBackoff = time.Hour
+)
+
+// statusUpdateFailure records status writes of a policy that failed since the last successful one
+type statusUpdateFailure struct {
+	Count int32
+	Err   error
+}
+
+// addStatusUpdateFailure remembers a failed status write, which can't be recorded in the status itself
+func (r *ImagePolicyReconciler) addStatusUpdateFailure(key types.NamespacedName, err error) {
+	r.statusUpdateFailuresMu.Lock()
+	defer r.statusUpdateFailuresMu.Unlock()
+
+	if r.statusUpdateFailures == nil {
+		r.statusUpdateFailures = map[types.NamespacedName]statusUpdateFailure{}
+	}
+	failure := r.statusUpdateFailures[key]
+	failure.Count++
+	failure.Err = err
+	r.statusUpdateFailures[key] = failure
+}
+
+// takeStatusUpdateFailures returns the failed status writes of a policy and forgets them
+func (r *ImagePolicyReconciler) takeStatusUpdateFailures(key types.NamespacedName) statusUpdateFailure {
+	r.statusUpdateFailuresMu.Lock()
+	defer r.statusUpdateFailuresMu.Unlock()
+
+	failure := r.statusUpdateFailures[key]
+	delete(r.statusUpdateFailures, key)
+	return failure
+}
+
+// recordReconcileResult updates ConsecutiveFailures and LastError with the outcome of a reconcile: a failed
+// digest fetch, or status writes that failed since the last reconcile, count as failures. It returns the
+// number of consecutive failures, 0 when the reconcile succeeded.
+func (r *ImagePolicyReconciler) recordReconcileResult(policy *securityv1.ImagePolicy, fetchErr error) int32 {
+	updateFailure := r.takeStatusUpdateFailures(types.NamespacedName{Namespace: policy.Namespace, Name: policy.Name})
+	if fetchErr == nil && updateFailure.Count == 0 {
+		policy.Status.ConsecutiveFailures = 0
+		policy.Status.LastError = ""
+		return 0
+	}
+
+	policy.Status.ConsecutiveFailures += updateFailure.Count
+	if updateFailure.Err != nil {
+		policy.Status.LastError = fmt.Sprintf("failed to update status: %v", updateFailure.Err)
+	}
+	if fetchErr != nil {
+		policy.Status.ConsecutiveFailures++
+		policy.Status.LastError = fetchErr.Error()
+	}
+	return policy.Status.ConsecutiveFailures
+}
+
+// errorRequeueInterval returns the first retry of a failing policy, never later than its check interval
+func (r *ImagePolicyReconciler) errorRequeueInterval(interval time.Duration) time.Duration {
+	first := r.ErrorRequeueInterval
+	if first <= 0 {
+		first = defaultErrorRequeueInterval
+	}
+	return min(first, interval)
+}
+
+// failureBackoff returns the requeue of a policy that failed reconciles in a row: first after one failure,
+// doubled for every further failure up to maxFailureBackoff, so transient errors are retried quickly and
+// chronic ones end up well past the check interval
+func failureBackoff(first time.Duration, failures int32) time.Duration {
+	backoff := first
+	for i := int32(1); i < failures && backoff < maxFailureBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxFailureBackoff)
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Reconcile failures", func() {
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "failing", Namespace: "default"}
+
+	It("should retry failing policies quickly, then back off", func() {
+		Expect(failureBackoff(10*time.Second, 1)).To(Equal(10 * time.Second))
+		Expect(failureBackoff(10*time.Second, 2)).To(Equal(20 * time.Second))
+		Expect(failureBackoff(10*time.Second, 4)).To(Equal(80 * time.Second))
+		Expect(failureBackoff(10*time.Second, 1000)).To(Equal(maxFailureBackoff))
+	})
+
+	It("should start the error requeue at ErrorRequeueInterval, at most the check interval", func() {
+		Expect((&ImagePolicyReconciler{}).errorRequeueInterval(time.Minute)).To(Equal(defaultErrorRequeueInterval))
+		Expect((&ImagePolicyReconciler{ErrorRequeueInterval: 30 * time.Second}).errorRequeueInterval(time.Minute)).To(Equal(30 * time.Second))
+		Expect((&ImagePolicyReconciler{ErrorRequeueInterval: 5 * time.Minute}).errorRequeueInterval(time.Minute)).To(Equal(time.Minute))
+	})
+
+	It("should count failed reconciles until one succeeds", func() {
+		failing := true
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/token":
+				_, _ = w.Write([]byte(`{"token":"test"}`))
+			case failing:
+				w.WriteHeader(http.StatusInternalServerError)
+			default:
+				w.Header().Set("Docker-Content-Digest", "sha256:1111111111111111111111111111111111111111111111111111111111111111")
+			}
+		}))
+		previousRegistryURL, previousAuthURL := dockerHubRegistryURL, dockerHubAuthURL
+		dockerHubRegistryURL, dockerHubAuthURL = server.URL, server.URL
+		DeferCleanup(func() {
+			server.Close()
+			dockerHubRegistryURL, dockerHubAuthURL = previousRegistryURL, previousAuthURL
+		})
+
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Spec:       securityv1.ImagePolicySpec{Repository: "jonlimpw/cg-demo"},
+		}
+		failStatusUpdate := false
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(policy).WithStatusSubresource(policy).
+			WithInterceptorFuncs(interceptor.Funcs{
+				SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+					if failStatusUpdate {
+						return errors.New("etcd is unavailable")
+					}
+					return c.SubResource(subResourceName).Update(ctx, obj, opts...)
+				},
+			}).Build()
+		recorder := record.NewFakeRecorder(10)
+		reconciler := &ImagePolicyReconciler{Client: fakeClient, Recorder: recorder, DockerHubMaxRetries: 1}
+		reconcileStatus := func() securityv1.ImagePolicyStatus {
+			GinkgoHelper()
+			_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			updated := &securityv1.ImagePolicy{}
+			Expect(fakeClient.Get(ctx, key, updated)).To(Succeed())
+			return updated.Status
+		}
+
+		status := reconcileStatus()
+		Expect(status.ConsecutiveFailures).To(Equal(int32(1)))
+		Expect(status.LastError).To(ContainSubstring("failed to fetch the latest digest of jonlimpw/cg-demo"))
+		Expect(recorder.Events).NotTo(Receive(ContainSubstring("ReconcileFailing")))
+
+		reconcileStatus()
+		Expect(reconcileStatus().ConsecutiveFailures).To(Equal(int32(failureEventThreshold)))
+		Expect(recorder.Events).To(Receive(ContainSubstring("ReconcileFailing")))
+
+		// The fourth failure waits past the check interval
+		result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(80 * time.Second))
+
+		status = reconcileStatus()
+		Expect(status.ConsecutiveFailures).To(Equal(int32(failureEventThreshold + 2)))
+
+		// A successful reconcile resets the count
+		failing = false
+		status = reconcileStatus()
+		Expect(status.ConsecutiveFailures).To(BeZero())
+		Expect(status.LastError).To(BeEmpty())
+
+		// A status write that fails is counted by the next one that goes through
+		updated := &securityv1.ImagePolicy{}
+		Expect(fakeClient.Get(ctx, key, updated)).To(Succeed())
+		updated.Status.Repositories = nil
+		Expect(fakeClient.Status().Update(ctx, updated)).To(Succeed())
+		failStatusUpdate = true
+		_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).To(MatchError(ContainSubstring("etcd is unavailable")))
+
+		failStatusUpdate = false
+		status = reconcileStatus()
+		Expect(status.ConsecutiveFailures).To(Equal(int32(1)))
+		Expect(status.LastError).To(Equal("failed to update status: etcd is unavailable"))
+		Expect(reconcileStatus().ConsecutiveFailures).To(BeZero())
+	})
+
+	It("should requeue failing reconciles with a backoff and successful ones on the check interval", func() {
+		failing := true
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/token":
+				_, _ = w.Write([]byte(`{"token":"test"}`))
+			case failing:
+				w.WriteHeader(http.StatusInternalServerError)
+			default:
+				w.Header().Set("Docker-Content-Digest", "sha256:1111111111111111111111111111111111111111111111111111111111111111")
+			}
+		}))
+		previousRegistryURL, previousAuthURL := dockerHubRegistryURL, dockerHubAuthURL
+		dockerHubRegistryURL, dockerHubAuthURL = server.URL, server.URL
+		DeferCleanup(func() {
+			server.Close()
+			dockerHubRegistryURL, dockerHubAuthURL = previousRegistryURL, previousAuthURL
+		})
+
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Spec:       securityv1.ImagePolicySpec{Repository: "jonlimpw/cg-demo", CheckIntervalSeconds: ptr.To(int32(300))},
+		}
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(policy).WithStatusSubresource(policy).Build()
+		reconciler := &ImagePolicyReconciler{Client: fakeClient, Recorder: record.NewFakeRecorder(100), DockerHubMaxRetries: 1,
+			ErrorRequeueInterval: 15 * time.Second}
+		requeueAfter := func() time.Duration {
+			GinkgoHelper()
+			result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			return result.RequeueAfter
+		}
+
+		// Failures are retried from the error interval, doubling past the check interval
+		var progression []time.Duration
+		for range 6 {
+			progression = append(progression, requeueAfter())
+		}
+		Expect(progression).To(Equal([]time.Duration{
+			15 * time.Second, 30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute,
+		}))
+
+		// Once the registry answers again, the policy is back on its check interval
+		failing = false
+		Expect(requeueAfter()).To(Equal(5 * time.Minute))
+	})
+})
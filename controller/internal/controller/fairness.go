@@ -0,0 +1,133 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+// errNamespaceAnalysisTimeout cancels the analyses of a namespace that exceeded its NamespaceAnalysisTimeout
+var errNamespaceAnalysisTimeout = errors.New("namespace analysis timeout exceeded")
+
+// namespaceAnalysisTimeout returns a policy's NamespaceAnalysisTimeout, 0 when unset
+func namespaceAnalysisTimeout(policy *securityv1.ImagePolicy) (time.Duration, error) {
+	if policy.Spec.NamespaceAnalysisTimeout == nil || *policy.Spec.NamespaceAnalysisTimeout == "" {
+		return 0, nil
+	}
+
+	timeout, err := time.ParseDuration(*policy.Spec.NamespaceAnalysisTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid NamespaceAnalysisTimeout %q: %w", *policy.Spec.NamespaceAnalysisTimeout, err)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("invalid NamespaceAnalysisTimeout %q: must be positive", *policy.Spec.NamespaceAnalysisTimeout)
+	}
+	return timeout, nil
+}
+
+// interleavedByNamespace returns the indexes of the workloads taking one of each namespace in turn, so the
+// analyses of a namespace with many workloads don't take every slot while the other namespaces wait
+func interleavedByNamespace(deployments []workload) []int {
+	var namespaces []string
+	byNamespace := map[string][]int{}
+	for i, deployment := range deployments {
+		namespace := deployment.GetNamespace()
+		if _, seen := byNamespace[namespace]; !seen {
+			namespaces = append(namespaces, namespace)
+		}
+		byNamespace[namespace] = append(byNamespace[namespace], i)
+	}
+
+	order := make([]int, 0, len(deployments))
+	for round := 0; len(order) < len(deployments); round++ {
+		for _, namespace := range namespaces {
+			if round < len(byNamespace[namespace]) {
+				order = append(order, byNamespace[namespace][round])
+			}
+		}
+	}
+	return order
+}
+
+// namespaceBudget is the time the analyses of a namespace's workloads share within a reconcile
+type namespaceBudget struct {
+	once   sync.Once
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// context returns the context the namespace's analyses run with, starting its timeout on first use so
+// waiting for other namespaces' analyses doesn't count against it
+func (b *namespaceBudget) context(parent context.Context, timeout time.Duration) context.Context {
+	b.once.Do(func() {
+		if timeout > 0 {
+			b.ctx, b.cancel = context.WithTimeoutCause(parent, timeout, errNamespaceAnalysisTimeout)
+		} else {
+			b.ctx, b.cancel = context.WithCancel(parent)
+		}
+	})
+	return b.ctx
+}
+
+// exceeded checks if the namespace ran out of time
+func (b *namespaceBudget) exceeded() bool {
+	return b.ctx != nil && errors.Is(context.Cause(b.ctx), errNamespaceAnalysisTimeout)
+}
+
+// release frees the namespace's timer once its analyses are done
+func (b *namespaceBudget) release() {
+	if b.cancel != nil {
+		b.cancel()
+	}
+}
+
+// timedOutAnalysis is the Unknown result of a workload whose namespace ran out of analysis time
+func timedOutAnalysis(deployment workload, timeout time.Duration) workloadAnalysis {
+	now := metav1.Now()
+	return workloadAnalysis{Status: securityv1.DeploymentStatus{
+		Kind:      deployment.Kind,
+		Name:      deployment.GetName(),
+		Namespace: deployment.GetNamespace(),
+		Reason:    securityv1.ComplianceReasonUnknown,
+		Message: fmt.Sprintf("Analysis of namespace %s didn't finish within the namespaceAnalysisTimeout of %s",
+			deployment.GetNamespace(), timeout),
+		LastUpdated: &now,
+	}}
+}
+
+// keepUnknownStatus carries over what the last reconcile recorded about a workload whose analysis timed out,
+// with the Unknown reason: nothing new was learned about it, so nothing tracked across reconciles is reset
+func keepUnknownStatus(policy *securityv1.ImagePolicy, deployment workload, status *securityv1.DeploymentStatus) {
+	previous := findDeploymentStatus(policy, deployment)
+	if previous == nil {
+		return
+	}
+	unknown := previous.DeepCopy()
+	unknown.IsCompliant = false
+	unknown.Reason = status.Reason
+	unknown.Message = status.Message
+	unknown.LastUpdated = status.LastUpdated
+	*status = *unknown
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+// syncFinalizer adds the finalizer when RevertOnDelete is set and removes it otherwise
+func (r *ImagePolicyReconciler) syncFinalizer(ctx context.Context, policy *securityv1.ImagePolicy) error {
+	var changed bool
+	if policy.Spec.RevertOnDelete {
+		changed = controllerutil.AddFinalizer(policy, securityv1.ImagePolicyFinalizer)
+	} else {
+		changed = controllerutil.RemoveFinalizer(policy, securityv1.ImagePolicyFinalizer)
+	}
+
+	if !changed {
+		return nil
+	}
+	return r.Update(ctx, policy)
+}
+
+// finalizeImagePolicy reverts workloads remediated by a deleted policy and releases its finalizer
+func (r *ImagePolicyReconciler) finalizeImagePolicy(ctx context.Context, policy *securityv1.ImagePolicy) error {
+	log := logf.FromContext(ctx)
+
+	if !controllerutil.ContainsFinalizer(policy, securityv1.ImagePolicyFinalizer) {
+		return nil
+	}
+
+	if policy.Spec.RevertOnDelete {
+		// Search every namespace since selectors may have changed since remediation
+		workloads, err := r.listWorkloads(ctx)
+		if err != nil {
+			return err
+		}
+
+		policyKey := types.NamespacedName{Namespace: policy.Namespace, Name: policy.Name}.String()
+		for _, deployment := range workloads {
+			if deployment.GetAnnotations()[securityv1.AnnotationRemediatedBy] != policyKey {
+				continue
+			}
+
+			if err := r.revertDeployment(ctx, deployment); err != nil {
+				log.Error(err, "Failed to revert remediated workload", "kind", deployment.Kind, "deployment", deployment.GetName(), "namespace", deployment.GetNamespace())
+				return err
+			}
+			log.Info("Reverted remediated workload", "kind", deployment.Kind, "deployment", deployment.GetName(), "namespace", deployment.GetNamespace())
+		}
+	}
+
+	controllerutil.RemoveFinalizer(policy, securityv1.ImagePolicyFinalizer)
+	return r.Update(ctx, policy)
+}
+
+// revertDeployment restores the original images and pull policies recorded during remediation and clears the annotations
+func (r *ImagePolicyReconciler) revertDeployment(ctx context.Context, deployment workload) error {
+	originalImages, err := originalImagesOf(deployment)
+	if err != nil {
+		return err
+	}
+	originalPullPolicies, err := originalPullPoliciesOf(deployment)
+	if err != nil {
+		return err
+	}
+
+	updatedDeployment := deployment.deepCopy()
+	for _, container := range updatedDeployment.containers() {
+		if image, ok := originalImages[container.Name]; ok {
+			*container.Image = image
+		}
+		if pullPolicy, ok := originalPullPolicies[container.Name]; ok {
+			*container.PullPolicy = corev1.PullPolicy(pullPolicy)
+		}
+	}
+
+	annotations := updatedDeployment.GetAnnotations()
+	delete(annotations, securityv1.AnnotationOriginalImage)
+	delete(annotations, securityv1.AnnotationOriginalPullPolicy)
+	delete(annotations, securityv1.AnnotationRemediatedBy)
+	updatedDeployment.SetAnnotations(annotations)
+
+	if err := r.Update(ctx, updatedDeployment.Object); err != nil {
+		return fmt.Errorf("failed to revert %s %s/%s: %w", deployment.Kind, deployment.GetNamespace(), deployment.GetName(), err)
+	}
+	return nil
+}
+
+// originalImagesOf returns the container name to original image mapping recorded on a workload
+func originalImagesOf(deployment workload) (map[string]string, error) {
+	return containerAnnotationOf(deployment, securityv1.AnnotationOriginalImage)
+}
+
+// originalPullPoliciesOf returns the container name to original pull policy mapping recorded on a workload
+func originalPullPoliciesOf(deployment workload) (map[string]string, error) {
+	return containerAnnotationOf(deployment, securityv1.AnnotationOriginalPullPolicy)
+}
+
+// containerAnnotationOf decodes a JSON object of container name to value recorded in a workload annotation
+func containerAnnotationOf(deployment workload, annotation string) (map[string]string, error) {
+	values := map[string]string{}
+
+	raw, ok := deployment.GetAnnotations()[annotation]
+	if !ok || raw == "" {
+		return values, nil
+	}
+
+	if err := json.Unmarshal([]byte(raw), &values); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation on %s %s/%s: %w", annotation,
+			deployment.Kind, deployment.GetNamespace(), deployment.GetName(), err)
+	}
+	return values, nil
+}
+
+// setOriginalImages records the original images and remediating policy on a workload
+func setOriginalImages(deployment workload, originalImages map[string]string, policy *securityv1.ImagePolicy) error {
+	raw, err := json.Marshal(originalImages)
+	if err != nil {
+		return fmt.Errorf("failed to encode original images: %w", err)
+	}
+
+	annotations := deployment.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[securityv1.AnnotationOriginalImage] = string(raw)
+	annotations[securityv1.AnnotationRemediatedBy] = types.NamespacedName{Namespace: policy.Namespace, Name: policy.Name}.String()
+	deployment.SetAnnotations(annotations)
+	return nil
+}
+
+// setOriginalPullPolicies records the original pull policies of the containers whose pull policy remediation changed
+func setOriginalPullPolicies(deployment workload, originalPullPolicies map[string]string) error {
+	if len(originalPullPolicies) == 0 {
+		return nil
+	}
+
+	raw, err := json.Marshal(originalPullPolicies)
+	if err != nil {
+		return fmt.Errorf("failed to encode original pull policies: %w", err)
+	}
+
+	annotations := deployment.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[securityv1.AnnotationOriginalPullPolicy] = string(raw)
+	deployment.SetAnnotations(annotations)
+	return nil
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+	"github.com/jonlimpw/chainguard-controller/internal/rekor"
+	"github.com/jonlimpw/chainguard-controller/internal/tuf"
+)
+
+// defaultFulcioRootKey is the data key read when a FulcioRootRef doesn't set one, the name of the
+// Fulcio certificate target in Sigstore's TUF repository
+const defaultFulcioRootKey = "fulcio.crt.pem"
+
+// defaultTUFRootKey is the data key read when a TUFRootRef doesn't set one
+const defaultTUFRootKey = "root.json"
+
+// defaultFulcioRoot returns the trust root of attestation policies with neither a TUF mirror nor a FulcioRootRef,
+// a variable so tests can point it at their own CA
+var defaultFulcioRoot = rekor.PublicGoodTrustRoot
+
+// attestationTrustRoot loads the Fulcio CA bundle an attestation policy's TUF mirror or FulcioRootRef
+// provides, or the public-good Fulcio root when it has neither
+func (r *ImagePolicyReconciler) attestationTrustRoot(ctx context.Context, policy *securityv1.AttestationPolicy) (*rekor.TrustRoot, error) {
+	if policy.TUFMirror != nil {
+		return r.tufTrustRoot(ctx, policy.TUFMirror)
+	}
+	if policy.FulcioRootRef == nil {
+		return defaultFulcioRoot(), nil
+	}
+	return r.fulcioTrustRoot(ctx, policy.FulcioRootRef)
+}
+
+// fulcioTrustRoot loads the Fulcio CA bundle a FulcioRootRef points at, or nil when there is no reference
+func (r *ImagePolicyReconciler) fulcioTrustRoot(ctx context.Context, ref *securityv1.FulcioRootRef) (*rekor.TrustRoot, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	if r.Client == nil {
+		return nil, fmt.Errorf("fulcioRootRef %s/%s requires cluster access", ref.Namespace, ref.Name)
+	}
+
+	kind, key := ref.Kind, ref.Key
+	if kind == "" {
+		kind = securityv1.FulcioRootKindConfigMap
+	}
+	if key == "" {
+		key = defaultFulcioRootKey
+	}
+
+	var bundle []byte
+	objectKey := client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}
+	switch kind {
+	case securityv1.FulcioRootKindSecret:
+		secret := &corev1.Secret{}
+		if err := r.Get(ctx, objectKey, secret); err != nil {
+			return nil, fmt.Errorf("failed to get Fulcio root secret %s/%s: %w", ref.Namespace, ref.Name, err)
+		}
+		bundle = secret.Data[key]
+	default:
+		configMap := &corev1.ConfigMap{}
+		if err := r.Get(ctx, objectKey, configMap); err != nil {
+			return nil, fmt.Errorf("failed to get Fulcio root configmap %s/%s: %w", ref.Namespace, ref.Name, err)
+		}
+		bundle = []byte(configMap.Data[key])
+	}
+	if len(bundle) == 0 {
+		return nil, fmt.Errorf("%s %s/%s has no %q key", kind, ref.Namespace, ref.Name, key)
+	}
+
+	trustRoot, err := rekor.ParseTrustRoot(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s/%s: %w", kind, ref.Namespace, ref.Name, err)
+	}
+	return trustRoot, nil
+}
+
+// tufTrustRoot downloads the Fulcio CA bundle from a TUF mirror, verifying the mirror's metadata from the
+// trusted root.json its RootRef points at
+func (r *ImagePolicyReconciler) tufTrustRoot(ctx context.Context, mirror *securityv1.TUFMirror) (*rekor.TrustRoot, error) {
+	ref := mirror.RootRef
+	if r.Client == nil {
+		return nil, fmt.Errorf("tufMirror rootRef %s/%s requires cluster access", ref.Namespace, ref.Name)
+	}
+
+	key, target := ref.Key, mirror.Target
+	if key == "" {
+		key = defaultTUFRootKey
+	}
+	if target == "" {
+		target = defaultFulcioRootKey
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, configMap); err != nil {
+		return nil, fmt.Errorf("failed to get TUF root configmap %s/%s: %w", ref.Namespace, ref.Name, err)
+	}
+	trustedRoot := configMap.Data[key]
+	if trustedRoot == "" {
+		return nil, fmt.Errorf("ConfigMap %s/%s has no %q key", ref.Namespace, ref.Name, key)
+	}
+
+	tufClient, err := r.tufClientFor(mirror.URL, []byte(trustedRoot))
+	if err != nil {
+		return nil, fmt.Errorf("ConfigMap %s/%s: %w", ref.Namespace, ref.Name, err)
+	}
+	bundle, err := tufClient.Target(ctx, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Fulcio root from TUF mirror %s: %w", mirror.URL, err)
+	}
+
+	trustRoot, err := rekor.ParseTrustRoot(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("TUF target %s: %w", target, err)
+	}
+	return trustRoot, nil
+}
+
+// tufClientFor returns the client of a TUF mirror, reused between checks so its verified metadata is only
+// refreshed every tuf.RefreshInterval. A new trusted root gets a new client.
+func (r *ImagePolicyReconciler) tufClientFor(url string, trustedRoot []byte) (*tuf.Client, error) {
+	key := url + "\n" + string(trustedRoot)
+
+	r.tufClientsMu.Lock()
+	defer r.tufClientsMu.Unlock()
+
+	if tufClient, ok := r.tufClients[key]; ok {
+		return tufClient, nil
+	}
+
+	tufClient, err := tuf.NewClient(url, trustedRoot)
+	if err != nil {
+		return nil, err
+	}
+	if r.tufClients == nil {
+		r.tufClients = map[string]*tuf.Client{}
+	}
+	r.tufClients[key] = tufClient
+	return tufClient, nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+	"github.com/jonlimpw/chainguard-controller/internal/tuf"
+	"github.com/jonlimpw/chainguard-controller/internal/tuf/tuftest"
+)
+
+var _ = Describe("Fulcio root bundle", func() {
+	// newRootBundle returns a PEM bundle holding a self-signed CA certificate
+	newRootBundle := func() string {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: "private-fulcio-root"},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().Add(time.Hour),
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+			KeyUsage:              x509.KeyUsageCertSign,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		Expect(err).NotTo(HaveOccurred())
+		return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	}
+
+	newReconciler := func() *ImagePolicyReconciler {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "fulcio-root", Namespace: "sigstore-system"},
+			Data:       map[string]string{defaultFulcioRootKey: newRootBundle(), "empty": ""},
+		}
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "fulcio-root", Namespace: "default"},
+			Data:       map[string][]byte{"roots.pem": []byte(newRootBundle())},
+		}
+		return &ImagePolicyReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(configMap, secret).Build(),
+		}
+	}
+
+	It("should load a custom root bundle from a ConfigMap", func() {
+		trustRoot, err := newReconciler().fulcioTrustRoot(context.Background(),
+			&securityv1.FulcioRootRef{Name: "fulcio-root", Namespace: "sigstore-system"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(trustRoot).NotTo(BeNil())
+	})
+
+	It("should load a custom root bundle from a Secret key", func() {
+		trustRoot, err := newReconciler().fulcioTrustRoot(context.Background(),
+			&securityv1.FulcioRootRef{Kind: securityv1.FulcioRootKindSecret, Name: "fulcio-root", Namespace: "default", Key: "roots.pem"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(trustRoot).NotTo(BeNil())
+	})
+
+	It("should fail when the bundle or its key is missing", func() {
+		reconciler := newReconciler()
+		_, err := reconciler.fulcioTrustRoot(context.Background(), &securityv1.FulcioRootRef{Name: "missing", Namespace: "sigstore-system"})
+		Expect(err).To(MatchError(ContainSubstring("failed to get Fulcio root configmap sigstore-system/missing")))
+
+		_, err = reconciler.fulcioTrustRoot(context.Background(), &securityv1.FulcioRootRef{Name: "fulcio-root", Namespace: "sigstore-system", Key: "empty"})
+		Expect(err).To(MatchError(`ConfigMap sigstore-system/fulcio-root has no "empty" key`))
+	})
+
+	It("should fetch the root bundle from a TUF mirror", func() {
+		bundle := newRootBundle()
+		repository := tuftest.NewRepository(map[string][]byte{"fulcio_v1.crt.pem": []byte(bundle)})
+		DeferCleanup(repository.Close)
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "tuf-root", Namespace: "sigstore-system"},
+			Data:       map[string]string{defaultTUFRootKey: string(repository.TrustedRoot())},
+		}
+		reconciler := &ImagePolicyReconciler{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(configMap).Build()}
+		policy := &securityv1.AttestationPolicy{TUFMirror: &securityv1.TUFMirror{
+			URL:     repository.URL,
+			RootRef: securityv1.TUFRootRef{Name: "tuf-root", Namespace: "sigstore-system"},
+			Target:  "fulcio_v1.crt.pem",
+		}}
+
+		trustRoot, err := reconciler.attestationTrustRoot(context.Background(), policy)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(trustRoot).NotTo(BeNil())
+
+		// The verified metadata is reused by the next check
+		tufClient, err := reconciler.tufClientFor(repository.URL, repository.TrustedRoot())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.tufClients).To(HaveLen(1))
+		data, err := tufClient.Target(context.Background(), "fulcio_v1.crt.pem")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal(bundle))
+
+		// A bundle the mirror serves under a different hash than its targets metadata records is rejected
+		repository.Serve(repository.TargetPath("fulcio_v1.crt.pem"), []byte(newRootBundle()))
+		_, err = (&ImagePolicyReconciler{Client: reconciler.Client}).attestationTrustRoot(context.Background(), policy)
+		Expect(err).To(MatchError(tuf.ErrVerification))
+	})
+
+	It("should fall back to the public-good Fulcio root without a reference", func() {
+		trustRoot, err := (&ImagePolicyReconciler{}).attestationTrustRoot(context.Background(), &securityv1.AttestationPolicy{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(trustRoot).To(BeIdenticalTo(defaultFulcioRoot()))
+	})
+})
@@ -0,0 +1,194 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+	"github.com/jonlimpw/chainguard-controller/internal/gitops"
+)
+
+// manifestImagePattern matches YAML "image:" fields, capturing the prefix, optional quotes and the image reference.
+// Only spaces and tabs are skipped, so a match never reaches into the next line.
+var manifestImagePattern = regexp.MustCompile(`(?m)^([ \t]*(?:-[ \t]+)?image:[ \t]*)(["']?)([^\s"'#]+)(["']?)`)
+
+// handleGitOpsRemediation proposes the remediation as a pull request against the policy's GitRepoRef.
+// History and events are only recorded when a new pull request is opened, not when one is already pending.
+func (r *ImagePolicyReconciler) handleGitOpsRemediation(ctx context.Context, policy *securityv1.ImagePolicy, deployment workload, status *securityv1.DeploymentStatus, rules complianceRules, latestDigests map[string]string) {
+	log := logf.FromContext(ctx)
+
+	pr, err := r.remediateViaGitOps(ctx, policy, rules, latestDigests)
+	switch {
+	case stderrors.Is(err, gitops.ErrManifestNotFound), stderrors.Is(err, gitops.ErrNoChanges):
+		// Nothing we can change in Git; report it without treating it as a failed remediation attempt
+		log.Info("GitOps remediation skipped", "kind", deployment.Kind, "deployment", deployment.GetName(), "namespace", deployment.GetNamespace(), "reason", err.Error())
+		r.Recorder.Event(policy, corev1.EventTypeWarning, "GitOpsRemediationSkipped",
+			fmt.Sprintf("Cannot remediate %s %s/%s via GitOps: %v", deployment.Kind, deployment.GetNamespace(), deployment.GetName(), err))
+		return
+	case err != nil:
+		log.Error(err, "Failed to open GitOps remediation pull request", "kind", deployment.Kind, "deployment", deployment.GetName(), "namespace", deployment.GetNamespace())
+		remediationsCounter.WithLabelValues("failure").Inc()
+		r.Recorder.Event(policy, corev1.EventTypeWarning, "AutoRemediationFailed",
+			fmt.Sprintf("Failed to open pull request remediating %s %s/%s: %v", deployment.Kind, deployment.GetNamespace(), deployment.GetName(), err))
+		return
+	}
+
+	status.ProposedDigest = remediationDigest(rules, status, latestDigests)
+	if !pr.Created {
+		log.Info("GitOps remediation pull request already open", "kind", deployment.Kind, "deployment", deployment.GetName(), "namespace", deployment.GetNamespace(), "pullRequest", pr.URL)
+		return
+	}
+
+	log.Info("Opened GitOps remediation pull request", "kind", deployment.Kind, "deployment", deployment.GetName(), "namespace", deployment.GetNamespace(), "pullRequest", pr.URL)
+	remediationsCounter.WithLabelValues("success").Inc()
+	record := securityv1.RemediationRecord{
+		Kind:           deployment.Kind,
+		Name:           deployment.GetName(),
+		Namespace:      deployment.GetNamespace(),
+		OldDigest:      status.CurrentDigest,
+		NewDigest:      status.ProposedDigest,
+		PullRequestURL: pr.URL,
+		Timestamp:      metav1.Now(),
+	}
+	recordRemediation(policy, record)
+	r.notifyRemediation(ctx, policy, deployment, record, status.Repository)
+	r.Recorder.Event(policy, corev1.EventTypeNormal, "GitOpsPullRequestOpened",
+		fmt.Sprintf("Opened %s to remediate %s %s/%s", pr.URL, deployment.Kind, deployment.GetNamespace(), deployment.GetName()))
+	r.recordWorkloadRemediation(policy, deployment, record)
+}
+
+// remediateViaGitOps bumps the monitored images in the GitRepoRef manifest and opens a pull request.
+// The head branch is derived from the policy and the latest digests, so every workload remediated to
+// the same digests shares a single pull request. Images are substituted by the rules of the reconcile.
+func (r *ImagePolicyReconciler) remediateViaGitOps(ctx context.Context, policy *securityv1.ImagePolicy, rules complianceRules, latestDigests map[string]string) (*gitops.PullRequest, error) {
+	ref := policy.Spec.GitRepoRef
+	if ref == nil {
+		return nil, fmt.Errorf("gitRepoRef is required for the GitOps remediation strategy")
+	}
+
+	token, err := r.loadGitToken(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	gitClient, err := r.newGitOpsClient(ref.URL, token)
+	if err != nil {
+		return nil, err
+	}
+
+	branch := ref.Branch
+	if branch == "" {
+		branch = "main"
+	}
+
+	policyKey := types.NamespacedName{Namespace: policy.Namespace, Name: policy.Name}.String()
+	return gitClient.ProposeChange(ctx, gitops.Change{
+		BaseBranch:    branch,
+		HeadBranch:    gitOpsBranch(policy, latestDigests),
+		Path:          ref.Path,
+		Title:         fmt.Sprintf("Update images to latest digests (ImagePolicy %s)", policyKey),
+		Body:          gitOpsPullRequestBody(policyKey, latestDigests),
+		CommitMessage: fmt.Sprintf("Update images to latest digests\n\nRequested by ImagePolicy %s.", policyKey),
+		Update: func(content []byte) ([]byte, error) {
+			return substituteManifestImages(content, latestDigests, rules), nil
+		},
+	})
+}
+
+// newGitOpsClient creates the Git client, honouring GitOpsAPIURL when set
+func (r *ImagePolicyReconciler) newGitOpsClient(repoURL, token string) (*gitops.Client, error) {
+	if r.GitOpsAPIURL != "" {
+		return gitops.NewClientWithAPIURL(repoURL, r.GitOpsAPIURL, token)
+	}
+	return gitops.NewClient(repoURL, token)
+}
+
+// loadGitToken reads the API token from the GitRepoRef Secret
+func (r *ImagePolicyReconciler) loadGitToken(ctx context.Context, policy *securityv1.ImagePolicy) (string, error) {
+	ref := policy.Spec.GitRepoRef.SecretRef
+	namespace, err := referenceNamespace(policy, ref.Namespace)
+	if err != nil {
+		return "", fmt.Errorf("git secret %s: %w", ref.Name, err)
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+		return "", fmt.Errorf("failed to get git secret %s/%s: %w", namespace, ref.Name, err)
+	}
+
+	token := strings.TrimSpace(string(secret.Data["token"]))
+	if token == "" {
+		return "", fmt.Errorf("secret %s/%s has no token key", namespace, ref.Name)
+	}
+	return token, nil
+}
+
+// substituteManifestImages rewrites the image fields of a manifest using the same rules as in-cluster remediation
+func substituteManifestImages(content []byte, latestDigests map[string]string, rules complianceRules) []byte {
+	return manifestImagePattern.ReplaceAllFunc(content, func(match []byte) []byte {
+		groups := manifestImagePattern.FindSubmatch(match)
+		newImage, ok := remediatedImage(string(groups[3]), latestDigests, rules)
+		if !ok {
+			return match
+		}
+		return []byte(string(groups[1]) + string(groups[2]) + newImage + string(groups[4]))
+	})
+}
+
+// gitOpsBranch returns a head branch name unique to the policy and the set of latest digests
+func gitOpsBranch(policy *securityv1.ImagePolicy, latestDigests map[string]string) string {
+	hash := sha256.New()
+	for _, repository := range sortedRepositories(latestDigests) {
+		fmt.Fprintf(hash, "%s@%s\n", repository, latestDigests[repository])
+	}
+	return fmt.Sprintf("imagepolicy/%s/%s/%s", policy.Namespace, policy.Name, hex.EncodeToString(hash.Sum(nil))[:12])
+}
+
+// gitOpsPullRequestBody lists the digests the pull request moves to
+func gitOpsPullRequestBody(policyKey string, latestDigests map[string]string) string {
+	var body strings.Builder
+	fmt.Fprintf(&body, "ImagePolicy `%s` found workloads that are not on the latest image digests.\n\n", policyKey)
+	for _, repository := range sortedRepositories(latestDigests) {
+		if digest := latestDigests[repository]; digest != "" {
+			fmt.Fprintf(&body, "- `%s` → `%s`\n", repository, digest)
+		}
+	}
+	return body.String()
+}
+
+// sortedRepositories returns the repositories of a digest map in a stable order
+func sortedRepositories(latestDigests map[string]string) []string {
+	repositories := make([]string, 0, len(latestDigests))
+	for repository := range latestDigests {
+		repositories = append(repositories, repository)
+	}
+	sort.Strings(repositories)
+	return repositories
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("GitOps remediation", func() {
+	const (
+		repository   = "jonlimpw/cg-demo"
+		latestDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		staleDigest  = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+		pullURL      = "https://github.com/org/deploy/pull/1"
+	)
+	latestDigests := map[string]string{repository: latestDigest}
+	rules := complianceRules{EnforceLatest: true}
+
+	DescribeTable("substituting manifest images",
+		func(manifest, expected string) {
+			Expect(string(substituteManifestImages([]byte(manifest), latestDigests, rules))).To(Equal(expected))
+		},
+		Entry("an outdated digest",
+			"image: jonlimpw/cg-demo@"+staleDigest+"\n",
+			"image: jonlimpw/cg-demo@"+latestDigest+"\n"),
+		Entry("double-quoted and single-quoted images",
+			"image: \"jonlimpw/cg-demo:1.0\"\nimage: 'docker.io/jonlimpw/cg-demo@"+staleDigest+"'\n",
+			"image: \"jonlimpw/cg-demo@"+latestDigest+"\"\nimage: 'docker.io/jonlimpw/cg-demo@"+latestDigest+"'\n"),
+		Entry("list items",
+			"containers:\n  - image: jonlimpw/cg-demo@"+staleDigest+"\n    name: app\n",
+			"containers:\n  - image: jonlimpw/cg-demo@"+latestDigest+"\n    name: app\n"),
+		Entry("a trailing comment",
+			"image: jonlimpw/cg-demo@"+staleDigest+" # bumped by CI\n",
+			"image: jonlimpw/cg-demo@"+latestDigest+" # bumped by CI\n"),
+		Entry("an unmonitored repository",
+			"image: nginx@"+staleDigest+"\n",
+			"image: nginx@"+staleDigest+"\n"),
+		Entry("an image field without a value on its line",
+			"image:\n  jonlimpw/cg-demo@"+staleDigest+"\n",
+			"image:\n  jonlimpw/cg-demo@"+staleDigest+"\n"),
+	)
+
+	Context("opening pull requests", func() {
+		var (
+			reconciler *ImagePolicyReconciler
+			recorder   *record.FakeRecorder
+			policy     *securityv1.ImagePolicy
+			status     *securityv1.DeploymentStatus
+			manifest   *string
+			committed  string
+		)
+		deployment, _ := newWorkload(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"}})
+		ctx := context.Background()
+
+		BeforeEach(func() {
+			content := "image: jonlimpw/cg-demo@" + staleDigest + "\n"
+			manifest, committed = &content, ""
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /repos/org/deploy/pulls", func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("[]"))
+			})
+			mux.HandleFunc("GET /repos/org/deploy/contents/apps/demo.yaml", func(w http.ResponseWriter, _ *http.Request) {
+				if manifest == nil {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(map[string]string{
+					"sha": "blob", "encoding": "base64", "content": base64.StdEncoding.EncodeToString([]byte(*manifest)),
+				})
+			})
+			mux.HandleFunc("GET /repos/org/deploy/git/ref/heads/main", func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(`{"object":{"sha":"base"}}`))
+			})
+			mux.HandleFunc("POST /repos/org/deploy/git/refs", func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+			})
+			mux.HandleFunc("PUT /repos/org/deploy/contents/apps/demo.yaml", func(_ http.ResponseWriter, r *http.Request) {
+				var body struct {
+					Content string `json:"content"`
+				}
+				Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+				decoded, _ := base64.StdEncoding.DecodeString(body.Content)
+				committed = string(decoded)
+			})
+			mux.HandleFunc("POST /repos/org/deploy/pulls", func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte(`{"html_url":"` + pullURL + `"}`))
+			})
+			server := httptest.NewServer(mux)
+			DeferCleanup(server.Close)
+
+			policy = &securityv1.ImagePolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+				Spec: securityv1.ImagePolicySpec{
+					Repository:          repository,
+					RemediationStrategy: securityv1.RemediationStrategyGitOps,
+					GitRepoRef: &securityv1.GitRepoRef{
+						URL:       "https://github.com/org/deploy",
+						Path:      "apps/demo.yaml",
+						SecretRef: corev1.SecretReference{Name: "git"},
+					},
+				},
+			}
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "git", Namespace: "default"},
+				Data:       map[string][]byte{"token": []byte("token")},
+			}
+			recorder = record.NewFakeRecorder(10)
+			reconciler = &ImagePolicyReconciler{
+				Client:       fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build(),
+				Recorder:     recorder,
+				GitOpsAPIURL: server.URL,
+			}
+			status = &securityv1.DeploymentStatus{Kind: securityv1.WorkloadKindDeployment, Name: "demo", Namespace: "default",
+				Repository: repository, CurrentDigest: staleDigest, Reason: securityv1.NonComplianceReasonOutdatedDigest}
+		})
+
+		It("should record the pull request in the remediation history", func() {
+			reconciler.handleGitOpsRemediation(ctx, policy, deployment, status, rules, latestDigests)
+
+			Expect(committed).To(Equal("image: jonlimpw/cg-demo@" + latestDigest + "\n"))
+			Expect(status.ProposedDigest).To(Equal(latestDigest))
+			Expect(policy.Status.RemediationHistory).To(HaveLen(1))
+			Expect(policy.Status.RemediationHistory[0].PullRequestURL).To(Equal(pullURL))
+			Expect(policy.Status.RemediationHistory[0].OldDigest).To(Equal(staleDigest))
+			Expect(policy.Status.RemediationHistory[0].NewDigest).To(Equal(latestDigest))
+			Expect(recorder.Events).To(Receive(ContainSubstring("GitOpsPullRequestOpened Opened " + pullURL)))
+		})
+
+		It("should skip a manifest missing from the repository", func() {
+			manifest = nil
+
+			reconciler.handleGitOpsRemediation(ctx, policy, deployment, status, rules, latestDigests)
+
+			Expect(committed).To(BeEmpty())
+			Expect(status.ProposedDigest).To(BeEmpty())
+			Expect(policy.Status.RemediationHistory).To(BeEmpty())
+			Expect(recorder.Events).To(Receive(ContainSubstring("GitOpsRemediationSkipped")))
+		})
+
+		It("should skip a manifest that is already up to date", func() {
+			content := "image: jonlimpw/cg-demo@" + latestDigest + "\n"
+			manifest = &content
+
+			reconciler.handleGitOpsRemediation(ctx, policy, deployment, status, rules, latestDigests)
+
+			Expect(committed).To(BeEmpty())
+			Expect(status.ProposedDigest).To(BeEmpty())
+			Expect(policy.Status.RemediationHistory).To(BeEmpty())
+			Expect(recorder.Events).To(Receive(ContainSubstring("GitOpsRemediationSkipped")))
+		})
+	})
+})
@@ -0,0 +1,169 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+// defaultGoldenDigestKey is the data key read when a GoldenDigestRef doesn't set one
+const defaultGoldenDigestKey = "digest"
+
+// goldenDigestPattern matches a bare digest promoted for every monitored repository
+var goldenDigestPattern = regexp.MustCompile(`^(sha256:[a-f0-9]{64}|sha512:[a-f0-9]{128})$`)
+
+// fetchGoldenDigests is fetchLatestDigests for policies with a GoldenDigestRef: the target digest of each
+// repository is read from the ConfigMap on every reconcile instead of being fetched from DockerHub. A
+// repository the ConfigMap promotes no digest for maps to an empty digest, and the error is returned.
+func (r *ImagePolicyReconciler) fetchGoldenDigests(ctx context.Context, policy *securityv1.ImagePolicy, repositories []string, checkInterval int32) (map[string]string, []securityv1.RepositoryStatus, time.Duration, error) {
+	log := logf.FromContext(ctx)
+
+	now := metav1.Now()
+	interval := time.Duration(checkInterval) * time.Second
+	golden, err := r.loadGoldenDigests(ctx, policy, repositories)
+
+	latestDigests := map[string]string{}
+	repositoryStatuses := make([]securityv1.RepositoryStatus, 0, len(repositories))
+	var missing []string
+	for _, repository := range repositories {
+		repoStatus := securityv1.RepositoryStatus{Repository: repository}
+		if previous := findRepositoryStatus(policy, repository); previous != nil {
+			repoStatus.LatestDigest = previous.LatestDigest
+			repoStatus.LastChecked = previous.LastChecked
+		}
+
+		digest := golden[repository]
+		latestDigests[repository] = digest
+		if err == nil && digest == "" {
+			missing = append(missing, repository)
+		}
+		if digest != "" {
+			// Only move LastChecked on promotions and once per interval, so rereading the ConfigMap doesn't write the status
+			if digest != repoStatus.LatestDigest || repoStatus.LastChecked == nil || now.Sub(repoStatus.LastChecked.Time) > interval {
+				repoStatus.LastChecked = &now
+			}
+			repoStatus.LatestDigest = digest
+		}
+		repositoryStatuses = append(repositoryStatuses, repoStatus)
+	}
+	if len(missing) > 0 {
+		err = fmt.Errorf("goldenDigestRef %s promotes no digest for %s", goldenDigestRefName(policy), strings.Join(missing, ", "))
+	}
+
+	if err != nil {
+		log.Error(err, "Failed to read the golden digests")
+		r.updateCondition(policy, securityv1.ConditionTypeDegraded, metav1.ConditionTrue, "GoldenDigestError", err.Error())
+		policy.Status.ComplianceStatus = securityv1.ComplianceStatusError
+		return latestDigests, repositoryStatuses, 0, err
+	}
+	if degraded := meta.FindStatusCondition(policy.Status.Conditions, securityv1.ConditionTypeDegraded); degraded != nil && degraded.Reason == "GoldenDigestError" {
+		meta.RemoveStatusCondition(&policy.Status.Conditions, securityv1.ConditionTypeDegraded)
+	}
+	log.V(1).Info("Read golden digests", "goldenDigestRef", goldenDigestRefName(policy), "digests", latestDigests)
+	return latestDigests, repositoryStatuses, 0, nil
+}
+
+// goldenDigestRefName returns the namespace/name of a policy's GoldenDigestRef, defaulting to the policy namespace
+func goldenDigestRefName(policy *securityv1.ImagePolicy) string {
+	namespace := policy.Spec.GoldenDigestRef.Namespace
+	if namespace == "" {
+		namespace = policy.Namespace
+	}
+	return namespace + "/" + policy.Spec.GoldenDigestRef.Name
+}
+
+// loadGoldenDigests reads the digests the policy's GoldenDigestRef promotes for the repositories, keyed by repository
+func (r *ImagePolicyReconciler) loadGoldenDigests(ctx context.Context, policy *securityv1.ImagePolicy, repositories []string) (map[string]string, error) {
+	ref := policy.Spec.GoldenDigestRef
+	if r.Client == nil {
+		return nil, fmt.Errorf("goldenDigestRef %s requires cluster access", goldenDigestRefName(policy))
+	}
+
+	key := ref.Key
+	if key == "" {
+		key = defaultGoldenDigestKey
+	}
+
+	namespace, err := referenceNamespace(policy, ref.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("goldenDigestRef %s: %w", ref.Name, err)
+	}
+	configMap := &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, configMap); err != nil {
+		return nil, fmt.Errorf("failed to get golden digest configmap %s/%s: %w", namespace, ref.Name, err)
+	}
+	value, ok := configMap.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("ConfigMap %s/%s has no %q key", namespace, ref.Name, key)
+	}
+
+	digests, err := parseGoldenDigests(value, repositories)
+	if err != nil {
+		return nil, fmt.Errorf("ConfigMap %s/%s key %q: %w", namespace, ref.Name, key, err)
+	}
+	return digests, nil
+}
+
+// parseGoldenDigests maps repositories to the digest a GoldenDigestRef value promotes for them. The value
+// holds a bare digest for every repository, image references for specific ones, or both; empty lines
+// and # comments are skipped, and an image reference takes precedence over a bare digest. Invalid lines
+// are reported by number only, since the error ends up in the policy status.
+func parseGoldenDigests(value string, repositories []string) (map[string]string, error) {
+	digests := map[string]string{}
+	var everyRepository string
+	for number, line := range strings.Split(value, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if goldenDigestPattern.MatchString(line) {
+			everyRepository = line
+			continue
+		}
+
+		digest := imageDigest(line)
+		if digest == "" {
+			return nil, fmt.Errorf("invalid golden digest on line %d, expected a digest or <repository>@<digest>", number+1)
+		}
+		for _, repository := range repositories {
+			if imageUsesRepository(line, repository) {
+				digests[repository] = digest
+			}
+		}
+	}
+
+	if everyRepository != "" {
+		for _, repository := range repositories {
+			if digests[repository] == "" {
+				digests[repository] = everyRepository
+			}
+		}
+	}
+	return digests, nil
+}
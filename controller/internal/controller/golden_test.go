@@ -0,0 +1,144 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+	"github.com/jonlimpw/chainguard-controller/internal/registry/registrytest"
+)
+
+var _ = Describe("Golden digests", func() {
+	const (
+		goldenDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		nginxDigest  = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	)
+
+	ctx := context.Background()
+
+	Context("when parsing a golden digest value", func() {
+		repositories := []string{"jonlimpw/cg-demo", "nginx"}
+
+		It("should hold every repository to a bare digest", func() {
+			digests, err := parseGoldenDigests(goldenDigest+"\n", repositories)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(digests).To(Equal(map[string]string{"jonlimpw/cg-demo": goldenDigest, "nginx": goldenDigest}))
+		})
+
+		It("should prefer the digest promoted for a repository", func() {
+			digests, err := parseGoldenDigests("# promoted by the release pipeline\n"+goldenDigest+"\ndocker.io/library/nginx@"+nginxDigest, repositories)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(digests).To(Equal(map[string]string{"jonlimpw/cg-demo": goldenDigest, "nginx": nginxDigest}))
+		})
+
+		It("should leave out repositories without a promoted digest", func() {
+			digests, err := parseGoldenDigests("jonlimpw/cg-demo@"+goldenDigest, repositories)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(digests).To(Equal(map[string]string{"jonlimpw/cg-demo": goldenDigest}))
+		})
+
+		It("should reject lines that aren't digests without repeating them", func() {
+			_, err := parseGoldenDigests("# promoted by the release pipeline\n"+goldenDigest+"\ntoken=ghp_secret", repositories)
+			Expect(err).To(MatchError("invalid golden digest on line 3, expected a digest or <repository>@<digest>"))
+		})
+	})
+
+	Context("when reconciling", func() {
+		key := types.NamespacedName{Name: "golden", Namespace: "default"}
+		var fakeRegistry *registrytest.Registry
+		var fakeClient client.Client
+		var reconciler *ImagePolicyReconciler
+
+		newDeployment := func(name, digest string) *appsv1.Deployment {
+			return &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"automation": "true"}},
+				Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "app", Image: "jonlimpw/cg-demo@" + digest}},
+				}}},
+			}
+		}
+
+		BeforeEach(func() {
+			fakeRegistry = registrytest.NewRegistry()
+			DeferCleanup(fakeRegistry.Close)
+			latestDigest := fakeRegistry.PutImage("jonlimpw/cg-demo", "latest")
+
+			policy := &securityv1.ImagePolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec: securityv1.ImagePolicySpec{
+					Repository:      "jonlimpw/cg-demo",
+					GoldenDigestRef: &securityv1.GoldenDigestRef{Name: "promoted"},
+				},
+			}
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme.Scheme).
+				WithObjects(policy, newDeployment("promoted", goldenDigest), newDeployment("ahead", latestDigest),
+					&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "promoted", Namespace: "default"}, Data: map[string]string{"digest": goldenDigest}},
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}).
+				WithStatusSubresource(policy).Build()
+			reconciler = &ImagePolicyReconciler{Client: fakeClient, Recorder: record.NewFakeRecorder(20),
+				DockerHubRegistryURL: fakeRegistry.URL, DockerHubAuthURL: fakeRegistry.URL}
+		})
+
+		It("should measure and remediate against the promoted digest without asking DockerHub", func() {
+			_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			updated := &securityv1.ImagePolicy{}
+			Expect(fakeClient.Get(ctx, key, updated)).To(Succeed())
+			Expect(updated.Status.LatestDigest).To(Equal(goldenDigest))
+			Expect(updated.Status.TotalDeployments).To(Equal(int32(2)))
+			Expect(updated.Status.CompliantDeployments).To(Equal(int32(1)))
+			Expect(fakeRegistry.TokenRequests()).To(BeZero())
+
+			ahead := &appsv1.Deployment{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "ahead", Namespace: "default"}, ahead)).To(Succeed())
+			Expect(ahead.Spec.Template.Spec.Containers[0].Image).To(Equal("jonlimpw/cg-demo@" + goldenDigest))
+		})
+
+		It("should report a missing golden digest and fail closed", func() {
+			configMap := &corev1.ConfigMap{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "promoted", Namespace: "default"}, configMap)).To(Succeed())
+			Expect(fakeClient.Delete(ctx, configMap)).To(Succeed())
+
+			_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			updated := &securityv1.ImagePolicy{}
+			Expect(fakeClient.Get(ctx, key, updated)).To(Succeed())
+			Expect(updated.Status.CompliantDeployments).To(BeZero())
+			Expect(updated.Status.LastError).To(ContainSubstring("golden digest configmap default/promoted"))
+			degraded := meta.FindStatusCondition(updated.Status.Conditions, securityv1.ConditionTypeDegraded)
+			Expect(degraded).NotTo(BeNil())
+			Expect(degraded.Reason).To(Equal("GoldenDigestError"))
+			Expect(fakeRegistry.TokenRequests()).To(BeZero())
+		})
+	})
+})
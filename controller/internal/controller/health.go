@@ -0,0 +1,58 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+	"github.com/jonlimpw/chainguard-controller/internal/rekor"
+)
+
+// RekorHealthCheck is a healthz.Checker that fails when a Rekor server needed by an ImagePolicy
+// requiring attestations is unreachable, since those policies would otherwise silently mark every
+// workload as unattested. It passes when no policy requires attestations.
+func (r *ImagePolicyReconciler) RekorHealthCheck(req *http.Request) error {
+	policies := &securityv1.ImagePolicyList{}
+	if err := r.List(req.Context(), policies); err != nil {
+		return fmt.Errorf("failed to list image policies: %w", err)
+	}
+
+	checked := map[*rekor.Client]bool{}
+	for i := range policies.Items {
+		policy := &policies.Items[i]
+		attestationPolicy := withAttestationNamespace(policy.Spec.AttestationPolicy, policy.Namespace)
+		if !attestationPolicy.RequiresVerification() || attestationPolicy.AttestationSource == securityv1.AttestationSourceReferrers {
+			continue
+		}
+
+		rekorClient, err := r.rekorClientFor(req.Context(), attestationPolicy)
+		if err != nil {
+			return fmt.Errorf("ImagePolicy %s/%s: %w", policy.Namespace, policy.Name, err)
+		}
+		if checked[rekorClient] {
+			continue
+		}
+		checked[rekorClient] = true
+
+		if err := rekorClient.HealthCheck(req.Context()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Rekor health check", func() {
+	var rekorURL string
+
+	BeforeEach(func() {
+		// Rekor answers 404 for every path, so any check against it fails
+		server := httptest.NewServer(http.NotFoundHandler())
+		DeferCleanup(server.Close)
+		rekorURL = server.URL
+	})
+
+	newReconciler := func(requireAttestation bool) *ImagePolicyReconciler {
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+			Spec: securityv1.ImagePolicySpec{
+				Repository: "jonlimpw/cg-demo",
+				AttestationPolicy: &securityv1.AttestationPolicy{
+					RequireAttestation: ptr.To(requireAttestation),
+					RekorURL:           rekorURL,
+					RekorPublicKeyRef:  &securityv1.RekorPublicKeyRef{Name: "rekor-key"},
+				},
+			},
+		}
+		keyMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "rekor-key", Namespace: "default"},
+			Data:       map[string]string{"rekor.pub": string(testRekorPublicKey())},
+		}
+		return &ImagePolicyReconciler{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(policy, keyMap).Build()}
+	}
+
+	It("should fail when a policy requiring attestations can't reach its Rekor server", func() {
+		req := httptest.NewRequest(http.MethodGet, "/readyz/rekor", nil)
+		Expect(newReconciler(true).RekorHealthCheck(req)).To(MatchError(ContainSubstring(rekorURL)))
+	})
+
+	It("should pass when no policy requires attestations", func() {
+		req := httptest.NewRequest(http.MethodGet, "/readyz/rekor", nil)
+		Expect(newReconciler(false).RekorHealthCheck(req)).To(Succeed())
+	})
+})
@@ -0,0 +1,125 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Ignored workloads", func() {
+	const (
+		repository   = "myorg/api"
+		latestDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		oldDigest    = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	)
+
+	ctx := context.Background()
+
+	newIgnoreDeployment := func(name string, labels, annotations map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels, Annotations: annotations},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "app", Image: repository + "@" + oldDigest}},
+			}}},
+		}
+	}
+
+	It("should only ignore workloads labeled true", func() {
+		for value, ignored := range map[string]bool{"true": true, "false": false, "": false} {
+			w, _ := newWorkload(newIgnoreDeployment("demo", map[string]string{securityv1.LabelIgnore: value}, nil))
+			Expect(w.ignored()).To(Equal(ignored), "label value %q", value)
+		}
+	})
+
+	It("should leave ignored workloads out of the status and totals, even with a compliance override", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/token":
+				_, _ = w.Write([]byte(`{"token":"test"}`))
+			case "/v2/" + repository + "/manifests/latest":
+				w.Header().Set("Docker-Content-Digest", latestDigest)
+				_, _ = w.Write([]byte(`{"schemaVersion":2,"mediaType":"` + mediaTypeDockerManifest + `"}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		previousRegistryURL, previousAuthURL := dockerHubRegistryURL, dockerHubAuthURL
+		dockerHubRegistryURL, dockerHubAuthURL = server.URL, server.URL
+		DeferCleanup(func() {
+			server.Close()
+			dockerHubRegistryURL, dockerHubAuthURL = previousRegistryURL, previousAuthURL
+		})
+
+		key := types.NamespacedName{Name: "demo", Namespace: "default"}
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Spec:       securityv1.ImagePolicySpec{Repository: repository},
+		}
+		ignored := newIgnoreDeployment("ignored", map[string]string{securityv1.LabelIgnore: "true"},
+			map[string]string{securityv1.AnnotationComplianceOverride: "INC-1234"})
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(policy, newIgnoreDeployment("monitored", nil, nil), ignored,
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}).
+			WithStatusSubresource(policy).Build()
+		reconciler := &ImagePolicyReconciler{Client: fakeClient, Recorder: record.NewFakeRecorder(10), DockerHubMaxRetries: 1}
+
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		updated := &securityv1.ImagePolicy{}
+		Expect(fakeClient.Get(ctx, key, updated)).To(Succeed())
+		Expect(updated.Status.TotalDeployments).To(Equal(int32(1)))
+		Expect(updated.Status.CompliantDeployments).To(BeZero())
+		Expect(updated.Status.MonitoredDeployments).To(HaveLen(1))
+		Expect(updated.Status.MonitoredDeployments[0].Name).To(Equal("monitored"))
+
+		current := &appsv1.Deployment{}
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(ignored), current)).To(Succeed())
+		Expect(current.Spec.Template.Spec.Containers[0].Image).To(Equal(repository + "@" + oldDigest))
+	})
+
+	It("should neither select ignored workloads for admission nor map their changes to policies", func() {
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"},
+			Spec:       securityv1.ImagePolicySpec{Repository: repository, BlockOnAdmission: true},
+		}
+		reconciler := &ImagePolicyReconciler{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(policy).Build()}
+		ignored, _ := newWorkload(newIgnoreDeployment("ignored", map[string]string{securityv1.LabelIgnore: "true"}, nil))
+		monitored, _ := newWorkload(newIgnoreDeployment("monitored", nil, nil))
+
+		Expect(reconciler.policySelectsWorkload(ctx, policy, ignored)).To(BeFalse())
+		Expect(reconciler.policySelectsWorkload(ctx, policy, monitored)).To(BeTrue())
+		Expect(reconciler.policiesForWorkload(ctx, ignored.Object)).To(BeEmpty())
+		Expect(reconciler.policiesForWorkload(ctx, monitored.Object)).To(HaveLen(1))
+	})
+})
@@ -17,25 +17,51 @@ limitations under the License.
 package controller
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
+	"io"
+	"maps"
+	"math/rand/v2"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+	"github.com/jonlimpw/chainguard-controller/internal/registry"
 	"github.com/jonlimpw/chainguard-controller/internal/rekor"
+	"github.com/jonlimpw/chainguard-controller/internal/tuf"
 )
 
 // DockerHubManifest represents the Docker Hub registry manifest response
@@ -47,9 +73,135 @@ type DockerHubManifest struct {
 	} `json:"config"`
 }
 
+// Registry manifest media types
+const (
+	mediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeOCIImageManifest   = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeOCIImageIndex      = "application/vnd.oci.image.index.v1+json"
+)
+
+// maxManifestSize bounds the manifests whose digest is computed from the body, the limit registries commonly enforce
+const maxManifestSize = 4 << 20
+
+// manifestAccept is the Accept header of manifest requests. Without the OCI image manifest, registries may
+// refuse or convert single-platform OCI images, as Chainguard and Wolfi images often are.
+var manifestAccept = strings.Join([]string{mediaTypeDockerManifest, mediaTypeDockerManifestList, mediaTypeOCIImageManifest, mediaTypeOCIImageIndex}, ", ")
+
+// platformManifestAccept is the Accept header of requests for the manifest of a single platform
+var platformManifestAccept = strings.Join([]string{mediaTypeDockerManifest, mediaTypeOCIImageManifest}, ", ")
+
+// DockerHubManifestList represents a Docker manifest list or OCI image index
+type DockerHubManifestList struct {
+	MediaType     string `json:"mediaType"`
+	SchemaVersion int    `json:"schemaVersion"`
+	Manifests     []struct {
+		MediaType string `json:"mediaType"`
+		Digest    string `json:"digest"`
+		Platform  struct {
+			Architecture string `json:"architecture"`
+			OS           string `json:"os"`
+			Variant      string `json:"variant,omitempty"`
+		} `json:"platform"`
+	} `json:"manifests"`
+}
+
 // DockerHubToken represents the Docker Hub authentication token
 type DockerHubToken struct {
 	Token string `json:"token"`
+
+	// AccessToken is the OAuth2 name of the token some registries (and mirrors) return instead
+	AccessToken string `json:"access_token,omitempty"`
+}
+
+// registryCredentials holds basic auth credentials for the DockerHub token endpoint
+type registryCredentials struct {
+	Username string
+	Password string
+}
+
+// DockerHub endpoints used unless the reconciler overrides them, variables so tests can point them at a mock registry
+var (
+	dockerHubRegistryURL = "https://registry-1.docker.io"
+	dockerHubAuthURL     = "https://auth.docker.io"
+)
+
+// defaultCheckIntervalSeconds is the check interval of policies without CheckIntervalSeconds when the
+// manager doesn't set --default-check-interval (1 minute, demo-friendly)
+const defaultCheckIntervalSeconds = 60
+
+// notFoundCheckInterval is how often a repository DockerHub reported as nonexistent is checked again,
+// unless the policy's check interval is longer
+const notFoundCheckInterval = 10 * time.Minute
+
+// perNamespaceListThreshold is the most namespaces a namespace selector may match for workloads to be
+// listed per namespace instead of cluster-wide. Each List is an API round trip, so per-namespace listing
+// only pays off for small selections (see BenchmarkListWorkloads)
+const perNamespaceListThreshold = 10
+
+// defaultMaxConcurrentAnalyses is the number of workloads analyzed in parallel when MaxConcurrentAnalyses isn't set
+const defaultMaxConcurrentAnalyses = 8
+
+// maxRequeueJitter bounds RequeueJitter so a jittered check interval never gets close to zero
+const maxRequeueJitter = 0.5
+
+// systemNamespaces are skipped when ExcludeSystemNamespaces is set
+var systemNamespaces = []string{"kube-system", "kube-public", "kube-node-lease"}
+
+// digestRequest describes which digest to resolve from the registry
+type digestRequest struct {
+	Repository  string
+	Tag         string
+	Platform    string
+	Credentials *registryCredentials
+
+	// Mirror is the registry mirror to resolve the digest through instead of DockerHub, if any
+	Mirror string
+
+	// Proxy is the HTTP(S) proxy registry requests go through, if any
+	Proxy string
+
+	// DigestType is DigestTypeConfig to resolve the config digest (image ID) instead of the manifest digest
+	DigestType string
+}
+
+// cacheKey returns the digest cache key in registry/repository:tag[@platform][#config][|credentials] form.
+// Authenticated requests are keyed by a hash of their credentials so a digest resolved with one pull
+// secret is never served to a policy with different (or no) credentials.
+func (d digestRequest) cacheKey() string {
+	registry := "registry-1.docker.io"
+	if d.Mirror != "" {
+		registry = strings.TrimPrefix(strings.TrimPrefix(d.Mirror, "https://"), "http://")
+	}
+	key := registry + "/" + dockerHubRepository(d.Repository) + ":" + d.Tag
+	if d.Platform != "" {
+		key += "@" + d.Platform
+	}
+	if d.DigestType == securityv1.DigestTypeConfig {
+		key += "#config"
+	}
+	if d.Credentials != nil {
+		key += "|" + fmt.Sprintf("%x", sha256.Sum256([]byte(d.Credentials.Username+":"+d.Credentials.Password)))
+	}
+	return key
+}
+
+// dockerConfigJSON represents the contents of a kubernetes.io/dockerconfigjson Secret
+type dockerConfigJSON struct {
+	Auths map[string]struct {
+		Username string `json:"username,omitempty"`
+		Password string `json:"password,omitempty"`
+		Auth     string `json:"auth,omitempty"`
+	} `json:"auths"`
+}
+
+// dockerHubAuthKeys are the dockerconfig registry keys that refer to DockerHub
+var dockerHubAuthKeys = []string{
+	"https://index.docker.io/v1/",
+	"index.docker.io",
+	"docker.io",
+	"registry-1.docker.io",
+	"https://registry-1.docker.io",
 }
 
 // ImagePolicyReconciler reconciles a ImagePolicy object
@@ -58,525 +210,2377 @@ type ImagePolicyReconciler struct {
 	Scheme      *runtime.Scheme
 	Recorder    record.EventRecorder
 	RekorClient *rekor.Client
+
+	// APIReader reads remediated workloads back from the API server rather than the cache, to confirm the
+	// update took effect; the Client when nil
+	APIReader client.Reader
+
+	// rekorClientErr is why RekorClient couldn't be created, while RetryRekorClient retries; both are guarded
+	// by rekorClientMu
+	rekorClientMu  sync.RWMutex
+	rekorClientErr error
+
+	// DockerHubMaxRetries is the number of attempts per digest fetch (default 3)
+	DockerHubMaxRetries int
+
+	// RegistryMirror is the default DockerHub mirror digests are resolved through, overridable per policy
+	RegistryMirror string
+
+	// RegistryProxy is the default HTTP(S) proxy for registry requests, overridable per policy
+	RegistryProxy string
+
+	// DockerHubRegistryURL and DockerHubAuthURL are the base URLs of the DockerHub registry and token APIs,
+	// e.g. a registrytest.Registry in tests; empty uses DockerHub
+	DockerHubRegistryURL string
+	DockerHubAuthURL     string
+
+	// DockerHubRateLimiter bounds digest fetches per second across all reconciles; nil means unlimited
+	DockerHubRateLimiter *rate.Limiter
+
+	// MaxParallelRegistryRequests bounds the registry requests in flight across all reconciles, queueing
+	// the rest; 0 means unlimited
+	MaxParallelRegistryRequests int
+
+	// DefaultCheckIntervalSeconds applies to policies without CheckIntervalSeconds (default 60)
+	DefaultCheckIntervalSeconds int32
+
+	// DefaultEnforceLatest applies to policies without EnforceLatestDigest; nil means true
+	DefaultEnforceLatest *bool
+
+	// MaxConcurrentReconciles is the number of ImagePolicies reconciled in parallel (default 1)
+	MaxConcurrentReconciles int
+
+	// MaxConcurrentAnalyses is the number of workloads of a policy analyzed in parallel (default 8)
+	MaxConcurrentAnalyses int
+
+	// ErrorRequeueInterval is the first retry of a policy whose reconcile failed, doubled for every further
+	// failure up to an hour and capped by the check interval (default 10s)
+	ErrorRequeueInterval time.Duration
+
+	// RequeueJitter randomizes each policy's check interval by up to this fraction either way (at most 0.5),
+	// so policies created together don't hit DockerHub in lockstep; 0 requeues exactly on the interval
+	RequeueJitter float64
+
+	// GitOpsAPIURL overrides the API endpoint derived from GitRepoRef.URL (e.g. a GitHub Enterprise proxy)
+	GitOpsAPIURL string
+
+	// TracerProvider creates the reconcile, digest fetch, analysis and attestation spans; the global
+	// OpenTelemetry provider when nil, which drops them unless an exporter was set up
+	TracerProvider trace.TracerProvider
+
+	digestCacheOnce sync.Once
+	digestCache     *digestCache
+
+	registryRequestsOnce sync.Once
+	registryRequests     *semaphore.Weighted
+
+	// rekorClients holds clients for policies overriding the Rekor URL or public key, keyed by URL and key
+	rekorClientsMu sync.Mutex
+	rekorClients   map[string]*rekor.Client
+
+	// tufClients holds the clients of policies' TUF mirrors, keyed by URL and trusted root
+	tufClientsMu sync.Mutex
+	tufClients   map[string]*tuf.Client
+
+	// statusUpdateFailures holds the status writes that failed per policy, counted on the next one that succeeds
+	statusUpdateFailuresMu sync.Mutex
+	statusUpdateFailures   map[types.NamespacedName]statusUpdateFailure
 }
 
 // +kubebuilder:rbac:groups=security.chainguard.dev,resources=imagepolicies,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=security.chainguard.dev,resources=imagepolicies/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=security.chainguard.dev,resources=imagepolicies/finalizers,verbs=update
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
 func (r *ImagePolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, span := r.startSpan(ctx, "Reconcile",
+		attribute.String("imagepolicy.namespace", req.Namespace), attribute.String("imagepolicy.name", req.Name))
+	defer span.End()
+
+	result, err := r.reconcile(ctx, req)
+	return result, spanError(span, err)
+}
+
+// reconcile checks the workloads of an ImagePolicy against the latest digests and remediates them,
+// adding the repositories, workload count and compliance result to the Reconcile span in ctx
+func (r *ImagePolicyReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
-	log.Info("=== RECONCILE STARTED ===", "namespacedName", req.NamespacedName)
+	log.V(1).Info("Reconciling ImagePolicy", "namespacedName", req.NamespacedName)
 
 	// Fetch the ImagePolicy instance
 	imagePolicy := &securityv1.ImagePolicy{}
 	if err := r.Get(ctx, req.NamespacedName, imagePolicy); err != nil {
 		if errors.IsNotFound(err) {
-			log.Info("ImagePolicy resource not found. Ignoring since object must be deleted")
+			log.V(1).Info("ImagePolicy resource not found. Ignoring since object must be deleted")
+			compliantDeploymentsGauge.DeleteLabelValues(req.Namespace, req.Name)
+			totalDeploymentsGauge.DeleteLabelValues(req.Namespace, req.Name)
 			return ctrl.Result{}, nil
 		}
-		log.Error(err, "Failed to get ImagePolicy")
-		return ctrl.Result{}, err
+		log.Error(err, "Failed to get ImagePolicy")
+		return ctrl.Result{}, err
+	}
+
+	// Handle deletion and keep the finalizer in sync with RevertOnDelete
+	if !imagePolicy.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.finalizeImagePolicy(ctx, imagePolicy)
+	}
+	if err := r.syncFinalizer(ctx, imagePolicy); err != nil {
+		log.Error(err, "Failed to update ImagePolicy finalizer")
+		return ctrl.Result{}, err
+	}
+
+	// Keep the stored status to skip the write when the reconcile changes nothing
+	previousStatus := imagePolicy.Status.DeepCopy()
+
+	// Leave paused policies and their workloads untouched; removing the annotation triggers a reconcile
+	if isPaused(imagePolicy) {
+		log.Info("ImagePolicy is paused, skipping reconciliation")
+		return ctrl.Result{}, r.pauseImagePolicy(ctx, imagePolicy)
+	}
+	resumeImagePolicy(imagePolicy)
+
+	// Set default values if not specified
+	checkInterval := r.checkInterval(imagePolicy)
+
+	tags := trackedTags(imagePolicy)
+	rules := r.rulesFor(imagePolicy)
+
+	remediationMode := securityv1.RemediationModeAuto
+	if imagePolicy.Spec.RemediationMode != "" {
+		remediationMode = imagePolicy.Spec.RemediationMode
+	}
+	// Images can't be pulled by their config digest, so there is nothing to pin workloads to
+	if imagePolicy.Spec.DigestType == securityv1.DigestTypeConfig {
+		remediationMode = securityv1.RemediationModeOff
+	}
+
+	// Validate the automation gate up front; remediation is disabled until it is fixed
+	if err := validateAutomationGate(automationGate(imagePolicy)); err != nil {
+		log.Error(err, "Invalid automation gate")
+		r.updateCondition(imagePolicy, securityv1.ConditionTypeDegraded, metav1.ConditionTrue,
+			"InvalidAutomationGate", err.Error())
+	}
+
+	// Validate the attestation MaxAge up front so a bad value is visible on the policy
+	if _, err := attestationMaxAge(imagePolicy.Spec.AttestationPolicy); err != nil {
+		log.Error(err, "Invalid attestation policy")
+		r.updateCondition(imagePolicy, securityv1.ConditionTypeDegraded, metav1.ConditionTrue,
+			"InvalidMaxAge", err.Error())
+	}
+
+	// Validate the drift SLO up front too; it isn't tracked until fixed
+	maxDrift, err := maxDriftDuration(imagePolicy)
+	if err != nil {
+		log.Error(err, "Invalid max drift duration")
+		r.updateCondition(imagePolicy, securityv1.ConditionTypeDegraded, metav1.ConditionTrue,
+			"InvalidMaxDriftDuration", err.Error())
+	}
+	if _, err := remediationCooldown(imagePolicy); err != nil {
+		log.Error(err, "Invalid remediation cooldown")
+		r.updateCondition(imagePolicy, securityv1.ConditionTypeDegraded, metav1.ConditionTrue,
+			"InvalidRemediationCooldown", err.Error())
+	}
+	if _, err := parseRemediationWindow(imagePolicy); err != nil {
+		log.Error(err, "Invalid remediation window")
+		r.updateCondition(imagePolicy, securityv1.ConditionTypeDegraded, metav1.ConditionTrue,
+			"InvalidRemediationWindow", err.Error())
+	}
+	if _, err := namespaceAnalysisTimeout(imagePolicy); err != nil {
+		log.Error(err, "Invalid namespace analysis timeout")
+		r.updateCondition(imagePolicy, securityv1.ConditionTypeDegraded, metav1.ConditionTrue,
+			"InvalidNamespaceAnalysisTimeout", err.Error())
+	}
+
+	// References to other namespaces' Secrets and ConfigMaps are never read
+	if errs := foreignReferences(imagePolicy); len(errs) > 0 {
+		log.Error(errs.ToAggregate(), "Invalid reference")
+		r.updateCondition(imagePolicy, securityv1.ConditionTypeDegraded, metav1.ConditionTrue,
+			"ForeignNamespaceReference", errs.ToAggregate().Error())
+	}
+
+	// Validate the repository pattern up front; a malformed glob matches nothing
+	if imagePolicy.Spec.RepositoryPattern != "" {
+		if err := validateRepositoryPattern(imagePolicy.Spec.RepositoryPattern); err != nil {
+			log.Error(err, "Invalid repository pattern")
+			r.updateCondition(imagePolicy, securityv1.ConditionTypeDegraded, metav1.ConditionTrue,
+				"InvalidRepositoryPattern", err.Error())
+		}
+	}
+
+	// Tally the DockerHub requests the reconcile makes for the policy status
+	registryStats := &registryRequestStats{}
+	ctx = withRegistryRequestStats(ctx, registryStats)
+
+	// Find deployments to monitor
+	deployments, err := r.findDeploymentsToMonitor(ctx, imagePolicy)
+	if err != nil {
+		log.Error(err, "Failed to find deployments to monitor")
+		return ctrl.Result{}, err
+	}
+
+	// Only workloads running the named container are governed; a name no workload has is most likely a typo
+	containerNotFound := false
+	if rules.ContainerName != "" {
+		named := workloadsWithContainer(deployments, rules.ContainerName)
+		if containerNotFound = len(deployments) > 0 && len(named) == 0; containerNotFound {
+			log.Info("No selected workload has the named container", "containerName", rules.ContainerName, "workloads", len(deployments))
+			r.updateCondition(imagePolicy, securityv1.ConditionTypeDegraded, metav1.ConditionTrue,
+				"ContainerNotFound", fmt.Sprintf("None of the %d workloads using a monitored repository has a container named %q", len(deployments), rules.ContainerName))
+		}
+		deployments = named
+	}
+
+	// Fetch the latest digest of each monitored repository, including every repository matching the pattern
+	repositories := monitoredRepositories(imagePolicy, deployments)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.StringSlice("repositories", repositories), attribute.Int("deployments", len(deployments)))
+	latestDigests, repositoryStatuses, requeueAfter, fetchErr := r.fetchLatestDigests(ctx, imagePolicy, repositories, tags, checkInterval)
+
+	// Record the repositories right away: remediation reads the tracked tags' digests from the status
+	imagePolicy.Status.Repositories = repositoryStatuses
+	rules.TrackedDigests = trackedDigests(repositoryStatuses)
+
+	// Workloads may pin the latest multi-arch image by its index or by a platform manifest; a golden digest is exact
+	if rules.EnforceLatest && imagePolicy.Spec.DigestType != securityv1.DigestTypeConfig && imagePolicy.Spec.GoldenDigestRef == nil {
+		rules.MultiArchDigests = r.findMultiArchDigests(ctx, imagePolicy, deployments, repositories, latestDigests, rules.TrackedDigests, time.Duration(checkInterval)*time.Second)
+	}
+
+	// Check the digests workloads run still exist, if requested; config digests aren't manifests to look up
+	if imagePolicy.Spec.VerifyDigestExists != nil && *imagePolicy.Spec.VerifyDigestExists && imagePolicy.Spec.DigestType != securityv1.DigestTypeConfig {
+		rules.MissingDigests = r.findMissingDigests(ctx, imagePolicy, deployments, repositories, time.Duration(checkInterval)*time.Second)
+	}
+
+	// Resolve the tags workloads run so their attestations can be verified or they can be pinned, if requested
+	if (rules.AttestationPolicy.RequiresVerification() && rules.AttestationPolicy.ResolveTags) || pinsResolvedTags(rules) {
+		rules.TagDigests = r.resolveTagDigests(ctx, imagePolicy, deployments, repositories, time.Duration(checkInterval)*time.Second)
+	}
+
+	// Analyze compliance in parallel, then record events and remediate one workload at a time
+	analyses := r.analyzeWorkloads(ctx, deployments, repositories, latestDigests, rules)
+	deploymentStatuses := []securityv1.DeploymentStatus{}
+	compliantCount := int32(0)
+	budget := newRemediationBudget(imagePolicy, deployments)
+	rollingOut := false
+	unknownCount := int32(0)
+	timedOutNamespaces := map[string]int{}
+
+	for i, deployment := range deployments {
+		status, repositoryCompliance := analyses[i].Status, analyses[i].RepositoryCompliance
+		if status.Reason == securityv1.ComplianceReasonUnknown {
+			// Nothing was learned about the workload, so it is neither counted, reported nor remediated
+			keepUnknownStatus(imagePolicy, deployment, &status)
+			unknownCount++
+			timedOutNamespaces[deployment.GetNamespace()]++
+			rollingOut = rollingOut || status.RemediationRollingOut
+			deploymentStatuses = append(deploymentStatuses, status)
+			continue
+		}
+		if !rules.AttestationPolicy.Enforced() && status.HasValidAttestation != nil && !*status.HasValidAttestation {
+			r.Recorder.Event(imagePolicy, corev1.EventTypeWarning, "AttestationWarning",
+				fmt.Sprintf("%s %s/%s failed attestation verification (not enforced): %s", deployment.Kind, deployment.GetNamespace(), deployment.GetName(), status.AttestationDetails.Error))
+		}
+		trackStaleness(imagePolicy, deployment, &status, latestDigests[status.Repository],
+			slices.Concat(rules.TrackedDigests[status.Repository], rules.MultiArchDigests[status.Repository]))
+		trackDrift(imagePolicy, deployment, &status)
+		trackRollout(imagePolicy, deployment, &status)
+		trackRemediation(imagePolicy, deployment, &status)
+		r.recordComplianceOverride(imagePolicy, deployment, &status)
+		r.recordWorkloadCompliance(ctx, imagePolicy, deployment, &status)
+		r.recordDriftViolation(imagePolicy, deployment, &status, maxDrift)
+		log.V(1).Info("Workload compliance status", "kind", deployment.Kind, "deployment", deployment.GetName(), "isCompliant", status.IsCompliant)
+
+		// Tally per-repository compliance
+		for i := range repositoryStatuses {
+			compliant, uses := repositoryCompliance[repositoryStatuses[i].Repository]
+			if !uses {
+				continue
+			}
+			repositoryStatuses[i].TotalDeployments++
+			if compliant {
+				repositoryStatuses[i].CompliantDeployments++
+			}
+		}
+
+		if status.IsCompliant {
+			compliantCount++
+		} else if status.Reason == securityv1.NonComplianceReasonDeniedDigest {
+			// Denied digests are flagged and remediated even when not enforcing the latest digest
+			r.Recorder.Event(imagePolicy, corev1.EventTypeWarning, "DeniedDigestInUse",
+				fmt.Sprintf("%s %s/%s is using denied %s image digest %s in %s %s", deployment.Kind, deployment.GetNamespace(), deployment.GetName(), status.Repository, status.CurrentDigest, strings.ToLower(status.ContainerKind), status.ContainerName))
+
+			r.handleRemediation(ctx, imagePolicy, deployment, &status, rules, latestDigests, remediationMode, budget)
+		} else if status.Reason == securityv1.NonComplianceReasonDisallowedRegistry {
+			// Remediation can't move an image to another registry, so only report it
+			r.Recorder.Event(imagePolicy, corev1.EventTypeWarning, "DisallowedRegistry",
+				fmt.Sprintf("%s %s/%s is non-compliant in %s %s: %s", deployment.Kind, deployment.GetNamespace(), deployment.GetName(), strings.ToLower(status.ContainerKind), status.ContainerName, status.Message))
+		} else if status.Reason == securityv1.NonComplianceReasonRemediationInProgress {
+			// The workload already runs the remediated digests, its pods just haven't all been replaced yet
+			log.V(1).Info("Waiting for remediation to roll out", "kind", deployment.Kind, "deployment", deployment.GetName(), "namespace", deployment.GetNamespace(),
+				"progress", status.Message)
+		} else if rules.EnforceLatest || status.Reason == securityv1.NonComplianceReasonTagBased {
+			// Create event for non-compliant deployment
+			r.recordNonCompliantEvent(imagePolicy, deployment, &status, latestDigests[status.Repository])
+
+			r.handleRemediation(ctx, imagePolicy, deployment, &status, rules, latestDigests, remediationMode, budget)
+		}
+		r.notifyComplianceChange(ctx, imagePolicy, deployment, &status, latestDigests)
+		rollingOut = rollingOut || status.RemediationRollingOut
+		deploymentStatuses = append(deploymentStatuses, status)
+	}
+
+	for _, namespace := range slices.Sorted(maps.Keys(timedOutNamespaces)) {
+		r.Recorder.Event(imagePolicy, corev1.EventTypeWarning, "NamespaceAnalysisTimedOut",
+			fmt.Sprintf("Compliance of %d workloads in namespace %s is unknown: their analysis didn't finish within the namespaceAnalysisTimeout of %s",
+				timedOutNamespaces[namespace], namespace, rules.NamespaceAnalysisTimeout))
+	}
+
+	// Update status
+	for i := range repositoryStatuses {
+		repositoryStatuses[i].ResolvedImage = resolvedImage(repositoryStatuses[i].Repository, repositoryStatuses[i].LatestDigest)
+	}
+	if len(repositoryStatuses) > 0 {
+		imagePolicy.Status.LatestDigest = repositoryStatuses[0].LatestDigest
+		imagePolicy.Status.LastChecked = repositoryStatuses[0].LastChecked
+		imagePolicy.Status.ResolvedTag = repositoryStatuses[0].ResolvedTag
+		imagePolicy.Status.ResolvedImage = repositoryStatuses[0].ResolvedImage
+	}
+	imagePolicy.Status.MonitoredDeployments, imagePolicy.Status.OmittedDeployments = trimMonitoredDeployments(imagePolicy, deploymentStatuses)
+	imagePolicy.Status.TotalDeployments = int32(len(deployments))
+	imagePolicy.Status.CompliantDeployments = compliantCount
+	compliantDeploymentsGauge.WithLabelValues(imagePolicy.Namespace, imagePolicy.Name).Set(float64(compliantCount))
+	totalDeploymentsGauge.WithLabelValues(imagePolicy.Namespace, imagePolicy.Name).Set(float64(len(deployments)))
+
+	// Determine overall compliance status
+	if len(deployments) == 0 {
+		imagePolicy.Status.ComplianceStatus = securityv1.ComplianceStatusUnknown
+		r.updateCondition(imagePolicy, securityv1.ConditionTypeReady, metav1.ConditionTrue,
+			"NoDeployments", "No deployments found matching the policy")
+	} else if compliantCount == int32(len(deployments)) {
+		imagePolicy.Status.ComplianceStatus = securityv1.ComplianceStatusCompliant
+		r.updateCondition(imagePolicy, securityv1.ConditionTypeReady, metav1.ConditionTrue,
+			"AllCompliant", "All monitored deployments are compliant")
+	} else if compliantCount+unknownCount == int32(len(deployments)) {
+		// Only workloads whose analysis timed out may be non-compliant
+		imagePolicy.Status.ComplianceStatus = securityv1.ComplianceStatusUnknown
+		r.updateCondition(imagePolicy, securityv1.ConditionTypeReady, metav1.ConditionTrue,
+			"NamespaceAnalysisTimedOut", fmt.Sprintf("%d of %d deployments are compliant, the analysis of the others timed out",
+				compliantCount, len(deployments)))
+	} else {
+		message := fmt.Sprintf("%d of %d deployments are non-compliant", int32(len(deployments))-compliantCount-unknownCount, len(deployments))
+		if unknownCount > 0 {
+			message += fmt.Sprintf(", %d unknown as their analysis timed out", unknownCount)
+		}
+		imagePolicy.Status.ComplianceStatus = securityv1.ComplianceStatusNonCompliant
+		r.updateCondition(imagePolicy, securityv1.ConditionTypeReady, metav1.ConditionTrue, "NonCompliant", message)
+	}
+
+	// A repository that doesn't exist can't be enforced, whatever the workloads use
+	if slices.ContainsFunc(repositoryStatuses, func(s securityv1.RepositoryStatus) bool { return s.NotFound }) {
+		imagePolicy.Status.ComplianceStatus = securityv1.ComplianceStatusError
+	} else if degraded := meta.FindStatusCondition(imagePolicy.Status.Conditions, securityv1.ConditionTypeDegraded); degraded != nil && degraded.Reason == "RepositoryNotFound" {
+		meta.RemoveStatusCondition(&imagePolicy.Status.Conditions, securityv1.ConditionTypeDegraded)
+	}
+
+	// Nor can a container that doesn't exist
+	if containerNotFound {
+		imagePolicy.Status.ComplianceStatus = securityv1.ComplianceStatusError
+	} else if degraded := meta.FindStatusCondition(imagePolicy.Status.Conditions, securityv1.ConditionTypeDegraded); degraded != nil && degraded.Reason == "ContainerNotFound" {
+		meta.RemoveStatusCondition(&imagePolicy.Status.Conditions, securityv1.ConditionTypeDegraded)
+	}
+
+	r.updateAttestationCondition(imagePolicy, rules.AttestationPolicy, deploymentStatuses)
+	r.updateDriftCondition(imagePolicy, maxDrift, deploymentStatuses)
+
+	// Retry failing policies sooner than the check interval, backing off further the longer they fail
+	if failures := r.recordReconcileResult(imagePolicy, fetchErr); failures > 0 {
+		if failures >= failureEventThreshold {
+			r.Recorder.Event(imagePolicy, corev1.EventTypeWarning, "ReconcileFailing",
+				fmt.Sprintf("ImagePolicy failed %d reconciles in a row: %s", failures, imagePolicy.Status.LastError))
+		}
+		requeueAfter = max(requeueAfter, failureBackoff(r.errorRequeueInterval(time.Duration(checkInterval)*time.Second), failures))
+	}
+
+	registryStats.apply(&imagePolicy.Status, time.Now())
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("result", string(imagePolicy.Status.ComplianceStatus)),
+		attribute.Int("compliant", int(compliantCount)))
+
+	// The status now reflects this generation of the spec, conditions carried over from earlier reconciles included
+	imagePolicy.Status.ObservedGeneration = imagePolicy.Generation
+	for i := range imagePolicy.Status.Conditions {
+		imagePolicy.Status.Conditions[i].ObservedGeneration = imagePolicy.Generation
+	}
+
+	// Update the status, unless nothing changed: every write bumps the resourceVersion and wakes up watchers
+	if statusChanged(previousStatus, &imagePolicy.Status) {
+		if err := r.Status().Update(ctx, imagePolicy); err != nil {
+			log.Error(err, "Failed to update ImagePolicy status")
+			r.addStatusUpdateFailure(req.NamespacedName, err)
+			return ctrl.Result{}, err
+		}
+		statusUpdatesCounter.WithLabelValues("updated").Inc()
+	} else {
+		log.V(1).Info("Status unchanged, skipping update")
+		statusUpdatesCounter.WithLabelValues("skipped").Inc()
+	}
+
+	// Requeue after the jittered check interval, sooner when DockerHub asked us to back off or the reconcile failed,
+	// or later when no repository exists
+	if requeueAfter == 0 {
+		requeueAfter = r.jitteredInterval(time.Duration(checkInterval) * time.Second)
+	}
+
+	// Remediate deferred workloads as soon as the remediation window opens
+	if deferral, err := remediationDeferral(imagePolicy, time.Now()); err == nil && deferral > 0 && deferral < requeueAfter &&
+		remediationMode == securityv1.RemediationModeAuto && imagePolicy.Spec.RemediationStrategy != securityv1.RemediationStrategyGitOps &&
+		compliantCount < int32(len(deployments)) {
+		requeueAfter = deferral
+	}
+	// Remediate the workloads that waited on other rollouts or a PodDisruptionBudget soon after, and check on
+	// remediation rollouts: rollout progress only changes workload status, which doesn't trigger reconciles
+	if (budget.deferred || rollingOut) && rolloutRequeueInterval < requeueAfter {
+		requeueAfter = rolloutRequeueInterval
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// fetchLatestDigests returns the latest digest of each repository, that of the primary tag, along with its
+// refreshed status. Repositories checked within the check interval reuse their last known digest; a repository whose
+// fetch fails maps to an empty digest so its workloads can't be considered compliant, and the last
+// such failure is returned. Running out of the controller's own rate limit isn't a failure, nor is a
+// repository that doesn't exist, which has its own condition and back-off. Policies with a GoldenDigestRef
+// read the digests from it instead.
+func (r *ImagePolicyReconciler) fetchLatestDigests(ctx context.Context, policy *securityv1.ImagePolicy, repositories []string, tags []string, checkInterval int32) (map[string]string, []securityv1.RepositoryStatus, time.Duration, error) {
+	log := logf.FromContext(ctx)
+
+	// Promoted digests replace the registry's latest entirely
+	if policy.Spec.GoldenDigestRef != nil {
+		return r.fetchGoldenDigests(ctx, policy, repositories, checkInterval)
+	}
+
+	now := metav1.Now()
+	interval := time.Duration(checkInterval) * time.Second
+	latestDigests := map[string]string{}
+	repositoryStatuses := make([]securityv1.RepositoryStatus, 0, len(repositories))
+	var requeueAfter time.Duration
+	var fetched, sawRateLimit bool
+	var fetchErr error
+
+	var creds *registryCredentials
+	var credsErr error
+	credsLoaded := false
+
+	for _, repository := range repositories {
+		repoStatus := securityv1.RepositoryStatus{Repository: repository}
+		if previous := findRepositoryStatus(policy, repository); previous != nil {
+			repoStatus.LatestDigest = previous.LatestDigest
+			repoStatus.LastChecked = previous.LastChecked
+			repoStatus.ResolvedTag = previous.ResolvedTag
+			repoStatus.NotFound = previous.NotFound
+			if len(policy.Spec.Tags) > 0 {
+				repoStatus.Tags = previous.Tags
+			}
+		}
+
+		// Check if we need to fetch the latest digest, backing off for repositories that don't exist
+		checkEvery := interval
+		if repoStatus.NotFound {
+			checkEvery = max(interval, notFoundCheckInterval)
+		}
+		shouldCheck := repoStatus.LastChecked == nil || now.Time.Sub(repoStatus.LastChecked.Time) > checkEvery
+		// Tags added to or removed from the policy since the last check are resolved right away
+		if len(policy.Spec.Tags) > 0 && !slices.EqualFunc(repoStatus.Tags, tags, func(s securityv1.TagStatus, tag string) bool { return s.Tag == tag }) {
+			shouldCheck = true
+		}
+		if !shouldCheck {
+			latestDigests[repository] = repoStatus.LatestDigest
+			repositoryStatuses = append(repositoryStatuses, repoStatus)
+			continue
+		}
+		latestDigests[repository] = ""
+
+		// Load the pull secret once, and only if a repository is due for a check
+		if !credsLoaded {
+			creds, credsErr = r.loadRegistryCredentials(ctx, policy)
+			credsLoaded = true
+			if credsErr != nil {
+				log.Error(credsErr, "Failed to load registry credentials")
+				r.updateCondition(policy, securityv1.ConditionTypeDegraded, metav1.ConditionTrue,
+					"PullSecretError", fmt.Sprintf("Failed to load pull secret: %v", credsErr))
+				policy.Status.ComplianceStatus = securityv1.ComplianceStatusError
+			}
+		}
+		if credsErr != nil {
+			fetchErr = fmt.Errorf("failed to load pull secret: %w", credsErr)
+			repositoryStatuses = append(repositoryStatuses, repoStatus)
+			continue
+		}
+
+		// Track the highest tag within the semver range instead of a fixed tag, if set
+		repoTag := tags[0]
+		var err error
+		if policy.Spec.TagSemverRange != "" {
+			repoTag, err = r.resolveSemverTag(ctx, repository, policy.Spec.TagSemverRange, creds, r.registryProxy(policy))
+		}
+
+		var latestDigest string
+		if err == nil {
+			log.V(1).Info("Fetching latest digest from DockerHub", "repository", repository, "tag", repoTag, "platform", policy.Spec.Platform)
+			latestDigest, err = r.getLatestDigestFromDockerHub(ctx, digestRequest{
+				Repository:  repository,
+				Tag:         repoTag,
+				Platform:    policy.Spec.Platform,
+				Credentials: creds,
+				Mirror:      r.registryMirror(policy),
+				Proxy:       r.registryProxy(policy),
+				DigestType:  policy.Spec.DigestType,
+			}, interval)
+		}
+		var rateLimited *rateLimitedError
+		var throttled *throttledError
+		var notFound *registry.NotFoundError
+		if err != nil && !stderrors.As(err, &throttled) && !stderrors.As(err, &notFound) {
+			fetchErr = fmt.Errorf("failed to fetch the latest digest of %s: %w", repository, err)
+		}
+		var authFailed *registry.AuthError
+		switch {
+		case stderrors.As(err, &throttled):
+			// Keep the previous digest until a token is available; this isn't a DockerHub failure
+			log.Info("DockerHub request rate limit reached, requeueing", "repository", repository, "retryAfter", throttled.RetryAfter)
+			latestDigests[repository] = repoStatus.LatestDigest
+			if throttled.RetryAfter > requeueAfter {
+				requeueAfter = throttled.RetryAfter
+			}
+		case stderrors.As(err, &rateLimited):
+			// Wait at least a full interval so a throttled policy doesn't add to the pressure
+			log.Info("DockerHub rate limit persisted through retries", "repository", repository, "attempts", rateLimited.Attempts, "retryAfter", rateLimited.RetryAfter)
+			requeueAfter = max(requeueAfter, rateLimited.RetryAfter, interval)
+			sawRateLimit = true
+			r.updateCondition(policy, securityv1.ConditionTypeDegraded, metav1.ConditionTrue,
+				"RateLimited", fmt.Sprintf("DockerHub rate-limited %s, add pull credentials or a registry mirror: %v", repository, err))
+			r.Recorder.Event(policy, corev1.EventTypeWarning, "DockerHubRateLimited",
+				fmt.Sprintf("DockerHub rate-limited %s after %d attempts", repository, rateLimited.Attempts))
+			policy.Status.ComplianceStatus = securityv1.ComplianceStatusError
+		case stderrors.Is(err, errInvalidSemverRange):
+			log.Error(err, "Invalid tag semver range")
+			r.updateCondition(policy, securityv1.ConditionTypeDegraded, metav1.ConditionTrue,
+				"InvalidTagSemverRange", err.Error())
+			policy.Status.ComplianceStatus = securityv1.ComplianceStatusError
+		case stderrors.Is(err, errNoMatchingTag):
+			log.Error(err, "No tag matches the semver range", "repository", repository, "range", policy.Spec.TagSemverRange)
+			r.updateCondition(policy, securityv1.ConditionTypeDegraded, metav1.ConditionTrue,
+				"NoMatchingTag", fmt.Sprintf("No tag of %s matches %q", repository, policy.Spec.TagSemverRange))
+			policy.Status.ComplianceStatus = securityv1.ComplianceStatusError
+		case stderrors.As(err, &notFound):
+			log.Error(err, "Repository or tag not found on DockerHub", "repository", repository, "tag", repoTag)
+			r.updateCondition(policy, securityv1.ConditionTypeDegraded, metav1.ConditionTrue,
+				"RepositoryNotFound", fmt.Sprintf("Repository %s or its tag %s doesn't exist on DockerHub", repository, repoTag))
+			policy.Status.ComplianceStatus = securityv1.ComplianceStatusError
+			repoStatus.NotFound = true
+			repoStatus.LatestDigest = ""
+			repoStatus.LastChecked = &now
+		case stderrors.As(err, &authFailed):
+			log.Error(err, "DockerHub denied access to the repository", "repository", repository)
+			r.updateCondition(policy, securityv1.ConditionTypeDegraded, metav1.ConditionTrue,
+				"RegistryAuthFailed", fmt.Sprintf("DockerHub denied access to %s, check pullSecretRef: %v", repository, err))
+			policy.Status.ComplianceStatus = securityv1.ComplianceStatusError
+		case err != nil:
+			log.Error(err, "Failed to fetch latest digest from DockerHub", "repository", repository)
+			r.updateCondition(policy, securityv1.ConditionTypeDegraded, metav1.ConditionTrue,
+				"DockerHubError", fmt.Sprintf("Failed to fetch digest for %s: %v", repository, err))
+			policy.Status.ComplianceStatus = securityv1.ComplianceStatusError
+		default:
+			fetched = true
+			repoStatus.LatestDigest = latestDigest
+			repoStatus.LastChecked = &now
+			repoStatus.NotFound = false
+			repoStatus.ResolvedTag = ""
+			if policy.Spec.TagSemverRange != "" {
+				repoStatus.ResolvedTag = repoTag
+			}
+			latestDigests[repository] = latestDigest
+			log.V(1).Info("Successfully fetched latest digest", "repository", repository, "tag", repoTag, "digest", latestDigest)
+
+			// Resolve the other tracked tags too, whose digests are compliant as well
+			if len(policy.Spec.Tags) > 0 {
+				var tagsErr error
+				repoStatus.Tags, tagsErr = r.fetchTagDigests(ctx, policy, repository, tags, latestDigest, repoStatus.Tags, creds, interval)
+				if tagsErr != nil {
+					log.Error(tagsErr, "Failed to fetch the latest digest of a tracked tag", "repository", repository)
+					r.updateCondition(policy, securityv1.ConditionTypeDegraded, metav1.ConditionTrue,
+						"DockerHubError", tagsErr.Error())
+					policy.Status.ComplianceStatus = securityv1.ComplianceStatusError
+					fetchErr = tagsErr
+				}
+			}
+		}
+		repositoryStatuses = append(repositoryStatuses, repoStatus)
+	}
+
+	// Clear the rate limit signal once DockerHub answers again
+	if fetched && !sawRateLimit {
+		if degraded := meta.FindStatusCondition(policy.Status.Conditions, securityv1.ConditionTypeDegraded); degraded != nil && degraded.Reason == "RateLimited" {
+			meta.RemoveStatusCondition(&policy.Status.Conditions, securityv1.ConditionTypeDegraded)
+		}
+	}
+
+	// Don't spin on policies whose repositories don't exist
+	allNotFound := len(repositoryStatuses) > 0
+	for _, repoStatus := range repositoryStatuses {
+		allNotFound = allNotFound && repoStatus.NotFound
+	}
+	if allNotFound {
+		requeueAfter = max(requeueAfter, interval, notFoundCheckInterval)
+	}
+
+	return latestDigests, repositoryStatuses, requeueAfter, fetchErr
+}
+
+// fetchTagDigests returns the latest digest of each tracked tag of a repository given the primary tag's,
+// along with the last fetch failure. A tag that couldn't be resolved is listed without a digest, unless
+// the controller's own rate limit deferred its fetch and the previous digest is kept.
+func (r *ImagePolicyReconciler) fetchTagDigests(ctx context.Context, policy *securityv1.ImagePolicy, repository string, tags []string, primaryDigest string, previous []securityv1.TagStatus, creds *registryCredentials, interval time.Duration) ([]securityv1.TagStatus, error) {
+	statuses := []securityv1.TagStatus{{Tag: tags[0], LatestDigest: primaryDigest}}
+	var fetchErr error
+	for _, tag := range tags[1:] {
+		digest, err := r.getLatestDigestFromDockerHub(ctx, digestRequest{
+			Repository:  repository,
+			Tag:         tag,
+			Platform:    policy.Spec.Platform,
+			Credentials: creds,
+			Mirror:      r.registryMirror(policy),
+			Proxy:       r.registryProxy(policy),
+			DigestType:  policy.Spec.DigestType,
+		}, interval)
+		var throttled *throttledError
+		switch {
+		case stderrors.As(err, &throttled):
+			if i := slices.IndexFunc(previous, func(s securityv1.TagStatus) bool { return s.Tag == tag }); i >= 0 {
+				digest = previous[i].LatestDigest
+			}
+		case err != nil:
+			fetchErr = fmt.Errorf("failed to fetch digest for %s:%s: %w", repository, tag, err)
+		}
+		statuses = append(statuses, securityv1.TagStatus{Tag: tag, LatestDigest: digest})
+	}
+	return statuses, fetchErr
+}
+
+// trackedDigests returns the latest digests of every tracked tag, keyed by repository
+func trackedDigests(repositoryStatuses []securityv1.RepositoryStatus) map[string][]string {
+	digests := map[string][]string{}
+	for _, status := range repositoryStatuses {
+		for _, tag := range status.Tags {
+			if tag.LatestDigest != "" {
+				digests[status.Repository] = append(digests[status.Repository], tag.LatestDigest)
+			}
+		}
+	}
+	return digests
+}
+
+// findRepositoryStatus returns the last recorded status of a repository, falling back to the
+// top-level LatestDigest and LastChecked for policies last reconciled before per-repository status
+func findRepositoryStatus(policy *securityv1.ImagePolicy, repository string) *securityv1.RepositoryStatus {
+	for i := range policy.Status.Repositories {
+		if policy.Status.Repositories[i].Repository == repository {
+			return &policy.Status.Repositories[i]
+		}
+	}
+	if len(policy.Status.Repositories) == 0 && repository == policy.Spec.Repository && policy.Status.LastChecked != nil {
+		return &securityv1.RepositoryStatus{
+			Repository:   repository,
+			LatestDigest: policy.Status.LatestDigest,
+			LastChecked:  policy.Status.LastChecked,
+		}
+	}
+	return nil
+}
+
+// getLatestDigestFromDockerHub fetches the latest digest for a repository tag from DockerHub.
+// Digests fetched within cacheTTL by any policy are served from the shared digest cache.
+func (r *ImagePolicyReconciler) getLatestDigestFromDockerHub(ctx context.Context, digestReq digestRequest, cacheTTL time.Duration) (string, error) {
+	log := logf.FromContext(ctx)
+	ctx, span := r.startSpan(ctx, "GetLatestDigest",
+		attribute.String("repository", digestReq.Repository), attribute.String("tag", digestReq.Tag))
+	defer span.End()
+
+	cache := r.getDigestCache()
+	cacheKey := digestReq.cacheKey()
+	if digest, ok := cache.get(cacheKey, cacheTTL); ok {
+		log.V(2).Info("Using cached digest", "cacheKey", cacheKey, "cacheHit", true, "digest", digest)
+		span.SetAttributes(attribute.Bool("cacheHit", true), attribute.String("digest", digest))
+		return digest, nil
+	}
+	span.SetAttributes(attribute.Bool("cacheHit", false))
+
+	// Policies reconciled in parallel that miss on the same key share a single fetch
+	digest, err := cache.fetch(cacheKey, func() (string, error) {
+		// Take a token from the shared budget, requeueing instead of blocking the worker when none is left
+		if err := r.reserveDockerHubRequest(); err != nil {
+			return "", err
+		}
+
+		return retryDockerHub(ctx, r.DockerHubMaxRetries, func() (string, error) {
+			release, err := r.acquireRegistryRequest(ctx)
+			if err != nil {
+				return "", err
+			}
+			defer release()
+			return r.fetchDigestFromDockerHub(ctx, digestReq)
+		})
+	})
+	if err != nil {
+		return "", spanError(span, err)
+	}
+
+	log.V(1).Info("Successfully fetched latest digest", "repository", digestReq.Repository, "tag", digestReq.Tag,
+		"digest", digest, "cacheKey", cacheKey, "cacheHit", false)
+	span.SetAttributes(attribute.String("digest", digest))
+	return digest, nil
+}
+
+// reserveDockerHubRequest takes a token from the shared DockerHub budget, returning a throttledError
+// instead of waiting when none is left
+func (r *ImagePolicyReconciler) reserveDockerHubRequest() error {
+	if r.DockerHubRateLimiter == nil {
+		return nil
+	}
+	reservation := r.DockerHubRateLimiter.Reserve()
+	if delay := reservation.Delay(); delay > 0 {
+		reservation.Cancel()
+		return &throttledError{RetryAfter: max(delay, time.Second)}
+	}
+	return nil
+}
+
+// registryURL returns the base URL of the DockerHub registry API, DockerHubRegistryURL when set
+func (r *ImagePolicyReconciler) registryURL() string {
+	if r.DockerHubRegistryURL != "" {
+		return r.DockerHubRegistryURL
+	}
+	return dockerHubRegistryURL
+}
+
+// authURL returns the base URL of the DockerHub token API, DockerHubAuthURL when set
+func (r *ImagePolicyReconciler) authURL() string {
+	if r.DockerHubAuthURL != "" {
+		return r.DockerHubAuthURL
+	}
+	return dockerHubAuthURL
+}
+
+// acquireRegistryRequest waits for a slot among the MaxParallelRegistryRequests in flight, returning the
+// function releasing it. Slots are held for a single attempt, not while backing off between retries.
+func (r *ImagePolicyReconciler) acquireRegistryRequest(ctx context.Context) (func(), error) {
+	r.registryRequestsOnce.Do(func() {
+		if r.MaxParallelRegistryRequests > 0 {
+			r.registryRequests = semaphore.NewWeighted(int64(r.MaxParallelRegistryRequests))
+		}
+	})
+	if r.registryRequests == nil {
+		return func() {}, nil
+	}
+	if err := r.registryRequests.Acquire(ctx, 1); err != nil {
+		return nil, err
+	}
+	return func() { r.registryRequests.Release(1) }, nil
+}
+
+// getDigestCache returns the digest cache shared across reconciles, creating it on first use
+func (r *ImagePolicyReconciler) getDigestCache() *digestCache {
+	r.digestCacheOnce.Do(func() {
+		r.digestCache = newDigestCache()
+	})
+	return r.digestCache
+}
+
+// fetchDigestFromDockerHub performs a single attempt to fetch the digest.
+// When credentials are set the token is requested with basic auth, allowing private repositories.
+// When a platform is set, the platform-specific digest is resolved from multi-arch images.
+func (r *ImagePolicyReconciler) fetchDigestFromDockerHub(ctx context.Context, digestReq digestRequest) (string, error) {
+	if digestReq.Mirror != "" {
+		return fetchDigestFromMirror(ctx, r.mirrorRequest(digestReq))
+	}
+
+	token, err := r.fetchDockerHubToken(ctx, digestReq.Repository, digestReq.Credentials, digestReq.Proxy)
+	if err != nil {
+		return "", err
+	}
+
+	// Get manifest for the tracked tag
+	client := registryHTTPClient(digestReq.Proxy)
+	getManifest := func(reference, accept string) (*http.Response, error) {
+		manifestURL := fmt.Sprintf("%s/v2/%s/manifests/%s", r.registryURL(), dockerHubRepository(digestReq.Repository), reference)
+		req, err := http.NewRequestWithContext(ctx, "GET", manifestURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create manifest request: %w", err)
+		}
+
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept", accept)
+
+		sent := time.Now()
+		resp, err := client.Do(req)
+		if err != nil {
+			observeRegistryRequest(ctx, sent, nil)
+			return nil, networkError(ctx, fmt.Errorf("failed to get manifest: %w", err))
+		}
+		observeRegistryRequest(ctx, sent, resp)
+
+		if resp.StatusCode != http.StatusOK {
+			err := registry.NewStatusError(resp, "registry")
+			_ = resp.Body.Close()
+			return nil, err
+		}
+		return resp, nil
+	}
+
+	resp, err := getManifest(digestReq.Tag, manifestAccept)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if digestReq.DigestType == securityv1.DigestTypeConfig {
+		return configDigest(resp, digestReq.Platform, func(digest string) (*http.Response, error) {
+			return getManifest(digest, platformManifestAccept)
+		})
+	}
+	return manifestDigest(resp, digestReq.Platform)
+}
+
+// manifestDigest returns the digest of a manifest response, descending into multi-arch images when a platform is set
+func manifestDigest(resp *http.Response, platform string) (string, error) {
+	// Descend into multi-arch images when a platform is requested
+	contentType := strings.TrimSpace(strings.Split(resp.Header.Get("Content-Type"), ";")[0])
+	if platform != "" && isManifestList(contentType) {
+		return platformDigest(resp, platform)
+	}
+
+	// Get the digest from the Docker-Content-Digest header, which ends up in image references and the status
+	if digest := resp.Header.Get("Docker-Content-Digest"); digest != "" {
+		if _, _, err := registry.ParseDigest(digest); err != nil {
+			return "", fmt.Errorf("invalid Docker-Content-Digest header: %w", err)
+		}
+		return digest, nil
+	}
+
+	// The header is optional for OCI registries, and the digest of a manifest is that of its body
+	if contentType != "" && !isDigestableManifest(contentType) {
+		return "", fmt.Errorf("no digest found in response headers for unsupported manifest media type %s", contentType)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if len(body) > maxManifestSize {
+		return "", fmt.Errorf("manifest exceeds %d bytes", maxManifestSize)
+	}
+
+	// Some proxies drop the Content-Type along with the digest, but schema 2 manifests declare their own media type
+	if contentType == "" {
+		var manifest DockerHubManifest
+		if err := json.Unmarshal(body, &manifest); err != nil || !isDigestableManifest(manifest.MediaType) {
+			return "", fmt.Errorf("no digest found in response headers")
+		}
+		if platform != "" && isManifestList(manifest.MediaType) {
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+			return platformDigest(resp, platform)
+		}
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(body)), nil
+}
+
+// isManifestList checks if a media type is that of a multi-arch image
+func isManifestList(mediaType string) bool {
+	return mediaType == mediaTypeDockerManifestList || mediaType == mediaTypeOCIImageIndex
+}
+
+// isDigestableManifest checks if a media type is that of a manifest whose digest is the sha256 of its body
+func isDigestableManifest(mediaType string) bool {
+	switch mediaType {
+	case mediaTypeDockerManifest, mediaTypeDockerManifestList, mediaTypeOCIImageManifest, mediaTypeOCIImageIndex:
+		return true
+	default:
+		return false
+	}
+}
+
+// configDigest returns the config digest (image ID) from the body of a manifest response. The config of
+// a multi-arch image is read from the manifest of the platform, fetched with getManifest.
+func configDigest(resp *http.Response, platform string, getManifest func(digest string) (*http.Response, error)) (string, error) {
+	contentType := strings.TrimSpace(strings.Split(resp.Header.Get("Content-Type"), ";")[0])
+	if isManifestList(contentType) {
+		if platform == "" {
+			return "", fmt.Errorf("the config digest of a multi-arch image needs a platform")
+		}
+		digest, err := platformDigest(resp, platform)
+		if err != nil {
+			return "", err
+		}
+		if resp, err = getManifest(digest); err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+	}
+
+	var manifest DockerHubManifest
+	if err := json.NewDecoder(resp.Body).Decode(&manifest); err != nil {
+		return "", fmt.Errorf("failed to decode manifest: %w", err)
+	}
+	if manifest.Config.Digest == "" {
+		return "", fmt.Errorf("no config digest found in %s manifest", manifest.MediaType)
+	}
+	return manifest.Config.Digest, nil
+}
+
+// fetchDockerHubToken requests a pull token for a repository through proxy, using basic auth when credentials are set
+func (r *ImagePolicyReconciler) fetchDockerHubToken(ctx context.Context, repository string, credentials *registryCredentials, proxy string) (string, error) {
+	// Get authentication token from DockerHub
+	tokenURL := fmt.Sprintf("%s/token?service=registry.docker.io&scope=repository:%s:pull", r.authURL(), dockerHubRepository(repository))
+
+	tokenReq, err := http.NewRequestWithContext(ctx, "GET", tokenURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	if credentials != nil {
+		tokenReq.SetBasicAuth(credentials.Username, credentials.Password)
+	}
+
+	client := registryHTTPClient(proxy)
+	sent := time.Now()
+	tokenResp, err := client.Do(tokenReq)
+	if err != nil {
+		observeRegistryRequest(ctx, sent, nil)
+		return "", networkError(ctx, fmt.Errorf("failed to get auth token: %w", err))
+	}
+	defer tokenResp.Body.Close()
+	observeRegistryRequest(ctx, sent, tokenResp)
+
+	if tokenResp.StatusCode != http.StatusOK {
+		return "", registry.NewStatusError(tokenResp, "auth")
+	}
+
+	var tokenData DockerHubToken
+	if err := json.NewDecoder(tokenResp.Body).Decode(&tokenData); err != nil {
+		return "", fmt.Errorf("failed to decode token response: %w", err)
+	}
+
+	return tokenData.Token, nil
+}
+
+// platformDigest selects the digest matching platform (os/arch[/variant]) from a manifest list response
+func platformDigest(resp *http.Response, platform string) (string, error) {
+	var manifestList DockerHubManifestList
+	if err := json.NewDecoder(resp.Body).Decode(&manifestList); err != nil {
+		return "", fmt.Errorf("failed to decode manifest list: %w", err)
+	}
+
+	parts := strings.Split(platform, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return "", fmt.Errorf("invalid platform %q, expected os/arch[/variant]", platform)
+	}
+
+	for _, manifest := range manifestList.Manifests {
+		if manifest.Platform.OS != parts[0] || manifest.Platform.Architecture != parts[1] {
+			continue
+		}
+		if len(parts) == 3 && manifest.Platform.Variant != parts[2] {
+			continue
+		}
+		return manifest.Digest, nil
+	}
+
+	return "", fmt.Errorf("no manifest found for platform %s", platform)
+}
+
+// loadRegistryCredentials reads DockerHub credentials from the policy's pull secret, if one is configured
+func (r *ImagePolicyReconciler) loadRegistryCredentials(ctx context.Context, policy *securityv1.ImagePolicy) (*registryCredentials, error) {
+	ref := policy.Spec.PullSecretRef
+	if ref == nil {
+		return nil, nil
+	}
+
+	namespace, err := referenceNamespace(policy, ref.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("pullSecretRef %s: %w", ref.Name, err)
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get secret %s/%s: %w", namespace, ref.Name, err)
+	}
+
+	if secret.Type != corev1.SecretTypeDockerConfigJson {
+		return nil, fmt.Errorf("secret %s/%s has type %s, expected %s", namespace, ref.Name, secret.Type, corev1.SecretTypeDockerConfigJson)
+	}
+
+	var config dockerConfigJSON
+	if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &config); err != nil {
+		return nil, fmt.Errorf("secret %s/%s contains malformed %s: %w", namespace, ref.Name, corev1.DockerConfigJsonKey, err)
+	}
+
+	// Prefer credentials for the manager's mirror when digests are resolved through it
+	authKeys := dockerHubAuthKeys
+	if mirror := r.registryMirror(policy); r.trustedMirror(mirror) {
+		host := mirrorHost(mirror)
+		authKeys = append([]string{host, "https://" + host}, dockerHubAuthKeys...)
+	}
+
+	for _, key := range authKeys {
+		auth, ok := config.Auths[key]
+		if !ok {
+			continue
+		}
+
+		if auth.Username != "" && auth.Password != "" {
+			return &registryCredentials{Username: auth.Username, Password: auth.Password}, nil
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return nil, fmt.Errorf("secret %s/%s has malformed auth for %s: %w", namespace, ref.Name, key, err)
+		}
+		username, password, found := strings.Cut(string(decoded), ":")
+		if !found {
+			return nil, fmt.Errorf("secret %s/%s has malformed auth for %s: expected username:password", namespace, ref.Name, key)
+		}
+		return &registryCredentials{Username: username, Password: password}, nil
+	}
+
+	return nil, fmt.Errorf("secret %s/%s has no credentials for docker.io", namespace, ref.Name)
+}
+
+// findDeploymentsToMonitor finds workloads (Deployments, StatefulSets, DaemonSets, CronJobs and Jobs) that match the policy selectors,
+// leaving out those with the ignore label.
+// Workloads are listed cluster-wide and filtered by namespace in memory, unless a namespace selector narrows the
+// search to at most perNamespaceListThreshold namespaces, where listing each namespace is cheaper.
+func (r *ImagePolicyReconciler) findDeploymentsToMonitor(ctx context.Context, policy *securityv1.ImagePolicy) ([]workload, error) {
+	// Get namespaces to search
+	namespaces, err := r.getNamespacesToMonitor(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	var listOpts []client.ListOption
+
+	// Add deployment selector if specified
+	if policy.Spec.DeploymentSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(policy.Spec.DeploymentSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deployment selector: %w", err)
+		}
+		listOpts = append(listOpts, client.MatchingLabelsSelector{Selector: selector})
+	}
+
+	var workloads []workload
+	if policy.Spec.NamespaceSelector != nil && len(namespaces) <= perNamespaceListThreshold {
+		workloads, err = r.listWorkloadsPerNamespace(ctx, namespaces, listOpts...)
+	} else {
+		workloads, err = r.listWorkloadsInNamespaces(ctx, namespaces, listOpts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Filter workloads that use images from any monitored repository, each workload once
+	var deployments []workload
+	for _, deployment := range uniqueWorkloads(workloads) {
+		if !deployment.ignored() && r.deploymentUsesMonitoredRepository(policy, deployment) {
+			deployments = append(deployments, deployment)
+		}
+	}
+
+	return deployments, nil
+}
+
+// uniqueWorkloads drops repeated workloads (same kind, namespace and name), keeping the first occurrence
+func uniqueWorkloads(workloads []workload) []workload {
+	seen := make(map[string]bool, len(workloads))
+	unique := workloads[:0:0]
+	for _, deployment := range workloads {
+		key := deployment.Kind + "/" + deployment.GetNamespace() + "/" + deployment.GetName()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, deployment)
+	}
+	return unique
+}
+
+// getNamespacesToMonitor returns the list of namespaces to monitor based on the policy,
+// minus any excluded namespaces
+func (r *ImagePolicyReconciler) getNamespacesToMonitor(ctx context.Context, policy *securityv1.ImagePolicy) ([]string, error) {
+	namespaceList := &corev1.NamespaceList{}
+	if policy.Spec.NamespaceSelector == nil {
+		// Monitor all namespaces
+		if err := r.List(ctx, namespaceList); err != nil {
+			return nil, fmt.Errorf("failed to list namespaces: %w", err)
+		}
+	} else {
+		// Use selector to find namespaces
+		selector, err := metav1.LabelSelectorAsSelector(policy.Spec.NamespaceSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace selector: %w", err)
+		}
+
+		if err := r.List(ctx, namespaceList, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, fmt.Errorf("failed to list namespaces with selector: %w", err)
+		}
+	}
+
+	var namespaces []string
+	for _, ns := range namespaceList.Items {
+		if namespaceExcluded(policy, ns.Name) {
+			continue
+		}
+		namespaces = append(namespaces, ns.Name)
+	}
+	return namespaces, nil
+}
+
+// listWorkloadsPerNamespace lists the workloads of each namespace with a List call per namespace
+func (r *ImagePolicyReconciler) listWorkloadsPerNamespace(ctx context.Context, namespaces []string, opts ...client.ListOption) ([]workload, error) {
+	var workloads []workload
+	for _, namespace := range namespaces {
+		namespaceWorkloads, err := r.listWorkloads(ctx, append(opts, client.InNamespace(namespace))...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list workloads in namespace %s: %w", namespace, err)
+		}
+		workloads = append(workloads, namespaceWorkloads...)
+	}
+	return workloads, nil
+}
+
+// listWorkloadsInNamespaces lists workloads cluster-wide and keeps those in the given namespaces
+func (r *ImagePolicyReconciler) listWorkloadsInNamespaces(ctx context.Context, namespaces []string, opts ...client.ListOption) ([]workload, error) {
+	allWorkloads, err := r.listWorkloads(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workloads: %w", err)
+	}
+
+	monitored := make(map[string]bool, len(namespaces))
+	for _, namespace := range namespaces {
+		monitored[namespace] = true
+	}
+
+	var workloads []workload
+	for _, deployment := range allWorkloads {
+		if monitored[deployment.GetNamespace()] {
+			workloads = append(workloads, deployment)
+		}
+	}
+	return workloads, nil
+}
+
+// namespaceExcluded checks if a namespace is excluded by ExcludeNamespaces or ExcludeSystemNamespaces
+func namespaceExcluded(policy *securityv1.ImagePolicy, namespace string) bool {
+	if slices.Contains(policy.Spec.ExcludeNamespaces, namespace) {
+		return true
+	}
+	return policy.Spec.ExcludeSystemNamespaces && slices.Contains(systemNamespaces, namespace)
+}
+
+// deploymentUsesRepository checks if a workload uses images from the specified repository
+func (r *ImagePolicyReconciler) deploymentUsesRepository(deployment workload, repository string) bool {
+	for _, container := range deployment.containers() {
+		if imageUsesRepository(*container.Image, repository) {
+			return true
+		}
+	}
+	return false
+}
+
+// monitoredRepositories returns the policy's repositories followed by those matching its RepositoryPattern
+// that the governed containers of the workloads run, sorted so the status order doesn't follow the list order
+func monitoredRepositories(policy *securityv1.ImagePolicy, deployments []workload) []string {
+	repositories := policy.Spec.MonitoredRepositories()
+	var matched []string
+	for _, deployment := range deployments {
+		for _, repository := range patternRepositories(deployment.governedContainers(policy.Spec.ContainerName), policy.Spec.RepositoryPattern) {
+			if !slices.Contains(repositories, repository) && !slices.Contains(matched, repository) {
+				matched = append(matched, repository)
+			}
+		}
+	}
+	slices.Sort(matched)
+	return append(repositories, matched...)
+}
+
+// deploymentUsesMonitoredRepository checks if a workload uses images from any of the policy's repositories
+// or from a repository matching its RepositoryPattern
+func (r *ImagePolicyReconciler) deploymentUsesMonitoredRepository(policy *securityv1.ImagePolicy, deployment workload) bool {
+	for _, repository := range policy.Spec.MonitoredRepositories() {
+		if r.deploymentUsesRepository(deployment, repository) {
+			return true
+		}
+	}
+	return len(patternRepositories(deployment.containers(), policy.Spec.RepositoryPattern)) > 0
+}
+
+// governsRepository checks if a container the rules apply to uses images from the specified repository
+func governsRepository(deployment workload, repository string, rules complianceRules) bool {
+	return slices.ContainsFunc(deployment.governedContainers(rules.ContainerName), func(container podContainer) bool {
+		return imageUsesRepository(*container.Image, repository)
+	})
+}
+
+// workloadsWithContainer returns the workloads that have a container named containerName
+func workloadsWithContainer(deployments []workload, containerName string) []workload {
+	var named []workload
+	for _, deployment := range deployments {
+		if slices.ContainsFunc(deployment.containers(), func(container podContainer) bool { return container.Name == containerName }) {
+			named = append(named, deployment)
+		}
+	}
+	return named
+}
+
+// complianceRules are the policy settings a workload's images are checked against
+type complianceRules struct {
+	EnforceLatest     bool
+	AllowedDigests    []string
+	DeniedDigests     []string
+	AttestationPolicy *securityv1.AttestationPolicy
+
+	// ContainerName limits the rules to the container with this name, if set
+	ContainerName string
+
+	// MissingDigests are in-use digests the registry doesn't have, keyed by repository@digest.
+	// It is only filled in by reconciles of policies with VerifyDigestExists
+	MissingDigests map[string]bool
+
+	// TagDigests are the digests tags in use resolve to, keyed by repository:tag. It is only filled
+	// in by reconciles of policies with attestationPolicy.resolveTags
+	TagDigests map[string]string
+
+	// TrackedDigests are the latest digests of each of the policy's Tags, keyed by repository, as last
+	// recorded in its status. A workload running any of them is as compliant as one on the latest digest
+	TrackedDigests map[string][]string
+
+	// MultiArchDigests are in-use digests referencing the latest image through the other level of its
+	// multi-arch index, keyed by repository. They are as compliant as the latest digest; it is only
+	// filled in by reconciles of policies enforcing the latest digest
+	MultiArchDigests map[string][]string
+
+	// AllowedRegistries are the registries governed containers may pull from; any registry when empty
+	AllowedRegistries []string
+
+	// RegistryProxy is the HTTP(S) proxy referrer attestations are fetched through, if any
+	RegistryProxy string
+
+	// PinTags makes tag-based images non-compliant so remediation pins them to a digest, see MutateTagsToDigests
+	PinTags bool
+
+	// NamespaceAnalysisTimeout bounds the analysis of each namespace's workloads, unbounded when 0
+	NamespaceAnalysisTimeout time.Duration
+}
+
+// checkInterval returns a policy's CheckIntervalSeconds, else the manager's DefaultCheckIntervalSeconds
+func (r *ImagePolicyReconciler) checkInterval(policy *securityv1.ImagePolicy) int32 {
+	if policy.Spec.CheckIntervalSeconds != nil {
+		return *policy.Spec.CheckIntervalSeconds
+	}
+	if r.DefaultCheckIntervalSeconds > 0 {
+		return r.DefaultCheckIntervalSeconds
+	}
+	return defaultCheckIntervalSeconds
+}
+
+// trackedTags returns the tags a policy tracks, primary first: Tags if set, else Tag, else "latest"
+func trackedTags(policy *securityv1.ImagePolicy) []string {
+	if len(policy.Spec.Tags) > 0 {
+		return policy.Spec.Tags
+	}
+	if policy.Spec.Tag != "" {
+		return []string{policy.Spec.Tag}
+	}
+	return []string{"latest"}
+}
+
+// jitteredInterval returns the interval moved by a random amount of up to RequeueJitter of it either way
+func (r *ImagePolicyReconciler) jitteredInterval(interval time.Duration) time.Duration {
+	fraction := min(r.RequeueJitter, maxRequeueJitter)
+	if fraction <= 0 {
+		return interval
+	}
+	return interval + time.Duration((2*rand.Float64()-1)*fraction*float64(interval))
+}
+
+// rulesFor returns the compliance rules of a policy, with the manager's defaults for unset fields
+func (r *ImagePolicyReconciler) rulesFor(policy *securityv1.ImagePolicy) complianceRules {
+	enforceLatest := true
+	if r.DefaultEnforceLatest != nil {
+		enforceLatest = *r.DefaultEnforceLatest
+	}
+	if policy.Spec.EnforceLatestDigest != nil {
+		enforceLatest = *policy.Spec.EnforceLatestDigest
+	}
+	namespaceTimeout, _ := namespaceAnalysisTimeout(policy)
+
+	return complianceRules{
+		EnforceLatest:     enforceLatest,
+		AllowedDigests:    policy.Spec.AllowedDigests,
+		DeniedDigests:     policy.Spec.DeniedDigests,
+		AttestationPolicy: withAttestationNamespace(policy.Spec.AttestationPolicy, policy.Namespace),
+		ContainerName:     policy.Spec.ContainerName,
+		TrackedDigests:    trackedDigests(policy.Status.Repositories),
+		AllowedRegistries: policy.Spec.AllowedRegistries,
+		RegistryProxy:     r.registryProxy(policy),
+		PinTags:           policy.Spec.MutateTagsToDigests != nil && *policy.Spec.MutateTagsToDigests,
+		// Invalid timeouts are reported by the reconcile and leave namespaces unbounded
+		NamespaceAnalysisTimeout: namespaceTimeout,
+	}
+}
+
+// reportsOver checks if a candidate status should be reported instead of the current one:
+// the first status is always reported, non-compliant beats compliant and denied digests beat everything
+func reportsOver(candidate, current securityv1.DeploymentStatus, first bool) bool {
+	return first ||
+		(current.IsCompliant && !candidate.IsCompliant) ||
+		(candidate.Reason == securityv1.NonComplianceReasonDeniedDigest && current.Reason != securityv1.NonComplianceReasonDeniedDigest)
+}
+
+// analyzeWorkloadCompliance analyzes a workload against every monitored repository it uses.
+// The returned status reports the most significant non-compliant repository (or the first one used
+// when all are compliant), and the map holds the compliance of each repository the workload uses.
+func (r *ImagePolicyReconciler) analyzeWorkloadCompliance(ctx context.Context, deployment workload, repositories []string, latestDigests map[string]string, rules complianceRules) (securityv1.DeploymentStatus, map[string]bool) {
+	var status securityv1.DeploymentStatus
+	repositoryCompliance := map[string]bool{}
+
+	for _, repository := range repositories {
+		if !governsRepository(deployment, repository, rules) {
+			continue
+		}
+
+		repoStatus := r.analyzeDeploymentCompliance(ctx, deployment, repository, latestDigests[repository], rules)
+		if reportsOver(repoStatus, status, len(repositoryCompliance) == 0) {
+			status = repoStatus
+		}
+		repositoryCompliance[repository] = repoStatus.IsCompliant
+	}
+
+	// A container from a registry outside the allowlist makes the workload non-compliant whatever its digests,
+	// unless its compliance is overridden
+	if container, host, found := disallowedRegistryContainer(deployment.governedContainers(rules.ContainerName), rules.AllowedRegistries); found &&
+		len(repositoryCompliance) > 0 && status.Reason != securityv1.NonComplianceReasonDeniedDigest && status.Reason != securityv1.ComplianceReasonOverridden {
+		status.IsCompliant = false
+		status.ContainerName = container.Name
+		status.ContainerKind = container.Kind
+		status.Reason = securityv1.NonComplianceReasonDisallowedRegistry
+		status.Message = fmt.Sprintf("image %s is pulled from %s, which isn't an allowed registry", *container.Image, host)
+	}
+
+	return status, repositoryCompliance
+}
+
+// workloadAnalysis is the compliance of a workload, as returned by analyzeWorkloadCompliance
+type workloadAnalysis struct {
+	Status               securityv1.DeploymentStatus
+	RepositoryCompliance map[string]bool
+}
+
+// analyzeWorkloads analyzes the compliance of each workload, up to MaxConcurrentAnalyses at a time since
+// attestation checks wait on Rekor or the registry, and returns the results in workload order. Namespaces
+// take turns, and the workloads of a namespace that exceeds the NamespaceAnalysisTimeout get Unknown results:
+// those in flight are cancelled and the rest skipped. Analysis only reads shared state; recording events and
+// remediating are left to the caller.
+func (r *ImagePolicyReconciler) analyzeWorkloads(ctx context.Context, deployments []workload, repositories []string, latestDigests map[string]string, rules complianceRules) []workloadAnalysis {
+	log := logf.FromContext(ctx)
+
+	limit := r.MaxConcurrentAnalyses
+	if limit <= 0 {
+		limit = defaultMaxConcurrentAnalyses
+	}
+
+	analyses := make([]workloadAnalysis, len(deployments))
+	budgets := map[string]*namespaceBudget{}
+	var group errgroup.Group
+	group.SetLimit(limit)
+	for _, i := range interleavedByNamespace(deployments) {
+		deployment := deployments[i]
+		budget, ok := budgets[deployment.GetNamespace()]
+		if !ok {
+			budget = &namespaceBudget{}
+			budgets[deployment.GetNamespace()] = budget
+		}
+
+		group.Go(func() error {
+			namespaceCtx := budget.context(ctx, rules.NamespaceAnalysisTimeout)
+			if !budget.exceeded() {
+				log.V(2).Info("Processing workload", "kind", deployment.Kind, "deployment", deployment.GetName(), "namespace", deployment.GetNamespace(), "enforceLatest", rules.EnforceLatest)
+				analyses[i].Status, analyses[i].RepositoryCompliance = r.analyzeWorkloadCompliance(namespaceCtx, deployment, repositories, latestDigests, rules)
+			}
+			// Checks cut short by the timeout would read as failures, so the result is unknown either way
+			if budget.exceeded() {
+				analyses[i] = timedOutAnalysis(deployment, rules.NamespaceAnalysisTimeout)
+			}
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	for namespace, budget := range budgets {
+		if budget.exceeded() {
+			log.Info("Namespace analysis timed out", "namespace", namespace, "timeout", rules.NamespaceAnalysisTimeout)
+		}
+		budget.release()
+	}
+	return analyses
+}
+
+// analyzeDeploymentCompliance analyzes if a workload is compliant with the policy.
+// Workloads with a compliance override in effect are always compliant; otherwise denied digests never are. Allowed digests are compliant regardless of latestDigest,
+// but attestation requirements still apply.
+func (r *ImagePolicyReconciler) analyzeDeploymentCompliance(ctx context.Context, deployment workload, repository, latestDigest string, rules complianceRules) securityv1.DeploymentStatus {
+	log := logf.FromContext(ctx)
+	ctx, span := r.startSpan(ctx, "AnalyzeDeploymentCompliance", attribute.String("repository", repository),
+		attribute.String("workload.kind", deployment.Kind), attribute.String("workload.namespace", deployment.GetNamespace()),
+		attribute.String("workload.name", deployment.GetName()))
+	defer span.End()
+	now := metav1.Now()
+	status := securityv1.DeploymentStatus{
+		Kind:        deployment.Kind,
+		Repository:  repository,
+		Name:        deployment.GetName(),
+		Namespace:   deployment.GetNamespace(),
+		IsCompliant: true,
+		LastUpdated: &now,
+	}
+	attestationPolicy := rules.AttestationPolicy
+
+	// Check every governed container using our repository; the most significant non-compliance is reported
+	first := true
+	var reportedImage string
+	for _, container := range deployment.governedContainers(rules.ContainerName) {
+		if !imageUsesRepository(*container.Image, repository) {
+			continue
+		}
+
+		containerStatus := securityv1.DeploymentStatus{
+			ContainerName: container.Name,
+			ContainerKind: container.Kind,
+		}
+
+		// Extract digest from image reference
+		if currentDigest := imageDigest(*container.Image); currentDigest != "" {
+			containerStatus.CurrentDigest = currentDigest
+
+			switch {
+			case slices.Contains(rules.DeniedDigests, currentDigest):
+				log.V(1).Info("Denied digest in use",
+					"deployment", deployment.GetName(),
+					"namespace", deployment.GetNamespace(),
+					"container", container.Name,
+					"currentDigest", currentDigest)
+				containerStatus.Reason = securityv1.NonComplianceReasonDeniedDigest
+				containerStatus.Message = fmt.Sprintf("digest %s is denied by the policy", currentDigest)
+			case rules.MissingDigests[repository+"@"+currentDigest]:
+				log.V(1).Info("Digest not found in the registry",
+					"deployment", deployment.GetName(),
+					"namespace", deployment.GetNamespace(),
+					"container", container.Name,
+					"currentDigest", currentDigest)
+				containerStatus.Reason = securityv1.NonComplianceReasonDigestNotFound
+				containerStatus.Message = fmt.Sprintf("digest %s doesn't exist in %s", currentDigest, repository)
+			case slices.Contains(rules.AllowedDigests, currentDigest):
+				log.V(1).Info("Digest is allowlisted - compliant",
+					"deployment", deployment.GetName(),
+					"namespace", deployment.GetNamespace(),
+					"container", container.Name,
+					"currentDigest", currentDigest)
+			case !rules.EnforceLatest:
+			case latestDigest == "":
+				// Can't determine compliance without latest digest - mark as unknown/error
+				log.V(1).Info("Cannot determine compliance - latest digest unavailable",
+					"deployment", deployment.GetName(),
+					"namespace", deployment.GetNamespace(),
+					"container", container.Name,
+					"currentDigest", currentDigest)
+				// Conservative: assume non-compliant when we can't verify
+				containerStatus.Reason = securityv1.NonComplianceReasonLatestDigestUnknown
+				containerStatus.Message = fmt.Sprintf("latest digest of %s is unavailable", repository)
+			case currentDigest != latestDigest && !slices.Contains(rules.TrackedDigests[repository], currentDigest) &&
+				!slices.Contains(rules.MultiArchDigests[repository], currentDigest):
+				log.V(1).Info("Digest mismatch detected",
+					"deployment", deployment.GetName(),
+					"namespace", deployment.GetNamespace(),
+					"container", container.Name,
+					"containerKind", container.Kind,
+					"currentDigest", currentDigest,
+					"latestDigest", latestDigest)
+				containerStatus.Reason = securityv1.NonComplianceReasonOutdatedDigest
+				containerStatus.Message = fmt.Sprintf("digest %s is outdated, latest is %s", currentDigest, latestDigest)
+			default:
+				log.V(2).Info("Digest match - compliant",
+					"deployment", deployment.GetName(),
+					"namespace", deployment.GetNamespace(),
+					"container", container.Name,
+					"currentDigest", currentDigest,
+					"latestDigest", latestDigest)
+			}
+		} else {
+			// Image uses tag, not digest - this is non-compliant if enforcing digests
+			containerStatus.CurrentDigest = "tag-based"
+			if rules.EnforceLatest || rules.PinTags {
+				containerStatus.Reason = securityv1.NonComplianceReasonTagBased
+				containerStatus.Message = fmt.Sprintf("image %s uses a tag instead of a digest", *container.Image)
+				containerStatus.PinnedDigest = pinnedDigest(*container.Image, repository, latestDigest, rules)
+			}
+		}
+		containerStatus.IsCompliant = containerStatus.Reason == ""
+
+		if reportsOver(containerStatus, status, first) {
+			reportedImage = *container.Image
+			status.CurrentDigest = containerStatus.CurrentDigest
+			status.ContainerName = containerStatus.ContainerName
+			status.ContainerKind = containerStatus.ContainerKind
+			status.IsCompliant = containerStatus.IsCompliant
+			status.Reason = containerStatus.Reason
+			status.Message = containerStatus.Message
+			status.PinnedDigest = containerStatus.PinnedDigest
+		}
+		first = false
+		if status.Reason == securityv1.NonComplianceReasonDeniedDigest {
+			break
+		}
+	}
+
+	// Verify attestations if policy requires it and any container was checked (all may be skipped)
+	if !first && attestationPolicy.RequiresVerification() {
+		var attestationResult *rekor.AttestationResult
+		var checks attestationChecks
+
+		// Verify tag-based images against the digest their tag resolves to, if requested
+		digest, resolvedDigest := status.CurrentDigest, ""
+		if digest == "tag-based" && attestationPolicy.ResolveTags {
+			resolvedDigest = rules.TagDigests[repository+":"+imageTag(reportedImage)]
+			if resolvedDigest != "" {
+				digest = resolvedDigest
+			}
+		}
+
+		// Check if we have a valid digest for attestation verification
+		switch {
+		case digest == "tag-based" && attestationPolicy.ResolveTags:
+			attestationResult = &rekor.AttestationResult{
+				Verified: false,
+				Error:    fmt.Sprintf("Cannot verify attestations for %s - failed to resolve its tag to a digest", reportedImage),
+			}
+		case digest == "tag-based" || digest == "":
+			// Tag-based images cannot be verified for attestations
+			attestationResult = &rekor.AttestationResult{
+				Verified: false,
+				Error:    "Cannot verify attestations for tag-based images - digest required",
+			}
+		default:
+			// Verify attestation for digest-based images
+			attestationResult, checks = r.verifyAttestationPolicy(ctx, repository, digest, attestationPolicy, rules.RegistryProxy)
+		}
+
+		// Update status with attestation information
+		hasValidAttestation := attestationResult.Verified
+		status.HasValidAttestation = &hasValidAttestation
+
+		status.AttestationDetails = newAttestationDetails(attestationResult, checks)
+		status.AttestationDetails.LastChecked = &now
+		status.AttestationDetails.ResolvedDigest = resolvedDigest
+
+		// Mark as non-compliant if attestation verification fails, unless attestations are only reported or
+		// couldn't be checked and the policy fails open
+		if !attestationResult.Verified {
+			failOpen := attestationResult.Unavailable && attestationPolicy.FailsOpen()
+			log.V(1).Info("Attestation verification failed",
+				"deployment", deployment.GetName(),
+				"namespace", deployment.GetNamespace(),
+				"digest", digest,
+				"error", attestationResult.Error,
+				"enforced", attestationPolicy.Enforced(),
+				"failOpen", failOpen)
+			if attestationPolicy.Enforced() && !failOpen {
+				if status.IsCompliant {
+					status.Reason = securityv1.NonComplianceReasonAttestationFailed
+					status.Message = fmt.Sprintf("attestation verification failed: %s", attestationResult.Error)
+				}
+				status.IsCompliant = false
+			}
+		}
+	}
+
+	if status.IsCompliant {
+		status.Reason = securityv1.ComplianceReasonCompliant
+	}
+
+	// An emergency override wins over every check until it lapses
+	if message, overridden, err := complianceOverride(deployment, now.Time); err != nil {
+		log.Error(err, "Ignoring invalid compliance override", "deployment", deployment.GetName(), "namespace", deployment.GetNamespace())
+	} else if overridden {
+		log.V(1).Info("Compliance overridden", "deployment", deployment.GetName(), "namespace", deployment.GetNamespace(),
+			"override", message, "reason", status.Reason)
+		status.IsCompliant = true
+		status.Reason = securityv1.ComplianceReasonOverridden
+		status.Message = message
+	}
+	span.SetAttributes(attribute.Bool("compliant", status.IsCompliant), attribute.String("result", status.Reason))
+	return status
+}
+
+// verifyAttestation verifies that an image digest has valid attestations in Rekor, or in the
+// registry's OCI referrers, fetched through proxy, when the policy's AttestationSource is Referrers
+func (r *ImagePolicyReconciler) verifyAttestation(ctx context.Context, repository, imageDigest string, policy *securityv1.AttestationPolicy, proxy string) *rekor.AttestationResult {
+	source := policy.AttestationSource
+	if source == "" {
+		source = securityv1.AttestationSourceRekor
+	}
+	ctx, span := r.startSpan(ctx, "VerifyAttestation", attribute.String("repository", repository),
+		attribute.String("digest", imageDigest), attribute.String("source", source))
+	defer span.End()
+
+	result := r.checkAttestation(ctx, repository, imageDigest, policy, proxy)
+	switch {
+	case result.Verified:
+		span.SetAttributes(attribute.String("result", "Verified"))
+	case result.Unavailable:
+		// Only an unreachable source is a failure of the span; a missing attestation is a result
+		span.SetAttributes(attribute.String("result", "Unavailable"))
+		span.SetStatus(codes.Error, result.Error)
+	default:
+		span.SetAttributes(attribute.String("result", "Failed"), attribute.String("error", result.Error))
+	}
+	return result
+}
+
+// checkAttestation does the verification of verifyAttestation
+func (r *ImagePolicyReconciler) checkAttestation(ctx context.Context, repository, imageDigest string, policy *securityv1.AttestationPolicy, proxy string) *rekor.AttestationResult {
+	log := logf.FromContext(ctx)
+
+	// Skip verification if no digest available
+	if imageDigest == "" {
+		return &rekor.AttestationResult{
+			Verified: false,
+			Error:    "no image digest available for verification",
+		}
 	}
 
-	// Set default values if not specified
-	checkInterval := int32(60) // 1 minute default (demo-friendly)
-	if imagePolicy.Spec.CheckIntervalSeconds != nil {
-		checkInterval = *imagePolicy.Spec.CheckIntervalSeconds
-	}
+	// Prepare policy parameters
+	var allowedIssuers []string
+	var requiredTypes []string
 
-	enforceLatest := true
-	if imagePolicy.Spec.EnforceLatestDigest != nil {
-		enforceLatest = *imagePolicy.Spec.EnforceLatestDigest
+	if policy.AllowedIssuers != nil {
+		allowedIssuers = policy.AllowedIssuers
 	}
 
-	// Check if we need to fetch the latest digest
-	now := metav1.Now()
-	shouldCheck := imagePolicy.Status.LastChecked == nil ||
-		now.Time.Sub(imagePolicy.Status.LastChecked.Time) > time.Duration(checkInterval)*time.Second
-
-	var latestDigest string
-	var err error
+	if policy.RequiredTypes != nil {
+		requiredTypes = policy.RequiredTypes
+	}
 
-	if shouldCheck {
-		log.Info("Fetching latest digest from DockerHub", "repository", imagePolicy.Spec.Repository)
-		latestDigest, err = r.getLatestDigestFromDockerHub(ctx, imagePolicy.Spec.Repository)
-		if err != nil {
-			log.Error(err, "Failed to fetch latest digest from DockerHub")
-			r.updateCondition(imagePolicy, securityv1.ConditionTypeDegraded, metav1.ConditionTrue,
-				"DockerHubError", fmt.Sprintf("Failed to fetch digest: %v", err))
-			imagePolicy.Status.ComplianceStatus = securityv1.ComplianceStatusError
-		} else {
-			imagePolicy.Status.LatestDigest = latestDigest
-			imagePolicy.Status.LastChecked = &now
-			log.Info("Successfully fetched latest digest", "digest", latestDigest)
+	// Reject attestations older than MaxAge, if configured
+	var notBefore time.Time
+	maxAge, err := attestationMaxAge(policy)
+	if err != nil {
+		return &rekor.AttestationResult{
+			Verified: false,
+			Error:    err.Error(),
 		}
-	} else {
-		latestDigest = imagePolicy.Status.LatestDigest
+	}
+	if maxAge > 0 {
+		notBefore = time.Now().Add(-maxAge)
 	}
 
-	// Find deployments to monitor
-	deployments, err := r.findDeploymentsToMonitor(ctx, imagePolicy)
+	// Require signing certificates to chain to the configured Fulcio root, or the public-good one
+	fulcioRoot, err := r.attestationTrustRoot(ctx, policy)
 	if err != nil {
-		log.Error(err, "Failed to find deployments to monitor")
-		return ctrl.Result{}, err
+		log.Error(err, "Failed to load Fulcio root", "digest", imageDigest)
+		return &rekor.AttestationResult{
+			Verified: false,
+			Error:    err.Error(),
+		}
 	}
 
-	// Analyze compliance
-	deploymentStatuses := []securityv1.DeploymentStatus{}
-	compliantCount := int32(0)
+	// Only accept attestations whose subject is the monitored repository, not just any image with the digest
+	expectedSubject := &rekor.ExpectedSubject{Repository: repository, Digest: imageDigest}
 
-	for _, deployment := range deployments {
-		log.Info("Processing deployment", "deployment", deployment.Name, "namespace", deployment.Namespace, "enforceLatest", enforceLatest)
-		status := r.analyzeDeploymentCompliance(ctx, deployment, imagePolicy.Spec.Repository, latestDigest, enforceLatest, imagePolicy.Spec.AttestationPolicy)
-		deploymentStatuses = append(deploymentStatuses, status)
-		log.Info("Deployment compliance status", "deployment", deployment.Name, "isCompliant", status.IsCompliant)
-		if status.IsCompliant {
-			compliantCount++
-		} else if enforceLatest {
-			// Create event for non-compliant deployment
-			r.Recorder.Event(imagePolicy, corev1.EventTypeWarning, "NonCompliantImage",
-				fmt.Sprintf("Deployment %s/%s is using outdated image digest", deployment.Namespace, deployment.Name))
-
-			// Debug logging for auto-remediation conditions
-			hasAutomation := r.hasAutomationEnabled(deployment)
-			hasLatestDigest := latestDigest != ""
-			log.Info("Checking auto-remediation conditions",
-				"deployment", deployment.Name,
-				"namespace", deployment.Namespace,
-				"hasAutomation", hasAutomation,
-				"hasLatestDigest", hasLatestDigest,
-				"latestDigest", latestDigest)
-
-			// Check if deployment has automation enabled
-			if hasAutomation && hasLatestDigest {
-				log.Info("Auto-remediation enabled for deployment", "deployment", deployment.Name, "namespace", deployment.Namespace)
-				if err := r.remediateDeployment(ctx, deployment, imagePolicy.Spec.Repository, latestDigest); err != nil {
-					log.Error(err, "Failed to auto-remediate deployment", "deployment", deployment.Name, "namespace", deployment.Namespace)
-					r.Recorder.Event(imagePolicy, corev1.EventTypeWarning, "AutoRemediationFailed",
-						fmt.Sprintf("Failed to auto-remediate deployment %s/%s: %v", deployment.Namespace, deployment.Name, err))
-				} else {
-					log.Info("Successfully auto-remediated deployment", "deployment", deployment.Name, "namespace", deployment.Namespace)
-					r.Recorder.Event(imagePolicy, corev1.EventTypeNormal, "AutoRemediated",
-						fmt.Sprintf("Auto-remediated deployment %s/%s to use latest digest", deployment.Namespace, deployment.Name))
-					// Note: Don't update status here - let the next reconciliation cycle detect the actual change
-				}
-			} else {
-				log.Info("Auto-remediation skipped",
-					"deployment", deployment.Name,
-					"namespace", deployment.Namespace,
-					"reason", fmt.Sprintf("hasAutomation=%v, hasLatestDigest=%v", hasAutomation, hasLatestDigest))
+	// Only accept attestations signed by the approved workflows, if configured
+	var expectedIdentity *rekor.ExpectedIdentity
+	if len(policy.AllowedSANs) > 0 || len(policy.AllowedBuildConfigURIs) > 0 {
+		expectedIdentity = &rekor.ExpectedIdentity{SANs: policy.AllowedSANs, BuildConfigURIs: policy.AllowedBuildConfigURIs}
+	}
+
+	// Verify attestation via the registry's referrers
+	if policy.AttestationSource == securityv1.AttestationSourceReferrers {
+		result, err := r.verifyReferrerAttestation(ctx, repository, imageDigest, allowedIssuers, requiredTypes, notBefore, policy.MinSLSALevel, fulcioRoot, expectedSubject, expectedIdentity, proxy)
+		if err != nil {
+			log.Error(err, "Failed to verify attestation via OCI referrers", "repository", repository, "digest", imageDigest)
+			return &rekor.AttestationResult{
+				Verified:    false,
+				Error:       fmt.Sprintf("referrers verification failed: %v", err),
+				Unavailable: !stderrors.Is(err, errNoReferrers),
 			}
 		}
+		return result
 	}
 
-	// Update status
-	imagePolicy.Status.MonitoredDeployments = deploymentStatuses
-	imagePolicy.Status.TotalDeployments = int32(len(deployments))
-	imagePolicy.Status.CompliantDeployments = compliantCount
-
-	// Determine overall compliance status
-	if len(deployments) == 0 {
-		imagePolicy.Status.ComplianceStatus = securityv1.ComplianceStatusUnknown
-		r.updateCondition(imagePolicy, securityv1.ConditionTypeReady, metav1.ConditionTrue,
-			"NoDeployments", "No deployments found matching the policy")
-	} else if compliantCount == int32(len(deployments)) {
-		imagePolicy.Status.ComplianceStatus = securityv1.ComplianceStatusCompliant
-		r.updateCondition(imagePolicy, securityv1.ConditionTypeReady, metav1.ConditionTrue,
-			"AllCompliant", "All monitored deployments are compliant")
-	} else {
-		imagePolicy.Status.ComplianceStatus = securityv1.ComplianceStatusNonCompliant
-		r.updateCondition(imagePolicy, securityv1.ConditionTypeReady, metav1.ConditionTrue,
-			"NonCompliant", fmt.Sprintf("%d of %d deployments are non-compliant",
-				int32(len(deployments))-compliantCount, len(deployments)))
+	// Skip verification if Rekor client not available
+	rekorClient, err := r.rekorClientFor(ctx, policy)
+	if err != nil {
+		log.Error(err, "Rekor client not available, skipping attestation verification", "rekorURL", policy.RekorURL)
+		// A missing or invalid public key is a misconfiguration, not an outage to fail open on
+		return &rekor.AttestationResult{
+			Verified:    false,
+			Error:       err.Error(),
+			Unavailable: stderrors.Is(err, errRekorNotInitialized),
+		}
 	}
 
-	// Update the status
-	if err := r.Status().Update(ctx, imagePolicy); err != nil {
-		log.Error(err, "Failed to update ImagePolicy status")
-		return ctrl.Result{}, err
+	// Verify attestation via Rekor
+	result, err := rekorClient.VerifyAttestation(ctx, imageDigest, allowedIssuers, requiredTypes, notBefore, policy.MinSLSALevel, fulcioRoot, expectedSubject, expectedIdentity)
+	if err != nil {
+		log.Error(err, "Failed to verify attestation via Rekor", "digest", imageDigest)
+		var unavailable *rekor.UnavailableError
+		return &rekor.AttestationResult{
+			Verified:    false,
+			Error:       fmt.Sprintf("Rekor verification failed: %v", err),
+			Unavailable: stderrors.As(err, &unavailable),
+		}
 	}
 
-	// Requeue after the check interval
-	return ctrl.Result{RequeueAfter: time.Duration(checkInterval) * time.Second}, nil
+	return result
 }
 
-// getLatestDigestFromDockerHub fetches the latest digest for a repository from DockerHub
-func (r *ImagePolicyReconciler) getLatestDigestFromDockerHub(ctx context.Context, repository string) (string, error) {
-	log := logf.FromContext(ctx)
-
-	maxRetries := 3
-	baseDelay := 5 * time.Second // Longer delay for rate limiting
+// sbomAttestationTypes are the attestation types accepted as an SBOM by RequireSBOM
+var sbomAttestationTypes = []string{"spdxjson", "cyclonedx"}
+
+// verifySBOM looks for an SPDX or CycloneDX attestation of a digest, from the same source and issuers
+// (and within the same MaxAge) as the policy's other attestations. The error tells a missing SBOM
+// apart from an attestation source that couldn't be queried.
+func (r *ImagePolicyReconciler) verifySBOM(ctx context.Context, repository, imageDigest string, policy *securityv1.AttestationPolicy, proxy string) *rekor.AttestationResult {
+	sbomPolicy := *policy
+	sbomPolicy.RequiredTypes = sbomAttestationTypes
+	sbomPolicy.MinSLSALevel = 0
+
+	result := r.verifyAttestation(ctx, repository, imageDigest, &sbomPolicy, proxy)
+	switch {
+	case result.Verified:
+	case result.Unavailable:
+		result.Error = fmt.Sprintf("SBOM could not be checked, attestation source unreachable: %s", result.Error)
+	default:
+		result.Error = fmt.Sprintf("no SBOM attestation found: %s", result.Error)
+	}
+	return result
+}
 
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		if attempt > 0 {
-			delay := time.Duration(attempt) * baseDelay
-			log.Info("Retrying DockerHub API request", "attempt", attempt+1, "delay", delay)
-			time.Sleep(delay)
-		}
+// vulnAttestationTypes are the attestation types whose findings MaxVulnerabilitySeverity is checked against
+var vulnAttestationTypes = []string{"vuln"}
 
-		digest, err := r.fetchDigestFromDockerHub(ctx, repository)
-		if err != nil {
-			// If it's a rate limit error, retry
-			if strings.Contains(err.Error(), "status 429") {
-				log.Info("Rate limited by DockerHub, will retry", "attempt", attempt+1)
-				continue
-			}
-			// For other errors, return immediately
-			return "", err
-		}
+// vulnerabilitySeverities are the severities of MaxVulnerabilitySeverity from the lowest, in the lowercase
+// form scanners' findings are counted in
+var vulnerabilitySeverities = []string{"low", "medium", "high", "critical"}
 
-		log.Info("Successfully fetched latest digest", "repository", repository, "digest", digest)
-		return digest, nil
+// vulnerabilitiesAtLeast returns the number of findings of the given severity or higher, none for an unknown severity
+func vulnerabilitiesAtLeast(counts map[string]int32, severity string) int32 {
+	lowest := slices.Index(vulnerabilitySeverities, strings.ToLower(severity))
+	if lowest < 0 {
+		return 0
 	}
 
-	return "", fmt.Errorf("failed to fetch digest after %d attempts due to rate limiting", maxRetries)
-}
-
-// fetchDigestFromDockerHub performs a single attempt to fetch the digest
-func (r *ImagePolicyReconciler) fetchDigestFromDockerHub(ctx context.Context, repository string) (string, error) {
-	// Get authentication token from DockerHub
-	tokenURL := fmt.Sprintf("https://auth.docker.io/token?service=registry.docker.io&scope=repository:%s:pull", repository)
-
-	tokenResp, err := http.Get(tokenURL)
-	if err != nil {
-		return "", fmt.Errorf("failed to get auth token: %w", err)
+	var total int32
+	for _, s := range vulnerabilitySeverities[lowest:] {
+		total += counts[s]
 	}
-	defer tokenResp.Body.Close()
+	return total
+}
 
-	if tokenResp.StatusCode == 429 {
-		return "", fmt.Errorf("DockerHub auth API returned status 429")
+// verifyVulnerabilities looks for a vulnerability scan attestation of a digest, from the same source and issuers
+// (and within the same MaxAge) as the policy's other attestations, and fails it when it reports findings at or
+// above MaxVulnerabilitySeverity. Their number is only returned when a scan was found.
+func (r *ImagePolicyReconciler) verifyVulnerabilities(ctx context.Context, repository, imageDigest string, policy *securityv1.AttestationPolicy, proxy string) (*rekor.AttestationResult, *int32) {
+	scanPolicy := *policy
+	scanPolicy.RequiredTypes = vulnAttestationTypes
+	scanPolicy.MinSLSALevel = 0
+
+	result := r.verifyAttestation(ctx, repository, imageDigest, &scanPolicy, proxy)
+	switch {
+	case result.Verified:
+	case result.Unavailable:
+		result.Error = fmt.Sprintf("vulnerability scan could not be checked, attestation source unreachable: %s", result.Error)
+		return result, nil
+	default:
+		result.Error = fmt.Sprintf("no vulnerability scan attestation found: %s", result.Error)
+		return result, nil
 	}
 
-	var tokenData DockerHubToken
-	if err := json.NewDecoder(tokenResp.Body).Decode(&tokenData); err != nil {
-		return "", fmt.Errorf("failed to decode token response: %w", err)
+	count := vulnerabilitiesAtLeast(result.Vulnerabilities, policy.MaxVulnerabilitySeverity)
+	if count > 0 {
+		result.Verified = false
+		result.Error = fmt.Sprintf("vulnerability scan by %s reports %d finding(s) of severity %s or higher", result.Scanner, count, policy.MaxVulnerabilitySeverity)
 	}
+	return result, &count
+}
 
-	// Get manifest for latest tag
-	manifestURL := fmt.Sprintf("https://registry-1.docker.io/v2/%s/manifests/latest", repository)
+// attestationChecks are the outcomes of the SBOM and vulnerability checks a policy requires besides its
+// attestations, nil for checks it doesn't require
+type attestationChecks struct {
+	HasSBOM            *bool
+	VulnerabilityCount *int32
+}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", manifestURL, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to create manifest request: %w", err)
+// verifyAttestationPolicy runs the attestation, SBOM and vulnerability checks an attestation policy requires
+// for a digest, combined into one result
+func (r *ImagePolicyReconciler) verifyAttestationPolicy(ctx context.Context, repository, imageDigest string, policy *securityv1.AttestationPolicy, proxy string) (*rekor.AttestationResult, attestationChecks) {
+	var result *rekor.AttestationResult
+	var checks attestationChecks
+	if policy.RequireAttestation != nil && *policy.RequireAttestation {
+		result = r.verifyAttestation(ctx, repository, imageDigest, policy, proxy)
 	}
+	if policy.RequireSBOM {
+		sbomResult := r.verifySBOM(ctx, repository, imageDigest, policy, proxy)
+		checks.HasSBOM = &sbomResult.Verified
+		result = withCheckResult(result, sbomResult)
+	}
+	if policy.MaxVulnerabilitySeverity != "" {
+		var scanResult *rekor.AttestationResult
+		scanResult, checks.VulnerabilityCount = r.verifyVulnerabilities(ctx, repository, imageDigest, policy, proxy)
+		result = withCheckResult(result, scanResult)
+	}
+	return result, checks
+}
 
-	req.Header.Set("Authorization", "Bearer "+tokenData.Token)
-	req.Header.Set("Accept", "application/vnd.docker.distribution.manifest.v2+json")
-
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to get manifest: %w", err)
+// newAttestationDetails reports an attestation result in workload status form
+func newAttestationDetails(result *rekor.AttestationResult, checks attestationChecks) *securityv1.AttestationDetails {
+	details := &securityv1.AttestationDetails{
+		Verified:           result.Verified,
+		AttestationType:    result.AttestationType,
+		PredicateType:      result.PredicateType,
+		BuilderID:          result.BuilderID,
+		Scanner:            result.Scanner,
+		Issuer:             result.Issuer,
+		SAN:                result.SAN,
+		BuildConfigURI:     result.BuildConfigURI,
+		Error:              result.Error,
+		RekorUnavailable:   result.Unavailable,
+		SubjectMismatch:    result.SubjectMismatch,
+		ProofVerified:      result.ProofVerified,
+		HasSBOM:            checks.HasSBOM,
+		VulnerabilityCount: checks.VulnerabilityCount,
 	}
-	defer resp.Body.Close()
+	if result.LogIndex > 0 {
+		details.RekorLogIndex = &result.LogIndex
+		details.SLSALevel = &result.SLSALevel
+	}
+	return details
+}
 
-	if resp.StatusCode == 429 {
-		return "", fmt.Errorf("DockerHub registry API returned status 429")
+// withCheckResult combines the result of the policy's earlier checks, nil if there were none, with an SBOM
+// or vulnerability check: both must be verified, and details come from the earlier checks if there are any
+func withCheckResult(result, checkResult *rekor.AttestationResult) *rekor.AttestationResult {
+	if result == nil {
+		return checkResult
 	}
+	if result.Verified && !checkResult.Verified {
+		result.Verified = false
+		result.Error = checkResult.Error
+		result.Unavailable = checkResult.Unavailable
+	}
+	return result
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("DockerHub API returned status %d", resp.StatusCode)
+// rekorClientFor returns the Rekor client for an attestation policy: the controller's client unless the
+// policy sets a different RekorURL or its own RekorPublicKeyRef, in which case a client is created once per
+// URL and key
+func (r *ImagePolicyReconciler) rekorClientFor(ctx context.Context, policy *securityv1.AttestationPolicy) (*rekor.Client, error) {
+	defaultClient, err := r.defaultRekorClient()
+	if policy.RekorURL == "" || (policy.RekorPublicKeyRef == nil && defaultClient != nil && defaultClient.URL() == policy.RekorURL) {
+		return defaultClient, err
 	}
 
-	// Get the digest from the Docker-Content-Digest header
-	digest := resp.Header.Get("Docker-Content-Digest")
-	if digest == "" {
-		return "", fmt.Errorf("no digest found in response headers")
+	publicKey, err := r.rekorPublicKey(ctx, policy.RekorPublicKeyRef)
+	if err != nil {
+		return nil, err
 	}
+	key := policy.RekorURL + "\n" + string(publicKey)
 
-	return digest, nil
-}
+	r.rekorClientsMu.Lock()
+	defer r.rekorClientsMu.Unlock()
 
-// findDeploymentsToMonitor finds deployments that match the policy selectors
-func (r *ImagePolicyReconciler) findDeploymentsToMonitor(ctx context.Context, policy *securityv1.ImagePolicy) ([]appsv1.Deployment, error) {
-	var deployments []appsv1.Deployment
+	if rekorClient, ok := r.rekorClients[key]; ok {
+		return rekorClient, nil
+	}
 
-	// Get namespaces to search
-	namespaces, err := r.getNamespacesToMonitor(ctx, policy)
+	rekorClient, err := rekor.NewClient(policy.RekorURL, publicKey)
 	if err != nil {
 		return nil, err
 	}
+	if r.rekorClients == nil {
+		r.rekorClients = map[string]*rekor.Client{}
+	}
+	r.rekorClients[key] = rekorClient
+	return rekorClient, nil
+}
 
-	// Search deployments in each namespace
-	for _, namespace := range namespaces {
-		deploymentList := &appsv1.DeploymentList{}
-		listOpts := []client.ListOption{
-			client.InNamespace(namespace),
-		}
+// updateAttestationCondition summarizes the attestation checks of the monitored workloads in the
+// AttestationVerified condition and AttestationStatus. The condition is removed when the policy doesn't
+// require attestations.
+func (r *ImagePolicyReconciler) updateAttestationCondition(policy *securityv1.ImagePolicy, attestationPolicy *securityv1.AttestationPolicy, statuses []securityv1.DeploymentStatus) {
+	if !attestationPolicy.RequiresVerification() {
+		meta.RemoveStatusCondition(&policy.Status.Conditions, securityv1.ConditionTypeAttestationVerified)
+		policy.Status.AttestationStatus = "N/A"
+		policy.Status.CheckedAttestations, policy.Status.VerifiedAttestations = 0, 0
+		return
+	}
 
-		// Add deployment selector if specified
-		if policy.Spec.DeploymentSelector != nil {
-			selector, err := metav1.LabelSelectorAsSelector(policy.Spec.DeploymentSelector)
-			if err != nil {
-				return nil, fmt.Errorf("invalid deployment selector: %w", err)
-			}
-			listOpts = append(listOpts, client.MatchingLabelsSelector{Selector: selector})
+	var checked, verified, unavailable int
+	for _, status := range statuses {
+		if status.HasValidAttestation == nil {
+			continue
 		}
-
-		if err := r.List(ctx, deploymentList, listOpts...); err != nil {
-			return nil, fmt.Errorf("failed to list deployments in namespace %s: %w", namespace, err)
+		checked++
+		if *status.HasValidAttestation {
+			verified++
+		} else if status.AttestationDetails != nil && status.AttestationDetails.RekorUnavailable {
+			unavailable++
 		}
-
-		// Filter deployments that use images from the monitored repository
-		for _, deployment := range deploymentList.Items {
-			if r.deploymentUsesRepository(deployment, policy.Spec.Repository) {
-				deployments = append(deployments, deployment)
+	}
+	policy.Status.AttestationStatus = fmt.Sprintf("%d/%d verified", verified, checked)
+	policy.Status.CheckedAttestations, policy.Status.VerifiedAttestations = int32(checked), int32(verified)
+
+	switch {
+	case unavailable > 0:
+		message := fmt.Sprintf("Rekor could not be queried for %d of %d deployments", unavailable, checked)
+		if attestationPolicy.AttestationSource != securityv1.AttestationSourceReferrers {
+			// Say so when the client itself is missing, rather than Rekor failing requests
+			if _, err := r.defaultRekorClient(); attestationPolicy.RekorURL == "" && err != nil {
+				message = fmt.Sprintf("Attestations of %d deployments can't be verified until the Rekor client is up: %v", unavailable, err)
 			}
 		}
+		if attestationPolicy.FailsOpen() {
+			message += "; failing open, their compliance is based on digests only"
+		}
+		r.updateCondition(policy, securityv1.ConditionTypeAttestationVerified, metav1.ConditionFalse, "RekorUnavailable", message)
+	case verified < checked:
+		r.updateCondition(policy, securityv1.ConditionTypeAttestationVerified, metav1.ConditionFalse,
+			"SomeUnverified", fmt.Sprintf("%d of %d deployments lack a valid attestation", checked-verified, checked))
+	default:
+		r.updateCondition(policy, securityv1.ConditionTypeAttestationVerified, metav1.ConditionTrue,
+			"AllVerified", fmt.Sprintf("All %d checked deployments have valid attestations", checked))
 	}
-
-	return deployments, nil
 }
 
-// getNamespacesToMonitor returns the list of namespaces to monitor based on the policy
-func (r *ImagePolicyReconciler) getNamespacesToMonitor(ctx context.Context, policy *securityv1.ImagePolicy) ([]string, error) {
-	if policy.Spec.NamespaceSelector == nil {
-		// Monitor all namespaces
-		namespaceList := &corev1.NamespaceList{}
-		if err := r.List(ctx, namespaceList); err != nil {
-			return nil, fmt.Errorf("failed to list namespaces: %w", err)
-		}
-
-		var namespaces []string
-		for _, ns := range namespaceList.Items {
-			namespaces = append(namespaces, ns.Name)
-		}
-		return namespaces, nil
+// attestationMaxAge parses the policy MaxAge; zero means no age limit
+func attestationMaxAge(policy *securityv1.AttestationPolicy) (time.Duration, error) {
+	if policy == nil || policy.MaxAge == nil || *policy.MaxAge == "" {
+		return 0, nil
 	}
 
-	// Use selector to find namespaces
-	selector, err := metav1.LabelSelectorAsSelector(policy.Spec.NamespaceSelector)
+	maxAge, err := time.ParseDuration(*policy.MaxAge)
 	if err != nil {
-		return nil, fmt.Errorf("invalid namespace selector: %w", err)
+		return 0, fmt.Errorf("invalid attestation MaxAge %q: %w", *policy.MaxAge, err)
+	}
+	if maxAge < 0 {
+		return 0, fmt.Errorf("invalid attestation MaxAge %q: must not be negative", *policy.MaxAge)
 	}
+	return maxAge, nil
+}
 
-	namespaceList := &corev1.NamespaceList{}
-	if err := r.List(ctx, namespaceList, client.MatchingLabelsSelector{Selector: selector}); err != nil {
-		return nil, fmt.Errorf("failed to list namespaces with selector: %w", err)
+// defaultAutomationGate is used when a policy doesn't set AutomationGate
+var defaultAutomationGate = securityv1.AutomationGate{Key: "automation", Value: "true", Source: securityv1.AutomationGateSourceLabel}
+
+// automationGate returns the policy's automation gate with defaults applied
+func automationGate(policy *securityv1.ImagePolicy) securityv1.AutomationGate {
+	if policy.Spec.AutomationGate == nil {
+		return defaultAutomationGate
 	}
 
-	var namespaces []string
-	for _, ns := range namespaceList.Items {
-		namespaces = append(namespaces, ns.Name)
+	gate := *policy.Spec.AutomationGate
+	if gate.Value == "" {
+		gate.Value = defaultAutomationGate.Value
 	}
-	return namespaces, nil
+	if gate.Source == "" {
+		gate.Source = securityv1.AutomationGateSourceLabel
+	}
+	return gate
 }
 
-// deploymentUsesRepository checks if a deployment uses images from the specified repository
-func (r *ImagePolicyReconciler) deploymentUsesRepository(deployment appsv1.Deployment, repository string) bool {
-	for _, container := range deployment.Spec.Template.Spec.Containers {
-		if strings.HasPrefix(container.Image, repository) || strings.HasPrefix(container.Image, "docker.io/"+repository) {
-			return true
+// validateAutomationGate checks the gate key is a valid label or annotation key, and the value a valid label value
+func validateAutomationGate(gate securityv1.AutomationGate) error {
+	if errs := validation.IsQualifiedName(gate.Key); len(errs) > 0 {
+		return fmt.Errorf("invalid automation gate key %q: %s", gate.Key, strings.Join(errs, "; "))
+	}
+	if gate.Source == securityv1.AutomationGateSourceLabel {
+		if errs := validation.IsValidLabelValue(gate.Value); len(errs) > 0 {
+			return fmt.Errorf("invalid automation gate value %q: %s", gate.Value, strings.Join(errs, "; "))
 		}
 	}
-	return false
+	return nil
+}
+
+// hasAutomationEnabled checks if a workload passes the policy's automation gate (by default the automation:true label).
+// An invalid gate never passes so a typo can't opt every workload in.
+func (r *ImagePolicyReconciler) hasAutomationEnabled(policy *securityv1.ImagePolicy, deployment workload) bool {
+	gate := automationGate(policy)
+	if validateAutomationGate(gate) != nil {
+		return false
+	}
+
+	values := deployment.GetLabels()
+	if gate.Source == securityv1.AutomationGateSourceAnnotation {
+		values = deployment.GetAnnotations()
+	}
+	value, exists := values[gate.Key]
+	return exists && value == gate.Value
 }
 
-// analyzeDeploymentCompliance analyzes if a deployment is compliant with the policy
-func (r *ImagePolicyReconciler) analyzeDeploymentCompliance(ctx context.Context, deployment appsv1.Deployment, repository, latestDigest string, enforceLatest bool, attestationPolicy *securityv1.AttestationPolicy) securityv1.DeploymentStatus {
+// handleRemediation remediates a non-compliant workload according to the policy's remediation mode.
+// Auto updates the workload, Audit only reports the digest it would apply, and Off does nothing.
+// Both Auto and Audit only act on workloads passing the AutomationGate (the automation:true label by default).
+// In-cluster remediations are staggered by the budget, which may be nil to allow them all. The rules are
+// those the reconcile analyzed the workload with, including its resolved tags and multi-arch digests.
+func (r *ImagePolicyReconciler) handleRemediation(ctx context.Context, policy *securityv1.ImagePolicy, deployment workload, status *securityv1.DeploymentStatus, rules complianceRules, latestDigests map[string]string, mode string, budget *remediationBudget) {
 	log := logf.FromContext(ctx)
-	now := metav1.Now()
-	status := securityv1.DeploymentStatus{
-		Name:        deployment.Name,
-		Namespace:   deployment.Namespace,
-		IsCompliant: true,
-		LastUpdated: &now,
+	latestDigest := remediationDigest(rules, status, latestDigests)
+
+	if mode == securityv1.RemediationModeOff {
+		log.V(1).Info("Auto-remediation disabled by policy", "kind", deployment.Kind, "deployment", deployment.GetName(), "namespace", deployment.GetNamespace())
+		return
 	}
 
-	// Find the container using our repository
-	for _, container := range deployment.Spec.Template.Spec.Containers {
-		if strings.HasPrefix(container.Image, repository) || strings.HasPrefix(container.Image, "docker.io/"+repository) {
-			// Extract digest from image reference
-			if strings.Contains(container.Image, "@sha256:") {
-				parts := strings.Split(container.Image, "@")
-				if len(parts) == 2 {
-					status.CurrentDigest = parts[1]
-				}
+	// Debug logging for auto-remediation conditions
+	hasAutomation := r.hasAutomationEnabled(policy, deployment)
+	hasLatestDigest := latestDigest != ""
+	log.V(2).Info("Checking auto-remediation conditions",
+		"kind", deployment.Kind,
+		"deployment", deployment.GetName(),
+		"namespace", deployment.GetNamespace(),
+		"mode", mode,
+		"hasAutomation", hasAutomation,
+		"hasLatestDigest", hasLatestDigest,
+		"latestDigest", latestDigest)
+
+	// Check if deployment has automation enabled
+	if !hasAutomation || !hasLatestDigest {
+		log.V(1).Info("Auto-remediation skipped",
+			"kind", deployment.Kind,
+			"deployment", deployment.GetName(),
+			"namespace", deployment.GetNamespace(),
+			"reason", fmt.Sprintf("hasAutomation=%v, hasLatestDigest=%v", hasAutomation, hasLatestDigest))
+		return
+	}
 
-				if enforceLatest {
-					if latestDigest == "" {
-						// Can't determine compliance without latest digest - mark as unknown/error
-						log.Info("Cannot determine compliance - latest digest unavailable",
-							"deployment", deployment.Name,
-							"namespace", deployment.Namespace,
-							"currentDigest", status.CurrentDigest)
-						status.IsCompliant = false // Conservative: assume non-compliant when we can't verify
-					} else if status.CurrentDigest != latestDigest {
-						log.Info("Digest mismatch detected",
-							"deployment", deployment.Name,
-							"namespace", deployment.Namespace,
-							"currentDigest", status.CurrentDigest,
-							"latestDigest", latestDigest)
-						status.IsCompliant = false
-					} else {
-						log.Info("Digest match - compliant",
-							"deployment", deployment.Name,
-							"namespace", deployment.Namespace,
-							"currentDigest", status.CurrentDigest,
-							"latestDigest", latestDigest)
-						status.IsCompliant = true
-					}
-				}
-			} else {
-				// Image uses tag, not digest - this is non-compliant if enforcing digests
-				status.CurrentDigest = "tag-based"
-				if enforceLatest {
-					status.IsCompliant = false
-				}
-			}
-			break
+	// Allowlisted digests are pinned on purpose, e.g. non-compliant only because of attestations
+	if status.Reason != securityv1.NonComplianceReasonDeniedDigest && slices.Contains(policy.Spec.AllowedDigests, status.CurrentDigest) {
+		log.V(1).Info("Auto-remediation skipped for allowlisted digest", "kind", deployment.Kind, "deployment", deployment.GetName(), "namespace", deployment.GetNamespace(), "digest", status.CurrentDigest)
+		return
+	}
+
+	// Don't move a workload onto digests that wouldn't pass the attestation policy themselves
+	if repository, digest, result := r.unverifiedRemediationTarget(ctx, deployment, rules, latestDigests); result != nil {
+		log.Info("Auto-remediation blocked, target digest failed attestation verification", "kind", deployment.Kind, "deployment", deployment.GetName(), "namespace", deployment.GetNamespace(),
+			"repository", repository, "digest", digest, "error", result.Error)
+		r.Recorder.Event(policy, corev1.EventTypeWarning, "RemediationBlockedUnverifiedTarget",
+			fmt.Sprintf("Not remediating %s %s/%s: target %s digest %s failed attestation verification: %s", deployment.Kind, deployment.GetNamespace(), deployment.GetName(), repository, digest, result.Error))
+		return
+	}
+
+	if mode == securityv1.RemediationModeAudit {
+		log.Info("Audit mode - reporting remediation without applying it", "kind", deployment.Kind, "deployment", deployment.GetName(), "namespace", deployment.GetNamespace())
+		status.ProposedDigest = latestDigest
+		r.Recorder.Event(policy, corev1.EventTypeNormal, "WouldRemediate",
+			fmt.Sprintf("Would remediate %s %s/%s to use digest %s (audit mode)", deployment.Kind, deployment.GetNamespace(), deployment.GetName(), latestDigest))
+		return
+	}
+
+	// A Job's pod template can't be changed once created, so it is only reported; its CronJob can be remediated
+	if deployment.Kind == securityv1.WorkloadKindJob {
+		if job, ok := deployment.Object.(*batchv1.Job); ok && skipJob(job) {
+			return
 		}
+		log.Info("Job can't be updated - reporting remediation without applying it", "kind", deployment.Kind, "deployment", deployment.GetName(), "namespace", deployment.GetNamespace())
+		status.ProposedDigest = latestDigest
+		r.Recorder.Event(policy, corev1.EventTypeNormal, "WouldRemediate",
+			fmt.Sprintf("Would remediate %s %s/%s to use digest %s (Jobs are immutable)", deployment.Kind, deployment.GetNamespace(), deployment.GetName(), latestDigest))
+		return
 	}
 
-	// Verify attestations if policy requires it
-	if attestationPolicy != nil && attestationPolicy.RequireAttestation != nil && *attestationPolicy.RequireAttestation {
-		var attestationResult *rekor.AttestationResult
+	if policy.Spec.RemediationStrategy == securityv1.RemediationStrategyGitOps {
+		r.handleGitOpsRemediation(ctx, policy, deployment, status, rules, latestDigests)
+		return
+	}
 
-		// Check if we have a valid digest for attestation verification
-		if status.CurrentDigest == "tag-based" || status.CurrentDigest == "" {
-			// Tag-based images cannot be verified for attestations
-			attestationResult = &rekor.AttestationResult{
-				Verified: false,
-				Error:    "Cannot verify attestations for tag-based images - digest required",
-			}
-		} else {
-			// Verify attestation for digest-based images
-			attestationResult = r.verifyAttestation(ctx, status.CurrentDigest, attestationPolicy)
+	// Only restart pods within the maintenance window
+	now := time.Now()
+	if deferral, err := remediationDeferral(policy, now); err != nil || deferral > 0 {
+		message := fmt.Sprintf("Not remediating %s %s/%s until the remediationWindow opens at %s",
+			deployment.Kind, deployment.GetNamespace(), deployment.GetName(), now.Add(deferral).UTC().Format(time.RFC3339))
+		if err != nil {
+			message = fmt.Sprintf("Not remediating %s %s/%s: %v", deployment.Kind, deployment.GetNamespace(), deployment.GetName(), err)
 		}
+		log.Info("Auto-remediation deferred outside the remediation window", "kind", deployment.Kind, "deployment", deployment.GetName(), "namespace", deployment.GetNamespace(),
+			"opensIn", deferral, "error", err)
+		r.Recorder.Event(policy, corev1.EventTypeNormal, "RemediationDeferred", message)
+		return
+	}
 
-		// Update status with attestation information
-		hasValidAttestation := attestationResult.Verified
-		status.HasValidAttestation = &hasValidAttestation
+	// Hold off while another controller may be reverting the last remediation
+	if remaining := cooldownRemaining(policy, status, now); remaining > 0 {
+		log.Info("Auto-remediation throttled by cooldown", "kind", deployment.Kind, "deployment", deployment.GetName(), "namespace", deployment.GetNamespace(),
+			"lastRemediated", status.LastRemediated.Time, "remaining", remaining)
+		r.Recorder.Event(policy, corev1.EventTypeWarning, "RemediationThrottled",
+			fmt.Sprintf("Not remediating %s %s/%s again for %s: it was remediated at %s and is non-compliant again, within the remediationCooldown",
+				deployment.Kind, deployment.GetNamespace(), deployment.GetName(), remaining.Round(time.Second), status.LastRemediated.UTC().Format(time.RFC3339)))
+		return
+	}
 
-		if attestationResult != nil {
-			status.AttestationDetails = &securityv1.AttestationDetails{
-				Verified:        attestationResult.Verified,
-				AttestationType: attestationResult.AttestationType,
-				Issuer:          attestationResult.Issuer,
-				LastChecked:     &now,
-				Error:           attestationResult.Error,
-			}
+	// Stagger rollouts so remediating many workloads at once doesn't take too many pods down
+	if budget.exhausted() {
+		budget.markDeferred()
+		log.Info("Auto-remediation deferred, too many rollouts in progress", "kind", deployment.Kind, "deployment", deployment.GetName(), "namespace", deployment.GetNamespace(),
+			"rollingOut", budget.rollingOut, "maxConcurrentRemediations", budget.limit)
+		r.Recorder.Event(policy, corev1.EventTypeNormal, "RemediationDeferred",
+			fmt.Sprintf("Not remediating %s %s/%s until a rollout completes: %d workloads are rolling out, the maxConcurrentRemediations of %d",
+				deployment.Kind, deployment.GetNamespace(), deployment.GetName(), budget.rollingOut, budget.limit))
+		return
+	}
 
-			if attestationResult.LogIndex > 0 {
-				status.AttestationDetails.RekorLogIndex = &attestationResult.LogIndex
-			}
+	// Rollouts don't go through the eviction API, so check the PodDisruptionBudgets ourselves
+	honorPDBs := policy.Spec.HonorPodDisruptionBudgets != nil && *policy.Spec.HonorPodDisruptionBudgets
+	disruptionBudgets, err := r.matchingDisruptionBudgets(ctx, deployment)
+	if err != nil {
+		log.Error(err, "Failed to check PodDisruptionBudgets", "kind", deployment.Kind, "deployment", deployment.GetName(), "namespace", deployment.GetNamespace())
+		if honorPDBs {
+			r.Recorder.Event(policy, corev1.EventTypeWarning, "RemediationBlockedByPDB",
+				fmt.Sprintf("Not remediating %s %s/%s: %v", deployment.Kind, deployment.GetNamespace(), deployment.GetName(), err))
+			return
 		}
-
-		// Mark as non-compliant if attestation verification fails
-		if !attestationResult.Verified {
-			log.Info("Attestation verification failed",
-				"deployment", deployment.Name,
-				"namespace", deployment.Namespace,
-				"digest", status.CurrentDigest,
-				"error", attestationResult.Error)
-			status.IsCompliant = false
+	}
+	if pdb := budget.blocking(disruptionBudgets); pdb != nil {
+		if honorPDBs {
+			budget.markDeferred()
+			log.Info("Auto-remediation blocked by PodDisruptionBudget", "kind", deployment.Kind, "deployment", deployment.GetName(), "namespace", deployment.GetNamespace(), "podDisruptionBudget", pdb.Name)
+			r.Recorder.Event(policy, corev1.EventTypeWarning, "RemediationBlockedByPDB",
+				fmt.Sprintf("Not remediating %s %s/%s: PodDisruptionBudget %s allows no more disruptions", deployment.Kind, deployment.GetNamespace(), deployment.GetName(), pdb.Name))
+			return
 		}
+		r.Recorder.Event(policy, corev1.EventTypeWarning, "PodDisruptionBudgetConflict",
+			fmt.Sprintf("Remediating %s %s/%s although PodDisruptionBudget %s allows no more disruptions", deployment.Kind, deployment.GetNamespace(), deployment.GetName(), pdb.Name))
 	}
 
-	return status
+	log.V(1).Info("Auto-remediation enabled for workload", "kind", deployment.Kind, "deployment", deployment.GetName(), "namespace", deployment.GetNamespace())
+	if err := r.remediateDeployment(ctx, policy, deployment, rules, latestDigests); err != nil {
+		log.Error(err, "Failed to auto-remediate workload", "kind", deployment.Kind, "deployment", deployment.GetName(), "namespace", deployment.GetNamespace())
+		r.Recorder.Event(policy, corev1.EventTypeWarning, "AutoRemediationFailed",
+			fmt.Sprintf("Failed to auto-remediate %s %s/%s: %v", deployment.Kind, deployment.GetNamespace(), deployment.GetName(), err))
+		return
+	}
+	budget.take(disruptionBudgets)
+
+	log.Info("Successfully auto-remediated workload", "kind", deployment.Kind, "deployment", deployment.GetName(), "namespace", deployment.GetNamespace())
+	record := securityv1.RemediationRecord{
+		Kind:      deployment.Kind,
+		Name:      deployment.GetName(),
+		Namespace: deployment.GetNamespace(),
+		OldDigest: status.CurrentDigest,
+		NewDigest: latestDigest,
+		Timestamp: metav1.Now(),
+	}
+	recordRemediation(policy, record)
+	status.LastRemediated = &record.Timestamp
+	r.notifyRemediation(ctx, policy, deployment, record, status.Repository)
+	r.Recorder.Event(policy, corev1.EventTypeNormal, "AutoRemediated",
+		fmt.Sprintf("Auto-remediated %s %s/%s to use latest digests", deployment.Kind, deployment.GetNamespace(), deployment.GetName()))
+	r.recordWorkloadRemediation(policy, deployment, record)
+	// The next reconciles report the workload RemediationInProgress until its controller has rolled it out
+	status.RemediationRollingOut = true
 }
 
-// verifyAttestation verifies that an image digest has valid attestations in Rekor
-func (r *ImagePolicyReconciler) verifyAttestation(ctx context.Context, imageDigest string, policy *securityv1.AttestationPolicy) *rekor.AttestationResult {
-	log := logf.FromContext(ctx)
+// unverifiedRemediationTarget verifies the attestations of each digest remediation would pin the workload's
+// containers to, returning the first that fails; a check that couldn't be completed doesn't fail when the
+// policy fails open. Nothing is verified unless the policy enforces attestations.
+func (r *ImagePolicyReconciler) unverifiedRemediationTarget(ctx context.Context, deployment workload, rules complianceRules, latestDigests map[string]string) (string, string, *rekor.AttestationResult) {
+	if !rules.AttestationPolicy.RequiresVerification() || !rules.AttestationPolicy.Enforced() {
+		return "", "", nil
+	}
 
-	// Skip verification if no digest available
-	if imageDigest == "" {
-		return &rekor.AttestationResult{
-			Verified: false,
-			Error:    "no image digest available for verification",
+	verified := map[string]bool{}
+	for _, container := range deployment.governedContainers(rules.ContainerName) {
+		newImage, ok := remediatedImage(*container.Image, latestDigests, rules)
+		if !ok || container.Kind == securityv1.ContainerKindEphemeralContainer {
+			continue
+		}
+		repository, _ := repositoryForImage(*container.Image, latestDigests)
+		digest := imageDigest(newImage)
+		if verified[digest] {
+			continue
 		}
-	}
 
-	// Skip verification if Rekor client not available
-	if r.RekorClient == nil {
-		log.Info("Rekor client not available, skipping attestation verification")
-		return &rekor.AttestationResult{
-			Verified: false,
-			Error:    "Rekor client not initialized",
+		if result, _ := r.verifyAttestationPolicy(ctx, repository, digest, rules.AttestationPolicy, rules.RegistryProxy); !result.Verified &&
+			!(result.Unavailable && rules.AttestationPolicy.FailsOpen()) {
+			return repository, digest, result
 		}
+		verified[digest] = true
 	}
+	return "", "", nil
+}
 
-	// Prepare policy parameters
-	var allowedIssuers []string
-	var requiredTypes []string
-
-	if policy.AllowedIssuers != nil {
-		allowedIssuers = policy.AllowedIssuers
+// recordRemediation prepends a remediation to the policy's history, keeping at most RemediationHistoryLimit records
+func recordRemediation(policy *securityv1.ImagePolicy, record securityv1.RemediationRecord) {
+	limit := 20
+	if policy.Spec.RemediationHistoryLimit != nil {
+		limit = int(*policy.Spec.RemediationHistoryLimit)
 	}
 
-	if policy.RequiredTypes != nil {
-		requiredTypes = policy.RequiredTypes
+	history := append([]securityv1.RemediationRecord{record}, policy.Status.RemediationHistory...)
+	if len(history) > limit {
+		history = history[:limit]
 	}
+	policy.Status.RemediationHistory = history
+}
 
-	// Verify attestation via Rekor
-	result, err := r.RekorClient.VerifyAttestation(ctx, imageDigest, allowedIssuers, requiredTypes)
-	if err != nil {
-		log.Error(err, "Failed to verify attestation via Rekor", "digest", imageDigest)
-		return &rekor.AttestationResult{
-			Verified: false,
-			Error:    fmt.Sprintf("Rekor verification failed: %v", err),
+// remediatedImage returns the digest-based image reference a container image should be remediated to.
+// It returns false for images outside the monitored repositories, repositories without a known latest
+// digest, allowlisted digests, and, when the policy doesn't enforce the latest digest, anything not denied
+// except tags it pins to the digest they resolve to.
+// Both in-cluster and GitOps remediation use it so they make the same substitutions.
+func remediatedImage(image string, latestDigests map[string]string, rules complianceRules) (string, bool) {
+	currentDigest := imageDigest(image)
+	if currentDigest == "" && pinsResolvedTags(rules) {
+		repository, latestDigest := repositoryForImage(image, latestDigests)
+		digest := pinnedDigest(image, repository, latestDigest, rules)
+		if repository == "" || digest == "" {
+			return "", false
 		}
+		return imageName(image) + "@" + digest, true
 	}
 
-	return result
-}
+	denied := slices.Contains(rules.DeniedDigests, currentDigest)
+	if !denied && (slices.Contains(rules.AllowedDigests, currentDigest) || !rules.EnforceLatest) {
+		return "", false
+	}
 
-// hasAutomationEnabled checks if a deployment has the automation:true label
-func (r *ImagePolicyReconciler) hasAutomationEnabled(deployment appsv1.Deployment) bool {
-	if deployment.Labels == nil {
-		return false
+	repository, latestDigest := repositoryForImage(image, latestDigests)
+	if latestDigest == "" || (!denied && (slices.Contains(rules.TrackedDigests[repository], currentDigest) ||
+		slices.Contains(rules.MultiArchDigests[repository], currentDigest))) {
+		return "", false
 	}
-	automation, exists := deployment.Labels["automation"]
-	return exists && automation == "true"
+
+	// Keep the image name as written, e.g. with a docker.io prefix or without library/
+	return imageName(image) + "@" + latestDigest, true
 }
 
-// remediateDeployment updates a deployment to use the latest compliant image digest
-func (r *ImagePolicyReconciler) remediateDeployment(ctx context.Context, deployment appsv1.Deployment, repository, latestDigest string) error {
-	// Create a copy of the deployment for updating
-	updatedDeployment := deployment.DeepCopy()
+// remediateDeployment updates a workload to use the latest compliant image digest of each repository it uses.
+// The original images are recorded in annotations so they can be reverted when the policy is deleted.
+func (r *ImagePolicyReconciler) remediateDeployment(ctx context.Context, policy *securityv1.ImagePolicy, deployment workload, rules complianceRules, latestDigests map[string]string) error {
+	// Create a copy of the workload for updating
+	updatedDeployment := deployment.deepCopy()
+
+	originalImages, err := originalImagesOf(deployment)
+	if err != nil {
+		remediationsCounter.WithLabelValues("failure").Inc()
+		return err
+	}
+	originalPullPolicies, err := originalPullPoliciesOf(deployment)
+	if err != nil {
+		remediationsCounter.WithLabelValues("failure").Inc()
+		return err
+	}
+	pinPullPolicy := policy.Spec.PinPullPolicy != nil && *policy.Spec.PinPullPolicy
 
-	// Find and update containers using the monitored repository
+	// Find and update governed containers using the monitored repository; skipped containers are pinned on purpose
 	updated := false
-	for i, container := range updatedDeployment.Spec.Template.Spec.Containers {
-		if strings.HasPrefix(container.Image, repository) || strings.HasPrefix(container.Image, "docker.io/"+repository) {
-			// Extract repository name without registry prefix
-			repoName := repository
-			if strings.HasPrefix(container.Image, "docker.io/") {
-				repoName = "docker.io/" + repository
-			}
+	expectedImages := map[string]string{}
+	for _, container := range updatedDeployment.governedContainers(rules.ContainerName) {
+		// Ephemeral containers can't be set through the pod template
+		if container.Kind == securityv1.ContainerKindEphemeralContainer {
+			continue
+		}
+
+		newImage, ok := remediatedImage(*container.Image, latestDigests, rules)
+		if !ok {
+			continue
+		}
 
-			// Update to use digest-based image reference
-			newImage := repoName + "@" + latestDigest
-			updatedDeployment.Spec.Template.Spec.Containers[i].Image = newImage
-			updated = true
+		// Keep the first image we replaced so revert restores what the user deployed
+		if _, exists := originalImages[container.Name]; !exists {
+			originalImages[container.Name] = *container.Image
 		}
+
+		// A digest can't change, so there is nothing for Always to pull again; containers already on
+		// the digest keep whatever pull policy they were deployed with
+		if pinPullPolicy && newImage != *container.Image && imageDigest(newImage) != "" && *container.PullPolicy != corev1.PullIfNotPresent {
+			if _, exists := originalPullPolicies[container.Name]; !exists {
+				originalPullPolicies[container.Name] = string(*container.PullPolicy)
+			}
+			*container.PullPolicy = corev1.PullIfNotPresent
+		}
+
+		// Update to use digest-based image reference
+		*container.Image = newImage
+		expectedImages[container.Name] = newImage
+		updated = true
 	}
 
 	if !updated {
-		return fmt.Errorf("no containers found using repository %s", repository)
+		remediationsCounter.WithLabelValues("failure").Inc()
+		if rules.ContainerName != "" {
+			return fmt.Errorf("container %q not found using a monitored repository with a known latest digest", rules.ContainerName)
+		}
+		return fmt.Errorf("no containers found using a monitored repository with a known latest digest")
+	}
+
+	if err := setOriginalImages(updatedDeployment, originalImages, policy); err != nil {
+		remediationsCounter.WithLabelValues("failure").Inc()
+		return err
+	}
+	if err := setOriginalPullPolicies(updatedDeployment, originalPullPolicies); err != nil {
+		remediationsCounter.WithLabelValues("failure").Inc()
+		return err
 	}
 
-	// Update the deployment
-	if err := r.Update(ctx, updatedDeployment); err != nil {
-		return fmt.Errorf("failed to update deployment: %w", err)
+	// Update the workload
+	if err := r.Update(ctx, updatedDeployment.Object); err != nil {
+		remediationsCounter.WithLabelValues("failure").Inc()
+		return fmt.Errorf("failed to update %s: %w", strings.ToLower(deployment.Kind), err)
+	}
+	if err := r.confirmRemediation(ctx, updatedDeployment, expectedImages); err != nil {
+		remediationsCounter.WithLabelValues("failure").Inc()
+		return err
 	}
 
+	remediationsCounter.WithLabelValues("success").Inc()
 	return nil
 }
 
+// statusChanged checks if a reconcile changed the status. The LastUpdated and attestation LastChecked
+// times every reconcile stamps on monitored workloads don't count, so they only move along with a real change.
+func statusChanged(previous, current *securityv1.ImagePolicyStatus) bool {
+	previous, current = previous.DeepCopy(), current.DeepCopy()
+	for _, status := range []*securityv1.ImagePolicyStatus{previous, current} {
+		for i := range status.MonitoredDeployments {
+			status.MonitoredDeployments[i].LastUpdated = nil
+			if details := status.MonitoredDeployments[i].AttestationDetails; details != nil {
+				details.LastChecked = nil
+			}
+		}
+	}
+	return !equality.Semantic.DeepEqual(previous, current)
+}
+
 // updateCondition updates or adds a condition to the ImagePolicy status
 func (r *ImagePolicyReconciler) updateCondition(policy *securityv1.ImagePolicy, conditionType string, status metav1.ConditionStatus, reason, message string) {
 	now := metav1.Now()
 	condition := metav1.Condition{
 		Type:               conditionType,
 		Status:             status,
+		ObservedGeneration: policy.Generation,
 		LastTransitionTime: now,
 		Reason:             reason,
 		Message:            message,
@@ -602,9 +2606,25 @@ func (r *ImagePolicyReconciler) updateCondition(policy *securityv1.ImagePolicy,
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ImagePolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Workloads aren't owned by policies, so map them back to the policies selecting them.
+	// Status-only updates (e.g. during rollouts) are filtered out to avoid reconcile storms.
+	workloadChanged := builder.WithPredicates(workloadChangedPredicate())
+	enqueuePolicies := handler.EnqueueRequestsFromMapFunc(r.policiesForWorkload)
+
+	r.rekorClientMu.RLock()
+	r.recordRekorAvailability()
+	r.rekorClientMu.RUnlock()
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&securityv1.ImagePolicy{}).
-		Owns(&appsv1.Deployment{}).
+		For(&securityv1.ImagePolicy{}, builder.WithPredicates(policyChangedPredicate())).
+		Watches(&appsv1.Deployment{}, enqueuePolicies, workloadChanged).
+		Watches(&appsv1.StatefulSet{}, enqueuePolicies, workloadChanged).
+		Watches(&appsv1.DaemonSet{}, enqueuePolicies, workloadChanged).
+		Watches(&batchv1.CronJob{}, enqueuePolicies, workloadChanged).
+		Watches(&batchv1.Job{}, enqueuePolicies, workloadChanged).
+		// New and relabeled namespaces can bring workloads into scope before the next check interval
+		Watches(&corev1.Namespace{}, r.enqueuePoliciesForNamespace(), builder.WithPredicates(predicate.LabelChangedPredicate{})).
 		Named("imagepolicy").
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Complete(r)
 }
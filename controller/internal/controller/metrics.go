@@ -0,0 +1,89 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// compliantDeploymentsGauge tracks compliant workloads per ImagePolicy
+	compliantDeploymentsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "imagepolicy_compliant_deployments",
+			Help: "Number of monitored workloads that comply with the ImagePolicy",
+		},
+		[]string{"namespace", "name"},
+	)
+
+	// totalDeploymentsGauge tracks monitored workloads per ImagePolicy
+	totalDeploymentsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "imagepolicy_total_deployments",
+			Help: "Number of workloads monitored by the ImagePolicy",
+		},
+		[]string{"namespace", "name"},
+	)
+
+	// remediationsCounter counts auto-remediation attempts by result (success/failure)
+	remediationsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "imagepolicy_remediations_total",
+			Help: "Total number of workload auto-remediations by result",
+		},
+		[]string{"result"},
+	)
+
+	// dockerHubRequestsCounter counts DockerHub API requests by HTTP status code
+	dockerHubRequestsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "imagepolicy_dockerhub_requests_total",
+			Help: "Total number of DockerHub auth and registry requests by status code",
+		},
+		[]string{"code"},
+	)
+
+	// statusUpdatesCounter counts ImagePolicy status writes by result (updated/skipped)
+	statusUpdatesCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "imagepolicy_status_updates_total",
+			Help: "Total number of ImagePolicy status updates, skipped when a reconcile changed nothing",
+		},
+		[]string{"result"},
+	)
+
+	// rekorClientAvailableGauge reports whether the controller's Rekor client is initialized
+	rekorClientAvailableGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "imagepolicy_rekor_client_available",
+			Help: "1 when the controller's Rekor client is initialized, 0 while its creation is retried",
+		},
+	)
+)
+
+func init() {
+	// Register with the controller-runtime registry so metrics are served on the manager's metrics endpoint
+	metrics.Registry.MustRegister(
+		compliantDeploymentsGauge,
+		totalDeploymentsGauge,
+		remediationsCounter,
+		dockerHubRequestsCounter,
+		statusUpdatesCounter,
+		rekorClientAvailableGauge,
+	)
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+	"github.com/jonlimpw/chainguard-controller/internal/registry"
+)
+
+// registryMirror returns the DockerHub mirror a policy resolves digests through: its own RegistryMirror,
+// else the manager's, or an empty string to use DockerHub directly
+func (r *ImagePolicyReconciler) registryMirror(policy *securityv1.ImagePolicy) string {
+	if policy.Spec.RegistryMirror != "" {
+		return policy.Spec.RegistryMirror
+	}
+	return r.RegistryMirror
+}
+
+// trustedMirror reports whether mirror is the manager's --registry-mirror, the only mirror pullSecretRef
+// credentials are sent to. A policy's own registryMirror is chosen by whoever can edit the policy, so it's
+// queried anonymously.
+func (r *ImagePolicyReconciler) trustedMirror(mirror string) bool {
+	return mirror != "" && mirror == r.RegistryMirror
+}
+
+// mirrorRequest returns digestReq without its credentials unless its mirror is trusted
+func (r *ImagePolicyReconciler) mirrorRequest(digestReq digestRequest) digestRequest {
+	if !r.trustedMirror(digestReq.Mirror) {
+		digestReq.Credentials = nil
+	}
+	return digestReq
+}
+
+// mirrorHost returns the host[:port] of a mirror given as [scheme://]host[:port][/prefix]
+func mirrorHost(mirror string) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(mirror, "https://"), "http://")
+	host, _, _ = strings.Cut(host, "/")
+	return host
+}
+
+// mirrorEndpoint splits a mirror given as [scheme://]host[:port][/prefix] into its base URL (https by default)
+// and the path prefix prepended to repositories, e.g. a Harbor proxy cache project
+func mirrorEndpoint(mirror string) (string, string, error) {
+	if !strings.Contains(mirror, "://") {
+		mirror = "https://" + mirror
+	}
+	parsed, err := url.Parse(mirror)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return "", "", fmt.Errorf("invalid registry mirror %q: expected [http(s)://]host[:port][/prefix]", mirror)
+	}
+	return parsed.Scheme + "://" + parsed.Host, strings.Trim(parsed.Path, "/"), nil
+}
+
+// fetchDigestFromMirror performs a single attempt to fetch the digest through a registry mirror. The manifest
+// is requested anonymously first and the mirror's auth challenge (Bearer token or Basic) answered if it asks.
+// Credentials are only sent over https, and to a token realm on the mirror's own host.
+func fetchDigestFromMirror(ctx context.Context, digestReq digestRequest) (string, error) {
+	baseURL, prefix, err := mirrorEndpoint(digestReq.Mirror)
+	if err != nil {
+		return "", err
+	}
+	repository := path.Join(prefix, dockerHubRepository(digestReq.Repository))
+	manifestURL := fmt.Sprintf("%s/v2/%s/manifests/%s", baseURL, repository, digestReq.Tag)
+
+	client := registryHTTPClient(digestReq.Proxy)
+	resp, err := getMirrorManifest(ctx, client, manifestURL, manifestAccept, "")
+	if err != nil {
+		return "", err
+	}
+	var authorization string
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		_ = resp.Body.Close()
+
+		authorization, err = mirrorAuthorization(ctx, client, baseURL, challenge, repository, digestReq.Credentials)
+		if err != nil {
+			return "", err
+		}
+		if resp, err = getMirrorManifest(ctx, client, manifestURL, manifestAccept, authorization); err != nil {
+			return "", err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", registry.NewStatusError(resp, "mirror")
+	}
+
+	if digestReq.DigestType == securityv1.DigestTypeConfig {
+		return configDigest(resp, digestReq.Platform, func(digest string) (*http.Response, error) {
+			platformURL := fmt.Sprintf("%s/v2/%s/manifests/%s", baseURL, repository, digest)
+			resp, err := getMirrorManifest(ctx, client, platformURL, platformManifestAccept, authorization)
+			if err != nil {
+				return nil, err
+			}
+			if resp.StatusCode != http.StatusOK {
+				err := registry.NewStatusError(resp, "mirror")
+				_ = resp.Body.Close()
+				return nil, err
+			}
+			return resp, nil
+		})
+	}
+
+	// The mirror's Docker-Content-Digest is trusted as is; it may lag upstream until the mirror refreshes the tag
+	return manifestDigest(resp, digestReq.Platform)
+}
+
+// getMirrorManifest requests a manifest from a mirror, with the given Authorization header if not empty
+func getMirrorManifest(ctx context.Context, client *http.Client, manifestURL, accept, authorization string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", manifestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create manifest request: %w", err)
+	}
+	req.Header.Set("Accept", accept)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, networkError(ctx, fmt.Errorf("failed to get manifest from mirror: %w", err))
+	}
+	return resp, nil
+}
+
+// mirrorAuthorization answers a WWW-Authenticate challenge, returning the Authorization header to retry with
+func mirrorAuthorization(ctx context.Context, client *http.Client, baseURL, challenge, repository string, credentials *registryCredentials) (string, error) {
+	scheme, params := parseAuthChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if credentials == nil {
+			return "", fmt.Errorf("registry mirror requires credentials, set pullSecretRef and --registry-mirror on the manager")
+		}
+		if !strings.HasPrefix(baseURL, "https://") {
+			return "", fmt.Errorf("registry mirror %s asks for credentials over plain http", baseURL)
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials.Username+":"+credentials.Password)), nil
+	case "bearer":
+	default:
+		return "", fmt.Errorf("registry mirror returned an unsupported auth challenge %q", challenge)
+	}
+
+	tokenURL, err := url.Parse(params["realm"])
+	if err != nil || tokenURL.Host == "" {
+		return "", fmt.Errorf("registry mirror returned an invalid token realm %q", params["realm"])
+	}
+	query := tokenURL.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = "repository:" + repository + ":pull"
+	}
+	query.Set("scope", scope)
+	tokenURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", tokenURL.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create mirror token request: %w", err)
+	}
+	// A token realm elsewhere, or reached over plain http, is asked for an anonymous token
+	if credentials != nil && tokenURL.Scheme == "https" && "https://"+tokenURL.Host == baseURL {
+		req.SetBasicAuth(credentials.Username, credentials.Password)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", networkError(ctx, fmt.Errorf("failed to get mirror auth token: %w", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", registry.NewStatusError(resp, "mirror auth")
+	}
+
+	var tokenData DockerHubToken
+	if err := json.NewDecoder(resp.Body).Decode(&tokenData); err != nil {
+		return "", fmt.Errorf("failed to decode mirror token response: %w", err)
+	}
+	token := tokenData.Token
+	if token == "" {
+		token = tokenData.AccessToken
+	}
+	return "Bearer " + token, nil
+}
+
+// parseAuthChallenge parses a WWW-Authenticate header such as
+// `Bearer realm="https://harbor/service/token",service="harbor-registry"` into its scheme and parameters
+func parseAuthChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := map[string]string{}
+
+	for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimLeft(rest, ", ") {
+		key, value, found := strings.Cut(rest, "=")
+		if !found {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+
+		if strings.HasPrefix(value, `"`) {
+			// Quoted values may contain commas, e.g. multiple scope actions
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				params[key] = value[1:]
+				break
+			}
+			params[key] = value[1 : end+1]
+			rest = value[end+2:]
+			continue
+		}
+
+		value, rest, _ = strings.Cut(value, ",")
+		params[key] = strings.TrimSpace(value)
+	}
+	return scheme, params
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+	"github.com/jonlimpw/chainguard-controller/internal/registry"
+)
+
+var _ = Describe("Registry mirrors", func() {
+	It("should follow the mirror's Bearer challenge and return its digest", func() {
+		var tokenRequests int
+		var server *httptest.Server
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case "/service/token":
+				tokenRequests++
+				Expect(req.URL.Query().Get("service")).To(Equal("mirror"))
+				Expect(req.URL.Query().Get("scope")).To(Equal("repository:dockerhub/chainguard/nginx:pull"))
+				_, _ = w.Write([]byte(`{"access_token":"mirror-token"}`))
+			case "/v2/dockerhub/chainguard/nginx/manifests/latest":
+				if req.Header.Get("Authorization") != "Bearer mirror-token" {
+					w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/service/token",service="mirror"`)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.Header().Set("Docker-Content-Digest", "sha256:5555555555555555555555555555555555555555555555555555555555555555")
+				w.WriteHeader(http.StatusOK)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		digest, err := (&ImagePolicyReconciler{}).fetchDigestFromDockerHub(context.Background(), digestRequest{
+			Repository: "chainguard/nginx",
+			Tag:        "latest",
+			Mirror:     server.URL + "/dockerhub",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(digest).To(Equal("sha256:5555555555555555555555555555555555555555555555555555555555555555"))
+		Expect(tokenRequests).To(Equal(1))
+	})
+
+	It("should read config digests through the manager's mirror with its authorization", func() {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Header.Get("Authorization") != "Basic dXNlcjpwYXNz" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="mirror"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			switch req.URL.Path {
+			case "/v2/chainguard/nginx/manifests/latest":
+				w.Header().Set("Content-Type", mediaTypeDockerManifestList)
+				_, _ = w.Write([]byte(`{"manifests":[{"digest":"sha256:arm64","platform":{"os":"linux","architecture":"arm64"}}]}`))
+			case "/v2/chainguard/nginx/manifests/sha256:arm64":
+				_, _ = w.Write([]byte(`{"config":{"digest":"sha256:arm64-config"}}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+		trustServer(server)
+
+		reconciler := &ImagePolicyReconciler{RegistryMirror: server.URL}
+		digest, err := reconciler.fetchDigestFromDockerHub(context.Background(), digestRequest{
+			Repository:  "chainguard/nginx",
+			Tag:         "latest",
+			Platform:    "linux/arm64",
+			Credentials: &registryCredentials{Username: "user", Password: "pass"},
+			Mirror:      server.URL,
+			DigestType:  securityv1.DigestTypeConfig,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(digest).To(Equal("sha256:arm64-config"))
+	})
+
+	It("should not send credentials to a policy's own mirror", func() {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			Expect(req.Header.Get("Authorization")).To(BeEmpty())
+			w.Header().Set("WWW-Authenticate", `Basic realm="mirror"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+		trustServer(server)
+
+		reconciler := &ImagePolicyReconciler{RegistryMirror: "mirror.internal"}
+		_, err := reconciler.fetchDigestFromDockerHub(context.Background(), digestRequest{
+			Repository:  "chainguard/nginx",
+			Tag:         "latest",
+			Credentials: &registryCredentials{Username: "user", Password: "pass"},
+			Mirror:      server.URL,
+		})
+		Expect(err).To(MatchError(ContainSubstring("requires credentials")))
+	})
+
+	It("should not send credentials over plain http", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			Expect(req.Header.Get("Authorization")).To(BeEmpty())
+			w.Header().Set("WWW-Authenticate", `Basic realm="mirror"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		reconciler := &ImagePolicyReconciler{RegistryMirror: server.URL}
+		_, err := reconciler.fetchDigestFromDockerHub(context.Background(), digestRequest{
+			Repository:  "chainguard/nginx",
+			Tag:         "latest",
+			Credentials: &registryCredentials{Username: "user", Password: "pass"},
+			Mirror:      server.URL,
+		})
+		Expect(err).To(MatchError(ContainSubstring("plain http")))
+	})
+
+	It("should request an anonymous token from a realm on another host", func() {
+		realm := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			_, _, hasAuth := req.BasicAuth()
+			Expect(hasAuth).To(BeFalse())
+			_, _ = w.Write([]byte(`{"token":"anonymous-token"}`))
+		}))
+		defer realm.Close()
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Header.Get("Authorization") != "Bearer anonymous-token" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="`+realm.URL+`/token"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", "sha256:5555555555555555555555555555555555555555555555555555555555555555")
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		trustServer(server)
+
+		reconciler := &ImagePolicyReconciler{RegistryMirror: server.URL}
+		digest, err := reconciler.fetchDigestFromDockerHub(context.Background(), digestRequest{
+			Repository:  "chainguard/nginx",
+			Tag:         "latest",
+			Credentials: &registryCredentials{Username: "user", Password: "pass"},
+			Mirror:      server.URL,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(digest).To(Equal("sha256:5555555555555555555555555555555555555555555555555555555555555555"))
+	})
+
+	It("should report repositories the mirror doesn't have", func() {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+
+		_, err := (&ImagePolicyReconciler{}).fetchDigestFromDockerHub(context.Background(), digestRequest{
+			Repository: "chainguard/missing",
+			Tag:        "latest",
+			Mirror:     server.URL,
+		})
+		var notFound *registry.NotFoundError
+		Expect(errors.As(err, &notFound)).To(BeTrue())
+	})
+
+	It("should prefer the policy's mirror over the manager's", func() {
+		reconciler := &ImagePolicyReconciler{RegistryMirror: "mirror.internal"}
+		policy := &securityv1.ImagePolicy{}
+		Expect(reconciler.registryMirror(policy)).To(Equal("mirror.internal"))
+
+		policy.Spec.RegistryMirror = "harbor.internal/dockerhub"
+		Expect(reconciler.registryMirror(policy)).To(Equal("harbor.internal/dockerhub"))
+	})
+
+	It("should default mirrors to https and split off the repository prefix", func() {
+		baseURL, prefix, err := mirrorEndpoint("harbor.internal:8443/dockerhub")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(baseURL).To(Equal("https://harbor.internal:8443"))
+		Expect(prefix).To(Equal("dockerhub"))
+
+		baseURL, prefix, err = mirrorEndpoint("http://localhost:5000")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(baseURL).To(Equal("http://localhost:5000"))
+		Expect(prefix).To(BeEmpty())
+	})
+
+	It("should parse auth challenges with quoted commas", func() {
+		scheme, params := parseAuthChallenge(`Bearer realm="https://auth.example/token",service="registry",scope="repository:a/b:pull,push"`)
+		Expect(scheme).To(Equal("Bearer"))
+		Expect(params).To(Equal(map[string]string{
+			"realm":   "https://auth.example/token",
+			"service": "registry",
+			"scope":   "repository:a/b:pull,push",
+		}))
+	})
+})
+
+// trustServer makes registry requests trust an httptest TLS server's certificate (httptest servers share
+// one certificate) for the rest of the spec
+func trustServer(server *httptest.Server) {
+	transport := http.DefaultTransport
+	http.DefaultTransport = server.Client().Transport
+	DeferCleanup(func() { http.DefaultTransport = transport })
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"slices"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+// defaultMaxMonitoredDeployments is the number of workload statuses listed inline when MaxMonitoredDeployments is unset
+const defaultMaxMonitoredDeployments = 500
+
+// trimMonitoredDeployments returns the workload statuses to list in the policy status and how many were left out,
+// keeping at most MaxMonitoredDeployments so a policy covering thousands of workloads stays under the etcd object
+// size limit. Non-compliant workloads come first, then those still in a remediation cooldown, each in listing order.
+// Workloads left out lose the state carried over between reconciles (e.g. StaleSince or LastNotified), so the
+// non-compliant ones that need it are kept when the cap allows.
+func trimMonitoredDeployments(policy *securityv1.ImagePolicy, statuses []securityv1.DeploymentStatus) ([]securityv1.DeploymentStatus, int32) {
+	limit := defaultMaxMonitoredDeployments
+	if policy.Spec.MaxMonitoredDeployments != nil {
+		limit = int(*policy.Spec.MaxMonitoredDeployments)
+	}
+	if len(statuses) <= limit {
+		return statuses, 0
+	}
+
+	rank := func(status securityv1.DeploymentStatus) int {
+		switch {
+		case !status.IsCompliant:
+			return 0
+		case status.LastRemediated != nil:
+			return 1
+		default:
+			return 2
+		}
+	}
+	kept := slices.Clone(statuses)
+	slices.SortStableFunc(kept, func(a, b securityv1.DeploymentStatus) int {
+		return rank(a) - rank(b)
+	})
+	return kept[:limit], int32(len(statuses) - limit)
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Monitored deployments cap", func() {
+	statuses := func(compliant ...bool) []securityv1.DeploymentStatus {
+		var result []securityv1.DeploymentStatus
+		for i, isCompliant := range compliant {
+			result = append(result, securityv1.DeploymentStatus{Name: fmt.Sprintf("app-%d", i), IsCompliant: isCompliant})
+		}
+		return result
+	}
+	names := func(statuses []securityv1.DeploymentStatus) []string {
+		var result []string
+		for _, status := range statuses {
+			result = append(result, status.Name)
+		}
+		return result
+	}
+
+	It("should list every workload under the cap", func() {
+		policy := &securityv1.ImagePolicy{}
+		kept, omitted := trimMonitoredDeployments(policy, statuses(true, false, true))
+		Expect(names(kept)).To(Equal([]string{"app-0", "app-1", "app-2"}))
+		Expect(omitted).To(BeZero())
+	})
+
+	It("should list non-compliant workloads first and count the rest", func() {
+		policy := &securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{MaxMonitoredDeployments: ptr.To[int32](2)}}
+		kept, omitted := trimMonitoredDeployments(policy, statuses(true, false, true, false, false))
+		Expect(names(kept)).To(Equal([]string{"app-1", "app-3"}))
+		Expect(omitted).To(Equal(int32(3)))
+	})
+
+	It("should keep compliant workloads in a remediation cooldown before the others", func() {
+		policy := &securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{MaxMonitoredDeployments: ptr.To[int32](2)}}
+		all := statuses(true, true, false)
+		all[1].LastRemediated = ptr.To(metav1.Now())
+		kept, omitted := trimMonitoredDeployments(policy, all)
+		Expect(names(kept)).To(Equal([]string{"app-2", "app-1"}))
+		Expect(omitted).To(Equal(int32(1)))
+	})
+
+	It("should only count workloads when the cap is zero", func() {
+		policy := &securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{MaxMonitoredDeployments: ptr.To[int32](0)}}
+		kept, omitted := trimMonitoredDeployments(policy, statuses(false, true))
+		Expect(kept).To(BeEmpty())
+		Expect(omitted).To(Equal(int32(2)))
+	})
+})
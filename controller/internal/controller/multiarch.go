@@ -0,0 +1,142 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+// findMultiArchDigests returns the digests workloads run that reference the latest image of a monitored repository
+// at the other level of its multi-arch index, keyed by repository: platform manifests of the latest index when the
+// policy has no Platform, or indexes listing the latest platform manifest when it has one. Nodes pull their own
+// platform from an index, so both run the latest image. Indexes are read from DockerHub, not the mirror, and
+// digests that couldn't be checked count as outdated until the next reconcile.
+func (r *ImagePolicyReconciler) findMultiArchDigests(ctx context.Context, policy *securityv1.ImagePolicy, deployments []workload, repositories []string, latestDigests map[string]string, trackedDigests map[string][]string, cacheTTL time.Duration) map[string][]string {
+	log := logf.FromContext(ctx)
+
+	credentials, err := r.loadRegistryCredentials(ctx, policy)
+	if err != nil {
+		log.Error(err, "Skipping multi-arch digest check, failed to load registry credentials")
+		return nil
+	}
+
+	equivalent := map[string][]string{}
+	checked := map[string]bool{}
+	for _, deployment := range deployments {
+		for _, container := range deployment.governedContainers(policy.Spec.ContainerName) {
+			digest := imageDigest(*container.Image)
+			if digest == "" {
+				continue
+			}
+			for _, repository := range repositories {
+				latestDigest := latestDigests[repository]
+				key := repository + "@" + digest
+				if !imageUsesRepository(*container.Image, repository) || checked[key] || latestDigest == "" ||
+					digest == latestDigest || slices.Contains(trackedDigests[repository], digest) {
+					continue
+				}
+				checked[key] = true
+
+				// The latest digest is the index without a Platform, the platform manifest with one
+				index, platformManifest := latestDigest, digest
+				if policy.Spec.Platform != "" {
+					index, platformManifest = digest, latestDigest
+				}
+				manifests, err := r.indexManifests(ctx, digestRequest{
+					Repository:  repository,
+					Tag:         index,
+					Credentials: credentials,
+					Proxy:       r.registryProxy(policy),
+				}, cacheTTL)
+				if err != nil {
+					log.Error(err, "Failed to read multi-arch index", "repository", repository, "digest", index)
+					continue
+				}
+				if slices.Contains(manifests, platformManifest) {
+					equivalent[repository] = append(equivalent[repository], digest)
+				}
+			}
+		}
+	}
+	return equivalent
+}
+
+// indexManifests returns the platform manifest digests listed by the manifest of a digest, given as the request's
+// Tag, or nil if it isn't a multi-arch index. Results are shared through the digest cache, as a comma-separated list.
+func (r *ImagePolicyReconciler) indexManifests(ctx context.Context, digestReq digestRequest, cacheTTL time.Duration) ([]string, error) {
+	cache := r.getDigestCache()
+	cacheKey := digestReq.cacheKey() + "#manifests"
+	joined, ok := cache.get(cacheKey, cacheTTL)
+	if !ok {
+		var err error
+		joined, err = cache.fetch(cacheKey, func() (string, error) {
+			if err := r.reserveDockerHubRequest(); err != nil {
+				return "", err
+			}
+
+			manifests, err := retryDockerHub(ctx, r.DockerHubMaxRetries, func() ([]string, error) {
+				release, err := r.acquireRegistryRequest(ctx)
+				if err != nil {
+					return nil, err
+				}
+				defer release()
+				return r.fetchIndexManifests(ctx, digestReq)
+			})
+			return strings.Join(manifests, ","), err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if joined == "" {
+		return nil, nil
+	}
+	return strings.Split(joined, ","), nil
+}
+
+// fetchIndexManifests performs a single attempt to read the platform manifest digests of a multi-arch index
+func (r *ImagePolicyReconciler) fetchIndexManifests(ctx context.Context, digestReq digestRequest) ([]string, error) {
+	token, err := r.fetchDockerHubToken(ctx, digestReq.Repository, digestReq.Credentials, digestReq.Proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	var index DockerHubManifestList
+	manifestURL := fmt.Sprintf("%s/v2/%s/manifests/%s", r.registryURL(), dockerHubRepository(digestReq.Repository), digestReq.Tag)
+	if err := fetchRegistryJSON(ctx, manifestURL, token, manifestAccept, digestReq.Proxy, &index); err != nil {
+		return nil, fmt.Errorf("failed to get manifest %s: %w", digestReq.Tag, err)
+	}
+
+	// OCI indexes may leave out their media type, but only indexes list manifests
+	if index.MediaType != "" && !isManifestList(index.MediaType) {
+		return nil, nil
+	}
+	var manifests []string
+	for _, manifest := range index.Manifests {
+		manifests = append(manifests, manifest.Digest)
+	}
+	return manifests, nil
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Multi-arch digests", func() {
+	const (
+		repository  = "jonlimpw/cg-demo"
+		indexDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		amd64Digest = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+		arm64Digest = "sha256:3333333333333333333333333333333333333333333333333333333333333333"
+		oldDigest   = "sha256:4444444444444444444444444444444444444444444444444444444444444444"
+	)
+	index := `{"schemaVersion":2,"mediaType":"` + mediaTypeOCIImageIndex + `","manifests":[` +
+		`{"digest":"` + amd64Digest + `","platform":{"os":"linux","architecture":"amd64"}},` +
+		`{"digest":"` + arm64Digest + `","platform":{"os":"linux","architecture":"arm64"}}]}`
+
+	ctx := context.Background()
+	var manifestRequests int
+
+	BeforeEach(func() {
+		manifestRequests = 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/token":
+				_, _ = w.Write([]byte(`{"token":"test"}`))
+			case "/v2/jonlimpw/cg-demo/manifests/" + indexDigest:
+				manifestRequests++
+				w.Header().Set("Content-Type", mediaTypeOCIImageIndex)
+				_, _ = w.Write([]byte(index))
+			case "/v2/jonlimpw/cg-demo/manifests/" + oldDigest:
+				manifestRequests++
+				w.Header().Set("Content-Type", mediaTypeOCIImageManifest)
+				_, _ = w.Write([]byte(`{"schemaVersion":2,"mediaType":"` + mediaTypeOCIImageManifest + `"}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		previousRegistryURL, previousAuthURL := dockerHubRegistryURL, dockerHubAuthURL
+		dockerHubRegistryURL, dockerHubAuthURL = server.URL, server.URL
+		DeferCleanup(func() {
+			server.Close()
+			dockerHubRegistryURL, dockerHubAuthURL = previousRegistryURL, previousAuthURL
+		})
+	})
+
+	newDeployment := func(digests ...string) workload {
+		var containers []corev1.Container
+		for i, digest := range digests {
+			containers = append(containers, corev1.Container{Name: "app-" + string(rune('a'+i)), Image: repository + "@" + digest})
+		}
+		w, _ := newWorkload(&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: containers}}},
+		})
+		return w
+	}
+
+	find := func(reconciler *ImagePolicyReconciler, platform, latestDigest string, deployment workload) map[string][]string {
+		policy := &securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{Repository: repository, Platform: platform}}
+		return reconciler.findMultiArchDigests(ctx, policy, []workload{deployment}, []string{repository},
+			map[string]string{repository: latestDigest}, nil, time.Minute)
+	}
+
+	It("should accept platform manifests of the latest index", func() {
+		reconciler := &ImagePolicyReconciler{}
+		Expect(find(reconciler, "", indexDigest, newDeployment(arm64Digest, oldDigest))).To(Equal(map[string][]string{repository: {arm64Digest}}))
+
+		// The index is read once per check interval, however many workloads run its platforms
+		Expect(find(reconciler, "", indexDigest, newDeployment(amd64Digest))).To(Equal(map[string][]string{repository: {amd64Digest}}))
+		Expect(manifestRequests).To(Equal(1))
+	})
+
+	It("should accept the index listing the latest platform manifest", func() {
+		Expect(find(&ImagePolicyReconciler{}, "linux/amd64", amd64Digest, newDeployment(indexDigest, oldDigest))).To(Equal(map[string][]string{repository: {indexDigest}}))
+		Expect(manifestRequests).To(Equal(2))
+	})
+
+	It("should accept the index only when it lists the latest platform manifest", func() {
+		Expect(find(&ImagePolicyReconciler{}, "linux/amd64", oldDigest, newDeployment(indexDigest))).To(BeEmpty())
+	})
+
+	It("should report workloads on the other level of the latest image as compliant", func() {
+		deployment := newDeployment(arm64Digest)
+		rules := complianceRules{EnforceLatest: true}
+		status := (&ImagePolicyReconciler{}).analyzeDeploymentCompliance(ctx, deployment, repository, indexDigest, rules)
+		Expect(status.Reason).To(Equal(securityv1.NonComplianceReasonOutdatedDigest))
+
+		rules.MultiArchDigests = map[string][]string{repository: {arm64Digest}}
+		status = (&ImagePolicyReconciler{}).analyzeDeploymentCompliance(ctx, deployment, repository, indexDigest, rules)
+		Expect(status.IsCompliant).To(BeTrue())
+		_, remediated := remediatedImage(repository+"@"+arm64Digest, map[string]string{repository: indexDigest}, rules)
+		Expect(remediated).To(BeFalse())
+	})
+
+	It("should keep workloads on the other level of the latest image when remediating", func() {
+		deployment := newDeployment(arm64Digest, oldDigest)
+		remediator := &ImagePolicyReconciler{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(deployment.Object).Build()}
+		policy := &securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{Repository: repository}}
+		latestDigests := map[string]string{repository: indexDigest}
+
+		// The rules the reconcile analyzed the workload with
+		rules := remediator.rulesFor(policy)
+		rules.MultiArchDigests = remediator.findMultiArchDigests(ctx, policy, []workload{deployment}, []string{repository}, latestDigests, nil, time.Minute)
+		Expect(remediator.remediateDeployment(ctx, policy, deployment, rules, latestDigests)).To(Succeed())
+
+		updated := &appsv1.Deployment{}
+		Expect(remediator.Get(ctx, types.NamespacedName{Name: "demo", Namespace: "default"}, updated)).To(Succeed())
+		Expect(updated.Spec.Template.Spec.Containers[0].Image).To(Equal(repository + "@" + arm64Digest))
+		Expect(updated.Spec.Template.Spec.Containers[1].Image).To(Equal(repository + "@" + indexDigest))
+	})
+})
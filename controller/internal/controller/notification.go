@@ -0,0 +1,154 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+	"github.com/jonlimpw/chainguard-controller/internal/notify"
+)
+
+// notificationTimeout bounds each webhook call, which runs outside the reconcile
+const notificationTimeout = 10 * time.Second
+
+// notifyComplianceChange notifies a non-compliant workload state once, recording it in status.LastNotified.
+// The state carried over from the previous reconcile suppresses repeat notifications until the event or
+// digest changes; compliant workloads carry no state so a later regression is notified again.
+func (r *ImagePolicyReconciler) notifyComplianceChange(ctx context.Context, policy *securityv1.ImagePolicy, deployment workload, status *securityv1.DeploymentStatus, latestDigests map[string]string) {
+	if status.IsCompliant {
+		return
+	}
+
+	event := securityv1.NotificationEventNonCompliant
+	if status.Reason == securityv1.NonComplianceReasonAttestationFailed {
+		event = securityv1.NotificationEventAttestationFailed
+	}
+
+	if previous := findDeploymentStatus(policy, deployment); previous != nil && previous.LastNotified != nil &&
+		previous.LastNotified.Event == event && previous.LastNotified.Digest == status.CurrentDigest {
+		status.LastNotified = previous.LastNotified
+		return
+	}
+
+	sent := r.sendNotification(ctx, policy, event, notify.Payload{
+		Text: fmt.Sprintf("%s %s/%s is non-compliant with ImagePolicy %s in %s %s: %s", deployment.Kind, deployment.GetNamespace(),
+			deployment.GetName(), types.NamespacedName{Namespace: policy.Namespace, Name: policy.Name}, strings.ToLower(status.ContainerKind), status.ContainerName, status.Message),
+		Kind:          deployment.Kind,
+		Namespace:     deployment.GetNamespace(),
+		Name:          deployment.GetName(),
+		Container:     status.ContainerName,
+		Repository:    status.Repository,
+		CurrentDigest: status.CurrentDigest,
+		LatestDigest:  latestDigests[status.Repository],
+		Reason:        status.Reason,
+		Message:       status.Message,
+	})
+	if sent {
+		status.LastNotified = &securityv1.NotifiedState{Event: event, Digest: status.CurrentDigest, Timestamp: metav1.Now()}
+	}
+}
+
+// notifyRemediation notifies a successful remediation; each remediation happens once so it needs no tracking
+func (r *ImagePolicyReconciler) notifyRemediation(ctx context.Context, policy *securityv1.ImagePolicy, deployment workload, record securityv1.RemediationRecord, repository string) {
+	text := fmt.Sprintf("Auto-remediated %s %s/%s to %s (ImagePolicy %s)", deployment.Kind, deployment.GetNamespace(), deployment.GetName(), record.NewDigest, types.NamespacedName{Namespace: policy.Namespace, Name: policy.Name})
+	if record.PullRequestURL != "" {
+		text = fmt.Sprintf("Opened %s to remediate %s %s/%s to %s (ImagePolicy %s)", record.PullRequestURL, deployment.Kind, deployment.GetNamespace(), deployment.GetName(), record.NewDigest, types.NamespacedName{Namespace: policy.Namespace, Name: policy.Name})
+	}
+
+	r.sendNotification(ctx, policy, securityv1.NotificationEventAutoRemediated, notify.Payload{
+		Text:           text,
+		Kind:           deployment.Kind,
+		Namespace:      deployment.GetNamespace(),
+		Name:           deployment.GetName(),
+		Repository:     repository,
+		CurrentDigest:  record.OldDigest,
+		LatestDigest:   record.NewDigest,
+		PullRequestURL: record.PullRequestURL,
+	})
+}
+
+// sendNotification posts the payload in the background if the policy subscribes to the event.
+// It returns true once the notification is dispatched; delivery failures are only logged so the
+// reconcile result never depends on the webhook.
+func (r *ImagePolicyReconciler) sendNotification(ctx context.Context, policy *securityv1.ImagePolicy, event string, payload notify.Payload) bool {
+	log := logf.FromContext(ctx)
+
+	config := policy.Spec.NotificationConfig
+	if config == nil || (len(config.Events) > 0 && !slices.Contains(config.Events, event)) {
+		return false
+	}
+
+	address, err := r.loadNotificationAddress(ctx, policy)
+	if err != nil {
+		log.Error(err, "Failed to load notification webhook address")
+		return false
+	}
+
+	payload.Event = event
+	payload.Policy = types.NamespacedName{Namespace: policy.Namespace, Name: policy.Name}.String()
+	payload.Timestamp = time.Now().UTC()
+
+	go func() {
+		sendCtx, cancel := context.WithTimeout(context.Background(), notificationTimeout)
+		defer cancel()
+		if err := notify.NewClient(address).Send(sendCtx, payload); err != nil {
+			log.Error(err, "Failed to send notification", "event", event, "kind", payload.Kind, "deployment", payload.Name, "namespace", payload.Namespace)
+		}
+	}()
+	return true
+}
+
+// loadNotificationAddress reads the webhook URL from the NotificationConfig Secret
+func (r *ImagePolicyReconciler) loadNotificationAddress(ctx context.Context, policy *securityv1.ImagePolicy) (string, error) {
+	ref := policy.Spec.NotificationConfig.SecretRef
+	namespace, err := referenceNamespace(policy, ref.Namespace)
+	if err != nil {
+		return "", fmt.Errorf("notification secret %s: %w", ref.Name, err)
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+		return "", fmt.Errorf("failed to get notification secret %s/%s: %w", namespace, ref.Name, err)
+	}
+
+	address := strings.TrimSpace(string(secret.Data["address"]))
+	if address == "" {
+		return "", fmt.Errorf("secret %s/%s has no address key", namespace, ref.Name)
+	}
+	return address, nil
+}
+
+// findDeploymentStatus returns the status recorded for a workload by the previous reconcile
+func findDeploymentStatus(policy *securityv1.ImagePolicy, deployment workload) *securityv1.DeploymentStatus {
+	for i := range policy.Status.MonitoredDeployments {
+		previous := &policy.Status.MonitoredDeployments[i]
+		if previous.Kind == deployment.Kind && previous.Namespace == deployment.GetNamespace() && previous.Name == deployment.GetName() {
+			return previous
+		}
+	}
+	return nil
+}
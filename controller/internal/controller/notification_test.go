@@ -0,0 +1,141 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+	"github.com/jonlimpw/chainguard-controller/internal/notify"
+)
+
+var _ = Describe("Notifications", func() {
+	const (
+		repository  = "jonlimpw/cg-demo"
+		staleDigest = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	)
+
+	var (
+		server     *httptest.Server
+		mu         sync.Mutex
+		received   []notify.Payload
+		reconciler *ImagePolicyReconciler
+		policy     *securityv1.ImagePolicy
+		deployment workload
+	)
+
+	ctx := context.Background()
+
+	receivedEvents := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		var events []string
+		for _, payload := range received {
+			events = append(events, payload.Event)
+		}
+		return events
+	}
+
+	// reconcileStatus runs one notification pass and stores the statuses like Reconcile does
+	reconcileStatus := func(statuses ...securityv1.DeploymentStatus) {
+		for i := range statuses {
+			reconciler.notifyComplianceChange(ctx, policy, deployment, &statuses[i], map[string]string{})
+		}
+		policy.Status.MonitoredDeployments = statuses
+	}
+
+	nonCompliant := securityv1.DeploymentStatus{
+		Kind: securityv1.WorkloadKindDeployment, Name: "demo", Namespace: "default",
+		Repository: repository, CurrentDigest: staleDigest, Reason: securityv1.NonComplianceReasonOutdatedDigest,
+	}
+
+	BeforeEach(func() {
+		received = nil
+		server = httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			var payload notify.Payload
+			Expect(json.NewDecoder(r.Body).Decode(&payload)).To(Succeed())
+			mu.Lock()
+			received = append(received, payload)
+			mu.Unlock()
+		}))
+		DeferCleanup(server.Close)
+
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "webhook", Namespace: "default"},
+			Data:       map[string][]byte{"address": []byte(server.URL)},
+		}
+		reconciler = &ImagePolicyReconciler{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build()}
+		policy = &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+			Spec: securityv1.ImagePolicySpec{
+				Repository:         repository,
+				NotificationConfig: &securityv1.NotificationConfig{SecretRef: corev1.SecretReference{Name: "webhook"}},
+			},
+		}
+		deployment, _ = newWorkload(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"}})
+	})
+
+	It("should only notify a non-compliant state once", func() {
+		reconcileStatus(nonCompliant)
+		Expect(policy.Status.MonitoredDeployments[0].LastNotified).NotTo(BeNil())
+		reconcileStatus(nonCompliant)
+
+		Eventually(receivedEvents).Should(Equal([]string{securityv1.NotificationEventNonCompliant}))
+		Consistently(receivedEvents, "200ms").Should(HaveLen(1))
+	})
+
+	It("should notify again after the workload was compliant", func() {
+		reconcileStatus(nonCompliant)
+		reconcileStatus(securityv1.DeploymentStatus{Kind: securityv1.WorkloadKindDeployment, Name: "demo", Namespace: "default", IsCompliant: true})
+		Expect(policy.Status.MonitoredDeployments[0].LastNotified).To(BeNil())
+		reconcileStatus(nonCompliant)
+
+		Eventually(receivedEvents).Should(HaveLen(2))
+	})
+
+	It("should notify when a non-compliant state changes", func() {
+		reconcileStatus(nonCompliant)
+		attestationFailed := nonCompliant
+		attestationFailed.Reason = securityv1.NonComplianceReasonAttestationFailed
+		reconcileStatus(attestationFailed)
+
+		Eventually(receivedEvents).Should(ConsistOf(securityv1.NotificationEventNonCompliant, securityv1.NotificationEventAttestationFailed))
+	})
+
+	It("should skip events the policy doesn't subscribe to", func() {
+		policy.Spec.NotificationConfig.Events = []string{securityv1.NotificationEventAutoRemediated}
+		reconcileStatus(nonCompliant)
+		Expect(policy.Status.MonitoredDeployments[0].LastNotified).To(BeNil())
+
+		reconciler.notifyRemediation(ctx, policy, deployment, securityv1.RemediationRecord{OldDigest: staleDigest}, repository)
+
+		Eventually(receivedEvents).Should(Equal([]string{securityv1.NotificationEventAutoRemediated}))
+		Consistently(receivedEvents, "200ms").Should(HaveLen(1))
+	})
+})
@@ -0,0 +1,120 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OCI manifests", func() {
+	const ociDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	const platformDigest = "sha256:3333333333333333333333333333333333333333333333333333333333333333"
+	manifest := `{"schemaVersion":2,"mediaType":"` + mediaTypeOCIImageManifest + `","config":{"digest":"sha256:2222"}}`
+	index := `{"schemaVersion":2,"mediaType":"` + mediaTypeOCIImageIndex + `","manifests":[{"digest":"` + platformDigest + `","platform":{"os":"linux","architecture":"arm64"}}]}`
+
+	ctx := context.Background()
+
+	// Like strict OCI registries, the server only returns manifests of a type the client accepts
+	BeforeEach(func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			accepts := func(mediaType string) bool { return strings.Contains(r.Header.Get("Accept"), mediaType) }
+			switch r.URL.Path {
+			case "/token":
+				_, _ = w.Write([]byte(`{"token":"test"}`))
+			case "/v2/chainguard/static/manifests/latest":
+				if !accepts(mediaTypeOCIImageManifest) {
+					w.WriteHeader(http.StatusNotAcceptable)
+					return
+				}
+				w.Header().Set("Content-Type", mediaTypeOCIImageManifest)
+				w.Header().Set("Docker-Content-Digest", ociDigest)
+				_, _ = w.Write([]byte(manifest))
+			case "/v2/chainguard/static/manifests/no-header":
+				w.Header().Set("Content-Type", mediaTypeOCIImageManifest+"; charset=utf-8")
+				_, _ = w.Write([]byte(manifest))
+			case "/v2/chainguard/static/manifests/bare":
+				// Like some proxies, drop the Content-Type along with the digest
+				w.Header()["Content-Type"] = nil
+				_, _ = w.Write([]byte(manifest))
+			case "/v2/chainguard/static/manifests/bare-index":
+				w.Header()["Content-Type"] = nil
+				_, _ = w.Write([]byte(index))
+			case "/v2/chainguard/static/manifests/bare-unknown":
+				w.Header()["Content-Type"] = nil
+				_, _ = w.Write([]byte(`{"schemaVersion":1}`))
+			case "/v2/chainguard/static/manifests/bad-header":
+				w.Header().Set("Content-Type", mediaTypeOCIImageManifest)
+				w.Header().Set("Docker-Content-Digest", "sha256:1111 latest")
+				_, _ = w.Write([]byte(manifest))
+			case "/v2/chainguard/static/manifests/schema1":
+				w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v1+prettyjws")
+				_, _ = w.Write([]byte(`{"schemaVersion":1}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		previousRegistryURL, previousAuthURL := dockerHubRegistryURL, dockerHubAuthURL
+		dockerHubRegistryURL, dockerHubAuthURL = server.URL, server.URL
+		DeferCleanup(func() {
+			server.Close()
+			dockerHubRegistryURL, dockerHubAuthURL = previousRegistryURL, previousAuthURL
+		})
+	})
+
+	fetch := func(tag string) (string, error) {
+		return (&ImagePolicyReconciler{}).fetchDigestFromDockerHub(ctx, digestRequest{Repository: "chainguard/static", Tag: tag})
+	}
+
+	It("should accept OCI image manifests", func() {
+		Expect(manifestAccept).To(ContainSubstring(mediaTypeOCIImageManifest))
+		Expect(fetch("latest")).To(Equal(ociDigest))
+	})
+
+	It("should fall back to the digest of the manifest body without a Docker-Content-Digest header", func() {
+		Expect(fetch("no-header")).To(Equal(fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(manifest)))))
+	})
+
+	It("should fall back to the media type the manifest declares without a Content-Type header", func() {
+		Expect(fetch("bare")).To(Equal(fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(manifest)))))
+		Expect(fetch("bare-index")).To(Equal(fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(index)))))
+
+		digest, err := (&ImagePolicyReconciler{}).fetchDigestFromDockerHub(ctx, digestRequest{Repository: "chainguard/static", Tag: "bare-index", Platform: "linux/arm64"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(digest).To(Equal(platformDigest))
+
+		_, err = fetch("bare-unknown")
+		Expect(err).To(MatchError("no digest found in response headers"))
+	})
+
+	It("should reject a malformed Docker-Content-Digest header", func() {
+		_, err := fetch("bad-header")
+		Expect(err).To(MatchError(ContainSubstring("invalid Docker-Content-Digest header: invalid digest format")))
+	})
+
+	It("should reject manifest types it can't compute the digest of", func() {
+		_, err := fetch("schema1")
+		Expect(err).To(MatchError(ContainSubstring("unsupported manifest media type application/vnd.docker.distribution.manifest.v1+prettyjws")))
+	})
+})
@@ -0,0 +1,68 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+// complianceOverride returns the status message of a workload's compliance override and whether it is in
+// effect: the compliance-override annotation is set with a reason and hasn't lapsed. An override without a
+// reason or with an expiry that can't be parsed is an error and not in effect.
+func complianceOverride(deployment workload, now time.Time) (string, bool, error) {
+	annotations := deployment.GetAnnotations()
+	reason, ok := annotations[securityv1.AnnotationComplianceOverride]
+	if !ok {
+		return "", false, nil
+	}
+	if reason = strings.TrimSpace(reason); reason == "" {
+		return "", false, fmt.Errorf("%s needs a reason", securityv1.AnnotationComplianceOverride)
+	}
+
+	value, ok := annotations[securityv1.AnnotationComplianceOverrideExpires]
+	if !ok {
+		return fmt.Sprintf("compliance overridden: %s", reason), true, nil
+	}
+	expires, err := time.Parse(time.RFC3339, strings.TrimSpace(value))
+	if err != nil {
+		return "", false, fmt.Errorf("invalid %s %q, expected an RFC 3339 time", securityv1.AnnotationComplianceOverrideExpires, value)
+	}
+	if !now.Before(expires) {
+		return "", false, nil
+	}
+	return fmt.Sprintf("compliance overridden until %s: %s", expires.UTC().Format(time.RFC3339), reason), true, nil
+}
+
+// recordComplianceOverride emits an event for each workload whose compliance is overridden, so overrides are
+// audited on the policy, and for each override that is ignored because it is invalid
+func (r *ImagePolicyReconciler) recordComplianceOverride(policy *securityv1.ImagePolicy, deployment workload, status *securityv1.DeploymentStatus) {
+	if status.Reason == securityv1.ComplianceReasonOverridden {
+		r.Recorder.Event(policy, corev1.EventTypeWarning, "ComplianceOverridden",
+			fmt.Sprintf("%s %s/%s is treated as compliant and not remediated, %s", deployment.Kind, deployment.GetNamespace(), deployment.GetName(), status.Message))
+		return
+	}
+	if _, _, err := complianceOverride(deployment, time.Now()); err != nil {
+		r.Recorder.Event(policy, corev1.EventTypeWarning, "InvalidComplianceOverride",
+			fmt.Sprintf("Ignoring the compliance override of %s %s/%s: %v", deployment.Kind, deployment.GetNamespace(), deployment.GetName(), err))
+	}
+}
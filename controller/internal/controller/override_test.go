@@ -0,0 +1,128 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Compliance overrides", func() {
+	const (
+		repository   = "jonlimpw/cg-demo"
+		latestDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		staleDigest  = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// outdated returns a Deployment running an outdated digest of repository with the given annotations
+	outdated := func(annotations map[string]string) workload {
+		w, _ := newWorkload(&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default", Annotations: annotations},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "app", Image: repository + "@" + staleDigest}},
+			}}},
+		})
+		return w
+	}
+
+	It("should parse the override annotations", func() {
+		_, active, err := complianceOverride(outdated(nil), now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(active).To(BeFalse())
+
+		message, active, err := complianceOverride(outdated(map[string]string{
+			securityv1.AnnotationComplianceOverride: "INC-1234 rollback pinned",
+		}), now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(active).To(BeTrue())
+		Expect(message).To(Equal("compliance overridden: INC-1234 rollback pinned"))
+
+		message, active, err = complianceOverride(outdated(map[string]string{
+			securityv1.AnnotationComplianceOverride:        "INC-1234",
+			securityv1.AnnotationComplianceOverrideExpires: "2026-03-02T00:00:00Z",
+		}), now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(active).To(BeTrue())
+		Expect(message).To(Equal("compliance overridden until 2026-03-02T00:00:00Z: INC-1234"))
+
+		_, active, err = complianceOverride(outdated(map[string]string{
+			securityv1.AnnotationComplianceOverride:        "INC-1234",
+			securityv1.AnnotationComplianceOverrideExpires: "2026-03-01T12:00:00Z",
+		}), now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(active).To(BeFalse(), "an override lapses at its expiry")
+
+		_, active, err = complianceOverride(outdated(map[string]string{
+			securityv1.AnnotationComplianceOverride:        "INC-1234",
+			securityv1.AnnotationComplianceOverrideExpires: "tomorrow",
+		}), now)
+		Expect(err).To(MatchError(ContainSubstring("expected an RFC 3339 time")))
+		Expect(active).To(BeFalse())
+
+		_, active, err = complianceOverride(outdated(map[string]string{securityv1.AnnotationComplianceOverride: " "}), now)
+		Expect(err).To(MatchError(ContainSubstring("needs a reason")))
+		Expect(active).To(BeFalse())
+	})
+
+	It("should mark an overridden workload compliant until the override lapses", func() {
+		reconciler := &ImagePolicyReconciler{}
+		rules := complianceRules{EnforceLatest: true}
+
+		status := reconciler.analyzeDeploymentCompliance(context.Background(), outdated(map[string]string{
+			securityv1.AnnotationComplianceOverride: "INC-1234",
+		}), repository, latestDigest, rules)
+		Expect(status.IsCompliant).To(BeTrue())
+		Expect(status.Reason).To(Equal(securityv1.ComplianceReasonOverridden))
+		Expect(status.Message).To(Equal("compliance overridden: INC-1234"))
+
+		status = reconciler.analyzeDeploymentCompliance(context.Background(), outdated(map[string]string{
+			securityv1.AnnotationComplianceOverride:        "INC-1234",
+			securityv1.AnnotationComplianceOverrideExpires: time.Now().Add(-time.Hour).Format(time.RFC3339),
+		}), repository, latestDigest, rules)
+		Expect(status.IsCompliant).To(BeFalse())
+		Expect(status.Reason).To(Equal(securityv1.NonComplianceReasonOutdatedDigest))
+	})
+
+	It("should record overridden and invalid overrides", func() {
+		recorder := record.NewFakeRecorder(4)
+		reconciler := &ImagePolicyReconciler{Recorder: recorder}
+		policy := &securityv1.ImagePolicy{ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"}}
+
+		reconciler.recordComplianceOverride(policy, outdated(nil), &securityv1.DeploymentStatus{
+			IsCompliant: true, Reason: securityv1.ComplianceReasonOverridden, Message: "compliance overridden: INC-1234"})
+		Expect(recorder.Events).To(Receive(And(ContainSubstring("ComplianceOverridden"), ContainSubstring("compliance overridden: INC-1234"))))
+
+		reconciler.recordComplianceOverride(policy, outdated(map[string]string{
+			securityv1.AnnotationComplianceOverride:        "INC-1234",
+			securityv1.AnnotationComplianceOverrideExpires: "tomorrow",
+		}), &securityv1.DeploymentStatus{Reason: securityv1.NonComplianceReasonOutdatedDigest})
+		Expect(recorder.Events).To(Receive(ContainSubstring("InvalidComplianceOverride")))
+
+		reconciler.recordComplianceOverride(policy, outdated(nil), &securityv1.DeploymentStatus{Reason: securityv1.NonComplianceReasonOutdatedDigest})
+		Expect(recorder.Events).NotTo(Receive())
+	})
+})
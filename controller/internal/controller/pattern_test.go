@@ -0,0 +1,189 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Repository patterns", func() {
+	const (
+		apiDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		webDigest = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+		oldDigest = "sha256:3333333333333333333333333333333333333333333333333333333333333333"
+	)
+
+	ctx := context.Background()
+
+	newPatternDeployment := func(name string, images ...string) *appsv1.Deployment {
+		var containers []corev1.Container
+		for i, image := range images {
+			containers = append(containers, corev1.Container{Name: []string{"app", "sidecar"}[i], Image: image})
+		}
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: containers}}},
+		}
+	}
+	newPatternWorkload := func(images ...string) workload {
+		w, _ := newWorkload(newPatternDeployment("demo", images...))
+		return w
+	}
+
+	It("should match DockerHub repositories against the glob", func() {
+		deployment := newPatternWorkload("myorg/api:v1", "docker.io/myorg/web@"+webDigest)
+		Expect(patternRepositories(deployment.containers(), "myorg/*")).To(Equal([]string{"myorg/api", "myorg/web"}))
+		Expect(patternRepositories(deployment.containers(), "myorg/w?b")).To(Equal([]string{"myorg/web"}))
+		Expect(patternRepositories(deployment.containers(), "")).To(BeEmpty())
+
+		// "*" doesn't cross a path separator, and other registries never match
+		Expect(patternRepositories(newPatternWorkload("myorg/team/api:v1").containers(), "myorg/*")).To(BeEmpty())
+		Expect(patternRepositories(newPatternWorkload("ghcr.io/myorg/api:v1").containers(), "myorg/*")).To(BeEmpty())
+		Expect(patternRepositories(newPatternWorkload("otherorg/api:v1").containers(), "myorg/*")).To(BeEmpty())
+	})
+
+	It("should match official images under library/ and report them by their short name", func() {
+		deployment := newPatternWorkload("nginx:1.27", "docker.io/library/redis:7")
+		Expect(patternRepositories(deployment.containers(), "library/*")).To(Equal([]string{"nginx", "redis"}))
+		Expect(patternRepositories(deployment.containers(), "nginx")).To(BeEmpty())
+	})
+
+	It("should reject malformed patterns", func() {
+		Expect(validateRepositoryPattern("myorg/[a-")).To(MatchError(ContainSubstring("invalid repository pattern")))
+		Expect(validateRepositoryPattern("myorg/*")).To(Succeed())
+		Expect(patternRepositories(newPatternWorkload("myorg/api:v1").containers(), "myorg/[a-")).To(BeEmpty())
+	})
+
+	It("should monitor the explicit repositories first, then the matched ones sorted", func() {
+		policy := &securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{
+			Repository:        "myorg/web",
+			RepositoryPattern: "myorg/*",
+		}}
+		deployments := []workload{
+			newPatternWorkload("myorg/worker:v1", "myorg/web:v1"),
+			newPatternWorkload("myorg/api:v1"),
+		}
+		Expect(monitoredRepositories(policy, deployments)).To(Equal([]string{"myorg/web", "myorg/api", "myorg/worker"}))
+
+		// Only the governed containers count
+		policy.Spec.ContainerName = "sidecar"
+		Expect(monitoredRepositories(policy, deployments)).To(Equal([]string{"myorg/web"}))
+	})
+
+	It("should discover workloads running a matching repository", func() {
+		fakeClient := fake.NewClientBuilder().WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+			newPatternDeployment("api", "myorg/api:v1"),
+			newPatternDeployment("other", "otherorg/api:v1"),
+		).Build()
+		reconciler := &ImagePolicyReconciler{Client: fakeClient}
+		policy := &securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{RepositoryPattern: "myorg/*"}}
+
+		deployments, err := reconciler.findDeploymentsToMonitor(ctx, policy)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deployments).To(HaveLen(1))
+		Expect(deployments[0].GetName()).To(Equal("api"))
+	})
+
+	It("should track and remediate each matched repository to its own latest digest", func() {
+		var manifestRequests []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/token":
+				_, _ = w.Write([]byte(`{"token":"test"}`))
+			case strings.HasPrefix(r.URL.Path, "/v2/myorg/api/"):
+				manifestRequests = append(manifestRequests, r.URL.Path)
+				w.Header().Set("Docker-Content-Digest", apiDigest)
+				_, _ = w.Write([]byte(`{"schemaVersion":2,"mediaType":"` + mediaTypeDockerManifest + `"}`))
+			case strings.HasPrefix(r.URL.Path, "/v2/myorg/web/"):
+				manifestRequests = append(manifestRequests, r.URL.Path)
+				w.Header().Set("Docker-Content-Digest", webDigest)
+				_, _ = w.Write([]byte(`{"schemaVersion":2,"mediaType":"` + mediaTypeDockerManifest + `"}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		previousRegistryURL, previousAuthURL := dockerHubRegistryURL, dockerHubAuthURL
+		dockerHubRegistryURL, dockerHubAuthURL = server.URL, server.URL
+		DeferCleanup(func() {
+			server.Close()
+			dockerHubRegistryURL, dockerHubAuthURL = previousRegistryURL, previousAuthURL
+		})
+
+		key := types.NamespacedName{Name: "myorg", Namespace: "default"}
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Spec:       securityv1.ImagePolicySpec{RepositoryPattern: "myorg/*"},
+		}
+		deployment := newPatternDeployment("demo", "myorg/web@"+oldDigest, "docker.io/myorg/api@"+oldDigest)
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(policy, deployment, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}).
+			WithStatusSubresource(policy).Build()
+		reconciler := &ImagePolicyReconciler{Client: fakeClient, Recorder: record.NewFakeRecorder(10), DockerHubMaxRetries: 1}
+
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		updated := &securityv1.ImagePolicy{}
+		Expect(fakeClient.Get(ctx, key, updated)).To(Succeed())
+		Expect(updated.Status.Repositories).To(HaveLen(2))
+		Expect(updated.Status.Repositories[0].Repository).To(Equal("myorg/api"))
+		Expect(updated.Status.Repositories[0].LatestDigest).To(Equal(apiDigest))
+		Expect(updated.Status.Repositories[1].Repository).To(Equal("myorg/web"))
+		Expect(updated.Status.Repositories[1].LatestDigest).To(Equal(webDigest))
+		Expect(updated.Status.TotalDeployments).To(Equal(int32(1)))
+		Expect(updated.Status.CompliantDeployments).To(BeZero())
+		Expect(meta.FindStatusCondition(updated.Status.Conditions, securityv1.ConditionTypeDegraded)).To(BeNil())
+
+		// Matched repositories are cached like explicit ones until the check interval passes
+		requests := len(manifestRequests)
+		_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(manifestRequests).To(HaveLen(requests))
+
+		// Each container is pinned to the latest digest of the repository it runs, never another match's
+		latestDigests := map[string]string{}
+		for _, repoStatus := range updated.Status.Repositories {
+			latestDigests[repoStatus.Repository] = repoStatus.LatestDigest
+		}
+		w, _ := newWorkload(deployment)
+		Expect(reconciler.remediateDeployment(ctx, updated, w, reconciler.rulesFor(updated), latestDigests)).To(Succeed())
+
+		remediated := &appsv1.Deployment{}
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(deployment), remediated)).To(Succeed())
+		Expect(remediated.Spec.Template.Spec.Containers[0].Image).To(Equal("myorg/web@" + webDigest))
+		Expect(remediated.Spec.Template.Spec.Containers[1].Image).To(Equal("docker.io/myorg/api@" + apiDigest))
+	})
+})
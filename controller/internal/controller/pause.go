@@ -0,0 +1,51 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+// isPaused reports whether a policy carries the paused annotation
+func isPaused(policy *securityv1.ImagePolicy) bool {
+	return policy.GetAnnotations()[securityv1.AnnotationPaused] == "true"
+}
+
+// pauseImagePolicy records that reconciliation is paused, writing the status only when it changes
+func (r *ImagePolicyReconciler) pauseImagePolicy(ctx context.Context, policy *securityv1.ImagePolicy) error {
+	if policy.Status.Paused && meta.IsStatusConditionFalse(policy.Status.Conditions, securityv1.ConditionTypeProgressing) {
+		return nil
+	}
+
+	policy.Status.Paused = true
+	r.updateCondition(policy, securityv1.ConditionTypeProgressing, metav1.ConditionFalse,
+		"Paused", "Reconciliation is paused by the "+securityv1.AnnotationPaused+" annotation")
+	return r.Status().Update(ctx, policy)
+}
+
+// resumeImagePolicy clears the paused state; the status is written by the reconcile that follows
+func resumeImagePolicy(policy *securityv1.ImagePolicy) {
+	if progressing := meta.FindStatusCondition(policy.Status.Conditions, securityv1.ConditionTypeProgressing); progressing != nil && progressing.Reason == "Paused" {
+		meta.RemoveStatusCondition(&policy.Status.Conditions, securityv1.ConditionTypeProgressing)
+	}
+	policy.Status.Paused = false
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Paused policies", func() {
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "paused", Namespace: "default"}
+
+	It("should skip reconciliation without requeueing", func() {
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace,
+				Annotations: map[string]string{securityv1.AnnotationPaused: "true"}},
+			Spec: securityv1.ImagePolicySpec{Repository: "jonlimpw/cg-demo"},
+		}
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(policy).WithStatusSubresource(policy).Build()
+		reconciler := &ImagePolicyReconciler{Client: fakeClient}
+
+		result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(ctrl.Result{}))
+
+		updated := &securityv1.ImagePolicy{}
+		Expect(fakeClient.Get(ctx, key, updated)).To(Succeed())
+		Expect(updated.Status.Paused).To(BeTrue())
+		Expect(updated.Status.LastChecked).To(BeNil(), "digests must not be fetched while paused")
+		progressing := meta.FindStatusCondition(updated.Status.Conditions, securityv1.ConditionTypeProgressing)
+		Expect(progressing).NotTo(BeNil())
+		Expect(progressing.Status).To(Equal(metav1.ConditionFalse))
+		Expect(progressing.Reason).To(Equal("Paused"))
+
+		// Further reconciles while paused don't rewrite the status
+		resourceVersion := updated.ResourceVersion
+		_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeClient.Get(ctx, key, updated)).To(Succeed())
+		Expect(updated.ResourceVersion).To(Equal(resourceVersion))
+	})
+
+	It("should clear the paused state on resume", func() {
+		policy := &securityv1.ImagePolicy{}
+		policy.Status.Paused = true
+		meta.SetStatusCondition(&policy.Status.Conditions, metav1.Condition{
+			Type: securityv1.ConditionTypeProgressing, Status: metav1.ConditionFalse, Reason: "Paused"})
+
+		Expect(isPaused(policy)).To(BeFalse())
+		resumeImagePolicy(policy)
+		Expect(policy.Status.Paused).To(BeFalse())
+		Expect(meta.FindStatusCondition(policy.Status.Conditions, securityv1.ConditionTypeProgressing)).To(BeNil())
+	})
+})
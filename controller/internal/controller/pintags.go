@@ -0,0 +1,58 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+// pinsResolvedTags checks if tag-based images are pinned to the digest their own tag resolves to, which
+// needs the tags resolved in the registry. When enforcing the latest digest they are pinned to it instead.
+func pinsResolvedTags(rules complianceRules) bool {
+	return rules.PinTags && !rules.EnforceLatest
+}
+
+// pinnedDigest returns the digest a tag-based image of a repository is pinned to, "" if it isn't pinned
+// or its tag couldn't be resolved
+func pinnedDigest(image, repository, latestDigest string, rules complianceRules) string {
+	switch {
+	case !rules.PinTags:
+		return ""
+	case rules.EnforceLatest:
+		return latestDigest
+	default:
+		return rules.TagDigests[repository+":"+imageTag(image)]
+	}
+}
+
+// remediationDigest returns the digest a workload is remediated to: the digest its tag is pinned to, "" if
+// the tag couldn't be resolved, or else the latest digest of the repository it is reported for
+func remediationDigest(rules complianceRules, status *securityv1.DeploymentStatus, latestDigests map[string]string) string {
+	if status.Reason == securityv1.NonComplianceReasonTagBased && pinsResolvedTags(rules) {
+		return status.PinnedDigest
+	}
+	return latestDigests[status.Repository]
+}
+
+// pinsTagsOf checks if remediation pins the tag-based images of a workload in place: the policy mutates tags
+// to digests in Auto mode without GitOps, and the workload passes the AutomationGate and isn't an immutable Job
+func (r *ImagePolicyReconciler) pinsTagsOf(policy *securityv1.ImagePolicy, deployment workload) bool {
+	mode := policy.Spec.RemediationMode
+	return r.rulesFor(policy).PinTags && (mode == "" || mode == securityv1.RemediationModeAuto) &&
+		policy.Spec.RemediationStrategy != securityv1.RemediationStrategyGitOps &&
+		deployment.Kind != securityv1.WorkloadKindJob && r.hasAutomationEnabled(policy, deployment)
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+	"github.com/jonlimpw/chainguard-controller/internal/registry/registrytest"
+)
+
+var _ = Describe("Mutating tags to digests", func() {
+	const repository = "jonlimpw/cg-demo"
+
+	ctx := context.Background()
+	var fakeRegistry *registrytest.Registry
+	var recorder *record.FakeRecorder
+	var reconciler *ImagePolicyReconciler
+	var policy *securityv1.ImagePolicy
+	var deployment workload
+	var latestDigest, tagDigest string
+
+	BeforeEach(func() {
+		fakeRegistry = registrytest.NewRegistry()
+		DeferCleanup(fakeRegistry.Close)
+		latestDigest = fakeRegistry.PutImage(repository, "latest")
+		tagDigest = fakeRegistry.PutImage(repository, "1.2")
+
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &ImagePolicyReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default", Labels: map[string]string{"automation": "true"}},
+				Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "app", Image: repository + ":1.2"}},
+				}}},
+			}).Build(),
+			Recorder:             recorder,
+			DockerHubRegistryURL: fakeRegistry.URL,
+			DockerHubAuthURL:     fakeRegistry.URL,
+		}
+		policy = &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+			Spec: securityv1.ImagePolicySpec{
+				Repository:          repository,
+				EnforceLatestDigest: ptr.To(false),
+				MutateTagsToDigests: ptr.To(true),
+			},
+		}
+
+		workloads, err := reconciler.listWorkloads(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(workloads).To(HaveLen(1))
+		deployment = workloads[0]
+	})
+
+	// rulesOf returns the rules a reconcile analyzes and remediates the deployment with, its tags resolved
+	rulesOf := func() complianceRules {
+		rules := reconciler.rulesFor(policy)
+		deployments := []workload{deployment}
+		rules.TagDigests = reconciler.resolveTagDigests(ctx, policy, deployments, monitoredRepositories(policy, deployments), time.Minute)
+		return rules
+	}
+
+	// analyze returns the status of the deployment with its tags resolved the way a reconcile does
+	analyze := func() *securityv1.DeploymentStatus {
+		status := reconciler.analyzeDeploymentCompliance(ctx, deployment, repository, latestDigest, rulesOf())
+		return &status
+	}
+
+	// deployedImage returns the image the deployment currently runs
+	deployedImage := func() string {
+		current := &appsv1.Deployment{}
+		Expect(reconciler.Get(ctx, types.NamespacedName{Name: "demo", Namespace: "default"}, current)).To(Succeed())
+		return current.Spec.Template.Spec.Containers[0].Image
+	}
+
+	It("should leave tags compliant unless the policy mutates them", func() {
+		policy.Spec.MutateTagsToDigests = nil
+		status := analyze()
+		Expect(status.IsCompliant).To(BeTrue())
+		Expect(status.PinnedDigest).To(BeEmpty())
+	})
+
+	It("should report the digest a tag is pinned to", func() {
+		status := analyze()
+		Expect(status.IsCompliant).To(BeFalse())
+		Expect(status.Reason).To(Equal(securityv1.NonComplianceReasonTagBased))
+		Expect(status.PinnedDigest).To(Equal(tagDigest))
+
+		policy.Spec.EnforceLatestDigest = nil
+		Expect(analyze().PinnedDigest).To(Equal(latestDigest))
+	})
+
+	It("should pin a tag to the digest it resolves to", func() {
+		status := analyze()
+		reconciler.handleRemediation(ctx, policy, deployment, status, rulesOf(), map[string]string{repository: latestDigest}, securityv1.RemediationModeAuto, nil)
+		Expect(recorder.Events).To(Receive(ContainSubstring("AutoRemediated")))
+
+		Expect(deployedImage()).To(Equal(repository + "@" + tagDigest))
+		Expect(policy.Status.RemediationHistory).To(HaveLen(1))
+		Expect(policy.Status.RemediationHistory[0].OldDigest).To(Equal("tag-based"))
+		Expect(policy.Status.RemediationHistory[0].NewDigest).To(Equal(tagDigest))
+	})
+
+	It("should only propose the pinned digest in Audit mode", func() {
+		status := analyze()
+		reconciler.handleRemediation(ctx, policy, deployment, status, rulesOf(), map[string]string{repository: latestDigest}, securityv1.RemediationModeAudit, nil)
+		Expect(recorder.Events).To(Receive(ContainSubstring("Would remediate Deployment default/demo to use digest " + tagDigest)))
+		Expect(status.ProposedDigest).To(Equal(tagDigest))
+		Expect(deployedImage()).To(Equal(repository + ":1.2"))
+	})
+
+	It("should not pin a tag that can't be resolved", func() {
+		fakeRegistry.FailManifest(repository, "1.2", registrytest.Failure{StatusCode: 404})
+		reconciler.DockerHubMaxRetries = 1
+		status := analyze()
+		Expect(status.Reason).To(Equal(securityv1.NonComplianceReasonTagBased))
+		Expect(status.PinnedDigest).To(BeEmpty())
+
+		reconciler.handleRemediation(ctx, policy, deployment, status, rulesOf(), map[string]string{repository: latestDigest}, securityv1.RemediationModeAuto, nil)
+		Expect(recorder.Events).NotTo(Receive())
+		Expect(deployedImage()).To(Equal(repository + ":1.2"))
+	})
+
+	It("should admit tags only when they will be pinned in place", func() {
+		Expect(reconciler.pinsTagsOf(policy, deployment)).To(BeTrue())
+
+		policy.Spec.RemediationMode = securityv1.RemediationModeAudit
+		Expect(reconciler.pinsTagsOf(policy, deployment)).To(BeFalse())
+
+		policy.Spec.RemediationMode = ""
+		deployment.GetLabels()["automation"] = "false"
+		Expect(reconciler.pinsTagsOf(policy, deployment)).To(BeFalse())
+	})
+})
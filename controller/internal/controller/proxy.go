@@ -0,0 +1,65 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+// registryTimeout bounds each registry request
+const registryTimeout = 30 * time.Second
+
+// proxyTransports holds one transport per registry proxy, so connections through it are reused across requests
+var proxyTransports sync.Map
+
+// registryProxy returns the HTTP(S) proxy a policy reaches registries through: its own RegistryProxy,
+// else the manager's, or an empty string to use the HTTPS_PROXY environment
+func (r *ImagePolicyReconciler) registryProxy(policy *securityv1.ImagePolicy) string {
+	if policy.Spec.RegistryProxy != "" {
+		return policy.Spec.RegistryProxy
+	}
+	return r.RegistryProxy
+}
+
+// registryHTTPClient returns the client for registry requests through proxy. Hosts in NO_PROXY (e.g. an
+// in-cluster mirror) are reached directly; without a proxy the HTTPS_PROXY and HTTP_PROXY environment applies.
+func registryHTTPClient(proxy string) *http.Client {
+	if proxy == "" {
+		return &http.Client{Timeout: registryTimeout}
+	}
+
+	if transport, ok := proxyTransports.Load(proxy); ok {
+		return &http.Client{Timeout: registryTimeout, Transport: transport.(http.RoundTripper)}
+	}
+	config := httpproxy.FromEnvironment()
+	config.HTTPProxy, config.HTTPSProxy = proxy, proxy
+	proxyFunc := config.ProxyFunc()
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+	actual, _ := proxyTransports.LoadOrStore(proxy, transport)
+	return &http.Client{Timeout: registryTimeout, Transport: actual.(http.RoundTripper)}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Registry proxies", func() {
+	It("should send token and manifest requests through the proxy", func() {
+		// DockerHub is given an unresolvable host, so only the proxy can answer for it
+		var proxied []string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			proxied = append(proxied, req.URL.Host+req.URL.Path)
+			switch req.URL.Path {
+			case "/token":
+				_, _ = w.Write([]byte(`{"token":"test"}`))
+			case "/v2/chainguard/nginx/manifests/latest":
+				w.Header().Set("Docker-Content-Digest", "sha256:5555555555555555555555555555555555555555555555555555555555555555")
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		previousRegistryURL, previousAuthURL := dockerHubRegistryURL, dockerHubAuthURL
+		dockerHubRegistryURL, dockerHubAuthURL = "http://registry.proxy-test.invalid", "http://auth.proxy-test.invalid"
+		DeferCleanup(func() {
+			proxy.Close()
+			dockerHubRegistryURL, dockerHubAuthURL = previousRegistryURL, previousAuthURL
+		})
+
+		reconciler := &ImagePolicyReconciler{RegistryProxy: proxy.URL}
+		policy := &securityv1.ImagePolicy{}
+		digest, err := reconciler.fetchDigestFromDockerHub(context.Background(), digestRequest{
+			Repository: "chainguard/nginx",
+			Tag:        "latest",
+			Proxy:      reconciler.registryProxy(policy),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(digest).To(Equal("sha256:5555555555555555555555555555555555555555555555555555555555555555"))
+		Expect(proxied).To(Equal([]string{
+			"auth.proxy-test.invalid/token",
+			"registry.proxy-test.invalid/v2/chainguard/nginx/manifests/latest",
+		}))
+	})
+
+	It("should prefer the policy's proxy over the manager's", func() {
+		reconciler := &ImagePolicyReconciler{RegistryProxy: "http://manager-proxy:3128"}
+		Expect(reconciler.registryProxy(&securityv1.ImagePolicy{})).To(Equal("http://manager-proxy:3128"))
+		Expect(reconciler.registryProxy(&securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{RegistryProxy: "http://policy-proxy:3128"}})).
+			To(Equal("http://policy-proxy:3128"))
+	})
+
+	It("should reach NO_PROXY hosts directly", func() {
+		previous, set := os.LookupEnv("NO_PROXY")
+		Expect(os.Setenv("NO_PROXY", ".svc.cluster.local")).To(Succeed())
+		DeferCleanup(func() {
+			if set {
+				_ = os.Setenv("NO_PROXY", previous)
+			} else {
+				_ = os.Unsetenv("NO_PROXY")
+			}
+		})
+
+		transport := registryHTTPClient("http://no-proxy-test:3128").Transport.(*http.Transport)
+		direct, err := http.NewRequest("GET", "http://mirror.registry.svc.cluster.local/v2/", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(transport.Proxy(direct)).To(BeNil())
+
+		proxied, err := http.NewRequest("GET", "https://registry-1.docker.io/v2/", nil)
+		Expect(err).NotTo(HaveOccurred())
+		proxyURL, err := transport.Proxy(proxied)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(proxyURL.String()).To(Equal("http://no-proxy-test:3128"))
+	})
+
+	It("should reuse the transport of a proxy", func() {
+		Expect(registryHTTPClient("http://reused-proxy:3128").Transport).To(BeIdenticalTo(registryHTTPClient("http://reused-proxy:3128").Transport))
+		Expect(registryHTTPClient("").Transport).To(BeNil())
+	})
+})
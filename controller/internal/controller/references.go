@@ -0,0 +1,101 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+// errForeignNamespace is returned when a policy references a Secret or ConfigMap in another namespace
+var errForeignNamespace = errors.New("must be in the ImagePolicy's namespace")
+
+// referenceNamespace returns the namespace of a Secret or ConfigMap a policy references, the policy's own when
+// unset. Other namespaces are rejected: the controller can read every namespace's Secrets, so honouring them
+// would let anyone who can create an ImagePolicy use another namespace's credentials
+func referenceNamespace(policy *securityv1.ImagePolicy, namespace string) (string, error) {
+	if namespace == "" || namespace == policy.Namespace {
+		return policy.Namespace, nil
+	}
+	return "", fmt.Errorf("%w %s, not %s", errForeignNamespace, policy.Namespace, namespace)
+}
+
+// foreignReferences returns the errors of the Secret and ConfigMap references of a policy naming another namespace
+func foreignReferences(policy *securityv1.ImagePolicy) field.ErrorList {
+	spec := field.NewPath("spec")
+	var errs field.ErrorList
+	check := func(path *field.Path, namespace string) {
+		if _, err := referenceNamespace(policy, namespace); err != nil {
+			errs = append(errs, field.Invalid(path.Child("namespace"), namespace, err.Error()))
+		}
+	}
+
+	if ref := policy.Spec.PullSecretRef; ref != nil {
+		check(spec.Child("pullSecretRef"), ref.Namespace)
+	}
+	if ref := policy.Spec.GitRepoRef; ref != nil {
+		check(spec.Child("gitRepoRef", "secretRef"), ref.SecretRef.Namespace)
+	}
+	if ref := policy.Spec.GoldenDigestRef; ref != nil {
+		check(spec.Child("goldenDigestRef"), ref.Namespace)
+	}
+	if config := policy.Spec.NotificationConfig; config != nil {
+		check(spec.Child("notificationConfig", "secretRef"), config.SecretRef.Namespace)
+	}
+	if attestationPolicy := policy.Spec.AttestationPolicy; attestationPolicy != nil {
+		if ref := attestationPolicy.FulcioRootRef; ref != nil {
+			check(spec.Child("attestationPolicy", "fulcioRootRef"), ref.Namespace)
+		}
+		if ref := attestationPolicy.RekorPublicKeyRef; ref != nil {
+			check(spec.Child("attestationPolicy", "rekorPublicKeyRef"), ref.Namespace)
+		}
+		if mirror := attestationPolicy.TUFMirror; mirror != nil {
+			check(spec.Child("attestationPolicy", "tufMirror", "rootRef"), mirror.RootRef.Namespace)
+		}
+	}
+	return errs
+}
+
+// withAttestationNamespace returns the attestation policy with its FulcioRootRef, RekorPublicKeyRef and TUF mirror
+// RootRef in the ImagePolicy namespace, copying it rather than modifying the spec. A reference to another namespace
+// is reported by the reconcile and never followed there.
+func withAttestationNamespace(policy *securityv1.AttestationPolicy, namespace string) *securityv1.AttestationPolicy {
+	if policy == nil {
+		return nil
+	}
+	fulcioRoot, rekorPublicKey, tufMirror := policy.FulcioRootRef, policy.RekorPublicKeyRef, policy.TUFMirror
+	if (fulcioRoot == nil || fulcioRoot.Namespace == namespace) && (rekorPublicKey == nil || rekorPublicKey.Namespace == namespace) &&
+		(tufMirror == nil || tufMirror.RootRef.Namespace == namespace) {
+		return policy
+	}
+
+	copied := policy.DeepCopy()
+	if copied.FulcioRootRef != nil {
+		copied.FulcioRootRef.Namespace = namespace
+	}
+	if copied.RekorPublicKeyRef != nil {
+		copied.RekorPublicKeyRef.Namespace = namespace
+	}
+	if copied.TUFMirror != nil {
+		copied.TUFMirror.RootRef.Namespace = namespace
+	}
+	return copied
+}
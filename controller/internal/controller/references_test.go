@@ -0,0 +1,120 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Secret references", func() {
+	ctx := context.Background()
+
+	// foreignSecret is a Secret of another namespace holding every key the policy loaders read
+	foreignSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "platform"},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: []byte(`{"auths":{"https://index.docker.io/v1/":{"username":"platform","password":"secret"}}}`),
+			"token":                    []byte("ghp_platform"),
+			"address":                  []byte("https://hooks.slack.com/services/platform"),
+		},
+	}
+
+	It("should default to the policy's namespace and reject others", func() {
+		policy := &securityv1.ImagePolicy{ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "team-a"}}
+		Expect(referenceNamespace(policy, "")).To(Equal("team-a"))
+		Expect(referenceNamespace(policy, "team-a")).To(Equal("team-a"))
+
+		_, err := referenceNamespace(policy, "platform")
+		Expect(err).To(MatchError(errForeignNamespace))
+		Expect(err).To(MatchError(ContainSubstring("namespace team-a, not platform")))
+	})
+
+	It("should never read the Secrets of another namespace", func() {
+		reconciler := &ImagePolicyReconciler{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(foreignSecret).Build()}
+		ref := corev1.SecretReference{Name: "shared", Namespace: "platform"}
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "team-a"},
+			Spec: securityv1.ImagePolicySpec{
+				Repository:         "jonlimpw/cg-demo",
+				PullSecretRef:      &ref,
+				GitRepoRef:         &securityv1.GitRepoRef{URL: "https://github.com/jonlimpw/deploy", SecretRef: ref},
+				NotificationConfig: &securityv1.NotificationConfig{SecretRef: ref},
+			},
+		}
+
+		credentials, err := reconciler.loadRegistryCredentials(ctx, policy)
+		Expect(err).To(MatchError(errForeignNamespace))
+		Expect(credentials).To(BeNil())
+
+		token, err := reconciler.loadGitToken(ctx, policy)
+		Expect(err).To(MatchError(errForeignNamespace))
+		Expect(token).To(BeEmpty())
+
+		address, err := reconciler.loadNotificationAddress(ctx, policy)
+		Expect(err).To(MatchError(errForeignNamespace))
+		Expect(address).To(BeEmpty())
+	})
+
+	It("should never read the golden digests of another namespace", func() {
+		foreignConfigMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "promoted", Namespace: "platform"},
+			Data:       map[string]string{defaultGoldenDigestKey: "sha256:" + strings.Repeat("1", 64)},
+		}
+		reconciler := &ImagePolicyReconciler{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(foreignConfigMap).Build()}
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "team-a"},
+			Spec: securityv1.ImagePolicySpec{
+				Repository:      "jonlimpw/cg-demo",
+				GoldenDigestRef: &securityv1.GoldenDigestRef{Name: "promoted", Namespace: "platform"},
+			},
+		}
+
+		digests, err := reconciler.loadGoldenDigests(ctx, policy, []string{"jonlimpw/cg-demo"})
+		Expect(err).To(MatchError(errForeignNamespace))
+		Expect(digests).To(BeNil())
+		Expect(foreignReferences(policy)).To(ConsistOf(HaveField("Field", "spec.goldenDigestRef.namespace")))
+	})
+
+	It("should only read attestation references from the ImagePolicy's namespace without changing the spec", func() {
+		policy := &securityv1.AttestationPolicy{
+			FulcioRootRef:     &securityv1.FulcioRootRef{Name: "fulcio-root"},
+			RekorPublicKeyRef: &securityv1.RekorPublicKeyRef{Name: "rekor-key", Namespace: "sigstore-system"},
+			TUFMirror:         &securityv1.TUFMirror{URL: "https://tuf.sigstore.internal", RootRef: securityv1.TUFRootRef{Name: "tuf-root", Namespace: "sigstore-system"}},
+		}
+		copied := withAttestationNamespace(policy, "team-a")
+		Expect(copied.FulcioRootRef.Namespace).To(Equal("team-a"))
+		Expect(copied.RekorPublicKeyRef.Namespace).To(Equal("team-a"))
+		Expect(copied.TUFMirror.RootRef.Namespace).To(Equal("team-a"))
+		Expect(policy.FulcioRootRef.Namespace).To(BeEmpty())
+		Expect(policy.RekorPublicKeyRef.Namespace).To(Equal("sigstore-system"))
+		Expect(policy.TUFMirror.RootRef.Namespace).To(Equal("sigstore-system"))
+
+		Expect(withAttestationNamespace(copied, "team-a")).To(BeIdenticalTo(copied))
+		Expect(withAttestationNamespace(nil, "team-a")).To(BeNil())
+	})
+})
@@ -0,0 +1,386 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	"github.com/jonlimpw/chainguard-controller/internal/registry"
+	"github.com/jonlimpw/chainguard-controller/internal/rekor"
+)
+
+// OCI artifact (media) types of attestations attached to an image as referrers
+const (
+	artifactTypeSigstoreBundle = "application/vnd.dev.sigstore.bundle.v0.3+json"
+	artifactTypeDSSEEnvelope   = "application/vnd.dsse.envelope.v1+json"
+)
+
+// annotationCosignCertificate carries the PEM signing certificate on cosign DSSE attestation layers
+const annotationCosignCertificate = "dev.sigstore.cosign/certificate"
+
+// errNoReferrers is returned when the registry lists no attestation referrers for a digest
+var errNoReferrers = errors.New("no attestation referrers found")
+
+// maxReferrersToInspect bounds how many attestation referrers are fetched per digest
+const maxReferrersToInspect = 20
+
+// maxReferrerContentSize bounds the size of a referrer manifest or attestation blob
+const maxReferrerContentSize = 4 << 20
+
+// inTotoPayloadType is the DSSE payload type of in-toto statements
+const inTotoPayloadType = "application/vnd.in-toto+json"
+
+// ociDescriptor is the subset of an OCI content descriptor needed to find attestations
+type ociDescriptor struct {
+	MediaType    string            `json:"mediaType"`
+	ArtifactType string            `json:"artifactType,omitempty"`
+	Digest       string            `json:"digest"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+}
+
+// ociReferrersIndex is the OCI image index returned by the referrers API
+type ociReferrersIndex struct {
+	Manifests []ociDescriptor `json:"manifests"`
+}
+
+// ociArtifactManifest is an OCI image manifest wrapping an attestation
+type ociArtifactManifest struct {
+	ArtifactType string          `json:"artifactType,omitempty"`
+	Config       ociDescriptor   `json:"config"`
+	Layers       []ociDescriptor `json:"layers"`
+}
+
+// dsseEnvelope is a DSSE envelope whose payload is an in-toto statement
+type dsseEnvelope struct {
+	PayloadType string          `json:"payloadType"`
+	Payload     string          `json:"payload"`
+	Signatures  []dsseSignature `json:"signatures"`
+}
+
+// dsseSignature is a signature over the pre-authentication encoding of a DSSE envelope
+type dsseSignature struct {
+	KeyID string `json:"keyid,omitempty"`
+	Sig   string `json:"sig"`
+}
+
+// sigstoreBundle is the subset of a Sigstore bundle needed to evaluate its attestation
+type sigstoreBundle struct {
+	VerificationMaterial struct {
+		Certificate *struct {
+			RawBytes string `json:"rawBytes"`
+		} `json:"certificate,omitempty"`
+		X509CertificateChain *struct {
+			Certificates []struct {
+				RawBytes string `json:"rawBytes"`
+			} `json:"certificates"`
+		} `json:"x509CertificateChain,omitempty"`
+	} `json:"verificationMaterial"`
+	DSSEEnvelope *dsseEnvelope `json:"dsseEnvelope,omitempty"`
+}
+
+// referrerAttestation is an in-toto statement extracted from a referrer artifact whose signature was verified
+type referrerAttestation struct {
+	Statement   []byte
+	Certificate *x509.Certificate
+}
+
+// isAttestationArtifact reports whether a referrer's artifact type is a DSSE-wrapped attestation
+func isAttestationArtifact(artifactType string) bool {
+	return artifactType == artifactTypeSigstoreBundle || artifactType == artifactTypeDSSEEnvelope
+}
+
+// verifyReferrerAttestation discovers attestations of an image digest through the registry's OCI referrers API,
+// requested through proxy, and checks them against the policy, returning the first match or the last mismatch
+func (r *ImagePolicyReconciler) verifyReferrerAttestation(ctx context.Context, repository, imageDigest string, allowedIssuers []string, requiredTypes []string, notBefore time.Time, minSLSALevel int32, fulcioRoot *rekor.TrustRoot, expectedSubject *rekor.ExpectedSubject, expectedIdentity *rekor.ExpectedIdentity, proxy string) (*rekor.AttestationResult, error) {
+	token, err := r.fetchDockerHubToken(ctx, repository, nil, proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	index := ociReferrersIndex{}
+	referrersURL := fmt.Sprintf("%s/v2/%s/referrers/%s", r.registryURL(), dockerHubRepository(repository), imageDigest)
+	if err := fetchRegistryJSON(ctx, referrersURL, token, mediaTypeOCIImageIndex, proxy, &index); err != nil {
+		return nil, fmt.Errorf("failed to list referrers: %w", err)
+	}
+
+	// The registry may ignore the artifactType filter, so filter here
+	var referrers []ociDescriptor
+	for _, referrer := range index.Manifests {
+		if isAttestationArtifact(referrer.ArtifactType) {
+			referrers = append(referrers, referrer)
+		}
+	}
+	if len(referrers) > maxReferrersToInspect {
+		referrers = referrers[:maxReferrersToInspect]
+	}
+
+	var lastResult *rekor.AttestationResult
+	for _, referrer := range referrers {
+		manifest := ociArtifactManifest{}
+		manifestURL := fmt.Sprintf("%s/v2/%s/manifests/%s", r.registryURL(), dockerHubRepository(repository), referrer.Digest)
+		content, err := fetchRegistryContent(ctx, manifestURL, token, mediaTypeOCIImageManifest, proxy, referrer.Digest)
+		if err != nil {
+			if errors.Is(err, errContentDigestMismatch) {
+				lastResult = &rekor.AttestationResult{Error: fmt.Sprintf("referrer %s: %v", referrer.Digest, err)}
+				continue
+			}
+			return nil, fmt.Errorf("failed to get referrer %s: %w", referrer.Digest, err)
+		}
+		if err := json.Unmarshal(content, &manifest); err != nil {
+			lastResult = &rekor.AttestationResult{Error: fmt.Sprintf("failed to decode referrer %s: %v", referrer.Digest, err)}
+			continue
+		}
+
+		for _, layer := range manifest.Layers {
+			if !isAttestationArtifact(layer.MediaType) {
+				continue
+			}
+
+			blobURL := fmt.Sprintf("%s/v2/%s/blobs/%s", r.registryURL(), dockerHubRepository(repository), layer.Digest)
+			blob, err := fetchRegistryContent(ctx, blobURL, token, layer.MediaType, proxy, layer.Digest)
+			if err != nil {
+				if errors.Is(err, errContentDigestMismatch) {
+					lastResult = &rekor.AttestationResult{Error: fmt.Sprintf("attestation %s of referrer %s: %v", layer.Digest, referrer.Digest, err)}
+					continue
+				}
+				return nil, fmt.Errorf("failed to get attestation %s: %w", layer.Digest, err)
+			}
+
+			attestation, err := parseReferrerAttestation(layer, blob)
+			if err != nil {
+				lastResult = &rekor.AttestationResult{Error: fmt.Sprintf("invalid referrer %s: %v", referrer.Digest, err)}
+				continue
+			}
+
+			// The referrer carries no signing time anyone vouches for, so MaxAge can't be enforced on it
+			result := rekor.EvaluateStatement(attestation.Statement, attestation.Certificate, time.Time{},
+				allowedIssuers, requiredTypes, notBefore, minSLSALevel, fulcioRoot, expectedSubject, expectedIdentity)
+			if result.Verified {
+				return result, nil
+			}
+			lastResult = result
+		}
+	}
+
+	if lastResult == nil {
+		return nil, fmt.Errorf("%w for digest %s", errNoReferrers, imageDigest)
+	}
+	return lastResult, nil
+}
+
+// parseReferrerAttestation extracts the in-toto statement and signing certificate from a Sigstore bundle or
+// cosign DSSE envelope layer, checking the envelope is signed by the certificate's key. The transparency log
+// entry of a bundle isn't verified, so its integrated time isn't used as the signing time.
+func parseReferrerAttestation(layer ociDescriptor, blob []byte) (*referrerAttestation, error) {
+	attestation := &referrerAttestation{}
+	envelope := &dsseEnvelope{}
+
+	switch layer.MediaType {
+	case artifactTypeSigstoreBundle:
+		bundle := sigstoreBundle{}
+		if err := json.Unmarshal(blob, &bundle); err != nil {
+			return nil, fmt.Errorf("failed to decode Sigstore bundle: %w", err)
+		}
+		if bundle.DSSEEnvelope == nil {
+			return nil, fmt.Errorf("Sigstore bundle has no DSSE envelope")
+		}
+		envelope = bundle.DSSEEnvelope
+
+		material := bundle.VerificationMaterial
+		var rawCert string
+		switch {
+		case material.Certificate != nil:
+			rawCert = material.Certificate.RawBytes
+		case material.X509CertificateChain != nil && len(material.X509CertificateChain.Certificates) > 0:
+			rawCert = material.X509CertificateChain.Certificates[0].RawBytes
+		}
+		if rawCert != "" {
+			der, err := base64.StdEncoding.DecodeString(rawCert)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode signing certificate: %w", err)
+			}
+			if attestation.Certificate, err = x509.ParseCertificate(der); err != nil {
+				return nil, fmt.Errorf("failed to parse signing certificate: %w", err)
+			}
+		}
+	case artifactTypeDSSEEnvelope:
+		if err := json.Unmarshal(blob, envelope); err != nil {
+			return nil, fmt.Errorf("failed to decode DSSE envelope: %w", err)
+		}
+
+		if certPEM := layer.Annotations[annotationCosignCertificate]; certPEM != "" {
+			block, _ := pem.Decode([]byte(certPEM))
+			if block == nil || block.Type != "CERTIFICATE" {
+				return nil, fmt.Errorf("invalid %s annotation", annotationCosignCertificate)
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse signing certificate: %w", err)
+			}
+			attestation.Certificate = cert
+		}
+	default:
+		return nil, fmt.Errorf("unsupported attestation media type %s", layer.MediaType)
+	}
+
+	if envelope.PayloadType != inTotoPayloadType {
+		return nil, fmt.Errorf("unexpected DSSE payload type %q", envelope.PayloadType)
+	}
+	statement, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode DSSE payload: %w", err)
+	}
+	if err := verifyEnvelopeSignature(envelope.PayloadType, statement, envelope.Signatures, attestation.Certificate); err != nil {
+		return nil, err
+	}
+	attestation.Statement = statement
+
+	return attestation, nil
+}
+
+// verifyEnvelopeSignature checks that one of a DSSE envelope's signatures over its payload was made with the
+// signing certificate's key. An envelope without a certificate or signatures is rejected, since a certificate
+// alone is public and can be copied from any Rekor entry.
+func verifyEnvelopeSignature(payloadType string, payload []byte, signatures []dsseSignature, cert *x509.Certificate) error {
+	if cert == nil {
+		return errors.New("attestation has no signing certificate")
+	}
+	if len(signatures) == 0 {
+		return errors.New("DSSE envelope is not signed")
+	}
+
+	verifier, err := signature.LoadVerifier(cert.PublicKey, crypto.SHA256)
+	if err != nil {
+		return fmt.Errorf("unsupported signing certificate key: %w", err)
+	}
+	message := dssePreAuthEncoding(payloadType, payload)
+	for _, sig := range signatures {
+		raw, err := base64.StdEncoding.DecodeString(sig.Sig)
+		if err != nil {
+			continue
+		}
+		if verifier.VerifySignature(bytes.NewReader(raw), bytes.NewReader(message)) == nil {
+			return nil
+		}
+	}
+	return errors.New("DSSE envelope signature doesn't verify against the signing certificate")
+}
+
+// dssePreAuthEncoding returns the DSSE pre-authentication encoding of a payload, the message its signatures sign
+func dssePreAuthEncoding(payloadType string, payload []byte) []byte {
+	return fmt.Appendf(nil, "DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload)
+}
+
+// errContentDigestMismatch is returned when registry content doesn't hash to the digest it was requested by
+var errContentDigestMismatch = errors.New("content doesn't match its digest")
+
+// verifyContentDigest checks that content hashes to a sha256 or sha512 digest
+func verifyContentDigest(content []byte, digest string) error {
+	algorithm, expected, err := registry.ParseDigest(digest)
+	if err != nil {
+		return err
+	}
+
+	var hasher hash.Hash
+	switch algorithm {
+	case "sha512":
+		hasher = sha512.New()
+	default:
+		hasher = sha256.New()
+	}
+	hasher.Write(content)
+	if actual := hex.EncodeToString(hasher.Sum(nil)); actual != expected {
+		return fmt.Errorf("%w %s: got %s:%s", errContentDigestMismatch, digest, algorithm, actual)
+	}
+	return nil
+}
+
+// fetchRegistryJSON performs an authenticated registry GET through proxy and decodes the JSON response into out
+func fetchRegistryJSON(ctx context.Context, registryURL, token, accept, proxy string, out interface{}) error {
+	resp, err := getRegistry(ctx, registryURL, token, accept, proxy)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxReferrerContentSize)).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// fetchRegistryContent performs an authenticated registry GET through proxy of content addressed by digest,
+// checking the response hashes to it
+func fetchRegistryContent(ctx context.Context, registryURL, token, accept, proxy, digest string) ([]byte, error) {
+	resp, err := getRegistry(ctx, registryURL, token, accept, proxy)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxReferrerContentSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if err := verifyContentDigest(content, digest); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// getRegistry performs an authenticated registry GET through proxy, returning the response of a 200
+func getRegistry(ctx context.Context, registryURL, token, accept, proxy string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", registryURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", accept)
+
+	client := registryHTTPClient(proxy)
+	sent := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		observeRegistryRequest(ctx, sent, nil)
+		return nil, err
+	}
+	observeRegistryRequest(ctx, sent, resp)
+
+	if resp.StatusCode != http.StatusOK {
+		err := registry.NewStatusError(resp, "registry")
+		_ = resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
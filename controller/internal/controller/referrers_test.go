@@ -0,0 +1,324 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jonlimpw/chainguard-controller/internal/rekor"
+)
+
+// referrerIssuer is the OIDC issuer recorded in the certificates of attestationSigner
+const referrerIssuer = "https://token.actions.githubusercontent.com"
+
+// testFulcio is the CA issuing attestationSigner certificates, which the suite trusts in place of the
+// public-good Fulcio root (see defaultFulcioRoot)
+var testFulcio = sync.OnceValues(func() (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-fulcio"},
+		NotBefore:             time.Now().Add(-24 * time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	cert, err := x509.ParseCertificate(der)
+	Expect(err).NotTo(HaveOccurred())
+	return cert, key
+})
+
+// testFulcioRoot is the trust root of testFulcio
+var testFulcioRoot = sync.OnceValue(func() *rekor.TrustRoot {
+	cert, _ := testFulcio()
+	trustRoot, err := rekor.ParseTrustRoot(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+	Expect(err).NotTo(HaveOccurred())
+	return trustRoot
+})
+
+// attestationSigner signs DSSE envelopes with a throwaway key and its certificate, which carries the
+// Fulcio OIDC issuer extension and is issued by testFulcio, or self-signed
+type attestationSigner struct {
+	key  *ecdsa.PrivateKey
+	cert []byte
+}
+
+func newAttestationSigner() *attestationSigner {
+	ca, caKey := testFulcio()
+	return issueAttestationSigner(ca, caKey)
+}
+
+// newSelfSignedAttestationSigner returns a signer whose certificate no Fulcio issued
+func newSelfSignedAttestationSigner() *attestationSigner {
+	return issueAttestationSigner(nil, nil)
+}
+
+// issueAttestationSigner returns a signer whose certificate parent issued, or that is self-signed when parent is nil
+func issueAttestationSigner(parent *x509.Certificate, parentKey *ecdsa.PrivateKey) *attestationSigner {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	issuerValue, err := asn1.Marshal(referrerIssuer)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber:    big.NewInt(time.Now().UnixNano()),
+		Subject:         pkix.Name{CommonName: "sigstore"},
+		NotBefore:       time.Now().Add(-time.Hour),
+		NotAfter:        time.Now().Add(time.Hour),
+		ExtraExtensions: []pkix.Extension{{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}, Value: issuerValue}},
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	Expect(err).NotTo(HaveOccurred())
+	return &attestationSigner{key: key, cert: cert}
+}
+
+// certificatePEM returns the signing certificate as the cosign certificate annotation carries it
+func (s *attestationSigner) certificatePEM() string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.cert}))
+}
+
+// envelope returns a DSSE envelope of an in-toto statement signed by the signer's key
+func (s *attestationSigner) envelope(statement string) dsseEnvelope {
+	digest := sha256.Sum256(dssePreAuthEncoding(inTotoPayloadType, []byte(statement)))
+	sig, err := ecdsa.SignASN1(rand.Reader, s.key, digest[:])
+	Expect(err).NotTo(HaveOccurred())
+	return dsseEnvelope{
+		PayloadType: inTotoPayloadType,
+		Payload:     base64.StdEncoding.EncodeToString([]byte(statement)),
+		Signatures:  []dsseSignature{{Sig: base64.StdEncoding.EncodeToString(sig)}},
+	}
+}
+
+// servedReferrer is a cosign DSSE attestation as a registry serves it: the referrer manifest and its layer
+type servedReferrer struct {
+	Manifest       []byte
+	ManifestDigest string
+	Blob           []byte
+	BlobDigest     string
+}
+
+// newServedReferrer returns a cosign DSSE attestation of an in-toto statement signed by a new signer
+func newServedReferrer(statement string) servedReferrer {
+	return newServedReferrerBy(newAttestationSigner(), statement)
+}
+
+// newServedReferrerBy returns a cosign DSSE attestation of an in-toto statement signed by signer
+func newServedReferrerBy(signer *attestationSigner, statement string) servedReferrer {
+	blob, err := json.Marshal(signer.envelope(statement))
+	Expect(err).NotTo(HaveOccurred())
+	served := servedReferrer{Blob: blob, BlobDigest: fmt.Sprintf("sha256:%x", sha256.Sum256(blob))}
+
+	served.Manifest, err = json.Marshal(ociArtifactManifest{Layers: []ociDescriptor{{
+		MediaType:   artifactTypeDSSEEnvelope,
+		Digest:      served.BlobDigest,
+		Annotations: map[string]string{annotationCosignCertificate: signer.certificatePEM()},
+	}}})
+	Expect(err).NotTo(HaveOccurred())
+	served.ManifestDigest = fmt.Sprintf("sha256:%x", sha256.Sum256(served.Manifest))
+	return served
+}
+
+// Index returns the referrers index listing the attestation
+func (s servedReferrer) Index() []byte {
+	return []byte(`{"manifests":[{"mediaType":"` + mediaTypeOCIImageManifest + `","artifactType":"` +
+		artifactTypeDSSEEnvelope + `","digest":"` + s.ManifestDigest + `"}]}`)
+}
+
+// serve writes the index, manifest or blob of the attestation requested under base, returning false for other paths
+func (s servedReferrer) serve(w http.ResponseWriter, r *http.Request, base, imageDigest string) bool {
+	switch r.URL.Path {
+	case base + "/referrers/" + imageDigest:
+		_, _ = w.Write(s.Index())
+	case base + "/manifests/" + s.ManifestDigest:
+		_, _ = w.Write(s.Manifest)
+	case base + "/blobs/" + s.BlobDigest:
+		_, _ = w.Write(s.Blob)
+	default:
+		return false
+	}
+	return true
+}
+
+var _ = Describe("OCI referrer attestations", func() {
+	statement := `{"_type":"https://in-toto.io/Statement/v1","predicateType":"https://slsa.dev/provenance/v1","predicate":{}}`
+
+	It("should only treat DSSE-wrapped artifacts as attestations", func() {
+		Expect(isAttestationArtifact(artifactTypeSigstoreBundle)).To(BeTrue())
+		Expect(isAttestationArtifact(artifactTypeDSSEEnvelope)).To(BeTrue())
+		Expect(isAttestationArtifact("application/vnd.dev.cosign.artifact.sig.v1+json")).To(BeFalse())
+		Expect(isAttestationArtifact("")).To(BeFalse())
+	})
+
+	It("should extract the statement and certificate from a Sigstore bundle without trusting its log entry", func() {
+		signer := newAttestationSigner()
+		envelope := signer.envelope(statement)
+		bundle := sigstoreBundle{DSSEEnvelope: &envelope}
+		bundle.VerificationMaterial.Certificate = &struct {
+			RawBytes string `json:"rawBytes"`
+		}{RawBytes: base64.StdEncoding.EncodeToString(signer.cert)}
+		blob, err := json.Marshal(bundle)
+		Expect(err).NotTo(HaveOccurred())
+
+		// An unverified tlog entry doesn't make the attestation recent enough for a MaxAge
+		var withEntry map[string]any
+		Expect(json.Unmarshal(blob, &withEntry)).To(Succeed())
+		withEntry["verificationMaterial"].(map[string]any)["tlogEntries"] = []map[string]string{{"logIndex": "42", "integratedTime": fmt.Sprint(time.Now().Unix())}}
+		blob, err = json.Marshal(withEntry)
+		Expect(err).NotTo(HaveOccurred())
+
+		attestation, err := parseReferrerAttestation(ociDescriptor{MediaType: artifactTypeSigstoreBundle}, blob)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(attestation.Statement)).To(Equal(statement))
+		Expect(attestation.Certificate).NotTo(BeNil())
+
+		result := rekor.EvaluateStatement(attestation.Statement, attestation.Certificate, time.Time{},
+			[]string{referrerIssuer}, []string{"slsaprovenance1"}, time.Time{}, 0, testFulcioRoot(), nil, nil)
+		Expect(result.Verified).To(BeTrue())
+		Expect(result.Issuer).To(Equal(referrerIssuer))
+
+		result = rekor.EvaluateStatement(attestation.Statement, attestation.Certificate, time.Time{},
+			[]string{referrerIssuer}, nil, time.Now().Add(-time.Hour), 0, testFulcioRoot(), nil, nil)
+		Expect(result.Verified).To(BeFalse())
+		Expect(result.Error).To(ContainSubstring("no signing time"))
+	})
+
+	It("should read the signing certificate of a cosign DSSE layer from its annotation", func() {
+		signer := newAttestationSigner()
+		blob, err := json.Marshal(signer.envelope(statement))
+		Expect(err).NotTo(HaveOccurred())
+		layer := ociDescriptor{
+			MediaType:   artifactTypeDSSEEnvelope,
+			Annotations: map[string]string{annotationCosignCertificate: signer.certificatePEM()},
+		}
+
+		attestation, err := parseReferrerAttestation(layer, blob)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(attestation.Statement)).To(Equal(statement))
+		Expect(attestation.Certificate).NotTo(BeNil())
+	})
+
+	It("should reject envelopes that don't carry an in-toto statement", func() {
+		signer := newAttestationSigner()
+		envelope := signer.envelope(statement)
+		envelope.PayloadType = "text/plain"
+		blob, err := json.Marshal(envelope)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = parseReferrerAttestation(ociDescriptor{
+			MediaType:   artifactTypeDSSEEnvelope,
+			Annotations: map[string]string{annotationCosignCertificate: signer.certificatePEM()},
+		}, blob)
+		Expect(err).To(MatchError(ContainSubstring("unexpected DSSE payload type")))
+	})
+
+	It("should reject unsigned envelopes and envelopes without a certificate", func() {
+		signer := newAttestationSigner()
+		envelope := signer.envelope(statement)
+		signed, err := json.Marshal(envelope)
+		Expect(err).NotTo(HaveOccurred())
+		envelope.Signatures = nil
+		unsigned, err := json.Marshal(envelope)
+		Expect(err).NotTo(HaveOccurred())
+		layer := ociDescriptor{
+			MediaType:   artifactTypeDSSEEnvelope,
+			Annotations: map[string]string{annotationCosignCertificate: signer.certificatePEM()},
+		}
+
+		_, err = parseReferrerAttestation(layer, unsigned)
+		Expect(err).To(MatchError(ContainSubstring("DSSE envelope is not signed")))
+
+		_, err = parseReferrerAttestation(ociDescriptor{MediaType: artifactTypeDSSEEnvelope}, signed)
+		Expect(err).To(MatchError(ContainSubstring("attestation has no signing certificate")))
+	})
+
+	It("should reject a statement signed by another key than the certificate's", func() {
+		// The certificate is public, e.g. copied from a Rekor entry, but the attacker signs with their own key
+		copied := newAttestationSigner()
+		blob, err := json.Marshal(newAttestationSigner().envelope(statement))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = parseReferrerAttestation(ociDescriptor{
+			MediaType:   artifactTypeDSSEEnvelope,
+			Annotations: map[string]string{annotationCosignCertificate: copied.certificatePEM()},
+		}, blob)
+		Expect(err).To(MatchError(ContainSubstring("signature doesn't verify")))
+
+		// Nor is a signature over another statement reused with the copied certificate
+		envelope := copied.envelope(statement)
+		envelope.Payload = base64.StdEncoding.EncodeToString([]byte(strings.Replace(statement, "provenance/v1", "provenance/v0.2", 1)))
+		blob, err = json.Marshal(envelope)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = parseReferrerAttestation(ociDescriptor{
+			MediaType:   artifactTypeDSSEEnvelope,
+			Annotations: map[string]string{annotationCosignCertificate: copied.certificatePEM()},
+		}, blob)
+		Expect(err).To(MatchError(ContainSubstring("signature doesn't verify")))
+	})
+
+	It("should reject attestation blobs that don't match their digest", func() {
+		const digest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		served := newServedReferrer(statement)
+		served.Blob = bytes.Replace(served.Blob, []byte(`"payloadType"`), []byte(` "payloadType"`), 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/token" {
+				_, _ = w.Write([]byte(`{"token":"test"}`))
+				return
+			}
+			if !served.serve(w, r, "/v2/jonlimpw/cg-demo", digest) {
+				http.NotFound(w, r)
+			}
+		}))
+		DeferCleanup(server.Close)
+		reconciler := &ImagePolicyReconciler{DockerHubRegistryURL: server.URL, DockerHubAuthURL: server.URL}
+
+		result, err := reconciler.verifyReferrerAttestation(context.Background(), "jonlimpw/cg-demo", digest,
+			nil, nil, time.Time{}, 0, nil, nil, nil, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Verified).To(BeFalse())
+		Expect(result.Error).To(ContainSubstring("content doesn't match its digest"))
+
+		Expect(verifyContentDigest(served.Blob, served.BlobDigest)).To(MatchError(errContentDigestMismatch))
+		Expect(verifyContentDigest(served.Manifest, served.ManifestDigest)).To(Succeed())
+	})
+})
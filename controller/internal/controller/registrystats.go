@@ -0,0 +1,92 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+// registryRequestWindow is how long a policy's RegistryRequestCount counts before it restarts
+const registryRequestWindow = time.Hour
+
+// registryRequestStats tallies the DockerHub requests of one reconcile, which may make them concurrently
+type registryRequestStats struct {
+	mu          sync.Mutex
+	count       int32
+	lastLatency time.Duration
+	last429     time.Time
+}
+
+// registryRequestStatsKey is the context key of the registryRequestStats of the reconcile in progress
+type registryRequestStatsKey struct{}
+
+// withRegistryRequestStats returns a context whose DockerHub requests are tallied in stats
+func withRegistryRequestStats(ctx context.Context, stats *registryRequestStats) context.Context {
+	return context.WithValue(ctx, registryRequestStatsKey{}, stats)
+}
+
+// observeRegistryRequest counts a DockerHub request sent at sent by its status code, or as an error
+// without a response, and tallies it for the reconcile in progress, if any
+func observeRegistryRequest(ctx context.Context, sent time.Time, resp *http.Response) {
+	code := "error"
+	if resp != nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	dockerHubRequestsCounter.WithLabelValues(code).Inc()
+
+	stats, ok := ctx.Value(registryRequestStatsKey{}).(*registryRequestStats)
+	if !ok {
+		return
+	}
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	stats.count++
+	stats.lastLatency = time.Since(sent)
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		stats.last429 = time.Now()
+	}
+}
+
+// apply adds the tallied requests to the policy status, restarting the count once the window is over.
+// Policies that never made a request are left without registry request stats.
+func (s *registryRequestStats) apply(status *securityv1.ImagePolicyStatus, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if status.RegistryRequestWindowStart == nil && s.count == 0 {
+		return
+	}
+	if status.RegistryRequestWindowStart == nil || now.Sub(status.RegistryRequestWindowStart.Time) >= registryRequestWindow {
+		status.RegistryRequestWindowStart = &metav1.Time{Time: now}
+		status.RegistryRequestCount = 0
+	}
+	status.RegistryRequestCount += s.count
+	if s.count > 0 {
+		status.RegistryLastLatencyMillis = s.lastLatency.Milliseconds()
+	}
+	if !s.last429.IsZero() {
+		status.RegistryLast429 = &metav1.Time{Time: s.last429}
+	}
+}
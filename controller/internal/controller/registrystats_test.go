@@ -0,0 +1,86 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+	"github.com/jonlimpw/chainguard-controller/internal/registry/registrytest"
+)
+
+var _ = Describe("Registry request stats", func() {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	It("should tally the DockerHub requests made with the reconcile's context", func() {
+		fakeRegistry := registrytest.NewRegistry()
+		DeferCleanup(fakeRegistry.Close)
+		fakeRegistry.PutImage("jonlimpw/cg-demo", "latest")
+		fakeRegistry.FailManifest("jonlimpw/cg-demo", "latest", registrytest.Failure{StatusCode: http.StatusTooManyRequests, RetryAfter: "0"})
+		reconciler := &ImagePolicyReconciler{DockerHubRegistryURL: fakeRegistry.URL, DockerHubAuthURL: fakeRegistry.URL}
+
+		stats := &registryRequestStats{}
+		ctx := withRegistryRequestStats(context.Background(), stats)
+		_, err := reconciler.getLatestDigestFromDockerHub(ctx, digestRequest{Repository: "jonlimpw/cg-demo", Tag: "latest"}, time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats.count).To(BeEquivalentTo(fakeRegistry.TokenRequests() + fakeRegistry.ManifestRequests("jonlimpw/cg-demo", "latest")))
+		Expect(stats.last429).NotTo(BeZero())
+
+		// Cached digests don't make requests
+		_, err = reconciler.getLatestDigestFromDockerHub(ctx, digestRequest{Repository: "jonlimpw/cg-demo", Tag: "latest"}, time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats.count).To(BeEquivalentTo(fakeRegistry.TokenRequests() + fakeRegistry.ManifestRequests("jonlimpw/cg-demo", "latest")))
+	})
+
+	It("should leave policies that never made a request without stats", func() {
+		status := &securityv1.ImagePolicyStatus{}
+		(&registryRequestStats{}).apply(status, now)
+		Expect(status.RegistryRequestWindowStart).To(BeNil())
+		Expect(status.RegistryRequestCount).To(BeZero())
+	})
+
+	It("should add requests to the current window", func() {
+		last429 := now.Add(-time.Second)
+		status := &securityv1.ImagePolicyStatus{
+			RegistryRequestCount:       5,
+			RegistryRequestWindowStart: &metav1.Time{Time: now.Add(-30 * time.Minute)},
+		}
+		(&registryRequestStats{count: 3, lastLatency: 120 * time.Millisecond, last429: last429}).apply(status, now)
+		Expect(status.RegistryRequestCount).To(BeEquivalentTo(8))
+		Expect(status.RegistryRequestWindowStart.Time).To(Equal(now.Add(-30 * time.Minute)))
+		Expect(status.RegistryLastLatencyMillis).To(BeEquivalentTo(120))
+		Expect(status.RegistryLast429.Time).To(Equal(last429))
+	})
+
+	It("should restart the count once the window is over", func() {
+		status := &securityv1.ImagePolicyStatus{
+			RegistryRequestCount:       40,
+			RegistryRequestWindowStart: &metav1.Time{Time: now.Add(-registryRequestWindow)},
+			RegistryLastLatencyMillis:  75,
+		}
+		(&registryRequestStats{}).apply(status, now)
+		Expect(status.RegistryRequestCount).To(BeZero())
+		Expect(status.RegistryRequestWindowStart.Time).To(Equal(now))
+		Expect(status.RegistryLastLatencyMillis).To(BeEquivalentTo(75))
+	})
+})
@@ -0,0 +1,163 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+	"github.com/jonlimpw/chainguard-controller/internal/rekor"
+)
+
+const (
+	// rekorInitBackoffBase and rekorInitBackoffCap bound the delay between Rekor client creation attempts
+	rekorInitBackoffBase = 5 * time.Second
+	rekorInitBackoffCap  = 5 * time.Minute
+
+	// defaultRekorPublicKeyKey is the data key read when a RekorPublicKeyRef doesn't set one, the name of the
+	// Rekor key target in Sigstore's TUF repository
+	defaultRekorPublicKeyKey = "rekor.pub"
+)
+
+// errRekorNotInitialized is returned for policies using the controller's Rekor client while it couldn't be created
+var errRekorNotInitialized = errors.New("the Rekor client is not initialized")
+
+// RetryRekorClient records that the controller's Rekor client failed to initialize at startup and returns
+// a runnable that keeps creating it in the background, so attestation verification resumes without a restart
+func (r *ImagePolicyReconciler) RetryRekorClient(rekorURL string, publicKey []byte, err error) manager.Runnable {
+	r.setRekorClient(nil, err)
+	return &rekorClientInitializer{
+		reconciler: r,
+		rekorURL:   rekorURL,
+		publicKey:  publicKey,
+		newClient:  rekor.NewClient,
+		backoff:    rekorInitBackoffBase,
+	}
+}
+
+// rekorClientInitializer creates the controller's Rekor client with exponential backoff
+type rekorClientInitializer struct {
+	reconciler *ImagePolicyReconciler
+	rekorURL   string
+	publicKey  []byte
+	newClient  func(rekorURL string, publicKey []byte) (*rekor.Client, error)
+
+	// backoff is the delay before the first attempt, doubled after each failure up to rekorInitBackoffCap
+	backoff time.Duration
+}
+
+// Start retries until the client is created or the manager stops. An invalid URL or public key isn't retried.
+func (i *rekorClientInitializer) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx).WithName("rekor")
+
+	if err := rekor.ValidateConfig(i.rekorURL, i.publicKey); err != nil {
+		log.Error(err, "Not retrying Rekor client creation, attestation verification is disabled")
+		return nil
+	}
+
+	delay := i.backoff
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+
+		rekorClient, err := i.newClient(i.rekorURL, i.publicKey)
+		if err != nil {
+			delay = min(delay*2, rekorInitBackoffCap)
+			log.Error(err, "Failed to create Rekor client, retrying", "rekorURL", i.rekorURL, "attempt", attempt, "retryAfter", delay)
+			i.reconciler.setRekorClient(nil, err)
+			continue
+		}
+
+		log.Info("Rekor client initialized, attestations are verified from the next reconcile", "rekorURL", rekorClient.URL(), "attempts", attempt)
+		i.reconciler.setRekorClient(rekorClient, nil)
+		return nil
+	}
+}
+
+// NeedLeaderElection is false: every replica verifies attestations, for admission and readiness too
+func (i *rekorClientInitializer) NeedLeaderElection() bool {
+	return false
+}
+
+// setRekorClient replaces the controller's Rekor client, along with the reason it is unavailable when nil
+func (r *ImagePolicyReconciler) setRekorClient(rekorClient *rekor.Client, err error) {
+	r.rekorClientMu.Lock()
+	defer r.rekorClientMu.Unlock()
+
+	r.RekorClient = rekorClient
+	r.rekorClientErr = err
+	r.recordRekorAvailability()
+}
+
+// defaultRekorClient returns the controller's Rekor client, or errRekorNotInitialized while there is none
+func (r *ImagePolicyReconciler) defaultRekorClient() (*rekor.Client, error) {
+	r.rekorClientMu.RLock()
+	defer r.rekorClientMu.RUnlock()
+
+	switch {
+	case r.RekorClient != nil:
+		return r.RekorClient, nil
+	case r.rekorClientErr != nil:
+		return nil, fmt.Errorf("%w, retrying in the background: %v", errRekorNotInitialized, r.rekorClientErr)
+	default:
+		return nil, errRekorNotInitialized
+	}
+}
+
+// recordRekorAvailability publishes whether the controller's Rekor client is initialized; callers hold rekorClientMu
+func (r *ImagePolicyReconciler) recordRekorAvailability() {
+	available := 0.0
+	if r.RekorClient != nil {
+		available = 1
+	}
+	rekorClientAvailableGauge.Set(available)
+}
+
+// rekorPublicKey loads the PEM public key a RekorPublicKeyRef points at, or nil when there is no reference
+func (r *ImagePolicyReconciler) rekorPublicKey(ctx context.Context, ref *securityv1.RekorPublicKeyRef) ([]byte, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	if r.Client == nil {
+		return nil, fmt.Errorf("rekorPublicKeyRef %s/%s requires cluster access", ref.Namespace, ref.Name)
+	}
+
+	key := ref.Key
+	if key == "" {
+		key = defaultRekorPublicKeyKey
+	}
+	configMap := &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, configMap); err != nil {
+		return nil, fmt.Errorf("failed to get Rekor public key configmap %s/%s: %w", ref.Namespace, ref.Name, err)
+	}
+	publicKey := configMap.Data[key]
+	if publicKey == "" {
+		return nil, fmt.Errorf("ConfigMap %s/%s has no %q key", ref.Namespace, ref.Name, key)
+	}
+	return []byte(publicKey), nil
+}
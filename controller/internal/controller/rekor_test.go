@@ -0,0 +1,203 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+	"github.com/jonlimpw/chainguard-controller/internal/rekor"
+)
+
+var _ = Describe("Rekor client initialization", func() {
+	startupErr := errors.New("dial tcp: connect: network is unreachable")
+
+	// newInitializer returns a reconciler whose Rekor client failed at startup, and an initializer that
+	// fails the given number of attempts before creating it
+	newInitializer := func(rekorURL string, failures int32, attempts *atomic.Int32) (*ImagePolicyReconciler, *rekorClientInitializer) {
+		reconciler := &ImagePolicyReconciler{}
+		initializer := reconciler.RetryRekorClient(rekorURL, testRekorPublicKey(), startupErr).(*rekorClientInitializer)
+		initializer.backoff = time.Millisecond
+		initializer.newClient = func(rekorURL string, publicKey []byte) (*rekor.Client, error) {
+			if attempts.Add(1) <= failures {
+				return nil, startupErr
+			}
+			return rekor.NewClient(rekorURL, publicKey)
+		}
+		return reconciler, initializer
+	}
+
+	It("should retry creating the client in the background until it succeeds", func() {
+		var attempts atomic.Int32
+		reconciler, initializer := newInitializer("https://rekor.internal", 2, &attempts)
+
+		_, err := reconciler.defaultRekorClient()
+		Expect(err).To(MatchError(errRekorNotInitialized))
+		Expect(err).To(MatchError(ContainSubstring("network is unreachable")))
+		Expect(initializer.NeedLeaderElection()).To(BeFalse())
+
+		Expect(initializer.Start(context.Background())).To(Succeed())
+		Expect(attempts.Load()).To(Equal(int32(3)))
+
+		rekorClient, err := reconciler.defaultRekorClient()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rekorClient.URL()).To(Equal("https://rekor.internal"))
+	})
+
+	It("should stop retrying when the manager stops", func() {
+		var attempts atomic.Int32
+		reconciler, initializer := newInitializer("https://rekor.internal", 1000, &attempts)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		Expect(initializer.Start(ctx)).To(Succeed())
+		Expect(attempts.Load()).To(BeNumerically(">", 1))
+
+		_, err := reconciler.defaultRekorClient()
+		Expect(err).To(MatchError(errRekorNotInitialized))
+	})
+
+	It("should not retry an invalid URL", func() {
+		var attempts atomic.Int32
+		_, initializer := newInitializer("rekor.internal", 0, &attempts)
+
+		Expect(initializer.Start(context.Background())).To(Succeed())
+		Expect(attempts.Load()).To(BeZero())
+	})
+
+	It("should not retry a private Rekor without a public key", func() {
+		var attempts atomic.Int32
+		_, initializer := newInitializer("https://rekor.internal", 0, &attempts)
+		initializer.publicKey = nil
+
+		Expect(initializer.Start(context.Background())).To(Succeed())
+		Expect(attempts.Load()).To(BeZero())
+	})
+
+	It("should report policies using the missing client as RekorUnavailable", func() {
+		reconciler := &ImagePolicyReconciler{}
+		reconciler.RetryRekorClient("", nil, startupErr)
+		attestationPolicy := &securityv1.AttestationPolicy{RequireAttestation: ptr.To(true)}
+
+		result := reconciler.verifyAttestation(context.Background(), "jonlimpw/cg-demo",
+			"sha256:1111111111111111111111111111111111111111111111111111111111111111", attestationPolicy, "")
+		Expect(result.Verified).To(BeFalse())
+		Expect(result.Unavailable).To(BeTrue())
+
+		policy := &securityv1.ImagePolicy{}
+		reconciler.updateAttestationCondition(policy, attestationPolicy, []securityv1.DeploymentStatus{{
+			HasValidAttestation: ptr.To(false),
+			AttestationDetails:  newAttestationDetails(result, attestationChecks{}),
+		}})
+		condition := meta.FindStatusCondition(policy.Status.Conditions, securityv1.ConditionTypeAttestationVerified)
+		Expect(condition.Reason).To(Equal("RekorUnavailable"))
+		Expect(condition.Message).To(ContainSubstring("until the Rekor client is up"))
+		Expect(condition.Message).To(ContainSubstring("network is unreachable"))
+	})
+
+	It("should only fail readiness when a policy requires attestations", func() {
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+			Spec: securityv1.ImagePolicySpec{
+				Repository:        "jonlimpw/cg-demo",
+				AttestationPolicy: &securityv1.AttestationPolicy{RequireAttestation: ptr.To(false)},
+			},
+		}
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(policy).Build()
+		reconciler := &ImagePolicyReconciler{Client: fakeClient}
+		reconciler.RetryRekorClient("", nil, startupErr)
+		req := httptest.NewRequest(http.MethodGet, "/readyz/rekor", nil)
+		Expect(reconciler.RekorHealthCheck(req)).To(Succeed())
+
+		policy.Spec.AttestationPolicy.RequireAttestation = ptr.To(true)
+		Expect(fakeClient.Update(context.Background(), policy)).To(Succeed())
+		Expect(reconciler.RekorHealthCheck(req)).To(MatchError(errRekorNotInitialized))
+	})
+})
+
+var _ = Describe("Rekor public keys", func() {
+	ctx := context.Background()
+	publicKey := testRekorPublicKey()
+	keyMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "rekor-key", Namespace: "team-a"},
+		Data:       map[string]string{"rekor.pub": string(publicKey)},
+	}
+
+	It("should create a client per Rekor URL and pinned key", func() {
+		reconciler := &ImagePolicyReconciler{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(keyMap).Build()}
+		attestationPolicy := &securityv1.AttestationPolicy{
+			RekorURL:          "https://rekor.internal",
+			RekorPublicKeyRef: &securityv1.RekorPublicKeyRef{Name: "rekor-key", Namespace: "team-a"},
+		}
+
+		rekorClient, err := reconciler.rekorClientFor(ctx, attestationPolicy)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rekorClient.URL()).To(Equal("https://rekor.internal"))
+		Expect(reconciler.rekorClientFor(ctx, attestationPolicy)).To(BeIdenticalTo(rekorClient))
+	})
+
+	It("should refuse a private Rekor URL without a pinned key rather than failing open", func() {
+		reconciler := &ImagePolicyReconciler{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()}
+		attestationPolicy := &securityv1.AttestationPolicy{
+			RequireAttestation: ptr.To(true),
+			FailureMode:        securityv1.AttestationFailureModeOpen,
+			RekorURL:           "https://rekor.internal",
+		}
+
+		_, err := reconciler.rekorClientFor(ctx, attestationPolicy)
+		Expect(err).To(MatchError(rekor.ErrNoPublicKey))
+
+		result := reconciler.verifyAttestation(ctx, "jonlimpw/cg-demo",
+			"sha256:1111111111111111111111111111111111111111111111111111111111111111", attestationPolicy, "")
+		Expect(result.Verified).To(BeFalse())
+		Expect(result.Unavailable).To(BeFalse())
+	})
+
+	It("should report a missing key in the referenced ConfigMap", func() {
+		reconciler := &ImagePolicyReconciler{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(keyMap).Build()}
+		_, err := reconciler.rekorPublicKey(ctx, &securityv1.RekorPublicKeyRef{Name: "rekor-key", Namespace: "team-a", Key: "other.pub"})
+		Expect(err).To(MatchError(`ConfigMap team-a/rekor-key has no "other.pub" key`))
+	})
+})
+
+// testRekorPublicKey returns the PEM public key of a fresh ECDSA key, for Rekor servers whose entries aren't verified
+func testRekorPublicKey() []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	Expect(err).NotTo(HaveOccurred())
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
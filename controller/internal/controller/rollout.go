@@ -0,0 +1,128 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+// Deployment Progressing condition reasons, see the deployment controller
+const (
+	deploymentNewReplicaSetAvailable   = "NewReplicaSetAvailable"
+	deploymentProgressDeadlineExceeded = "ProgressDeadlineExceeded"
+)
+
+// confirmRemediation re-reads a remediated workload from the API server and checks each container still runs
+// the image remediation set, which an admission webhook or another controller may have changed on the way
+func (r *ImagePolicyReconciler) confirmRemediation(ctx context.Context, deployment workload, expectedImages map[string]string) error {
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+
+	current, ok := deployment.Object.DeepCopyObject().(client.Object)
+	if !ok {
+		return fmt.Errorf("unexpected %s object %T", deployment.Kind, deployment.Object)
+	}
+	if err := reader.Get(ctx, client.ObjectKeyFromObject(current), current); err != nil {
+		return fmt.Errorf("failed to re-read remediated %s: %w", strings.ToLower(deployment.Kind), err)
+	}
+	confirmed, ok := newWorkload(current)
+	if !ok {
+		return fmt.Errorf("unexpected %s object %T", deployment.Kind, current)
+	}
+
+	for _, container := range confirmed.containers() {
+		if expected, ok := expectedImages[container.Name]; ok && *container.Image != expected {
+			return fmt.Errorf("remediation didn't take effect: %s %s runs %s instead of %s",
+				strings.ToLower(container.Kind), container.Name, *container.Image, expected)
+		}
+	}
+	return nil
+}
+
+// trackRollout keeps RemediationRollingOut across reconciles until the workload's controller has rolled the
+// remediated pod template out. Until then a workload that would be compliant is RemediationInProgress: its
+// pods still run the images it was remediated from.
+func trackRollout(policy *securityv1.ImagePolicy, deployment workload, status *securityv1.DeploymentStatus) {
+	previous := findDeploymentStatus(policy, deployment)
+	if previous == nil || !previous.RemediationRollingOut {
+		return
+	}
+
+	progress, done := rolloutProgress(deployment)
+	if done {
+		return
+	}
+	status.RemediationRollingOut = true
+	if status.Reason == securityv1.ComplianceReasonCompliant {
+		status.IsCompliant = false
+		status.Reason = securityv1.NonComplianceReasonRemediationInProgress
+		status.Message = fmt.Sprintf("Remediated %s is rolling out: %s", strings.ToLower(deployment.Kind), progress)
+	}
+}
+
+// rolloutProgress describes how far a workload's controller got rolling out its pod template, and whether it
+// is done. A Deployment is done once its Progressing condition reports the new ReplicaSet available; kinds
+// rolling out nothing by themselves (CronJobs, OnDelete updates) always are.
+func rolloutProgress(w workload) (string, bool) {
+	if !rolloutInProgress(w) {
+		if o, ok := w.Object.(*appsv1.Deployment); ok {
+			if progressing := deploymentProgressing(o); progressing != nil && progressing.Reason != deploymentNewReplicaSetAvailable {
+				return fmt.Sprintf("waiting for the new ReplicaSet to be available (%s)", progressing.Reason), false
+			}
+		}
+		return "", true
+	}
+
+	switch o := w.Object.(type) {
+	case *appsv1.Deployment:
+		if o.Status.ObservedGeneration < o.Generation {
+			return "waiting for the deployment controller to observe the update", false
+		}
+		progress := fmt.Sprintf("%d of %d updated replicas available", min(o.Status.AvailableReplicas, o.Status.UpdatedReplicas), ptr.Deref(o.Spec.Replicas, 1))
+		if progressing := deploymentProgressing(o); progressing != nil && progressing.Reason == deploymentProgressDeadlineExceeded {
+			progress += ", progress deadline exceeded: " + progressing.Message
+		}
+		return progress, false
+	case *appsv1.StatefulSet:
+		return fmt.Sprintf("%d of %d replicas updated and ready", min(o.Status.UpdatedReplicas, o.Status.ReadyReplicas), ptr.Deref(o.Spec.Replicas, 1)), false
+	case *appsv1.DaemonSet:
+		return fmt.Sprintf("%d of %d pods updated and available", min(o.Status.UpdatedNumberScheduled, o.Status.NumberAvailable), o.Status.DesiredNumberScheduled), false
+	default:
+		return "", true
+	}
+}
+
+// deploymentProgressing returns a Deployment's Progressing condition, nil when it has none
+func deploymentProgressing(deployment *appsv1.Deployment) *appsv1.DeploymentCondition {
+	for i := range deployment.Status.Conditions {
+		if condition := &deployment.Status.Conditions[i]; condition.Type == appsv1.DeploymentProgressing && condition.Status != corev1.ConditionUnknown {
+			return condition
+		}
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+	"github.com/jonlimpw/chainguard-controller/internal/registry/registrytest"
+)
+
+var _ = Describe("Remediation rollouts", func() {
+	const repository = "jonlimpw/cg-demo"
+
+	ctx := context.Background()
+
+	// rolledOut returns a Deployment whose controller finished rolling out its current generation
+	rolledOut := func() *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default", Generation: 2},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](2)},
+			Status: appsv1.DeploymentStatus{
+				ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2,
+				Conditions: []appsv1.DeploymentCondition{{
+					Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue, Reason: deploymentNewReplicaSetAvailable,
+				}},
+			},
+		}
+	}
+
+	// progress returns the rollout progress of a workload object
+	progress := func(obj client.Object) (string, bool) {
+		w, ok := newWorkload(obj)
+		Expect(ok).To(BeTrue())
+		return rolloutProgress(w)
+	}
+
+	It("should only consider a Deployment rolled out once the new ReplicaSet is available", func() {
+		deployment := rolledOut()
+		_, done := progress(deployment)
+		Expect(done).To(BeTrue())
+
+		deployment.Generation = 3
+		message, done := progress(deployment)
+		Expect(done).To(BeFalse())
+		Expect(message).To(ContainSubstring("observe the update"))
+
+		deployment = rolledOut()
+		deployment.Status.AvailableReplicas = 1
+		message, done = progress(deployment)
+		Expect(done).To(BeFalse())
+		Expect(message).To(Equal("1 of 2 updated replicas available"))
+
+		deployment.Status.Conditions[0] = appsv1.DeploymentCondition{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse,
+			Reason: deploymentProgressDeadlineExceeded, Message: `ReplicaSet "demo-7f9" has timed out progressing.`}
+		message, _ = progress(deployment)
+		Expect(message).To(ContainSubstring("progress deadline exceeded"))
+
+		// The replica counts can be up to date before the deployment controller reports the ReplicaSet available
+		deployment = rolledOut()
+		deployment.Status.Conditions[0].Reason = "ReplicaSetUpdated"
+		_, done = progress(deployment)
+		Expect(done).To(BeFalse())
+	})
+
+	It("should consider workloads that roll out nothing by themselves rolled out", func() {
+		_, done := progress(&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default", Generation: 2},
+			Spec:       appsv1.StatefulSetSpec{UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}},
+		})
+		Expect(done).To(BeTrue())
+
+		_, done = progress(&batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"}})
+		Expect(done).To(BeTrue())
+	})
+
+	It("should fail a remediation another writer undid on the way", func() {
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "app", Image: repository + ":v1"}},
+			}}},
+		}
+		remediator := &ImagePolicyReconciler{Client: fake.NewClientBuilder().WithObjects(deployment).
+			WithInterceptorFuncs(interceptor.Funcs{
+				// Stands in for a mutating webhook reverting the image
+				Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+					obj.(*appsv1.Deployment).Spec.Template.Spec.Containers[0].Image = repository + ":v1"
+					return c.Update(ctx, obj, opts...)
+				},
+			}).Build()}
+		w, _ := newWorkload(deployment)
+
+		err := remediator.remediateDeployment(ctx, &securityv1.ImagePolicy{}, w, complianceRules{EnforceLatest: true}, map[string]string{repository: "sha256:latest"})
+		Expect(err).To(MatchError(ContainSubstring("remediation didn't take effect: container app runs " + repository + ":v1 instead of " + repository + "@sha256:latest")))
+	})
+
+	It("should report a remediated workload in progress until it has rolled out", func() {
+		fakeRegistry := registrytest.NewRegistry()
+		DeferCleanup(fakeRegistry.Close)
+		staleDigest := fakeRegistry.PutImage(repository, "1.0")
+		latestDigest := fakeRegistry.PutImage(repository, "latest")
+
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+			Spec:       securityv1.ImagePolicySpec{Repository: repository},
+		}
+		deployment := rolledOut()
+		deployment.Labels = map[string]string{"automation": "true"}
+		deployment.Spec.Template.Spec.Containers = []corev1.Container{{Name: "app", Image: repository + "@" + staleDigest}}
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithObjects(policy, deployment, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}).
+			WithStatusSubresource(policy).Build()
+		reconciler := &ImagePolicyReconciler{
+			Client:               fakeClient,
+			Recorder:             record.NewFakeRecorder(20),
+			DockerHubRegistryURL: fakeRegistry.URL,
+			DockerHubAuthURL:     fakeRegistry.URL,
+		}
+
+		// reconcile returns the status of the deployment after a reconcile, and when the next one is due
+		key := types.NamespacedName{Name: "policy", Namespace: "default"}
+		reconcile := func() (securityv1.DeploymentStatus, ctrl.Result) {
+			result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeClient.Get(ctx, key, policy)).To(Succeed())
+			Expect(policy.Status.MonitoredDeployments).To(HaveLen(1))
+			return policy.Status.MonitoredDeployments[0], result
+		}
+
+		status, result := reconcile()
+		Expect(status.Reason).To(Equal(securityv1.NonComplianceReasonOutdatedDigest))
+		Expect(status.RemediationRollingOut).To(BeTrue())
+		Expect(result.RequeueAfter).To(Equal(rolloutRequeueInterval))
+
+		// The deployment controller hasn't observed the remediation yet
+		current := &appsv1.Deployment{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "demo", Namespace: "default"}, current)).To(Succeed())
+		Expect(current.Spec.Template.Spec.Containers[0].Image).To(Equal(repository + "@" + latestDigest))
+		current.Generation = 3
+		Expect(fakeClient.Update(ctx, current)).To(Succeed())
+
+		status, _ = reconcile()
+		Expect(status.IsCompliant).To(BeFalse())
+		Expect(status.Reason).To(Equal(securityv1.NonComplianceReasonRemediationInProgress))
+		Expect(status.Message).To(ContainSubstring("observe the update"))
+		Expect(policy.Status.RemediationHistory).To(HaveLen(1))
+
+		current.Status.ObservedGeneration = 3
+		current.Status.UpdatedReplicas = 2
+		Expect(fakeClient.Status().Update(ctx, current)).To(Succeed())
+
+		status, _ = reconcile()
+		Expect(status.IsCompliant).To(BeTrue())
+		Expect(status.RemediationRollingOut).To(BeFalse())
+		Expect(policy.Status.ComplianceStatus).To(Equal(securityv1.ComplianceStatusCompliant))
+	})
+})
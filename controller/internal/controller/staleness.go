@@ -0,0 +1,75 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+	"github.com/jonlimpw/chainguard-controller/internal/registry"
+)
+
+// trackStaleness sets StaleSince while a workload uses a digest other than the latest one or another tracked
+// tag's, keeping the time it was first seen on that digest across reconciles, and clears it otherwise
+func trackStaleness(policy *securityv1.ImagePolicy, deployment workload, status *securityv1.DeploymentStatus, latestDigest string, trackedDigests []string) {
+	if !registry.IsDigest(status.CurrentDigest) || latestDigest == "" || status.CurrentDigest == latestDigest ||
+		slices.Contains(trackedDigests, status.CurrentDigest) {
+		status.StaleSince = nil
+		return
+	}
+
+	if previous := findDeploymentStatus(policy, deployment); previous != nil && previous.StaleSince != nil &&
+		previous.CurrentDigest == status.CurrentDigest {
+		status.StaleSince = previous.StaleSince
+		return
+	}
+
+	staleSince := metav1.Now()
+	if status.LastUpdated != nil {
+		staleSince = *status.LastUpdated
+	}
+	status.StaleSince = &staleSince
+}
+
+// recordNonCompliantEvent emits a NonCompliantImage event whose message and annotations carry the current
+// and latest digests and, for stale workloads, how long they have been on the outdated digest
+func (r *ImagePolicyReconciler) recordNonCompliantEvent(policy *securityv1.ImagePolicy, deployment workload, status *securityv1.DeploymentStatus, latestDigest string) {
+	message := fmt.Sprintf("%s %s/%s is non-compliant in %s %s: %s", deployment.Kind, deployment.GetNamespace(), deployment.GetName(),
+		strings.ToLower(status.ContainerKind), status.ContainerName, status.Message)
+
+	annotations := map[string]string{
+		securityv1.EventAnnotationCurrentDigest: status.CurrentDigest,
+	}
+	if latestDigest != "" {
+		annotations[securityv1.EventAnnotationLatestDigest] = latestDigest
+	}
+	if status.StaleSince != nil {
+		staleFor := time.Since(status.StaleSince.Time)
+		message += fmt.Sprintf(" (on %s for %s, latest is %s)", status.CurrentDigest, staleFor.Round(time.Minute), latestDigest)
+		annotations[securityv1.EventAnnotationStaleSince] = status.StaleSince.UTC().Format(time.RFC3339)
+		annotations[securityv1.EventAnnotationStaleForSeconds] = strconv.FormatInt(int64(staleFor.Seconds()), 10)
+	}
+
+	r.Recorder.AnnotatedEventf(policy, annotations, corev1.EventTypeWarning, "NonCompliantImage", "%s", message)
+}
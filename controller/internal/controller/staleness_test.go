@@ -0,0 +1,114 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Digest staleness", func() {
+	const (
+		latestDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		staleDigest  = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	)
+
+	deployment, _ := newWorkload(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"}})
+	twoHoursAgo := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+
+	newStatus := func(digest string) securityv1.DeploymentStatus {
+		now := metav1.Now()
+		return securityv1.DeploymentStatus{
+			Kind:          securityv1.WorkloadKindDeployment,
+			Name:          "demo",
+			Namespace:     "default",
+			CurrentDigest: digest,
+			ContainerName: "app",
+			ContainerKind: securityv1.ContainerKindContainer,
+			LastUpdated:   &now,
+		}
+	}
+
+	policyWithPrevious := func(previous securityv1.DeploymentStatus) *securityv1.ImagePolicy {
+		policy := &securityv1.ImagePolicy{}
+		policy.Status.MonitoredDeployments = []securityv1.DeploymentStatus{previous}
+		return policy
+	}
+
+	It("should start tracking a workload first seen on an outdated digest", func() {
+		status := newStatus(staleDigest)
+		trackStaleness(&securityv1.ImagePolicy{}, deployment, &status, latestDigest, nil)
+		Expect(status.StaleSince).To(Equal(status.LastUpdated))
+	})
+
+	It("should keep the first-seen time while the workload stays on the same outdated digest", func() {
+		previous := newStatus(staleDigest)
+		previous.StaleSince = &twoHoursAgo
+
+		status := newStatus(staleDigest)
+		trackStaleness(policyWithPrevious(previous), deployment, &status, latestDigest, nil)
+		Expect(status.StaleSince).To(Equal(&twoHoursAgo))
+	})
+
+	It("should restart tracking when the workload moves to another outdated digest", func() {
+		previous := newStatus("sha256:3333333333333333333333333333333333333333333333333333333333333333")
+		previous.StaleSince = &twoHoursAgo
+
+		status := newStatus(staleDigest)
+		trackStaleness(policyWithPrevious(previous), deployment, &status, latestDigest, nil)
+		Expect(status.StaleSince).To(Equal(status.LastUpdated))
+	})
+
+	It("should clear tracking for workloads on the latest digest or without a digest", func() {
+		previous := newStatus(staleDigest)
+		previous.StaleSince = &twoHoursAgo
+
+		for _, digest := range []string{latestDigest, "tag-based", ""} {
+			status := newStatus(digest)
+			trackStaleness(policyWithPrevious(previous), deployment, &status, latestDigest, nil)
+			Expect(status.StaleSince).To(BeNil(), digest)
+		}
+	})
+
+	It("should include the digests and stale duration in the NonCompliantImage event", func() {
+		recorder := record.NewFakeRecorder(1)
+		reconciler := &ImagePolicyReconciler{Recorder: recorder}
+
+		status := newStatus(staleDigest)
+		status.Message = "digest " + staleDigest + " is outdated, latest is " + latestDigest
+		// Taken here rather than with the other fixtures, so the time the suite has run doesn't count
+		staleSince := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+		status.StaleSince = &staleSince
+		reconciler.recordNonCompliantEvent(&securityv1.ImagePolicy{}, deployment, &status, latestDigest)
+
+		var event string
+		Eventually(recorder.Events).Should(Receive(&event))
+		Expect(event).To(HavePrefix("Warning NonCompliantImage Deployment default/demo is non-compliant in container app"))
+		Expect(event).To(ContainSubstring("(on " + staleDigest + " for 2h0m0s, latest is " + latestDigest + ")"))
+		Expect(event).To(ContainSubstring(securityv1.EventAnnotationCurrentDigest + ":" + staleDigest))
+		Expect(event).To(ContainSubstring(securityv1.EventAnnotationLatestDigest + ":" + latestDigest))
+		Expect(event).To(ContainSubstring(securityv1.EventAnnotationStaleForSeconds + ":7200"))
+		Expect(event).To(ContainSubstring(securityv1.EventAnnotationStaleSince + ":" + staleSince.UTC().Format(time.RFC3339)))
+	})
+})
@@ -0,0 +1,166 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Status updates", func() {
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "unchanged", Namespace: "default"}
+
+	It("should ignore the timestamps stamped on every reconcile", func() {
+		earlier, later := metav1.Unix(1000, 0), metav1.Unix(2000, 0)
+		previous := &securityv1.ImagePolicyStatus{MonitoredDeployments: []securityv1.DeploymentStatus{{
+			Name: "demo", IsCompliant: true, LastUpdated: &earlier,
+			AttestationDetails: &securityv1.AttestationDetails{Verified: true, LastChecked: &earlier},
+		}}}
+		current := previous.DeepCopy()
+		current.MonitoredDeployments[0].LastUpdated = &later
+		current.MonitoredDeployments[0].AttestationDetails.LastChecked = &later
+		Expect(statusChanged(previous, current)).To(BeFalse())
+
+		current.MonitoredDeployments[0].IsCompliant = false
+		Expect(statusChanged(previous, current)).To(BeTrue())
+	})
+
+	It("should only write the status when a reconcile changes it", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/token":
+				_, _ = w.Write([]byte(`{"token":"test"}`))
+			case strings.HasPrefix(r.URL.Path, "/v2/jonlimpw/cg-demo/manifests/"):
+				w.Header().Set("Docker-Content-Digest", "sha256:1111111111111111111111111111111111111111111111111111111111111111")
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		previousRegistryURL, previousAuthURL := dockerHubRegistryURL, dockerHubAuthURL
+		dockerHubRegistryURL, dockerHubAuthURL = server.URL, server.URL
+		DeferCleanup(func() {
+			server.Close()
+			dockerHubRegistryURL, dockerHubAuthURL = previousRegistryURL, previousAuthURL
+		})
+
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Spec:       securityv1.ImagePolicySpec{Repository: "jonlimpw/cg-demo"},
+		}
+		statusWrites := 0
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(policy).WithStatusSubresource(policy).
+			WithInterceptorFuncs(interceptor.Funcs{
+				SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+					statusWrites++
+					return c.SubResource(subResourceName).Update(ctx, obj, opts...)
+				},
+			}).Build()
+		reconciler := &ImagePolicyReconciler{Client: fakeClient, Recorder: record.NewFakeRecorder(10)}
+
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statusWrites).To(Equal(1))
+
+		// The digest is known within the check interval and no workload changed
+		_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statusWrites).To(Equal(1))
+	})
+
+	It("should only record the observed generation once a reconcile completes", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/token":
+				_, _ = w.Write([]byte(`{"token":"test"}`))
+			case strings.HasPrefix(r.URL.Path, "/v2/jonlimpw/cg-demo/manifests/"):
+				w.Header().Set("Docker-Content-Digest", "sha256:1111111111111111111111111111111111111111111111111111111111111111")
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		previousRegistryURL, previousAuthURL := dockerHubRegistryURL, dockerHubAuthURL
+		dockerHubRegistryURL, dockerHubAuthURL = server.URL, server.URL
+		DeferCleanup(func() {
+			server.Close()
+			dockerHubRegistryURL, dockerHubAuthURL = previousRegistryURL, previousAuthURL
+		})
+
+		generationKey := types.NamespacedName{Name: "generation", Namespace: "default"}
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: generationKey.Name, Namespace: generationKey.Namespace, Generation: 1},
+			Spec:       securityv1.ImagePolicySpec{Repository: "jonlimpw/cg-demo"},
+		}
+		failLists := false
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(policy).WithStatusSubresource(policy).
+			WithInterceptorFuncs(interceptor.Funcs{
+				List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+					if _, ok := list.(*appsv1.DeploymentList); ok && failLists {
+						return errors.New("apiserver unavailable")
+					}
+					return c.List(ctx, list, opts...)
+				},
+			}).Build()
+		reconciler := &ImagePolicyReconciler{Client: fakeClient, Recorder: record.NewFakeRecorder(10)}
+		observed := func() *securityv1.ImagePolicy {
+			updated := &securityv1.ImagePolicy{}
+			Expect(fakeClient.Get(ctx, generationKey, updated)).To(Succeed())
+			return updated
+		}
+
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: generationKey})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(observed().Status.ObservedGeneration).To(Equal(int64(1)))
+
+		// A spec change isn't observed while reconciles fail halfway
+		updated := observed()
+		updated.Spec.Repositories = []string{"jonlimpw/other"}
+		updated.Generation = 2
+		Expect(fakeClient.Update(ctx, updated)).To(Succeed())
+		failLists = true
+		_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: generationKey})
+		Expect(err).To(HaveOccurred())
+		Expect(observed().Status.ObservedGeneration).To(Equal(int64(1)))
+
+		failLists = false
+		_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: generationKey})
+		Expect(err).NotTo(HaveOccurred())
+		status := observed().Status
+		Expect(status.ObservedGeneration).To(Equal(int64(2)))
+		Expect(status.Conditions).NotTo(BeEmpty())
+		for _, condition := range status.Conditions {
+			Expect(condition.ObservedGeneration).To(Equal(int64(2)), condition.Type)
+		}
+	})
+})
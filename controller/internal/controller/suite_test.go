@@ -64,6 +64,9 @@ var _ = BeforeSuite(func() {
 
 	// +kubebuilder:scaffold:scheme
 
+	// Attestations in the tests are signed by certificates of a test CA rather than the public-good Fulcio
+	defaultFulcioRoot = testFulcioRoot
+
 	By("bootstrapping test environment")
 	testEnv = &envtest.Environment{
 		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "config", "crd", "bases")},
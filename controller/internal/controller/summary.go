@@ -0,0 +1,106 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"cmp"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+// complianceShutdownTimeout bounds how long the compliance server waits for open requests when the manager stops
+const complianceShutdownTimeout = 10 * time.Second
+
+// ComplianceSummary is the JSON document served by the compliance endpoint
+type ComplianceSummary struct {
+	// TotalPolicies is the number of ImagePolicies in the cluster
+	TotalPolicies int `json:"totalPolicies"`
+
+	// NonCompliantPolicies is the number of policies whose ComplianceStatus is NonCompliant or Error
+	NonCompliantPolicies int `json:"nonCompliantPolicies"`
+
+	// TotalDeployments and CompliantDeployments add up the workloads monitored by every policy
+	TotalDeployments     int32 `json:"totalDeployments"`
+	CompliantDeployments int32 `json:"compliantDeployments"`
+
+	// Policies lists each policy, in namespace and name order
+	Policies []PolicySummary `json:"policies"`
+}
+
+// PolicySummary is the compliance of one ImagePolicy, as its last reconcile recorded it
+type PolicySummary struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+
+	// Status carries the per-repository and per-workload detail, including attestations
+	Status securityv1.ImagePolicyStatus `json:"status"`
+}
+
+// ComplianceServer returns a runnable serving the ComplianceSummary of every ImagePolicy as JSON on
+// GET /compliance at addr. It reads the policies from the manager's cache and needs no leader election.
+func (r *ImagePolicyReconciler) ComplianceServer(addr string) manager.Runnable {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /compliance", r.serveComplianceSummary)
+
+	shutdownTimeout := complianceShutdownTimeout
+	return &manager.Server{
+		Name:            "compliance",
+		Server:          &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second},
+		ShutdownTimeout: &shutdownTimeout,
+	}
+}
+
+// serveComplianceSummary writes the ComplianceSummary of every ImagePolicy
+func (r *ImagePolicyReconciler) serveComplianceSummary(w http.ResponseWriter, req *http.Request) {
+	log := logf.FromContext(req.Context())
+
+	policies := &securityv1.ImagePolicyList{}
+	if err := r.List(req.Context(), policies); err != nil {
+		log.Error(err, "Failed to list image policies for the compliance summary")
+		http.Error(w, "failed to list image policies", http.StatusInternalServerError)
+		return
+	}
+	slices.SortFunc(policies.Items, func(a, b securityv1.ImagePolicy) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+	})
+
+	summary := ComplianceSummary{TotalPolicies: len(policies.Items), Policies: []PolicySummary{}}
+	for _, policy := range policies.Items {
+		if status := policy.Status.ComplianceStatus; status == securityv1.ComplianceStatusNonCompliant || status == securityv1.ComplianceStatusError {
+			summary.NonCompliantPolicies++
+		}
+		summary.TotalDeployments += policy.Status.TotalDeployments
+		summary.CompliantDeployments += policy.Status.CompliantDeployments
+		summary.Policies = append(summary.Policies, PolicySummary{
+			Namespace: policy.Namespace,
+			Name:      policy.Name,
+			Status:    policy.Status,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(summary); err != nil {
+		log.Error(err, "Failed to write the compliance summary")
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Compliance summary", func() {
+	newPolicy := func(namespace, name, complianceStatus string, total, compliant int32, deployments ...securityv1.DeploymentStatus) *securityv1.ImagePolicy {
+		return &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       securityv1.ImagePolicySpec{Repository: "jonlimpw/cg-demo"},
+			Status: securityv1.ImagePolicyStatus{
+				ComplianceStatus:     complianceStatus,
+				TotalDeployments:     total,
+				CompliantDeployments: compliant,
+				MonitoredDeployments: deployments,
+			},
+		}
+	}
+
+	serve := func(method string, reconciler *ImagePolicyReconciler) *httptest.ResponseRecorder {
+		handler := reconciler.ComplianceServer(":0").(*manager.Server).Server.Handler
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(method, "/compliance", nil))
+		return recorder
+	}
+
+	It("should aggregate every policy's status, sorted by namespace and name", func() {
+		unattested := securityv1.DeploymentStatus{
+			Kind: securityv1.WorkloadKindDeployment, Name: "api", Namespace: "team-b",
+			IsCompliant: false, HasValidAttestation: ptr.To(false),
+			AttestationDetails: &securityv1.AttestationDetails{Error: "no attestation found"},
+		}
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			newPolicy("team-b", "demo", securityv1.ComplianceStatusNonCompliant, 2, 1, unattested),
+			newPolicy("team-a", "web", securityv1.ComplianceStatusCompliant, 3, 3),
+			newPolicy("team-a", "api", securityv1.ComplianceStatusError, 0, 0),
+		).Build()
+
+		response := serve(http.MethodGet, &ImagePolicyReconciler{Client: fakeClient})
+		Expect(response.Code).To(Equal(http.StatusOK))
+		Expect(response.Header().Get("Content-Type")).To(Equal("application/json"))
+
+		var summary ComplianceSummary
+		Expect(json.Unmarshal(response.Body.Bytes(), &summary)).To(Succeed())
+		Expect(summary.TotalPolicies).To(Equal(3))
+		Expect(summary.NonCompliantPolicies).To(Equal(2))
+		Expect(summary.TotalDeployments).To(Equal(int32(5)))
+		Expect(summary.CompliantDeployments).To(Equal(int32(4)))
+
+		var names []string
+		for _, policy := range summary.Policies {
+			names = append(names, policy.Namespace+"/"+policy.Name)
+		}
+		Expect(names).To(Equal([]string{"team-a/api", "team-a/web", "team-b/demo"}))
+		Expect(summary.Policies[2].Status.MonitoredDeployments).To(Equal([]securityv1.DeploymentStatus{unattested}))
+	})
+
+	It("should serve an empty list without policies", func() {
+		response := serve(http.MethodGet, &ImagePolicyReconciler{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()})
+		Expect(response.Code).To(Equal(http.StatusOK))
+		Expect(response.Body.String()).To(ContainSubstring(`"policies":[]`))
+	})
+
+	It("should be read-only", func() {
+		response := serve(http.MethodPost, &ImagePolicyReconciler{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()})
+		Expect(response.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+})
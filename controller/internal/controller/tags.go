@@ -0,0 +1,213 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/blang/semver/v4"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+	"github.com/jonlimpw/chainguard-controller/internal/registry"
+)
+
+// tagsPageSize is the number of tags requested per /tags/list page
+const tagsPageSize = 1000
+
+// errNoMatchingTag is returned when no tag of a repository satisfies the TagSemverRange
+var errNoMatchingTag = errors.New("no tag matches the semver range")
+
+// errInvalidSemverRange is returned when the TagSemverRange can't be parsed
+var errInvalidSemverRange = errors.New("invalid tag semver range")
+
+// dockerHubTagList is the response of the registry /tags/list endpoint
+type dockerHubTagList struct {
+	Tags []string `json:"tags"`
+}
+
+// resolveSemverTag returns the highest tag of a repository that satisfies the semver range, listing tags through proxy
+func (r *ImagePolicyReconciler) resolveSemverTag(ctx context.Context, repository, semverRange string, credentials *registryCredentials, proxy string) (string, error) {
+	log := logf.FromContext(ctx)
+
+	// Check the range before listing tags so a typo doesn't cost registry requests
+	inRange, err := semver.ParseRange(semverRange)
+	if err != nil {
+		return "", fmt.Errorf("%w %q: %v", errInvalidSemverRange, semverRange, err)
+	}
+
+	tags, err := retryDockerHub(ctx, r.DockerHubMaxRetries, func() ([]string, error) {
+		release, err := r.acquireRegistryRequest(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return r.listDockerHubTags(ctx, repository, credentials, proxy)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	tag, err := highestMatchingTag(tags, inRange)
+	if err != nil {
+		return "", fmt.Errorf("%s has %d tags: %w", repository, len(tags), err)
+	}
+	log.V(1).Info("Resolved semver tag", "repository", repository, "range", semverRange, "tag", tag, "tags", len(tags))
+	return tag, nil
+}
+
+// resolveTagDigests returns the digest each tag that workloads run from a monitored repository currently
+// resolves to, keyed by repository:tag, so their attestations can be verified. Tags that couldn't be resolved
+// are left out, and a policy whose pull secret can't be loaded resolves none.
+func (r *ImagePolicyReconciler) resolveTagDigests(ctx context.Context, policy *securityv1.ImagePolicy, deployments []workload, repositories []string, cacheTTL time.Duration) map[string]string {
+	log := logf.FromContext(ctx)
+
+	credentials, err := r.loadRegistryCredentials(ctx, policy)
+	if err != nil {
+		log.Error(err, "Skipping tag resolution, failed to load registry credentials")
+		return nil
+	}
+
+	resolved := map[string]string{}
+	checked := map[string]bool{}
+	for _, deployment := range deployments {
+		for _, container := range deployment.governedContainers(policy.Spec.ContainerName) {
+			tag := imageTag(*container.Image)
+			if tag == "" {
+				continue
+			}
+			for _, repository := range repositories {
+				key := repository + ":" + tag
+				if !imageUsesRepository(*container.Image, repository) || checked[key] {
+					continue
+				}
+				checked[key] = true
+
+				digest, err := r.getLatestDigestFromDockerHub(ctx, digestRequest{
+					Repository:  repository,
+					Tag:         tag,
+					Platform:    policy.Spec.Platform,
+					Credentials: credentials,
+					Mirror:      r.registryMirror(policy),
+					Proxy:       r.registryProxy(policy),
+				}, cacheTTL)
+				if err != nil {
+					log.Error(err, "Failed to resolve tag for attestation verification", "repository", repository, "tag", tag)
+					continue
+				}
+				resolved[key] = digest
+			}
+		}
+	}
+	return resolved
+}
+
+// listDockerHubTags lists every tag of a repository, following the registry's Link pagination
+func (r *ImagePolicyReconciler) listDockerHubTags(ctx context.Context, repository string, credentials *registryCredentials, proxy string) ([]string, error) {
+	token, err := r.fetchDockerHubToken(ctx, repository, credentials, proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	client := registryHTTPClient(proxy)
+	pageURL := fmt.Sprintf("%s/v2/%s/tags/list?n=%d", r.registryURL(), dockerHubRepository(repository), tagsPageSize)
+	var tags []string
+	for pageURL != "" {
+		req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create tags request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		sent := time.Now()
+		resp, err := client.Do(req)
+		if err != nil {
+			observeRegistryRequest(ctx, sent, nil)
+			return nil, networkError(ctx, fmt.Errorf("failed to list tags: %w", err))
+		}
+		observeRegistryRequest(ctx, sent, resp)
+
+		if resp.StatusCode != http.StatusOK {
+			err := registry.NewStatusError(resp, "registry")
+			_ = resp.Body.Close()
+			return nil, err
+		}
+
+		var page dockerHubTagList
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode tags response: %w", err)
+		}
+		tags = append(tags, page.Tags...)
+
+		pageURL, err = nextPageURL(req.URL, resp.Header.Get("Link"))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tags, nil
+}
+
+// nextPageURL returns the absolute URL of the rel="next" entry of a Link header, or "" on the last page
+func nextPageURL(current *url.URL, link string) (string, error) {
+	for _, entry := range strings.Split(link, ",") {
+		target, params, found := strings.Cut(strings.TrimSpace(entry), ";")
+		if !found || !strings.Contains(strings.ReplaceAll(params, " ", ""), `rel="next"`) {
+			continue
+		}
+
+		next, err := url.Parse(strings.Trim(strings.TrimSpace(target), "<>"))
+		if err != nil {
+			return "", fmt.Errorf("invalid Link header %q: %w", link, err)
+		}
+		return current.ResolveReference(next).String(), nil
+	}
+	return "", nil
+}
+
+// highestMatchingTag returns the highest tag within the semver range. Tags are parsed tolerantly
+// ("v1.2" is 1.2.0) and pre-releases are ignored; when tags parse to the same version the
+// canonical form ("1.2.0" over "1.2") is preferred.
+func highestMatchingTag(tags []string, inRange semver.Range) (string, error) {
+	var best string
+	var bestVersion semver.Version
+	for _, tag := range tags {
+		version, err := semver.ParseTolerant(tag)
+		if err != nil || len(version.Pre) > 0 || !inRange(version) {
+			continue
+		}
+
+		cmp := version.Compare(bestVersion)
+		if best == "" || cmp > 0 || (cmp == 0 && tag == version.String()) {
+			best = tag
+			bestVersion = version
+		}
+	}
+
+	if best == "" {
+		return "", errNoMatchingTag
+	}
+	return best, nil
+}
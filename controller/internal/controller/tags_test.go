@@ -0,0 +1,59 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/url"
+
+	"github.com/blang/semver/v4"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Semver tags", func() {
+	tags := []string{"latest", "1.1.9", "1.2", "v1.2.0", "1.2.0", "1.4.1", "1.10.0-rc.1", "1.9.3-alpine", "2.0.0"}
+
+	It("should pick the highest stable tag within the range", func() {
+		tag, err := highestMatchingTag(tags, semver.MustParseRange(">=1.2.0 <2.0.0"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tag).To(Equal("1.4.1"))
+	})
+
+	It("should prefer the canonical tag when several parse to the same version", func() {
+		tag, err := highestMatchingTag(tags, semver.MustParseRange("<1.3.0 >=1.2.0"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tag).To(Equal("1.2.0"))
+	})
+
+	It("should report when no tag matches", func() {
+		_, err := highestMatchingTag(tags, semver.MustParseRange(">=3.0.0"))
+		Expect(err).To(MatchError(errNoMatchingTag))
+	})
+
+	It("should follow the Link header to the next tags page", func() {
+		current, err := url.Parse("https://registry-1.docker.io/v2/org/app/tags/list?n=1000")
+		Expect(err).NotTo(HaveOccurred())
+
+		next, err := nextPageURL(current, `</v2/org/app/tags/list?last=1.4.1&n=1000>; rel="next"`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(next).To(Equal("https://registry-1.docker.io/v2/org/app/tags/list?last=1.4.1&n=1000"))
+
+		next, err = nextPageURL(current, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(next).To(BeEmpty())
+	})
+})
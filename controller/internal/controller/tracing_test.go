@@ -0,0 +1,138 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+	"github.com/jonlimpw/chainguard-controller/internal/registry/registrytest"
+)
+
+var _ = Describe("Reconcile tracing", func() {
+	const repository = "jonlimpw/cg-demo"
+
+	ctx := context.Background()
+	var fakeRegistry *registrytest.Registry
+	var spans *tracetest.SpanRecorder
+	var reconciler *ImagePolicyReconciler
+
+	BeforeEach(func() {
+		fakeRegistry = registrytest.NewRegistry()
+		DeferCleanup(fakeRegistry.Close)
+		spans = tracetest.NewSpanRecorder()
+		reconciler = &ImagePolicyReconciler{
+			DockerHubRegistryURL: fakeRegistry.URL,
+			DockerHubAuthURL:     fakeRegistry.URL,
+			TracerProvider:       sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)),
+		}
+	})
+
+	// span returns the ended span with the name, failing when there isn't exactly one
+	span := func(name string) sdktrace.ReadOnlySpan {
+		var found []sdktrace.ReadOnlySpan
+		for _, ended := range spans.Ended() {
+			if ended.Name() == name {
+				found = append(found, ended)
+			}
+		}
+		ExpectWithOffset(1, found).To(HaveLen(1), "spans named %s", name)
+		return found[0]
+	}
+
+	It("should trace the fetch and analysis of a reconcile as children of its span", func() {
+		latestDigest := fakeRegistry.PutImage(repository, "latest")
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+			Spec:       securityv1.ImagePolicySpec{Repository: repository},
+		}
+		reconciler.Client = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(policy, &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "app", Image: repository + "@" + latestDigest}},
+			}}},
+		}, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}).WithStatusSubresource(policy).Build()
+		reconciler.Recorder = record.NewFakeRecorder(10)
+
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "policy", Namespace: "default"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		reconcile := span("Reconcile")
+		Expect(reconcile.Attributes()).To(ContainElements(
+			attribute.String("imagepolicy.name", "policy"),
+			attribute.StringSlice("repositories", []string{repository}),
+			attribute.Int("deployments", 1),
+			attribute.String("result", securityv1.ComplianceStatusCompliant),
+		))
+
+		fetch := span("GetLatestDigest")
+		Expect(fetch.Parent().SpanID()).To(Equal(reconcile.SpanContext().SpanID()))
+		Expect(fetch.Attributes()).To(ContainElements(
+			attribute.String("repository", repository),
+			attribute.Bool("cacheHit", false),
+			attribute.String("digest", latestDigest),
+		))
+
+		analysis := span("AnalyzeDeploymentCompliance")
+		Expect(analysis.Parent().SpanID()).To(Equal(reconcile.SpanContext().SpanID()))
+		Expect(analysis.Attributes()).To(ContainElements(
+			attribute.String("workload.name", "demo"),
+			attribute.Bool("compliant", true),
+		))
+	})
+
+	It("should mark the span of a failed digest fetch", func() {
+		fakeRegistry.PutImage(repository, "latest")
+		fakeRegistry.FailManifest(repository, "latest", registrytest.Failure{StatusCode: 404})
+		reconciler.DockerHubMaxRetries = 1
+
+		_, err := reconciler.getLatestDigestFromDockerHub(ctx, digestRequest{Repository: repository, Tag: "latest"}, time.Minute)
+		Expect(err).To(HaveOccurred())
+
+		fetch := span("GetLatestDigest")
+		Expect(fetch.Status().Code).To(Equal(codes.Error))
+		Expect(fetch.Events()).To(ContainElement(HaveField("Name", "exception")))
+	})
+
+	It("should only fail the attestation span when the source is unreachable", func() {
+		result := reconciler.verifyAttestation(ctx, repository, "", &securityv1.AttestationPolicy{}, "")
+		Expect(result.Verified).To(BeFalse())
+
+		verification := span("VerifyAttestation")
+		Expect(verification.Attributes()).To(ContainElements(
+			attribute.String("source", securityv1.AttestationSourceRekor),
+			attribute.String("result", "Failed"),
+		))
+		Expect(verification.Status().Code).NotTo(Equal(codes.Error))
+	})
+})
@@ -0,0 +1,170 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Tracked tags", func() {
+	const (
+		repository   = "jonlimpw/cg-demo"
+		latestDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		stableDigest = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+		oldDigest    = "sha256:3333333333333333333333333333333333333333333333333333333333333333"
+	)
+
+	ctx := context.Background()
+
+	var manifestRequests []string
+	BeforeEach(func() {
+		manifestRequests = nil
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/token":
+				_, _ = w.Write([]byte(`{"token":"test"}`))
+			case r.URL.Path == "/v2/"+repository+"/manifests/latest":
+				manifestRequests = append(manifestRequests, r.URL.Path)
+				w.Header().Set("Docker-Content-Digest", latestDigest)
+			case r.URL.Path == "/v2/"+repository+"/manifests/stable":
+				manifestRequests = append(manifestRequests, r.URL.Path)
+				w.Header().Set("Docker-Content-Digest", stableDigest)
+			default:
+				manifestRequests = append(manifestRequests, r.URL.Path)
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		previousRegistryURL, previousAuthURL := dockerHubRegistryURL, dockerHubAuthURL
+		dockerHubRegistryURL, dockerHubAuthURL = server.URL, server.URL
+		DeferCleanup(func() {
+			server.Close()
+			dockerHubRegistryURL, dockerHubAuthURL = previousRegistryURL, previousAuthURL
+		})
+	})
+
+	newTaggedDeployment := func(name, digest string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"automation": "true"}},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "app", Image: repository + "@" + digest}},
+			}}},
+		}
+	}
+
+	reconcile := func(policy *securityv1.ImagePolicy, objects ...client.Object) (*ImagePolicyReconciler, client.Client) {
+		objects = append(objects, policy, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}})
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objects...).WithStatusSubresource(policy).Build()
+		reconciler := &ImagePolicyReconciler{Client: fakeClient, Recorder: record.NewFakeRecorder(10), DockerHubMaxRetries: 1}
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(policy)})
+		Expect(err).NotTo(HaveOccurred())
+		return reconciler, fakeClient
+	}
+
+	It("should fall back from Tags to Tag to latest", func() {
+		Expect(trackedTags(&securityv1.ImagePolicy{})).To(Equal([]string{"latest"}))
+		Expect(trackedTags(&securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{Tag: "v1"}})).To(Equal([]string{"v1"}))
+		Expect(trackedTags(&securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{Tag: "v1", Tags: []string{"stable", "latest"}}})).
+			To(Equal([]string{"stable", "latest"}))
+	})
+
+	It("should accept any tracked tag's digest and remediate to the primary tag", func() {
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "tags", Namespace: "default"},
+			Spec:       securityv1.ImagePolicySpec{Repository: repository, Tags: []string{"latest", "stable"}},
+		}
+		stable, outdated := newTaggedDeployment("stable", stableDigest), newTaggedDeployment("outdated", oldDigest)
+		_, fakeClient := reconcile(policy, stable, outdated)
+
+		updated := &securityv1.ImagePolicy{}
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(policy), updated)).To(Succeed())
+		Expect(updated.Status.LatestDigest).To(Equal(latestDigest))
+		Expect(updated.Status.Repositories[0].Tags).To(Equal([]securityv1.TagStatus{
+			{Tag: "latest", LatestDigest: latestDigest},
+			{Tag: "stable", LatestDigest: stableDigest},
+		}))
+		Expect(updated.Status.CompliantDeployments).To(Equal(int32(1)))
+		for _, status := range updated.Status.MonitoredDeployments {
+			Expect(status.IsCompliant).To(Equal(status.Name == "stable"), status.Name)
+			Expect(status.StaleSince == nil).To(Equal(status.Name == "stable"), status.Name)
+		}
+
+		// The workload on the stable digest is left alone, the outdated one moves to the primary tag
+		for name, want := range map[string]string{"stable": stableDigest, "outdated": latestDigest} {
+			remediated := &appsv1.Deployment{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, remediated)).To(Succeed())
+			Expect(remediated.Spec.Template.Spec.Containers[0].Image).To(Equal(repository+"@"+want), name)
+		}
+	})
+
+	It("should resolve tags added since the last check without waiting for the interval", func() {
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "tags", Namespace: "default"},
+			Spec:       securityv1.ImagePolicySpec{Repository: repository, Tags: []string{"latest"}},
+		}
+		reconciler, fakeClient := reconcile(policy)
+		Expect(manifestRequests).To(HaveLen(1))
+
+		updated := &securityv1.ImagePolicy{}
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(policy), updated)).To(Succeed())
+		updated.Spec.Tags = append(updated.Spec.Tags, "stable")
+		Expect(fakeClient.Update(ctx, updated)).To(Succeed())
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(policy)})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(policy), updated)).To(Succeed())
+		Expect(updated.Status.Repositories[0].Tags).To(HaveLen(2))
+		Expect(updated.Status.Repositories[0].Tags[1]).To(Equal(securityv1.TagStatus{Tag: "stable", LatestDigest: stableDigest}))
+	})
+
+	It("should flag a tracked tag that can't be resolved", func() {
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "tags", Namespace: "default"},
+			Spec:       securityv1.ImagePolicySpec{Repository: repository, Tags: []string{"latest", "missing"}},
+		}
+		_, fakeClient := reconcile(policy)
+
+		updated := &securityv1.ImagePolicy{}
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(policy), updated)).To(Succeed())
+		Expect(updated.Status.Repositories[0].Tags).To(Equal([]securityv1.TagStatus{
+			{Tag: "latest", LatestDigest: latestDigest},
+			{Tag: "missing"},
+		}))
+		degraded := meta.FindStatusCondition(updated.Status.Conditions, securityv1.ConditionTypeDegraded)
+		Expect(degraded).NotTo(BeNil())
+		Expect(degraded.Reason).To(Equal("DockerHubError"))
+		Expect(degraded.Message).To(ContainSubstring(repository + ":missing"))
+		Expect(strings.Join(manifestRequests, ",")).To(ContainSubstring("/manifests/missing"))
+	})
+})
@@ -0,0 +1,83 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/blang/semver/v4"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+// ValidateImagePolicy checks the spec fields reconciles would otherwise only report as Degraded conditions
+// (durations, the repository pattern, the semver range, the automation gate and remediation window), that
+// Secret and ConfigMap references stay in the policy's namespace and that required attestations name the
+// issuers to trust. It backs the ImagePolicy validating webhook; field
+// combinations the CRD schema can express are validated there instead.
+func ValidateImagePolicy(policy *securityv1.ImagePolicy) field.ErrorList {
+	spec := field.NewPath("spec")
+	var errs field.ErrorList
+
+	if policy.Spec.RepositoryPattern != "" {
+		if err := validateRepositoryPattern(policy.Spec.RepositoryPattern); err != nil {
+			errs = append(errs, field.Invalid(spec.Child("repositoryPattern"), policy.Spec.RepositoryPattern, err.Error()))
+		}
+	}
+	if policy.Spec.TagSemverRange != "" {
+		if _, err := semver.ParseRange(policy.Spec.TagSemverRange); err != nil {
+			errs = append(errs, field.Invalid(spec.Child("tagSemverRange"), policy.Spec.TagSemverRange, err.Error()))
+		}
+	}
+	if policy.Spec.AutomationGate != nil {
+		if err := validateAutomationGate(automationGate(policy)); err != nil {
+			errs = append(errs, field.Invalid(spec.Child("automationGate"), *policy.Spec.AutomationGate, err.Error()))
+		}
+	}
+	if _, err := maxDriftDuration(policy); err != nil {
+		errs = append(errs, field.Invalid(spec.Child("maxDriftDuration"), *policy.Spec.MaxDriftDuration, err.Error()))
+	}
+	if _, err := remediationCooldown(policy); err != nil {
+		errs = append(errs, field.Invalid(spec.Child("remediationCooldown"), *policy.Spec.RemediationCooldown, err.Error()))
+	}
+	if _, err := parseRemediationWindow(policy); err != nil {
+		errs = append(errs, field.Invalid(spec.Child("remediationWindow"), *policy.Spec.RemediationWindow, err.Error()))
+	}
+	if _, err := namespaceAnalysisTimeout(policy); err != nil {
+		errs = append(errs, field.Invalid(spec.Child("namespaceAnalysisTimeout"), *policy.Spec.NamespaceAnalysisTimeout, err.Error()))
+	}
+
+	errs = append(errs, foreignReferences(policy)...)
+
+	if attestationPolicy := policy.Spec.AttestationPolicy; attestationPolicy != nil {
+		path := spec.Child("attestationPolicy")
+		if _, err := attestationMaxAge(attestationPolicy); err != nil {
+			errs = append(errs, field.Invalid(path.Child("maxAge"), *attestationPolicy.MaxAge, err.Error()))
+		}
+		if attestationPolicy.RekorPublicKeyRef != nil && attestationPolicy.RekorURL == "" {
+			errs = append(errs, field.Required(path.Child("rekorURL"), "the Rekor server rekorPublicKeyRef is the key of is required"))
+		}
+		if attestationPolicy.TUFMirror != nil && attestationPolicy.FulcioRootRef != nil {
+			errs = append(errs, field.Forbidden(path.Child("fulcioRootRef"), "the Fulcio root comes from tufMirror when it is set"))
+		}
+		// Without issuers, an attestation signed through any OIDC provider would pass
+		if attestationPolicy.RequiresVerification() && len(attestationPolicy.AllowedIssuers) == 0 {
+			errs = append(errs, field.Required(path.Child("allowedIssuers"),
+				"the OIDC issuers to trust are required when requireAttestation, requireSBOM or maxVulnerabilitySeverity is set"))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("ImagePolicy validation", func() {
+	// invalidFields returns the paths of the fields ValidateImagePolicy rejects
+	invalidFields := func(spec securityv1.ImagePolicySpec) []string {
+		var paths []string
+		for _, err := range ValidateImagePolicy(&securityv1.ImagePolicy{Spec: spec}) {
+			paths = append(paths, err.Field)
+		}
+		return paths
+	}
+
+	It("should accept a valid spec", func() {
+		Expect(invalidFields(securityv1.ImagePolicySpec{
+			Repository:          "jonlimpw/cg-demo",
+			RepositoryPattern:   "chainguard/*",
+			TagSemverRange:      ">=1.2.0 <2.0.0",
+			MaxDriftDuration:    ptr.To("24h"),
+			RemediationCooldown: ptr.To("10m"),
+			RemediationWindow:   &securityv1.RemediationWindow{Start: "22:00", End: "04:00", TimeZone: "Europe/Berlin"},
+			AutomationGate:      &securityv1.AutomationGate{Key: "example.com/automation"},
+			AttestationPolicy: &securityv1.AttestationPolicy{
+				RequireAttestation: ptr.To(true),
+				AllowedIssuers:     []string{"https://token.actions.githubusercontent.com"},
+				MaxAge:             ptr.To("720h"),
+			},
+		})).To(BeEmpty())
+	})
+
+	It("should report every field reconciles would find invalid", func() {
+		Expect(invalidFields(securityv1.ImagePolicySpec{
+			Repository:               "jonlimpw/cg-demo",
+			RepositoryPattern:        "chainguard/[",
+			TagSemverRange:           "one point two",
+			MaxDriftDuration:         ptr.To("2 days"),
+			RemediationCooldown:      ptr.To("-5m"),
+			RemediationWindow:        &securityv1.RemediationWindow{Start: "02:00", End: "02:00"},
+			AutomationGate:           &securityv1.AutomationGate{Key: "not a key"},
+			NamespaceAnalysisTimeout: ptr.To("0s"),
+			PullSecretRef:            &corev1.SecretReference{Name: "dockerhub", Namespace: "platform"},
+			GitRepoRef:               &securityv1.GitRepoRef{SecretRef: corev1.SecretReference{Name: "github", Namespace: "platform"}},
+			NotificationConfig:       &securityv1.NotificationConfig{SecretRef: corev1.SecretReference{Name: "slack", Namespace: "platform"}},
+			GoldenDigestRef:          &securityv1.GoldenDigestRef{Name: "promoted", Namespace: "platform"},
+			AttestationPolicy: &securityv1.AttestationPolicy{
+				AllowedIssuers:    []string{"https://token.actions.githubusercontent.com"},
+				MaxAge:            ptr.To("a month"),
+				FulcioRootRef:     &securityv1.FulcioRootRef{Name: "fulcio-root", Namespace: "sigstore-system"},
+				RekorPublicKeyRef: &securityv1.RekorPublicKeyRef{Name: "rekor-key", Namespace: "sigstore-system"},
+				TUFMirror: &securityv1.TUFMirror{URL: "https://tuf.sigstore.internal",
+					RootRef: securityv1.TUFRootRef{Name: "tuf-root", Namespace: "sigstore-system"}},
+			},
+		})).To(ConsistOf(
+			"spec.repositoryPattern",
+			"spec.tagSemverRange",
+			"spec.maxDriftDuration",
+			"spec.remediationCooldown",
+			"spec.remediationWindow",
+			"spec.namespaceAnalysisTimeout",
+			"spec.automationGate",
+			"spec.attestationPolicy.maxAge",
+			"spec.pullSecretRef.namespace",
+			"spec.gitRepoRef.secretRef.namespace",
+			"spec.notificationConfig.secretRef.namespace",
+			"spec.goldenDigestRef.namespace",
+			"spec.attestationPolicy.fulcioRootRef.namespace",
+			"spec.attestationPolicy.rekorPublicKeyRef.namespace",
+			"spec.attestationPolicy.tufMirror.rootRef.namespace",
+			"spec.attestationPolicy.rekorURL",
+			"spec.attestationPolicy.fulcioRootRef",
+		))
+	})
+
+	It("should require the issuers to trust when attestations are required", func() {
+		for _, attestationPolicy := range []*securityv1.AttestationPolicy{
+			{RequireAttestation: ptr.To(true)},
+			{RequireSBOM: true},
+			{MaxVulnerabilitySeverity: "High"},
+		} {
+			errs := ValidateImagePolicy(&securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{
+				Repository: "jonlimpw/cg-demo", AttestationPolicy: attestationPolicy,
+			}})
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Type).To(Equal(field.ErrorTypeRequired))
+			Expect(errs[0].Field).To(Equal("spec.attestationPolicy.allowedIssuers"))
+		}
+
+		Expect(invalidFields(securityv1.ImagePolicySpec{
+			Repository: "jonlimpw/cg-demo", AttestationPolicy: &securityv1.AttestationPolicy{RequireAttestation: ptr.To(false)},
+		})).To(BeEmpty())
+	})
+})
@@ -0,0 +1,192 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+// namespaceEventDelay is how long policy reconciles triggered by namespace events are held back. The
+// workqueue merges requests for a policy waiting to be added, so namespaces created or relabeled in bulk
+// cause one reconcile per policy rather than one per namespace.
+const namespaceEventDelay = 5 * time.Second
+
+// policyChangedPredicate passes policy updates that change its spec or annotations (e.g. pausing it),
+// so the reconciler's own status writes don't requeue the policy.
+// Deleting a policy with a finalizer bumps its generation, so that still passes.
+func policyChangedPredicate() predicate.Predicate {
+	return predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{})
+}
+
+// workloadChangedPredicate passes workload updates that can change compliance: a container image or name
+// in the pod template, or the labels and annotations that select, gate and skip containers. Status updates
+// and spec changes such as scaling bump the resource version, or the generation, but are filtered out.
+func workloadChangedPredicate() predicate.Predicate {
+	return predicate.Or(containersChangedPredicate(), predicate.LabelChangedPredicate{}, predicate.AnnotationChangedPredicate{})
+}
+
+// containersChangedPredicate passes workload updates that change the containers of the pod template
+func containersChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			previous, ok := newWorkload(e.ObjectOld)
+			if !ok {
+				return true
+			}
+			updated, ok := newWorkload(e.ObjectNew)
+			if !ok {
+				return true
+			}
+			return !slices.EqualFunc(previous.containers(), updated.containers(), func(a, b podContainer) bool {
+				return a.Kind == b.Kind && a.Name == b.Name && *a.Image == *b.Image
+			})
+		},
+	}
+}
+
+// policiesForWorkload maps a changed workload to every ImagePolicy selecting it that monitors a repository
+// it uses, or that still reports it as monitored (e.g. after its image moved to another repository).
+// Requests for a policy already queued (e.g. from the old and new object of an update) are merged by the workqueue.
+func (r *ImagePolicyReconciler) policiesForWorkload(ctx context.Context, obj client.Object) []reconcile.Request {
+	log := logf.FromContext(ctx)
+
+	deployment, ok := newWorkload(obj)
+	if !ok {
+		return nil
+	}
+
+	policies := &securityv1.ImagePolicyList{}
+	if err := r.List(ctx, policies); err != nil {
+		log.Error(err, "Failed to list ImagePolicies for workload", "kind", deployment.Kind, "deployment", deployment.GetName(), "namespace", deployment.GetNamespace())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range policies.Items {
+		policy := &policies.Items[i]
+		if !policy.DeletionTimestamp.IsZero() || !r.policyWatchesWorkload(ctx, policy, deployment) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: policy.Namespace, Name: policy.Name},
+		})
+	}
+	return requests
+}
+
+// policyWatchesWorkload checks if a workload change is relevant to a policy
+func (r *ImagePolicyReconciler) policyWatchesWorkload(ctx context.Context, policy *securityv1.ImagePolicy, deployment workload) bool {
+	if findDeploymentStatus(policy, deployment) != nil {
+		return true
+	}
+
+	selected, err := r.policySelectsWorkload(ctx, policy, deployment)
+	if err != nil || !selected {
+		return false
+	}
+	return r.deploymentUsesMonitoredRepository(policy, deployment)
+}
+
+// enqueuePoliciesForNamespace returns the handler that delays and merges the reconciles of the policies
+// affected by a created, relabeled or deleted namespace
+func (r *ImagePolicyReconciler) enqueuePoliciesForNamespace() handler.EventHandler {
+	enqueue := func(ctx context.Context, obj client.Object, queue workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+		for _, request := range r.policiesForNamespace(ctx, obj) {
+			queue.AddAfter(request, namespaceEventDelay)
+		}
+	}
+
+	return handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent, queue workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, e.Object, queue)
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, queue workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, e.ObjectNew, queue)
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, queue workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, e.Object, queue)
+		},
+	}
+}
+
+// policiesForNamespace maps a namespace to every ImagePolicy monitoring it: those without a namespace selector
+// or whose selector matches its labels, unless they exclude it, and those still reporting workloads in it
+// (e.g. after its labels stopped matching or it was deleted)
+func (r *ImagePolicyReconciler) policiesForNamespace(ctx context.Context, obj client.Object) []reconcile.Request {
+	log := logf.FromContext(ctx)
+
+	namespace, ok := obj.(*corev1.Namespace)
+	if !ok {
+		return nil
+	}
+
+	policies := &securityv1.ImagePolicyList{}
+	if err := r.List(ctx, policies); err != nil {
+		log.Error(err, "Failed to list ImagePolicies for namespace", "namespace", namespace.Name)
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range policies.Items {
+		policy := &policies.Items[i]
+		if !policy.DeletionTimestamp.IsZero() || !policyWatchesNamespace(policy, namespace) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: policy.Namespace, Name: policy.Name},
+		})
+	}
+	return requests
+}
+
+// policyWatchesNamespace checks if a namespace change is relevant to a policy
+func policyWatchesNamespace(policy *securityv1.ImagePolicy, namespace *corev1.Namespace) bool {
+	for _, status := range policy.Status.MonitoredDeployments {
+		if status.Namespace == namespace.Name {
+			return true
+		}
+	}
+
+	if namespaceExcluded(policy, namespace.Name) {
+		return false
+	}
+	if policy.Spec.NamespaceSelector == nil {
+		return true
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(policy.Spec.NamespaceSelector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(namespace.Labels))
+}
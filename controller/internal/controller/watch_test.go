@@ -0,0 +1,206 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Workload watches", func() {
+	const repository = "jonlimpw/cg-demo"
+
+	ctx := context.Background()
+
+	newPolicy := func(name, repository string, mutate func(*securityv1.ImagePolicy)) client.Object {
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "policies"},
+			Spec:       securityv1.ImagePolicySpec{Repository: repository},
+		}
+		if mutate != nil {
+			mutate(policy)
+		}
+		return policy
+	}
+
+	newDeployment := func(image string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "app", Image: image}},
+			}}},
+		}
+	}
+
+	It("should enqueue the policies selecting a changed workload", func() {
+		reconciler := &ImagePolicyReconciler{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			newPolicy("matching", repository, nil),
+			newPolicy("other-repository", "jonlimpw/other", nil),
+			newPolicy("excluded", repository, func(policy *securityv1.ImagePolicy) {
+				policy.Spec.ExcludeNamespaces = []string{"default"}
+			}),
+			newPolicy("previously-monitored", "jonlimpw/other", func(policy *securityv1.ImagePolicy) {
+				policy.Status.MonitoredDeployments = []securityv1.DeploymentStatus{
+					{Kind: securityv1.WorkloadKindDeployment, Name: "demo", Namespace: "default"},
+				}
+			}),
+		).Build()}
+
+		requests := reconciler.policiesForWorkload(ctx, newDeployment(repository+":latest"))
+
+		var names []types.NamespacedName
+		for _, request := range requests {
+			names = append(names, request.NamespacedName)
+		}
+		Expect(names).To(ConsistOf(
+			types.NamespacedName{Namespace: "policies", Name: "matching"},
+			types.NamespacedName{Namespace: "policies", Name: "previously-monitored"},
+		))
+	})
+
+	Context("namespaces", func() {
+		newNamespace := func(name string, labels map[string]string) *corev1.Namespace {
+			return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+		}
+
+		newReconciler := func() *ImagePolicyReconciler {
+			return &ImagePolicyReconciler{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+				newPolicy("all-namespaces", repository, nil),
+				newPolicy("matching-selector", repository, func(policy *securityv1.ImagePolicy) {
+					policy.Spec.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}}
+				}),
+				newPolicy("other-selector", repository, func(policy *securityv1.ImagePolicy) {
+					policy.Spec.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "search"}}
+				}),
+				newPolicy("excluded", repository, func(policy *securityv1.ImagePolicy) {
+					policy.Spec.ExcludeNamespaces = []string{"payments"}
+				}),
+				newPolicy("previously-monitored", repository, func(policy *securityv1.ImagePolicy) {
+					policy.Spec.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "search"}}
+					policy.Status.MonitoredDeployments = []securityv1.DeploymentStatus{
+						{Kind: securityv1.WorkloadKindDeployment, Name: "demo", Namespace: "payments"},
+					}
+				}),
+			).Build()}
+		}
+
+		It("should enqueue the policies monitoring a namespace", func() {
+			requests := newReconciler().policiesForNamespace(ctx, newNamespace("payments", map[string]string{"team": "payments"}))
+
+			var names []types.NamespacedName
+			for _, request := range requests {
+				names = append(names, request.NamespacedName)
+			}
+			Expect(names).To(ConsistOf(
+				types.NamespacedName{Namespace: "policies", Name: "all-namespaces"},
+				types.NamespacedName{Namespace: "policies", Name: "matching-selector"},
+				types.NamespacedName{Namespace: "policies", Name: "previously-monitored"},
+			))
+		})
+
+		It("should delay the requests so namespaces created in bulk reconcile each policy once", func() {
+			queue := &delayRecordingQueue{TypedRateLimitingInterface: workqueue.NewTypedRateLimitingQueue(
+				workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())}
+			DeferCleanup(queue.ShutDown)
+
+			handler := newReconciler().enqueuePoliciesForNamespace()
+			for _, name := range []string{"team-a", "team-b", "team-c"} {
+				handler.Create(ctx, event.CreateEvent{Object: newNamespace(name, nil)}, queue)
+			}
+
+			Expect(queue.Len()).To(BeZero())
+			Expect(queue.delays).To(HaveLen(6)) // all-namespaces and excluded, for each namespace
+			Expect(queue.delays).To(HaveEach(namespaceEventDelay))
+		})
+	})
+
+	Context("update predicates", func() {
+		It("should requeue policies on spec and annotation changes but not on status updates", func() {
+			policy := &securityv1.ImagePolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "policies", Generation: 1}}
+			changed := policyChangedPredicate()
+
+			statusUpdated := policy.DeepCopy()
+			statusUpdated.ResourceVersion = "2"
+			statusUpdated.Status.ComplianceStatus = securityv1.ComplianceStatusCompliant
+			Expect(changed.Update(event.UpdateEvent{ObjectOld: policy, ObjectNew: statusUpdated})).To(BeFalse())
+
+			specChanged := policy.DeepCopy()
+			specChanged.Generation = 2
+			Expect(changed.Update(event.UpdateEvent{ObjectOld: policy, ObjectNew: specChanged})).To(BeTrue())
+
+			paused := policy.DeepCopy()
+			paused.Annotations = map[string]string{securityv1.AnnotationPaused: "true"}
+			Expect(changed.Update(event.UpdateEvent{ObjectOld: policy, ObjectNew: paused})).To(BeTrue())
+		})
+
+		It("should requeue for image changes but not for status updates or scaling", func() {
+			deployment := newDeployment(repository + ":v1")
+			deployment.Generation = 1
+			changed := workloadChangedPredicate()
+
+			statusUpdated := deployment.DeepCopy()
+			statusUpdated.ResourceVersion = "2"
+			statusUpdated.Status.UpdatedReplicas = 1
+			Expect(changed.Update(event.UpdateEvent{ObjectOld: deployment, ObjectNew: statusUpdated})).To(BeFalse())
+
+			scaled := deployment.DeepCopy()
+			scaled.Generation = 2
+			scaled.Spec.Replicas = ptr.To(int32(3))
+			Expect(changed.Update(event.UpdateEvent{ObjectOld: deployment, ObjectNew: scaled})).To(BeFalse())
+
+			imageChanged := deployment.DeepCopy()
+			imageChanged.Generation = 2
+			imageChanged.Spec.Template.Spec.Containers[0].Image = repository + ":v2"
+			Expect(changed.Update(event.UpdateEvent{ObjectOld: deployment, ObjectNew: imageChanged})).To(BeTrue())
+
+			sidecarAdded := deployment.DeepCopy()
+			sidecarAdded.Spec.Template.Spec.Containers = append(sidecarAdded.Spec.Template.Spec.Containers, corev1.Container{Name: "proxy", Image: "envoy"})
+			Expect(changed.Update(event.UpdateEvent{ObjectOld: deployment, ObjectNew: sidecarAdded})).To(BeTrue())
+
+			gated := deployment.DeepCopy()
+			gated.Labels = map[string]string{"automation": "true"}
+			Expect(changed.Update(event.UpdateEvent{ObjectOld: deployment, ObjectNew: gated})).To(BeTrue())
+		})
+	})
+})
+
+// delayRecordingQueue records the delay of each request added with AddAfter
+type delayRecordingQueue struct {
+	workqueue.TypedRateLimitingInterface[reconcile.Request]
+	delays []time.Duration
+}
+
+func (q *delayRecordingQueue) AddAfter(request reconcile.Request, delay time.Duration) {
+	q.delays = append(q.delays, delay)
+	q.TypedRateLimitingInterface.AddAfter(request, delay)
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+// weekdays maps the day names of RemediationWindow.Days to weekdays
+var weekdays = map[string]time.Weekday{
+	"Sun": time.Sunday, "Mon": time.Monday, "Tue": time.Tuesday, "Wed": time.Wednesday,
+	"Thu": time.Thursday, "Fri": time.Friday, "Sat": time.Saturday,
+}
+
+// remediationWindow is a parsed RemediationWindow
+type remediationWindow struct {
+	// days the window opens on, every day when empty
+	days []time.Weekday
+
+	// start and end are the times of day the window opens and closes at; an end before start is on the next day
+	start, end time.Duration
+
+	location *time.Location
+}
+
+// parseRemediationWindow parses a policy's RemediationWindow, nil when unset
+func parseRemediationWindow(policy *securityv1.ImagePolicy) (*remediationWindow, error) {
+	spec := policy.Spec.RemediationWindow
+	if spec == nil {
+		return nil, nil
+	}
+
+	window := &remediationWindow{location: time.UTC}
+	var err error
+	if window.start, err = parseTimeOfDay(spec.Start); err != nil {
+		return nil, fmt.Errorf("invalid RemediationWindow start: %w", err)
+	}
+	if window.end, err = parseTimeOfDay(spec.End); err != nil {
+		return nil, fmt.Errorf("invalid RemediationWindow end: %w", err)
+	}
+	if window.start == window.end {
+		return nil, errors.New("invalid RemediationWindow: start and end must differ")
+	}
+	if spec.TimeZone != "" {
+		if window.location, err = time.LoadLocation(spec.TimeZone); err != nil {
+			return nil, fmt.Errorf("invalid RemediationWindow time zone %q: %w", spec.TimeZone, err)
+		}
+	}
+	for _, day := range spec.Days {
+		weekday, ok := weekdays[day]
+		if !ok {
+			return nil, fmt.Errorf("invalid RemediationWindow day %q: expected Mon, Tue, Wed, Thu, Fri, Sat or Sun", day)
+		}
+		window.days = append(window.days, weekday)
+	}
+	return window, nil
+}
+
+// parseTimeOfDay parses an HH:MM time of day into the time since midnight
+func parseTimeOfDay(value string) (time.Duration, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an HH:MM time", value)
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+// opensIn returns how long until the window is next open after now, 0 while it is open
+func (w *remediationWindow) opensIn(now time.Time) time.Duration {
+	local := now.In(w.location)
+
+	// Start from yesterday, whose window may run past midnight, and look a week ahead
+	var next time.Time
+	for offset := -1; offset <= 7; offset++ {
+		day := time.Date(local.Year(), local.Month(), local.Day()+offset, 0, 0, 0, 0, w.location)
+		if len(w.days) > 0 && !slices.Contains(w.days, day.Weekday()) {
+			continue
+		}
+
+		opens := w.at(day, w.start)
+		closes := w.at(day, w.end)
+		if w.end < w.start {
+			closes = w.at(day.AddDate(0, 0, 1), w.end)
+		}
+		if !now.Before(opens) && now.Before(closes) {
+			return 0
+		}
+		if opens.After(now) && (next.IsZero() || opens.Before(next)) {
+			next = opens
+		}
+	}
+	return next.Sub(now)
+}
+
+// at returns the time of day on a day in the window's time zone, following its clock across DST changes
+func (w *remediationWindow) at(day time.Time, timeOfDay time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), int(timeOfDay/time.Hour), int(timeOfDay%time.Hour/time.Minute), 0, 0, w.location)
+}
+
+// remediationDeferral returns how long remediation of a policy's workloads is deferred by its
+// RemediationWindow, 0 when it may run now. An invalid window defers remediation until it is fixed,
+// which is reported by the returned error.
+func remediationDeferral(policy *securityv1.ImagePolicy, now time.Time) (time.Duration, error) {
+	window, err := parseRemediationWindow(policy)
+	if err != nil || window == nil {
+		return 0, err
+	}
+	return window.opensIn(now), nil
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Remediation window", func() {
+	const (
+		repository   = "jonlimpw/cg-demo"
+		latestDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		staleDigest  = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	)
+
+	// wednesday returns a time of day on Wednesday October 14, 2026, in UTC
+	wednesday := func(hour, minute int) time.Time {
+		return time.Date(2026, time.October, 14, hour, minute, 0, 0, time.UTC)
+	}
+	windowPolicy := func(window securityv1.RemediationWindow) *securityv1.ImagePolicy {
+		return &securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{RemediationWindow: &window}}
+	}
+	opensIn := func(window securityv1.RemediationWindow, now time.Time) time.Duration {
+		GinkgoHelper()
+		deferral, err := remediationDeferral(windowPolicy(window), now)
+		Expect(err).NotTo(HaveOccurred())
+		return deferral
+	}
+
+	It("should never defer remediation without a window", func() {
+		deferral, err := remediationDeferral(&securityv1.ImagePolicy{}, wednesday(12, 0))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deferral).To(BeZero())
+	})
+
+	It("should defer remediation until a daily window opens", func() {
+		window := securityv1.RemediationWindow{Start: "09:00", End: "17:30"}
+		Expect(opensIn(window, wednesday(9, 0))).To(BeZero())
+		Expect(opensIn(window, wednesday(17, 29))).To(BeZero())
+		Expect(opensIn(window, wednesday(8, 15))).To(Equal(45 * time.Minute))
+		Expect(opensIn(window, wednesday(17, 30))).To(Equal(15*time.Hour + 30*time.Minute))
+	})
+
+	It("should keep an overnight window open past midnight", func() {
+		window := securityv1.RemediationWindow{Start: "22:00", End: "04:00"}
+		Expect(opensIn(window, wednesday(23, 0))).To(BeZero())
+		Expect(opensIn(window, wednesday(3, 59))).To(BeZero())
+		Expect(opensIn(window, wednesday(4, 0))).To(Equal(18 * time.Hour))
+	})
+
+	It("should only open on the listed days, in the window's time zone", func() {
+		// 02:00 in Berlin on Saturday October 17 is midnight UTC, summer time being UTC+2
+		window := securityv1.RemediationWindow{Start: "02:00", End: "05:00", Days: []string{"Sat", "Sun"}, TimeZone: "Europe/Berlin"}
+		Expect(opensIn(window, wednesday(12, 0))).To(Equal(60 * time.Hour))
+		Expect(opensIn(window, time.Date(2026, time.October, 18, 1, 0, 0, 0, time.UTC))).To(BeZero())
+
+		// Sunday's window is the last of the week; the next is on Saturday
+		Expect(opensIn(window, time.Date(2026, time.October, 18, 3, 0, 0, 0, time.UTC))).To(Equal(5*24*time.Hour + 21*time.Hour))
+	})
+
+	It("should reject malformed windows", func() {
+		for _, window := range []securityv1.RemediationWindow{
+			{Start: "9am", End: "17:00"},
+			{Start: "09:00", End: "24:00"},
+			{Start: "09:00", End: "09:00"},
+			{Start: "09:00", End: "17:00", Days: []string{"Monday"}},
+			{Start: "09:00", End: "17:00", TimeZone: "Mars/Olympus_Mons"},
+		} {
+			_, err := parseRemediationWindow(windowPolicy(window))
+			Expect(err).To(MatchError(ContainSubstring("invalid RemediationWindow")), "%+v", window)
+		}
+	})
+
+	Context("remediation", func() {
+		var (
+			fakeClient client.Client
+			recorder   *record.FakeRecorder
+			running    *appsv1.Deployment
+		)
+
+		BeforeEach(func() {
+			running = &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default", Labels: map[string]string{"automation": "true"}},
+				Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "app", Image: repository + "@" + staleDigest}},
+				}}},
+			}
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(running).Build()
+			recorder = record.NewFakeRecorder(10)
+		})
+
+		// windowFromNow returns a UTC window opening and closing at the given offsets from now
+		windowFromNow := func(start, end time.Duration) *securityv1.RemediationWindow {
+			now := time.Now().UTC()
+			return &securityv1.RemediationWindow{Start: now.Add(start).Format("15:04"), End: now.Add(end).Format("15:04")}
+		}
+
+		// remediate runs remediation of the outdated workload and returns the image it ends up with
+		remediate := func(window *securityv1.RemediationWindow) string {
+			GinkgoHelper()
+			policy := &securityv1.ImagePolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+				Spec:       securityv1.ImagePolicySpec{Repository: repository, RemediationWindow: window},
+			}
+			w, _ := newWorkload(running.DeepCopy())
+			status := &securityv1.DeploymentStatus{Name: "demo", Namespace: "default", Repository: repository, CurrentDigest: staleDigest}
+			reconciler := &ImagePolicyReconciler{Client: fakeClient, Recorder: recorder}
+			reconciler.handleRemediation(context.Background(), policy, w, status, reconciler.rulesFor(policy), map[string]string{repository: latestDigest}, securityv1.RemediationModeAuto, nil)
+
+			deployment := &appsv1.Deployment{}
+			Expect(fakeClient.Get(context.Background(), types.NamespacedName{Name: "demo", Namespace: "default"}, deployment)).To(Succeed())
+			return deployment.Spec.Template.Spec.Containers[0].Image
+		}
+
+		It("should remediate within the window", func() {
+			Expect(remediate(windowFromNow(-time.Hour, time.Hour))).To(Equal(repository + "@" + latestDigest))
+			Expect(recorder.Events).To(Receive(ContainSubstring("AutoRemediated")))
+		})
+
+		It("should defer remediation outside the window with an event", func() {
+			Expect(remediate(windowFromNow(2*time.Hour, 3*time.Hour))).To(Equal(repository + "@" + staleDigest))
+			Expect(recorder.Events).To(Receive(And(ContainSubstring("Normal RemediationDeferred"), ContainSubstring("Deployment default/demo"))))
+		})
+
+		It("should defer remediation while the window is invalid", func() {
+			Expect(remediate(&securityv1.RemediationWindow{Start: "09:00", End: "09:00"})).To(Equal(repository + "@" + staleDigest))
+			Expect(recorder.Events).To(Receive(ContainSubstring("start and end must differ")))
+		})
+
+		It("should requeue a policy with deferred remediations when the window opens", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/token" {
+					_, _ = w.Write([]byte(`{"token":"test"}`))
+					return
+				}
+				w.Header().Set("Docker-Content-Digest", latestDigest)
+			}))
+			previousRegistryURL, previousAuthURL := dockerHubRegistryURL, dockerHubAuthURL
+			dockerHubRegistryURL, dockerHubAuthURL = server.URL, server.URL
+			DeferCleanup(func() {
+				server.Close()
+				dockerHubRegistryURL, dockerHubAuthURL = previousRegistryURL, previousAuthURL
+			})
+
+			key := types.NamespacedName{Name: "windowed", Namespace: "default"}
+			policy := &securityv1.ImagePolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec: securityv1.ImagePolicySpec{
+					Repository:           repository,
+					CheckIntervalSeconds: ptr.To(int32(3600)),
+					RemediationWindow:    windowFromNow(30*time.Minute, 2*time.Hour),
+				},
+			}
+			fakeClient = fake.NewClientBuilder().WithScheme(scheme.Scheme).
+				WithObjects(policy, running, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}).
+				WithStatusSubresource(policy).Build()
+			reconciler := &ImagePolicyReconciler{Client: fakeClient, Recorder: recorder}
+
+			result, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("~", 30*time.Minute, time.Minute))
+			Eventually(recorder.Events).Should(Receive(ContainSubstring("RemediationDeferred")))
+		})
+	})
+})
@@ -0,0 +1,382 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+	"github.com/jonlimpw/chainguard-controller/internal/registry"
+)
+
+// workload is a monitored object with a pod template (Deployment, StatefulSet, DaemonSet, CronJob or Job)
+type workload struct {
+	client.Object
+
+	// Kind is the workload kind, e.g. "Deployment"
+	Kind string
+
+	// Template points at the pod template inside Object, the job template's for CronJobs
+	Template *corev1.PodTemplateSpec
+}
+
+// newWorkload wraps a supported workload object, returning false for unsupported types
+func newWorkload(obj client.Object) (workload, bool) {
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		return workload{Object: o, Kind: securityv1.WorkloadKindDeployment, Template: &o.Spec.Template}, true
+	case *appsv1.StatefulSet:
+		return workload{Object: o, Kind: securityv1.WorkloadKindStatefulSet, Template: &o.Spec.Template}, true
+	case *appsv1.DaemonSet:
+		return workload{Object: o, Kind: securityv1.WorkloadKindDaemonSet, Template: &o.Spec.Template}, true
+	case *batchv1.CronJob:
+		return workload{Object: o, Kind: securityv1.WorkloadKindCronJob, Template: &o.Spec.JobTemplate.Spec.Template}, true
+	case *batchv1.Job:
+		return workload{Object: o, Kind: securityv1.WorkloadKindJob, Template: &o.Spec.Template}, true
+	default:
+		return workload{}, false
+	}
+}
+
+// deepCopy returns a copy of the workload whose template can be safely modified
+func (w workload) deepCopy() workload {
+	copied, _ := newWorkload(w.DeepCopyObject().(client.Object))
+	return copied
+}
+
+// podContainer references a container image inside a workload's pod template
+type podContainer struct {
+	// Kind is the container kind, e.g. "InitContainer"
+	Kind string
+
+	// Name is the container name, unique across all container kinds in the pod
+	Name string
+
+	// Image points at the image field inside the pod template so it can be updated in place
+	Image *string
+
+	// PullPolicy points at the imagePullPolicy field inside the pod template so it can be updated in place
+	PullPolicy *corev1.PullPolicy
+}
+
+// containers returns every container in the pod template: regular, init, then ephemeral containers
+func (w workload) containers() []podContainer {
+	spec := &w.Template.Spec
+	var containers []podContainer
+	for i := range spec.Containers {
+		containers = append(containers, podContainer{Kind: securityv1.ContainerKindContainer, Name: spec.Containers[i].Name, Image: &spec.Containers[i].Image, PullPolicy: &spec.Containers[i].ImagePullPolicy})
+	}
+	for i := range spec.InitContainers {
+		containers = append(containers, podContainer{Kind: securityv1.ContainerKindInitContainer, Name: spec.InitContainers[i].Name, Image: &spec.InitContainers[i].Image, PullPolicy: &spec.InitContainers[i].ImagePullPolicy})
+	}
+	for i := range spec.EphemeralContainers {
+		containers = append(containers, podContainer{Kind: securityv1.ContainerKindEphemeralContainer, Name: spec.EphemeralContainers[i].Name, Image: &spec.EphemeralContainers[i].Image, PullPolicy: &spec.EphemeralContainers[i].ImagePullPolicy})
+	}
+	return containers
+}
+
+// governedContainers returns the containers a policy applies to: all but the skipped ones, and only
+// the one named containerName when set
+func (w workload) governedContainers(containerName string) []podContainer {
+	skipped := w.skippedContainers()
+	var governed []podContainer
+	for _, container := range w.containers() {
+		if slices.Contains(skipped, container.Name) || (containerName != "" && container.Name != containerName) {
+			continue
+		}
+		governed = append(governed, container)
+	}
+	return governed
+}
+
+// ignored checks if the workload opted out of governance with the ignore label
+func (w workload) ignored() bool {
+	return w.GetLabels()[securityv1.LabelIgnore] == "true"
+}
+
+// skippedContainers returns the container names listed in the skip-containers annotation
+func (w workload) skippedContainers() []string {
+	var skipped []string
+	for _, name := range strings.Split(w.GetAnnotations()[securityv1.AnnotationSkipContainers], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			skipped = append(skipped, name)
+		}
+	}
+	return skipped
+}
+
+// imageReference is a container image reference parsed into its components
+type imageReference struct {
+	// Name is the reference as written without its tag or digest, e.g. "docker.io/nginx"
+	Name string
+
+	// Registry is the registry host, "index.docker.io" for DockerHub however it is written
+	Registry string
+
+	// Repository is the repository path within the registry, with library/ for official DockerHub images
+	Repository string
+
+	// Tag is the explicit tag, empty when the reference has none
+	Tag string
+
+	// Digest is the digest, empty for tag-based references
+	Digest string
+}
+
+// parseImageReference parses an image reference such as "registry.example.com:5000/team/app:1.2@sha256:..."
+func parseImageReference(image string) (imageReference, error) {
+	// The reference parser only accepts sha256 digests, so other algorithms are split off first
+	if base, digest, found := strings.Cut(image, "@"); found && !strings.HasPrefix(digest, "sha256:") {
+		if _, _, err := registry.ParseDigest(digest); err != nil {
+			return imageReference{}, fmt.Errorf("invalid image reference %q: %w", image, err)
+		}
+		parsed, err := parseImageReference(base)
+		if err != nil {
+			return imageReference{}, fmt.Errorf("invalid image reference %q: %w", image, err)
+		}
+		parsed.Digest = digest
+		return parsed, nil
+	}
+
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return imageReference{}, fmt.Errorf("invalid image reference %q: %w", image, err)
+	}
+	parsed := imageReference{
+		Name:       image,
+		Registry:   ref.Context().RegistryStr(),
+		Repository: ref.Context().RepositoryStr(),
+	}
+
+	// A digest reference may carry a tag too, which the parser drops
+	if digest, ok := ref.(name.Digest); ok {
+		parsed.Digest = digest.DigestStr()
+		parsed.Name, _, _ = strings.Cut(image, "@")
+	}
+	if tag, err := name.NewTag(parsed.Name); err == nil && strings.HasSuffix(parsed.Name, ":"+tag.TagStr()) {
+		parsed.Tag = tag.TagStr()
+		parsed.Name = strings.TrimSuffix(parsed.Name, ":"+parsed.Tag)
+	}
+	return parsed, nil
+}
+
+// imageUsesRepository checks if an image reference belongs to the DockerHub repository, with or without the docker.io prefix.
+// Official images match with or without library/, so "nginx" matches "nginx", "docker.io/nginx" and "docker.io/library/nginx".
+// Invalid references and images from other registries never match.
+func imageUsesRepository(image, repository string) bool {
+	ref, err := parseImageReference(image)
+	if err != nil {
+		return false
+	}
+	return ref.Registry == name.DefaultRegistry && ref.Repository == dockerHubRepository(repository)
+}
+
+// patternRepositories returns the DockerHub repositories of the containers' images that match the glob pattern,
+// in container order and without duplicates. Official images match as library/<name> but are returned by their
+// short name, like Repository is written; a malformed pattern matches nothing.
+func patternRepositories(containers []podContainer, pattern string) []string {
+	if pattern == "" {
+		return nil
+	}
+
+	var repositories []string
+	for _, container := range containers {
+		ref, err := parseImageReference(*container.Image)
+		if err != nil || ref.Registry != name.DefaultRegistry {
+			continue
+		}
+		if matched, err := path.Match(pattern, ref.Repository); err != nil || !matched {
+			continue
+		}
+		repository := strings.TrimPrefix(ref.Repository, "library/")
+		if !slices.Contains(repositories, repository) {
+			repositories = append(repositories, repository)
+		}
+	}
+	return repositories
+}
+
+// validateRepositoryPattern checks a RepositoryPattern is a well-formed glob
+func validateRepositoryPattern(pattern string) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid repository pattern %q: %w", pattern, err)
+	}
+	return nil
+}
+
+// disallowedRegistryContainer returns the first container whose image is pulled from a registry outside
+// allowed, and that registry host. Nothing is disallowed when allowed is empty; invalid references are skipped.
+func disallowedRegistryContainer(containers []podContainer, allowed []string) (podContainer, string, bool) {
+	if len(allowed) == 0 {
+		return podContainer{}, "", false
+	}
+
+	for _, container := range containers {
+		ref, err := parseImageReference(*container.Image)
+		if err != nil || registryAllowed(ref.Registry, allowed) {
+			continue
+		}
+		return container, ref.Registry, true
+	}
+	return podContainer{}, "", false
+}
+
+// registryAllowed checks if a parsed registry host is in the allowlist, where "docker.io" and
+// "index.docker.io" both allow DockerHub
+func registryAllowed(host string, allowed []string) bool {
+	for _, entry := range allowed {
+		allowedRegistry, err := name.NewRegistry(entry)
+		if err == nil && allowedRegistry.RegistryStr() == host {
+			return true
+		}
+	}
+	return false
+}
+
+// dockerHubRepository returns the DockerHub path of a repository, placing single-name official images under library/
+func dockerHubRepository(repository string) string {
+	if !strings.Contains(repository, "/") {
+		return "library/" + repository
+	}
+	return repository
+}
+
+// resolvedImage returns the fully qualified reference of a repository's digest, or an empty string if the digest is unknown
+func resolvedImage(repository, digest string) string {
+	if digest == "" {
+		return ""
+	}
+	return "docker.io/" + dockerHubRepository(repository) + "@" + digest
+}
+
+// imageName returns an image reference without its tag or digest
+func imageName(image string) string {
+	if ref, err := parseImageReference(image); err == nil {
+		return ref.Name
+	}
+	return image
+}
+
+// imageDigest returns the digest of a digest-based image reference, or an empty string for tag-based or invalid references
+func imageDigest(image string) string {
+	if ref, err := parseImageReference(image); err == nil {
+		return ref.Digest
+	}
+	return ""
+}
+
+// imageTag returns the tag of an image reference ("latest" when it has none), or "" for digest and invalid references
+func imageTag(image string) string {
+	ref, err := parseImageReference(image)
+	if err != nil || ref.Digest != "" {
+		return ""
+	}
+	if ref.Tag == "" {
+		return "latest"
+	}
+	return ref.Tag
+}
+
+// repositoryForImage returns the repository an image belongs to and its latest digest, or empty strings if none match
+func repositoryForImage(image string, latestDigests map[string]string) (string, string) {
+	for repository, latestDigest := range latestDigests {
+		if imageUsesRepository(image, repository) {
+			return repository, latestDigest
+		}
+	}
+	return "", ""
+}
+
+// listWorkloads lists Deployments, StatefulSets, DaemonSets, CronJobs and Jobs matching the given options
+func (r *ImagePolicyReconciler) listWorkloads(ctx context.Context, opts ...client.ListOption) ([]workload, error) {
+	var workloads []workload
+
+	deploymentList := &appsv1.DeploymentList{}
+	if err := r.List(ctx, deploymentList, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for i := range deploymentList.Items {
+		w, _ := newWorkload(&deploymentList.Items[i])
+		workloads = append(workloads, w)
+	}
+
+	statefulSetList := &appsv1.StatefulSetList{}
+	if err := r.List(ctx, statefulSetList, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for i := range statefulSetList.Items {
+		w, _ := newWorkload(&statefulSetList.Items[i])
+		workloads = append(workloads, w)
+	}
+
+	daemonSetList := &appsv1.DaemonSetList{}
+	if err := r.List(ctx, daemonSetList, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+	for i := range daemonSetList.Items {
+		w, _ := newWorkload(&daemonSetList.Items[i])
+		workloads = append(workloads, w)
+	}
+
+	cronJobList := &batchv1.CronJobList{}
+	if err := r.List(ctx, cronJobList, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list cronjobs: %w", err)
+	}
+	for i := range cronJobList.Items {
+		w, _ := newWorkload(&cronJobList.Items[i])
+		workloads = append(workloads, w)
+	}
+
+	jobList := &batchv1.JobList{}
+	if err := r.List(ctx, jobList, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	for i := range jobList.Items {
+		if skipJob(&jobList.Items[i]) {
+			continue
+		}
+		w, _ := newWorkload(&jobList.Items[i])
+		workloads = append(workloads, w)
+	}
+
+	return workloads, nil
+}
+
+// skipJob reports whether a Job is left out of monitoring: a Job created by a CronJob is covered by the
+// CronJob's template, and a Complete or Failed Job no longer runs its images
+func skipJob(job *batchv1.Job) bool {
+	if owner := metav1.GetControllerOf(job); owner != nil && owner.Kind == "CronJob" {
+		return true
+	}
+	for _, condition := range job.Status.Conditions {
+		if (condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed) && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,569 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Workload compliance", func() {
+	const (
+		repository   = "jonlimpw/cg-demo"
+		latestDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+		staleDigest  = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+		deniedDigest = "sha256:4444444444444444444444444444444444444444444444444444444444444444"
+	)
+
+	ctx := context.Background()
+	reconciler := &ImagePolicyReconciler{}
+
+	newDeployment := func(image, initImage string) workload {
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						InitContainers: []corev1.Container{{Name: "migrate", Image: initImage}},
+						Containers:     []corev1.Container{{Name: "app", Image: image}},
+					},
+				},
+			},
+		}
+		w, _ := newWorkload(deployment)
+		return w
+	}
+
+	It("should mark a deployment with an outdated init container as non-compliant", func() {
+		deployment := newDeployment(repository+"@"+latestDigest, repository+"@"+staleDigest)
+
+		Expect(reconciler.deploymentUsesRepository(deployment, repository)).To(BeTrue())
+
+		status := reconciler.analyzeDeploymentCompliance(ctx, deployment, repository, latestDigest, complianceRules{EnforceLatest: true})
+		Expect(status.IsCompliant).To(BeFalse())
+		Expect(status.CurrentDigest).To(Equal(staleDigest))
+		Expect(status.ContainerName).To(Equal("migrate"))
+		Expect(status.ContainerKind).To(Equal(securityv1.ContainerKindInitContainer))
+	})
+
+	It("should mark a deployment as compliant when every container uses the latest digest", func() {
+		deployment := newDeployment(repository+"@"+latestDigest, "docker.io/"+repository+"@"+latestDigest)
+
+		status := reconciler.analyzeDeploymentCompliance(ctx, deployment, repository, latestDigest, complianceRules{EnforceLatest: true})
+		Expect(status.IsCompliant).To(BeTrue())
+		Expect(status.ContainerName).To(Equal("app"))
+		Expect(status.ContainerKind).To(Equal(securityv1.ContainerKindContainer))
+	})
+
+	It("should mark an allowlisted digest as compliant even when it isn't the latest", func() {
+		deployment := newDeployment(repository+"@"+staleDigest, repository+"@"+latestDigest)
+
+		status := reconciler.analyzeDeploymentCompliance(ctx, deployment, repository, latestDigest, complianceRules{EnforceLatest: true, AllowedDigests: []string{staleDigest}})
+		Expect(status.IsCompliant).To(BeTrue())
+		Expect(status.CurrentDigest).To(Equal(staleDigest))
+	})
+
+	It("should not remediate allowlisted digests", func() {
+		deployment := newDeployment(repository+"@"+staleDigest, repository+"@"+staleDigest)
+		deployment.Template.Spec.InitContainers[0].Image = "docker.io/" + repository + ":v1"
+		fakeClient := fake.NewClientBuilder().WithObjects(deployment.Object).Build()
+		remediator := &ImagePolicyReconciler{Client: fakeClient}
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+			Spec:       securityv1.ImagePolicySpec{AllowedDigests: []string{staleDigest}},
+		}
+
+		Expect(remediator.remediateDeployment(ctx, policy, deployment, remediator.rulesFor(policy), map[string]string{repository: latestDigest})).To(Succeed())
+
+		updated := &appsv1.Deployment{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "demo", Namespace: "default"}, updated)).To(Succeed())
+		Expect(updated.Spec.Template.Spec.Containers[0].Image).To(Equal(repository + "@" + staleDigest))
+		Expect(updated.Spec.Template.Spec.InitContainers[0].Image).To(Equal("docker.io/" + repository + "@" + latestDigest))
+	})
+
+	DescribeTable("should set the reason code of each compliance outcome",
+		func(image, initImage, latest string, rules complianceRules, reason string) {
+			status := reconciler.analyzeDeploymentCompliance(ctx, newDeployment(image, initImage), repository, latest, rules)
+			Expect(status.Reason).To(Equal(reason))
+			Expect(status.IsCompliant).To(Equal(reason == securityv1.ComplianceReasonCompliant))
+		},
+		Entry("latest digest", repository+"@"+latestDigest, repository+"@"+latestDigest, latestDigest,
+			complianceRules{EnforceLatest: true}, securityv1.ComplianceReasonCompliant),
+		Entry("outdated digest without enforcement", repository+"@"+staleDigest, repository+"@"+latestDigest, latestDigest,
+			complianceRules{}, securityv1.ComplianceReasonCompliant),
+		Entry("outdated digest", repository+"@"+latestDigest, repository+"@"+staleDigest, latestDigest,
+			complianceRules{EnforceLatest: true}, securityv1.NonComplianceReasonOutdatedDigest),
+		Entry("tag", repository+"@"+latestDigest, repository+":v1", latestDigest,
+			complianceRules{EnforceLatest: true}, securityv1.NonComplianceReasonTagBased),
+		Entry("unknown latest digest", repository+"@"+latestDigest, repository+"@"+latestDigest, "",
+			complianceRules{EnforceLatest: true}, securityv1.NonComplianceReasonLatestDigestUnknown),
+		Entry("denied digest", repository+"@"+latestDigest, repository+"@"+deniedDigest, latestDigest,
+			complianceRules{EnforceLatest: true, DeniedDigests: []string{deniedDigest}}, securityv1.NonComplianceReasonDeniedDigest),
+		Entry("missing digest", repository+"@"+latestDigest, repository+"@"+staleDigest, latestDigest,
+			complianceRules{EnforceLatest: true, MissingDigests: map[string]bool{repository + "@" + staleDigest: true}}, securityv1.NonComplianceReasonDigestNotFound),
+		Entry("failed attestation", repository+"@"+latestDigest, repository+"@"+latestDigest, latestDigest,
+			complianceRules{EnforceLatest: true, AttestationPolicy: &securityv1.AttestationPolicy{RequireAttestation: ptr.To(true)}}, securityv1.NonComplianceReasonAttestationFailed),
+	)
+
+	It("should mark a denied digest as non-compliant even when it is the latest", func() {
+		deployment := newDeployment(repository+"@"+latestDigest, repository+"@"+latestDigest)
+
+		status := reconciler.analyzeDeploymentCompliance(ctx, deployment, repository, latestDigest, complianceRules{DeniedDigests: []string{latestDigest}})
+		Expect(status.IsCompliant).To(BeFalse())
+		Expect(status.Reason).To(Equal(securityv1.NonComplianceReasonDeniedDigest))
+	})
+
+	It("should report a denied digest over an outdated one", func() {
+		deployment := newDeployment(repository+"@"+staleDigest, repository+"@"+deniedDigest)
+
+		status := reconciler.analyzeDeploymentCompliance(ctx, deployment, repository, latestDigest, complianceRules{EnforceLatest: true, DeniedDigests: []string{deniedDigest}})
+		Expect(status.Reason).To(Equal(securityv1.NonComplianceReasonDeniedDigest))
+		Expect(status.ContainerName).To(Equal("migrate"))
+	})
+
+	It("should only replace denied digests when not enforcing the latest digest", func() {
+		deployment := newDeployment(repository+"@"+staleDigest, repository+"@"+deniedDigest)
+		fakeClient := fake.NewClientBuilder().WithObjects(deployment.Object).Build()
+		remediator := &ImagePolicyReconciler{Client: fakeClient}
+		enforceLatest := false
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+			Spec:       securityv1.ImagePolicySpec{EnforceLatestDigest: &enforceLatest, DeniedDigests: []string{deniedDigest}},
+		}
+
+		Expect(remediator.remediateDeployment(ctx, policy, deployment, remediator.rulesFor(policy), map[string]string{repository: latestDigest})).To(Succeed())
+
+		updated := &appsv1.Deployment{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "demo", Namespace: "default"}, updated)).To(Succeed())
+		Expect(updated.Spec.Template.Spec.Containers[0].Image).To(Equal(repository + "@" + staleDigest))
+		Expect(updated.Spec.Template.Spec.InitContainers[0].Image).To(Equal(repository + "@" + latestDigest))
+	})
+
+	It("should pin the pull policy of remediated containers only when asked to", func() {
+		deployment := newDeployment(repository+"@"+staleDigest, repository+"@"+latestDigest)
+		deployment.Template.Spec.Containers[0].ImagePullPolicy = corev1.PullAlways
+		deployment.Template.Spec.InitContainers[0].ImagePullPolicy = corev1.PullAlways
+		fakeClient := fake.NewClientBuilder().WithObjects(deployment.Object).Build()
+		remediator := &ImagePolicyReconciler{Client: fakeClient}
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+			Spec:       securityv1.ImagePolicySpec{PinPullPolicy: ptr.To(true)},
+		}
+
+		Expect(remediator.remediateDeployment(ctx, policy, deployment, remediator.rulesFor(policy), map[string]string{repository: latestDigest})).To(Succeed())
+
+		updated := &appsv1.Deployment{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "demo", Namespace: "default"}, updated)).To(Succeed())
+		Expect(updated.Spec.Template.Spec.Containers[0].Image).To(Equal(repository + "@" + latestDigest))
+		Expect(updated.Spec.Template.Spec.Containers[0].ImagePullPolicy).To(Equal(corev1.PullIfNotPresent))
+		Expect(updated.Spec.Template.Spec.InitContainers[0].ImagePullPolicy).To(Equal(corev1.PullAlways))
+		Expect(updated.Annotations).To(HaveKeyWithValue(securityv1.AnnotationOriginalPullPolicy, `{"app":"Always"}`))
+
+		// Reverting restores the pull policy along with the image
+		reverted, ok := newWorkload(updated)
+		Expect(ok).To(BeTrue())
+		Expect(remediator.revertDeployment(ctx, reverted)).To(Succeed())
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "demo", Namespace: "default"}, updated)).To(Succeed())
+		Expect(updated.Spec.Template.Spec.Containers[0].Image).To(Equal(repository + "@" + staleDigest))
+		Expect(updated.Spec.Template.Spec.Containers[0].ImagePullPolicy).To(Equal(corev1.PullAlways))
+		Expect(updated.Annotations).NotTo(HaveKey(securityv1.AnnotationOriginalPullPolicy))
+	})
+
+	It("should leave the pull policy alone by default", func() {
+		deployment := newDeployment(repository+"@"+staleDigest, repository+"@"+latestDigest)
+		deployment.Template.Spec.Containers[0].ImagePullPolicy = corev1.PullAlways
+		fakeClient := fake.NewClientBuilder().WithObjects(deployment.Object).Build()
+		remediator := &ImagePolicyReconciler{Client: fakeClient}
+		policy := &securityv1.ImagePolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"}}
+
+		Expect(remediator.remediateDeployment(ctx, policy, deployment, remediator.rulesFor(policy), map[string]string{repository: latestDigest})).To(Succeed())
+
+		updated := &appsv1.Deployment{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "demo", Namespace: "default"}, updated)).To(Succeed())
+		Expect(updated.Spec.Template.Spec.Containers[0].Image).To(Equal(repository + "@" + latestDigest))
+		Expect(updated.Spec.Template.Spec.Containers[0].ImagePullPolicy).To(Equal(corev1.PullAlways))
+		Expect(updated.Annotations).NotTo(HaveKey(securityv1.AnnotationOriginalPullPolicy))
+	})
+
+	It("should bump monitored images in a manifest using the remediation rules", func() {
+		manifest := []byte("containers:\n- name: app\n  image: \"" + repository + ":v1\"\n- name: sidecar\n  image: nginx:latest # pinned\n")
+
+		updated := substituteManifestImages(manifest, map[string]string{repository: latestDigest}, complianceRules{EnforceLatest: true})
+		Expect(string(updated)).To(Equal("containers:\n- name: app\n  image: \"" + repository + "@" + latestDigest + "\"\n- name: sidecar\n  image: nginx:latest # pinned\n"))
+	})
+
+	It("should mark a deployment as compliant when only skipped containers are outdated", func() {
+		deployment := newDeployment(repository+"@"+latestDigest, repository+"@"+staleDigest)
+		deployment.SetAnnotations(map[string]string{securityv1.AnnotationSkipContainers: "debug, migrate"})
+
+		status := reconciler.analyzeDeploymentCompliance(ctx, deployment, repository, latestDigest, complianceRules{EnforceLatest: true})
+		Expect(status.IsCompliant).To(BeTrue())
+		Expect(status.ContainerName).To(Equal("app"))
+	})
+
+	It("should not remediate skipped containers", func() {
+		deployment := newDeployment(repository+"@"+staleDigest, repository+"@"+staleDigest)
+		deployment.SetAnnotations(map[string]string{securityv1.AnnotationSkipContainers: "migrate"})
+		fakeClient := fake.NewClientBuilder().WithObjects(deployment.Object).Build()
+		remediator := &ImagePolicyReconciler{Client: fakeClient}
+		policy := &securityv1.ImagePolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"}}
+
+		Expect(remediator.remediateDeployment(ctx, policy, deployment, remediator.rulesFor(policy), map[string]string{repository: latestDigest})).To(Succeed())
+
+		updated := &appsv1.Deployment{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "demo", Namespace: "default"}, updated)).To(Succeed())
+		Expect(updated.Spec.Template.Spec.Containers[0].Image).To(Equal(repository + "@" + latestDigest))
+		Expect(updated.Spec.Template.Spec.InitContainers[0].Image).To(Equal(repository + "@" + staleDigest))
+	})
+
+	It("should only check the named container when containerName is set", func() {
+		deployment := newDeployment(repository+"@"+latestDigest, repository+"@"+staleDigest)
+		rules := complianceRules{EnforceLatest: true, ContainerName: "app"}
+
+		Expect(governsRepository(deployment, repository, rules)).To(BeTrue())
+		status := reconciler.analyzeDeploymentCompliance(ctx, deployment, repository, latestDigest, rules)
+		Expect(status.IsCompliant).To(BeTrue())
+		Expect(status.ContainerName).To(Equal("app"))
+
+		// A repository used only by other containers isn't governed
+		deployment = newDeployment("nginx:latest", repository+"@"+staleDigest)
+		Expect(governsRepository(deployment, repository, rules)).To(BeFalse())
+		Expect(workloadsWithContainer([]workload{deployment}, "web")).To(BeEmpty())
+	})
+
+	It("should only remediate the named container", func() {
+		deployment := newDeployment(repository+"@"+staleDigest, repository+"@"+staleDigest)
+		fakeClient := fake.NewClientBuilder().WithObjects(deployment.Object).Build()
+		remediator := &ImagePolicyReconciler{Client: fakeClient}
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+			Spec:       securityv1.ImagePolicySpec{ContainerName: "migrate"},
+		}
+
+		Expect(remediator.remediateDeployment(ctx, policy, deployment, remediator.rulesFor(policy), map[string]string{repository: latestDigest})).To(Succeed())
+
+		updated := &appsv1.Deployment{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "demo", Namespace: "default"}, updated)).To(Succeed())
+		Expect(updated.Spec.Template.Spec.Containers[0].Image).To(Equal(repository + "@" + staleDigest))
+		Expect(updated.Spec.Template.Spec.InitContainers[0].Image).To(Equal(repository + "@" + latestDigest))
+	})
+
+	It("should fail remediation when the named container doesn't exist", func() {
+		deployment := newDeployment(repository+"@"+staleDigest, repository+"@"+staleDigest)
+		remediator := &ImagePolicyReconciler{Client: fake.NewClientBuilder().WithObjects(deployment.Object).Build()}
+		policy := &securityv1.ImagePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+			Spec:       securityv1.ImagePolicySpec{ContainerName: "web"},
+		}
+
+		err := remediator.remediateDeployment(ctx, policy, deployment, remediator.rulesFor(policy), map[string]string{repository: latestDigest})
+		Expect(err).To(MatchError(ContainSubstring(`container "web" not found`)))
+	})
+
+	It("should gate remediation on the automation label by default", func() {
+		deployment := newDeployment(repository+"@"+staleDigest, "nginx:latest")
+		policy := &securityv1.ImagePolicy{}
+		Expect(reconciler.hasAutomationEnabled(policy, deployment)).To(BeFalse())
+
+		deployment.SetLabels(map[string]string{"automation": "true"})
+		Expect(reconciler.hasAutomationEnabled(policy, deployment)).To(BeTrue())
+	})
+
+	It("should gate remediation on a configured annotation", func() {
+		deployment := newDeployment(repository+"@"+staleDigest, "nginx:latest")
+		deployment.SetLabels(map[string]string{"automation": "true"})
+		deployment.SetAnnotations(map[string]string{"example.com/auto-update": "enabled"})
+		policy := &securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{AutomationGate: &securityv1.AutomationGate{
+			Key: "example.com/auto-update", Value: "enabled", Source: securityv1.AutomationGateSourceAnnotation,
+		}}}
+		Expect(reconciler.hasAutomationEnabled(policy, deployment)).To(BeTrue())
+
+		policy.Spec.AutomationGate.Source = securityv1.AutomationGateSourceLabel
+		Expect(reconciler.hasAutomationEnabled(policy, deployment)).To(BeFalse())
+	})
+
+	It("should never pass an invalid automation gate", func() {
+		deployment := newDeployment(repository+"@"+staleDigest, "nginx:latest")
+		deployment.SetLabels(map[string]string{"auto update": "true"})
+		policy := &securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{AutomationGate: &securityv1.AutomationGate{Key: "auto update"}}}
+
+		Expect(validateAutomationGate(automationGate(policy))).To(MatchError(ContainSubstring("invalid automation gate key")))
+		Expect(reconciler.hasAutomationEnabled(policy, deployment)).To(BeFalse())
+	})
+
+	It("should detect the repository in init containers only", func() {
+		deployment := newDeployment("nginx:latest", repository+"@"+staleDigest)
+
+		Expect(reconciler.deploymentUsesRepository(deployment, repository)).To(BeTrue())
+	})
+
+	Context("with multiple repositories", func() {
+		const (
+			workerRepository = "jonlimpw/cg-demo-worker"
+			workerDigest     = "sha256:3333333333333333333333333333333333333333333333333333333333333333"
+		)
+
+		repositories := []string{repository, workerRepository}
+		latestDigests := map[string]string{repository: latestDigest, workerRepository: workerDigest}
+
+		It("should not match a repository that is a prefix of another", func() {
+			Expect(imageUsesRepository(workerRepository+":v1", repository)).To(BeFalse())
+			Expect(imageUsesRepository("docker.io/"+repository+":v1", repository)).To(BeTrue())
+		})
+
+		It("should report compliance per repository", func() {
+			deployment := newDeployment(repository+"@"+latestDigest, workerRepository+"@"+staleDigest)
+
+			status, repositoryCompliance := reconciler.analyzeWorkloadCompliance(ctx, deployment, repositories, latestDigests, complianceRules{EnforceLatest: true})
+			Expect(status.IsCompliant).To(BeFalse())
+			Expect(status.Repository).To(Equal(workerRepository))
+			Expect(repositoryCompliance).To(Equal(map[string]bool{repository: true, workerRepository: false}))
+		})
+
+		It("should remediate each container to the latest digest of its repository", func() {
+			deployment := newDeployment(repository+"@"+staleDigest, "docker.io/"+workerRepository+"@"+staleDigest)
+			fakeClient := fake.NewClientBuilder().WithObjects(deployment.Object).Build()
+			remediator := &ImagePolicyReconciler{Client: fakeClient}
+			policy := &securityv1.ImagePolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"}}
+
+			Expect(remediator.remediateDeployment(ctx, policy, deployment, remediator.rulesFor(policy), latestDigests)).To(Succeed())
+
+			updated := &appsv1.Deployment{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "demo", Namespace: "default"}, updated)).To(Succeed())
+			Expect(updated.Spec.Template.Spec.Containers[0].Image).To(Equal(repository + "@" + latestDigest))
+			Expect(updated.Spec.Template.Spec.InitContainers[0].Image).To(Equal("docker.io/" + workerRepository + "@" + workerDigest))
+		})
+	})
+
+	Context("with official images", func() {
+		latestDigests := map[string]string{"nginx": latestDigest}
+
+		It("should match library images with or without the docker.io and library prefixes", func() {
+			for _, image := range []string{"nginx", "nginx:1.27", "docker.io/nginx@" + staleDigest, "docker.io/library/nginx:1.27", "library/nginx"} {
+				Expect(imageUsesRepository(image, "nginx")).To(BeTrue(), image)
+				Expect(imageUsesRepository(image, "library/nginx")).To(BeTrue(), image)
+			}
+			Expect(imageUsesRepository("nginx-unprivileged:1.27", "nginx")).To(BeFalse())
+			Expect(imageUsesRepository("bitnami/nginx:1.27", "nginx")).To(BeFalse())
+		})
+
+		It("should query DockerHub under the library namespace", func() {
+			Expect(dockerHubRepository("nginx")).To(Equal("library/nginx"))
+			Expect(dockerHubRepository("library/nginx")).To(Equal("library/nginx"))
+			Expect(dockerHubRepository(repository)).To(Equal(repository))
+			Expect(digestRequest{Repository: "nginx", Tag: "latest"}.cacheKey()).To(Equal("registry-1.docker.io/library/nginx:latest"))
+		})
+
+		It("should report the fully qualified image reference only once the digest is known", func() {
+			Expect(resolvedImage("nginx", latestDigest)).To(Equal("docker.io/library/nginx@" + latestDigest))
+			Expect(resolvedImage(repository, latestDigest)).To(Equal("docker.io/" + repository + "@" + latestDigest))
+			Expect(resolvedImage("nginx", "")).To(BeEmpty())
+		})
+
+		It("should pin the digest keeping the image name as written", func() {
+			rules := complianceRules{EnforceLatest: true}
+			for image, expected := range map[string]string{
+				"nginx:1.27":                     "nginx@" + latestDigest,
+				"docker.io/nginx@" + staleDigest: "docker.io/nginx@" + latestDigest,
+				"docker.io/library/nginx:1.27":   "docker.io/library/nginx@" + latestDigest,
+			} {
+				remediated, ok := remediatedImage(image, latestDigests, rules)
+				Expect(ok).To(BeTrue(), image)
+				Expect(remediated).To(Equal(expected), image)
+			}
+		})
+	})
+
+	Context("with tricky image references", func() {
+		It("should parse references into their components", func() {
+			for _, tc := range []struct {
+				image    string
+				expected imageReference
+			}{
+				{"nginx", imageReference{Name: "nginx", Registry: "index.docker.io", Repository: "library/nginx"}},
+				{"docker.io/" + repository + ":v1", imageReference{Name: "docker.io/" + repository, Registry: "index.docker.io", Repository: repository, Tag: "v1"}},
+				{"index.docker.io/" + repository + "@" + staleDigest, imageReference{Name: "index.docker.io/" + repository, Registry: "index.docker.io", Repository: repository, Digest: staleDigest}},
+				{"localhost:5000/app", imageReference{Name: "localhost:5000/app", Registry: "localhost:5000", Repository: "app"}},
+				{"registry.example.com:5000/team/app:1.2@" + staleDigest, imageReference{Name: "registry.example.com:5000/team/app", Registry: "registry.example.com:5000", Repository: "team/app", Tag: "1.2", Digest: staleDigest}},
+				{repository + ":latest@" + latestDigest, imageReference{Name: repository, Registry: "index.docker.io", Repository: repository, Tag: "latest", Digest: latestDigest}},
+			} {
+				parsed, err := parseImageReference(tc.image)
+				Expect(err).NotTo(HaveOccurred(), tc.image)
+				Expect(parsed).To(Equal(tc.expected), tc.image)
+			}
+		})
+
+		It("should reject invalid references", func() {
+			for _, image := range []string{"", "Upper/Case:1", repository + "@sha256:short", repository + "@md5:" + strings.Repeat("0", 32), repository + ":bad tag"} {
+				_, err := parseImageReference(image)
+				Expect(err).To(HaveOccurred(), image)
+				Expect(imageUsesRepository(image, repository)).To(BeFalse(), image)
+				Expect(imageDigest(image)).To(BeEmpty(), image)
+			}
+		})
+
+		It("should only match the repository on DockerHub", func() {
+			for image, expected := range map[string]bool{
+				"index.docker.io/" + repository + ":v1":                       true,
+				repository + ":1.2@" + staleDigest:                            true,
+				"registry.example.com:5000/" + repository + ":1.2":            false,
+				"registry.example.com:5000/" + repository + "@" + staleDigest: false,
+				"ghcr.io/" + repository:                                       false,
+				repository + "/sub:v1":                                        false,
+			} {
+				Expect(imageUsesRepository(image, repository)).To(Equal(expected), image)
+			}
+		})
+
+		It("should read the digest of a tag and digest reference", func() {
+			deployment := newDeployment(repository+":1.2@"+latestDigest, "nginx:latest")
+
+			status, _ := reconciler.analyzeWorkloadCompliance(ctx, deployment, []string{repository}, map[string]string{repository: latestDigest}, complianceRules{EnforceLatest: true})
+			Expect(status.CurrentDigest).To(Equal(latestDigest))
+			Expect(status.IsCompliant).To(BeTrue())
+
+			remediated, ok := remediatedImage("docker.io/"+repository+":1.2@"+staleDigest, map[string]string{repository: latestDigest}, complianceRules{EnforceLatest: true})
+			Expect(ok).To(BeTrue())
+			Expect(remediated).To(Equal("docker.io/" + repository + "@" + latestDigest))
+		})
+	})
+
+	Context("with allowed registries", func() {
+		rules := complianceRules{EnforceLatest: true, AllowedRegistries: []string{"docker.io", "cgr.dev"}}
+
+		It("should keep digest-pinned images from allowed registries compliant", func() {
+			deployment := newDeployment("docker.io/"+repository+"@"+latestDigest, "cgr.dev/chainguard/busybox@"+staleDigest)
+
+			status, _ := reconciler.analyzeWorkloadCompliance(ctx, deployment, []string{repository}, map[string]string{repository: latestDigest}, rules)
+			Expect(status.IsCompliant).To(BeTrue())
+		})
+
+		It("should mark workloads with a container from another registry as non-compliant", func() {
+			deployment := newDeployment(repository+"@"+latestDigest, "registry.example.com:5000/tools/migrate@"+latestDigest)
+
+			status, repositoryCompliance := reconciler.analyzeWorkloadCompliance(ctx, deployment, []string{repository}, map[string]string{repository: latestDigest}, rules)
+			Expect(status.IsCompliant).To(BeFalse())
+			Expect(status.Reason).To(Equal(securityv1.NonComplianceReasonDisallowedRegistry))
+			Expect(status.ContainerName).To(Equal("migrate"))
+			Expect(status.Message).To(ContainSubstring("pulled from registry.example.com:5000"))
+			Expect(repositoryCompliance).To(HaveKeyWithValue(repository, true))
+		})
+
+		It("should still report denied digests first", func() {
+			deployment := newDeployment(repository+"@"+deniedDigest, "ghcr.io/team/migrate:v1")
+
+			denying := rules
+			denying.DeniedDigests = []string{deniedDigest}
+			status, _ := reconciler.analyzeWorkloadCompliance(ctx, deployment, []string{repository}, map[string]string{repository: latestDigest}, denying)
+			Expect(status.Reason).To(Equal(securityv1.NonComplianceReasonDeniedDigest))
+		})
+
+		It("should match DockerHub however it is written", func() {
+			for _, image := range []string{"nginx", "docker.io/library/nginx", "index.docker.io/" + repository} {
+				ref, err := parseImageReference(image)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(registryAllowed(ref.Registry, []string{"docker.io"})).To(BeTrue(), image)
+				Expect(registryAllowed(ref.Registry, []string{"index.docker.io"})).To(BeTrue(), image)
+				Expect(registryAllowed(ref.Registry, []string{"cgr.dev"})).To(BeFalse(), image)
+			}
+		})
+	})
+
+	Context("with sha512 digests", func() {
+		var (
+			latestSHA512 = "sha512:" + strings.Repeat("1", 128)
+			staleSHA512  = "sha512:" + strings.Repeat("2", 128)
+		)
+
+		It("should parse sha512 digest references", func() {
+			parsed, err := parseImageReference("docker.io/" + repository + ":1.2@" + staleSHA512)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsed).To(Equal(imageReference{Name: "docker.io/" + repository, Registry: "index.docker.io", Repository: repository, Tag: "1.2", Digest: staleSHA512}))
+			Expect(imageUsesRepository(repository+"@"+staleSHA512, repository)).To(BeTrue())
+
+			_, err = parseImageReference(repository + "@sha512:" + strings.Repeat("1", 64))
+			Expect(err).To(MatchError(ContainSubstring("expected 128 lowercase hex characters")))
+		})
+
+		It("should compare sha512 digests like sha256 ones", func() {
+			deployment := newDeployment(repository+"@"+latestSHA512, repository+"@"+latestSHA512)
+			status := reconciler.analyzeDeploymentCompliance(ctx, deployment, repository, latestSHA512, complianceRules{EnforceLatest: true})
+			Expect(status.IsCompliant).To(BeTrue())
+			Expect(status.CurrentDigest).To(Equal(latestSHA512))
+
+			deployment = newDeployment(repository+"@"+staleSHA512, repository+"@"+latestSHA512)
+			status = reconciler.analyzeDeploymentCompliance(ctx, deployment, repository, latestSHA512, complianceRules{EnforceLatest: true})
+			Expect(status.IsCompliant).To(BeFalse())
+			Expect(status.Reason).To(Equal(securityv1.NonComplianceReasonOutdatedDigest))
+
+			status = reconciler.analyzeDeploymentCompliance(ctx, deployment, repository, latestSHA512, complianceRules{EnforceLatest: true, AllowedDigests: []string{staleSHA512}})
+			Expect(status.IsCompliant).To(BeTrue())
+		})
+
+		It("should remediate sha512 digests to the latest digest", func() {
+			remediated, ok := remediatedImage(repository+"@"+staleSHA512, map[string]string{repository: latestDigest}, complianceRules{EnforceLatest: true})
+			Expect(ok).To(BeTrue())
+			Expect(remediated).To(Equal(repository + "@" + latestDigest))
+		})
+	})
+
+	Context("with namespace exclusions", func() {
+		newNamespace := func(name string, labels map[string]string) *corev1.Namespace {
+			return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+		}
+
+		It("should subtract excluded and system namespaces from the selected namespaces", func() {
+			monitored := map[string]string{"monitor": "true"}
+			fakeClient := fake.NewClientBuilder().WithObjects(
+				newNamespace("default", monitored),
+				newNamespace("staging", monitored),
+				newNamespace("kube-system", monitored),
+				newNamespace("other", nil),
+			).Build()
+			lister := &ImagePolicyReconciler{Client: fakeClient}
+			policy := &securityv1.ImagePolicy{Spec: securityv1.ImagePolicySpec{
+				NamespaceSelector:       &metav1.LabelSelector{MatchLabels: monitored},
+				ExcludeNamespaces:       []string{"staging"},
+				ExcludeSystemNamespaces: true,
+			}}
+
+			namespaces, err := lister.getNamespacesToMonitor(ctx, policy)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespaces).To(ConsistOf("default"))
+		})
+
+		It("should keep system namespaces unless ExcludeSystemNamespaces is set", func() {
+			fakeClient := fake.NewClientBuilder().WithObjects(newNamespace("default", nil), newNamespace("kube-system", nil)).Build()
+			lister := &ImagePolicyReconciler{Client: fakeClient}
+
+			namespaces, err := lister.getNamespacesToMonitor(ctx, &securityv1.ImagePolicy{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespaces).To(ConsistOf("default", "kube-system"))
+		})
+	})
+})
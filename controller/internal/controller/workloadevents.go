@@ -0,0 +1,76 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+// recordWorkloadCompliance emits an event on the workload itself when its compliance changed since the
+// previous reconcile, so app teams see it in kubectl describe: a NonCompliant Warning for each new reason
+// or digest, and a Compliant event once it is fixed. Workloads first found compliant, and workloads whose
+// state is unchanged, get none. Transitions are also logged at info level; the per-reconcile detail of
+// the checks is only logged at V(1).
+func (r *ImagePolicyReconciler) recordWorkloadCompliance(ctx context.Context, policy *securityv1.ImagePolicy, deployment workload, status *securityv1.DeploymentStatus) {
+	log := logf.FromContext(ctx)
+	previous := findDeploymentStatus(policy, deployment)
+	policyKey := types.NamespacedName{Namespace: policy.Namespace, Name: policy.Name}
+
+	if status.IsCompliant {
+		if previous == nil || previous.IsCompliant {
+			return
+		}
+		message := fmt.Sprintf("Compliant with ImagePolicy %s", policyKey)
+		if status.Reason == securityv1.ComplianceReasonOverridden {
+			message = fmt.Sprintf("Treated as compliant with ImagePolicy %s, %s", policyKey, status.Message)
+		}
+		log.Info("Workload became compliant", "kind", deployment.Kind, "deployment", deployment.GetName(),
+			"namespace", deployment.GetNamespace(), "reason", status.Reason)
+		r.Recorder.Event(deployment.Object, corev1.EventTypeNormal, "Compliant", message)
+		return
+	}
+
+	if previous != nil && !previous.IsCompliant && previous.Reason == status.Reason && previous.CurrentDigest == status.CurrentDigest {
+		return
+	}
+	log.Info("Workload became non-compliant", "kind", deployment.Kind, "deployment", deployment.GetName(),
+		"namespace", deployment.GetNamespace(), "container", status.ContainerName, "reason", status.Reason,
+		"currentDigest", status.CurrentDigest, "message", status.Message)
+	r.Recorder.Event(deployment.Object, corev1.EventTypeWarning, "NonCompliant",
+		fmt.Sprintf("Non-compliant with ImagePolicy %s in %s %s: %s", policyKey, strings.ToLower(status.ContainerKind), status.ContainerName, status.Message))
+}
+
+// recordWorkloadRemediation emits an event on a workload the policy remediated, in the cluster or through a
+// pull request; each remediation happens once so it needs no tracking
+func (r *ImagePolicyReconciler) recordWorkloadRemediation(policy *securityv1.ImagePolicy, deployment workload, record securityv1.RemediationRecord) {
+	policyKey := types.NamespacedName{Namespace: policy.Namespace, Name: policy.Name}
+	if record.PullRequestURL != "" {
+		r.Recorder.Event(deployment.Object, corev1.EventTypeNormal, "GitOpsPullRequestOpened",
+			fmt.Sprintf("ImagePolicy %s opened %s to remediate to digest %s", policyKey, record.PullRequestURL, record.NewDigest))
+		return
+	}
+	r.Recorder.Event(deployment.Object, corev1.EventTypeNormal, "AutoRemediated",
+		fmt.Sprintf("Auto-remediated by ImagePolicy %s to use digest %s", policyKey, record.NewDigest))
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+)
+
+var _ = Describe("Workload events", func() {
+	const (
+		staleDigest = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+		otherDigest = "sha256:3333333333333333333333333333333333333333333333333333333333333333"
+	)
+
+	var recorder *record.FakeRecorder
+	var reconciler *ImagePolicyReconciler
+	var policy *securityv1.ImagePolicy
+	deployment, _ := newWorkload(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"}})
+
+	nonCompliant := func(digest string) *securityv1.DeploymentStatus {
+		return &securityv1.DeploymentStatus{Kind: securityv1.WorkloadKindDeployment, Name: "demo", Namespace: "default",
+			Reason: securityv1.NonComplianceReasonOutdatedDigest, CurrentDigest: digest, ContainerKind: securityv1.ContainerKindContainer,
+			ContainerName: "app", Message: "using outdated digest"}
+	}
+	compliant := &securityv1.DeploymentStatus{Kind: securityv1.WorkloadKindDeployment, Name: "demo", Namespace: "default",
+		IsCompliant: true, Reason: securityv1.ComplianceReasonCompliant}
+
+	// reconcile records the workload's status as if reconciled, then carries it over to the next reconcile
+	reconcile := func(status *securityv1.DeploymentStatus) {
+		reconciler.recordWorkloadCompliance(context.Background(), policy, deployment, status)
+		policy.Status.MonitoredDeployments = []securityv1.DeploymentStatus{*status}
+	}
+
+	BeforeEach(func() {
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &ImagePolicyReconciler{Recorder: recorder}
+		policy = &securityv1.ImagePolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"}}
+	})
+
+	It("should emit events only when compliance changes", func() {
+		reconcile(compliant)
+		Expect(recorder.Events).NotTo(Receive(), "a workload first found compliant has nothing to report")
+
+		reconcile(nonCompliant(staleDigest))
+		Expect(recorder.Events).To(Receive(Equal("Warning NonCompliant Non-compliant with ImagePolicy default/policy in container app: using outdated digest")))
+		reconcile(nonCompliant(staleDigest))
+		Expect(recorder.Events).NotTo(Receive())
+
+		reconcile(nonCompliant(otherDigest))
+		Expect(recorder.Events).To(Receive(ContainSubstring("Warning NonCompliant")))
+
+		reconcile(compliant)
+		Expect(recorder.Events).To(Receive(Equal("Normal Compliant Compliant with ImagePolicy default/policy")))
+		reconcile(compliant)
+		Expect(recorder.Events).NotTo(Receive())
+	})
+
+	It("should report a new non-compliant workload", func() {
+		reconcile(nonCompliant(staleDigest))
+		Expect(recorder.Events).To(Receive(ContainSubstring("Warning NonCompliant")))
+	})
+
+	It("should report remediations on the workload", func() {
+		reconciler.recordWorkloadRemediation(policy, deployment, securityv1.RemediationRecord{NewDigest: otherDigest})
+		Expect(recorder.Events).To(Receive(Equal("Normal AutoRemediated Auto-remediated by ImagePolicy default/policy to use digest " + otherDigest)))
+
+		reconciler.recordWorkloadRemediation(policy, deployment, securityv1.RemediationRecord{NewDigest: otherDigest, PullRequestURL: "https://github.com/org/repo/pull/1"})
+		Expect(recorder.Events).To(Receive(ContainSubstring("GitOpsPullRequestOpened")))
+	})
+})
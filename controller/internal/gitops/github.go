@@ -0,0 +1,300 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrManifestNotFound is returned when the manifest path doesn't exist on the base branch
+var ErrManifestNotFound = errors.New("manifest not found")
+
+// ErrNoChanges is returned when the update leaves the manifest unchanged
+var ErrNoChanges = errors.New("manifest already up to date")
+
+// Client opens pull requests against a GitHub (or GitHub Enterprise) repository
+type Client struct {
+	httpClient *http.Client
+	apiURL     string
+	owner      string
+	repo       string
+	token      string
+}
+
+// Change describes a manifest update proposed as a pull request
+type Change struct {
+	// BaseBranch is the branch the pull request targets
+	BaseBranch string
+
+	// HeadBranch is the branch the update is committed to; an open pull request from it is reused
+	HeadBranch string
+
+	// Path is the manifest file within the repository
+	Path string
+
+	// Title, Body and CommitMessage describe the change
+	Title         string
+	Body          string
+	CommitMessage string
+
+	// Update rewrites the manifest content
+	Update func(content []byte) ([]byte, error)
+}
+
+// PullRequest is the outcome of proposing a change
+type PullRequest struct {
+	URL string
+
+	// Created is false when an open pull request for the head branch already existed
+	Created bool
+}
+
+// NewClient creates a client for a repository URL such as https://github.com/org/repo
+func NewClient(repoURL, token string) (*Client, error) {
+	parsed, err := url.Parse(repoURL)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid git repository URL %q: expected https://<host>/<owner>/<repo>", repoURL)
+	}
+
+	owner, repo, found := strings.Cut(strings.Trim(strings.TrimSuffix(parsed.Path, ".git"), "/"), "/")
+	if !found || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return nil, fmt.Errorf("invalid git repository URL %q: expected https://<host>/<owner>/<repo>", repoURL)
+	}
+
+	// github.com uses a separate API host, GitHub Enterprise serves the API under /api/v3
+	apiURL := "https://api.github.com"
+	if parsed.Host != "github.com" {
+		apiURL = "https://" + parsed.Host + "/api/v3"
+	}
+
+	return &Client{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		apiURL:     apiURL,
+		owner:      owner,
+		repo:       repo,
+		token:      token,
+	}, nil
+}
+
+// NewClientWithAPIURL creates a client for a repository URL that talks to the given API endpoint
+func NewClientWithAPIURL(repoURL, apiURL, token string) (*Client, error) {
+	client, err := NewClient(repoURL, token)
+	if err != nil {
+		return nil, err
+	}
+	client.apiURL = strings.TrimSuffix(apiURL, "/")
+	return client, nil
+}
+
+// ProposeChange commits the updated manifest to the head branch and opens a pull request against
+// the base branch, reusing an already open pull request from the same head branch
+func (c *Client) ProposeChange(ctx context.Context, change Change) (*PullRequest, error) {
+	if existing, err := c.findOpenPullRequest(ctx, change.HeadBranch); err != nil {
+		return nil, err
+	} else if existing != "" {
+		return &PullRequest{URL: existing}, nil
+	}
+
+	content, fileSHA, err := c.getFile(ctx, change.Path, change.BaseBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := change.Update(content)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(content, updated) {
+		return nil, ErrNoChanges
+	}
+
+	created, err := c.createBranch(ctx, change.HeadBranch, change.BaseBranch)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		// A previous attempt left the branch behind; commit on top of its copy of the file
+		if _, fileSHA, err = c.getFile(ctx, change.Path, change.HeadBranch); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.putFile(ctx, change.Path, change.HeadBranch, change.CommitMessage, updated, fileSHA); err != nil {
+		return nil, err
+	}
+
+	prURL, err := c.createPullRequest(ctx, change)
+	if err != nil {
+		return nil, err
+	}
+	return &PullRequest{URL: prURL, Created: true}, nil
+}
+
+// findOpenPullRequest returns the URL of an open pull request from the head branch, if any
+func (c *Client) findOpenPullRequest(ctx context.Context, headBranch string) (string, error) {
+	query := url.Values{"head": {c.owner + ":" + headBranch}, "state": {"open"}}
+	var pulls []struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if _, err := c.do(ctx, http.MethodGet, "/pulls?"+query.Encode(), nil, &pulls, http.StatusOK); err != nil {
+		return "", fmt.Errorf("failed to list pull requests: %w", err)
+	}
+	if len(pulls) == 0 {
+		return "", nil
+	}
+	return pulls[0].HTMLURL, nil
+}
+
+// getFile returns the content and blob SHA of a file on a branch
+func (c *Client) getFile(ctx context.Context, path, branch string) ([]byte, string, error) {
+	var file struct {
+		SHA      string `json:"sha"`
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	status, err := c.do(ctx, http.MethodGet, "/contents/"+escapePath(path)+"?ref="+url.QueryEscape(branch), nil, &file, http.StatusOK)
+	if status == http.StatusNotFound {
+		return nil, "", fmt.Errorf("%w: %s on branch %s", ErrManifestNotFound, path, branch)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get %s: %w", path, err)
+	}
+	if file.Encoding != "base64" {
+		return nil, "", fmt.Errorf("%s is not a file", path)
+	}
+
+	content, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(file.Content, "\n", ""))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return content, file.SHA, nil
+}
+
+// createBranch creates the head branch from the base branch, returning false if it already exists
+func (c *Client) createBranch(ctx context.Context, headBranch, baseBranch string) (bool, error) {
+	var ref struct {
+		Object struct {
+			SHA string `json:"sha"`
+		} `json:"object"`
+	}
+	if _, err := c.do(ctx, http.MethodGet, "/git/ref/heads/"+escapePath(baseBranch), nil, &ref, http.StatusOK); err != nil {
+		return false, fmt.Errorf("failed to get branch %s: %w", baseBranch, err)
+	}
+
+	body := map[string]string{"ref": "refs/heads/" + headBranch, "sha": ref.Object.SHA}
+	status, err := c.do(ctx, http.MethodPost, "/git/refs", body, nil, http.StatusCreated)
+	if status == http.StatusUnprocessableEntity {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create branch %s: %w", headBranch, err)
+	}
+	return true, nil
+}
+
+// putFile commits new content for a file on a branch
+func (c *Client) putFile(ctx context.Context, path, branch, message string, content []byte, fileSHA string) error {
+	body := map[string]string{
+		"message": message,
+		"content": base64.StdEncoding.EncodeToString(content),
+		"sha":     fileSHA,
+		"branch":  branch,
+	}
+	if _, err := c.do(ctx, http.MethodPut, "/contents/"+escapePath(path), body, nil, http.StatusOK); err != nil {
+		return fmt.Errorf("failed to commit %s: %w", path, err)
+	}
+	return nil
+}
+
+// createPullRequest opens the pull request and returns its URL
+func (c *Client) createPullRequest(ctx context.Context, change Change) (string, error) {
+	body := map[string]string{
+		"title": change.Title,
+		"body":  change.Body,
+		"head":  change.HeadBranch,
+		"base":  change.BaseBranch,
+	}
+	var pull struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if _, err := c.do(ctx, http.MethodPost, "/pulls", body, &pull, http.StatusCreated); err != nil {
+		return "", fmt.Errorf("failed to open pull request: %w", err)
+	}
+	return pull.HTMLURL, nil
+}
+
+// do sends a request to the repository API and decodes the response into out, returning the status code.
+// Any status other than expected is returned as an error.
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}, expected int) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return 0, fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s/repos/%s/%s%s", c.apiURL, c.owner, c.repo, path), reader)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// StatusCreated and StatusOK are interchangeable for writes that may update in place
+	if resp.StatusCode != expected && !(expected == http.StatusOK && resp.StatusCode == http.StatusCreated) {
+		return resp.StatusCode, fmt.Errorf("%s %s returned status %d", method, path, resp.StatusCode)
+	}
+	if out == nil {
+		return resp.StatusCode, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp.StatusCode, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return resp.StatusCode, nil
+}
+
+// escapePath escapes each segment of a slash-separated path
+func escapePath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GitHub client", func() {
+	var (
+		server    *httptest.Server
+		client    *Client
+		committed []byte
+		files     map[string]string
+	)
+
+	ctx := context.Background()
+	change := Change{
+		BaseBranch:    "main",
+		HeadBranch:    "imagepolicy/default/policy/abc",
+		Path:          "apps/demo.yaml",
+		Title:         "Update images",
+		CommitMessage: "Update images",
+		Update: func(content []byte) ([]byte, error) {
+			return bytes.ReplaceAll(content, []byte("old"), []byte("new")), nil
+		},
+	}
+
+	BeforeEach(func() {
+		committed = nil
+		files = map[string]string{"main": "image: old\n"}
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /repos/org/deploy/pulls", func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("[]"))
+		})
+		mux.HandleFunc("GET /repos/org/deploy/contents/apps/demo.yaml", func(w http.ResponseWriter, r *http.Request) {
+			content, ok := files[r.URL.Query().Get("ref")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"sha": "blob", "encoding": "base64", "content": base64.StdEncoding.EncodeToString([]byte(content)),
+			})
+		})
+		mux.HandleFunc("GET /repos/org/deploy/git/ref/heads/main", func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"object":{"sha":"base"}}`))
+		})
+		mux.HandleFunc("POST /repos/org/deploy/git/refs", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+		})
+		mux.HandleFunc("PUT /repos/org/deploy/contents/apps/demo.yaml", func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Content string `json:"content"`
+			}
+			Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+			committed, _ = base64.StdEncoding.DecodeString(body.Content)
+		})
+		mux.HandleFunc("POST /repos/org/deploy/pulls", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"html_url":"https://github.com/org/deploy/pull/1"}`))
+		})
+		server = httptest.NewServer(mux)
+
+		var err error
+		client, err = NewClientWithAPIURL("https://github.com/org/deploy", server.URL, "token")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should commit the updated manifest and open a pull request", func() {
+		pr, err := client.ProposeChange(ctx, change)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pr.Created).To(BeTrue())
+		Expect(pr.URL).To(Equal("https://github.com/org/deploy/pull/1"))
+		Expect(string(committed)).To(Equal("image: new\n"))
+	})
+
+	It("should report a missing manifest", func() {
+		delete(files, "main")
+
+		_, err := client.ProposeChange(ctx, change)
+		Expect(errors.Is(err, ErrManifestNotFound)).To(BeTrue())
+	})
+
+	It("should report manifests that are already up to date", func() {
+		files["main"] = "image: new\n"
+
+		_, err := client.ProposeChange(ctx, change)
+		Expect(errors.Is(err, ErrNoChanges)).To(BeTrue())
+	})
+
+	It("should reject repository URLs without an owner and name", func() {
+		_, err := NewClient("https://github.com/org", "token")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,86 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Payload is the JSON body posted to the webhook. Text makes it render in Slack incoming
+// webhooks; the remaining fields are for generic receivers.
+type Payload struct {
+	Text           string    `json:"text"`
+	Event          string    `json:"event"`
+	Policy         string    `json:"policy"`
+	Kind           string    `json:"kind"`
+	Namespace      string    `json:"namespace"`
+	Name           string    `json:"name"`
+	Container      string    `json:"container,omitempty"`
+	Repository     string    `json:"repository,omitempty"`
+	CurrentDigest  string    `json:"currentDigest,omitempty"`
+	LatestDigest   string    `json:"latestDigest,omitempty"`
+	Reason         string    `json:"reason,omitempty"`
+	Message        string    `json:"message,omitempty"`
+	PullRequestURL string    `json:"pullRequestURL,omitempty"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// Client posts notifications to a webhook URL
+type Client struct {
+	httpClient *http.Client
+	url        string
+}
+
+// NewClient creates a client for the webhook URL
+func NewClient(url string) *Client {
+	return &Client{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		url:        url,
+	}
+}
+
+// Send posts the payload, returning an error if the webhook doesn't answer with a 2xx status
+func (c *Client) Send(ctx context.Context, payload Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("notification webhook returned status %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Webhook client", func() {
+	ctx := context.Background()
+
+	It("should post the payload as JSON", func() {
+		var received Payload
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Method).To(Equal(http.MethodPost))
+			Expect(r.Header.Get("Content-Type")).To(Equal("application/json"))
+			Expect(json.NewDecoder(r.Body).Decode(&received)).To(Succeed())
+		}))
+		defer server.Close()
+
+		payload := Payload{Text: "demo is non-compliant", Event: "NonCompliant", Kind: "Deployment", Namespace: "default", Name: "demo"}
+		Expect(NewClient(server.URL).Send(ctx, payload)).To(Succeed())
+		Expect(received.Text).To(Equal(payload.Text))
+		Expect(received.Event).To(Equal(payload.Event))
+		Expect(received.Name).To(Equal(payload.Name))
+	})
+
+	It("should return an error for non-2xx responses", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, "invalid_token", http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		err := NewClient(server.URL).Send(ctx, Payload{Event: "NonCompliant"})
+		Expect(err).To(MatchError(ContainSubstring("status 403: invalid_token")))
+	})
+})
@@ -0,0 +1,52 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"fmt"
+	"strings"
+)
+
+// digestHexLengths maps the supported digest algorithms to the length of their hex-encoded value
+var digestHexLengths = map[string]int{
+	"sha256": 64,
+	"sha512": 128,
+}
+
+// ParseDigest splits a digest such as "sha512:<hex>" into its algorithm and hex value, rejecting
+// unsupported algorithms and values that aren't lowercase hex of the algorithm's length
+func ParseDigest(digest string) (algorithm, hex string, err error) {
+	algorithm, hex, found := strings.Cut(digest, ":")
+	if !found {
+		return "", "", fmt.Errorf("invalid digest format: %s", digest)
+	}
+
+	length, ok := digestHexLengths[algorithm]
+	if !ok {
+		return "", "", fmt.Errorf("invalid digest format: %s: unsupported algorithm %q", digest, algorithm)
+	}
+	if len(hex) != length || strings.Trim(hex, "0123456789abcdef") != "" {
+		return "", "", fmt.Errorf("invalid digest format: %s: expected %d lowercase hex characters", digest, length)
+	}
+	return algorithm, hex, nil
+}
+
+// IsDigest reports whether a value is a digest with a supported algorithm
+func IsDigest(digest string) bool {
+	_, _, err := ParseDigest(digest)
+	return err == nil
+}
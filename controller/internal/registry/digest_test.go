@@ -0,0 +1,53 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseDigest", func() {
+	It("should split sha256 and sha512 digests", func() {
+		algorithm, hex, err := ParseDigest("sha256:" + strings.Repeat("a", 64))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(algorithm).To(Equal("sha256"))
+		Expect(hex).To(HaveLen(64))
+
+		algorithm, hex, err = ParseDigest("sha512:" + strings.Repeat("b", 128))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(algorithm).To(Equal("sha512"))
+		Expect(hex).To(HaveLen(128))
+	})
+
+	It("should reject unsupported algorithms and malformed values", func() {
+		for _, digest := range []string{
+			"",
+			"tag-based",
+			"md5:" + strings.Repeat("0", 32),
+			"sha256:" + strings.Repeat("0", 128),
+			"sha512:" + strings.Repeat("0", 64),
+			"sha256:" + strings.Repeat("A", 64),
+		} {
+			_, _, err := ParseDigest(digest)
+			Expect(err).To(MatchError(ContainSubstring("invalid digest format")), digest)
+			Expect(IsDigest(digest)).To(BeFalse(), digest)
+		}
+	})
+})
@@ -0,0 +1,114 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package registry defines the errors returned for DockerHub (and mirror) registry responses, so
+// callers can branch on the failure with errors.As instead of matching error messages, and parses
+// the digests registries identify content by.
+package registry
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimitError is returned for 429 responses
+type RateLimitError struct {
+	// API is the API that responded, e.g. "registry" or "auth"
+	API string
+
+	// RetryAfter is the delay requested by the Retry-After header, if any
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("DockerHub %s API returned status %d", e.API, http.StatusTooManyRequests)
+}
+
+// NotFoundError is returned for 404 responses: the repository, tag or digest doesn't exist
+type NotFoundError struct {
+	API string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("repository or tag not found: DockerHub %s API returned status %d", e.API, http.StatusNotFound)
+}
+
+// AuthError is returned for 401 and 403 responses, e.g. when pull secret credentials are rejected
+type AuthError struct {
+	API        string
+	StatusCode int
+}
+
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("DockerHub %s API rejected the credentials with status %d", e.API, e.StatusCode)
+}
+
+// ServerError is returned for 5xx responses
+type ServerError struct {
+	API        string
+	StatusCode int
+
+	// RetryAfter is the delay requested by the Retry-After header, if any
+	RetryAfter time.Duration
+}
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("DockerHub %s API returned status %d", e.API, e.StatusCode)
+}
+
+// StatusError is returned for any other unexpected response status
+type StatusError struct {
+	API        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("DockerHub %s API returned status %d", e.API, e.StatusCode)
+}
+
+// NewStatusError returns the typed error for a non-200 response from the given API
+func NewStatusError(resp *http.Response, api string) error {
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return &NotFoundError{API: api}
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return &AuthError{API: api, StatusCode: resp.StatusCode}
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return &RateLimitError{API: api, RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After"))}
+	case resp.StatusCode >= 500:
+		return &ServerError{API: api, StatusCode: resp.StatusCode, RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After"))}
+	default:
+		return &StatusError{API: api, StatusCode: resp.StatusCode}
+	}
+}
+
+// ParseRetryAfter parses a Retry-After header given either as seconds or an HTTP date
+func ParseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(when); delay > 0 {
+			return delay
+		}
+	}
+	return 0
+}
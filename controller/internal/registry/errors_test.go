@@ -0,0 +1,69 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewStatusError", func() {
+	response := func(code int, retryAfter string) *http.Response {
+		resp := &http.Response{StatusCode: code, Header: http.Header{}}
+		if retryAfter != "" {
+			resp.Header.Set("Retry-After", retryAfter)
+		}
+		return resp
+	}
+
+	It("should type each status so wrapped errors can be matched with errors.As", func() {
+		var rateLimit *RateLimitError
+		err := fmt.Errorf("fetch failed: %w", NewStatusError(response(http.StatusTooManyRequests, "7"), "registry"))
+		Expect(errors.As(err, &rateLimit)).To(BeTrue())
+		Expect(rateLimit.RetryAfter).To(Equal(7 * time.Second))
+
+		var notFound *NotFoundError
+		Expect(errors.As(NewStatusError(response(http.StatusNotFound, ""), "registry"), &notFound)).To(BeTrue())
+
+		var auth *AuthError
+		for _, code := range []int{http.StatusUnauthorized, http.StatusForbidden} {
+			Expect(errors.As(NewStatusError(response(code, ""), "auth"), &auth)).To(BeTrue())
+			Expect(auth.StatusCode).To(Equal(code))
+		}
+
+		var server *ServerError
+		Expect(errors.As(NewStatusError(response(http.StatusBadGateway, ""), "registry"), &server)).To(BeTrue())
+		Expect(server.StatusCode).To(Equal(http.StatusBadGateway))
+
+		var status *StatusError
+		Expect(errors.As(NewStatusError(response(http.StatusBadRequest, ""), "registry"), &status)).To(BeTrue())
+		Expect(status.Error()).To(Equal("DockerHub registry API returned status 400"))
+	})
+
+	It("should parse Retry-After as seconds or an HTTP date", func() {
+		Expect(ParseRetryAfter("")).To(BeZero())
+		Expect(ParseRetryAfter("30")).To(Equal(30 * time.Second))
+		Expect(ParseRetryAfter("soon")).To(BeZero())
+		Expect(ParseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))).To(BeNumerically("~", time.Minute, 2*time.Second))
+		Expect(ParseRetryAfter(time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))).To(BeZero())
+	})
+})
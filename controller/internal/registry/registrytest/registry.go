@@ -0,0 +1,200 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package registrytest provides a fake DockerHub registry and token API serving manifests from memory,
+// so digest fetches, retries and backoff can be tested deterministically without the network.
+package registrytest
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Token is the bearer token issued by the token API and required by the registry API
+const Token = "registrytest-token"
+
+// MediaTypeOCIImageManifest is the media type of the manifests PutImage serves
+const MediaTypeOCIImageManifest = "application/vnd.oci.image.manifest.v1+json"
+
+// tokenKey is the key of token API failures and requests
+const tokenKey = "token"
+
+// Failure is a response a Registry gives instead of the normal one, e.g. a 429 or a 5xx
+type Failure struct {
+	StatusCode int
+
+	// RetryAfter is the Retry-After header sent with the response, if any
+	RetryAfter string
+}
+
+// manifest is a manifest a Registry serves, by tag and by digest
+type manifest struct {
+	mediaType string
+	body      []byte
+}
+
+// Registry is a fake DockerHub serving both the registry API (/v2/<repository>/manifests/<reference>)
+// and the token API (/token) from a single httptest.Server; point the reconciler's DockerHubRegistryURL
+// and DockerHubAuthURL at its URL. Unknown manifests are a 404, and manifest requests without the
+// Token are a 401. Repositories are the paths requested, e.g. "library/nginx" for official images.
+type Registry struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	manifests map[string]manifest
+	failures  map[string][]Failure
+	requests  map[string]int
+}
+
+// NewRegistry starts a Registry, which the caller should Close when done
+func NewRegistry() *Registry {
+	r := &Registry{
+		manifests: map[string]manifest{},
+		failures:  map[string][]Failure{},
+		requests:  map[string]int{},
+	}
+	r.Server = httptest.NewServer(http.HandlerFunc(r.serveHTTP))
+	return r
+}
+
+// PutManifest serves a manifest body with the given media type under a repository tag and under its
+// digest, returning the digest
+func (r *Registry) PutManifest(repository, tag, mediaType string, body []byte) string {
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(body))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.manifests[manifestKey(repository, tag)] = manifest{mediaType: mediaType, body: body}
+	r.manifests[manifestKey(repository, digest)] = manifest{mediaType: mediaType, body: body}
+	return digest
+}
+
+// PutImage serves a single-platform OCI image manifest, unique to the repository tag, and returns its digest
+func (r *Registry) PutImage(repository, tag string) string {
+	config := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(repository+":"+tag)))
+	body := fmt.Sprintf(`{"schemaVersion":2,"mediaType":%q,"config":{"mediaType":"application/vnd.oci.image.config.v1+json","digest":%q,"size":2}}`,
+		MediaTypeOCIImageManifest, config)
+	return r.PutManifest(repository, tag, MediaTypeOCIImageManifest, []byte(body))
+}
+
+// FailManifest answers the next requests for a repository reference with the failures, in order,
+// before serving it normally again
+func (r *Registry) FailManifest(repository, reference string, failures ...Failure) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := manifestKey(repository, reference)
+	r.failures[key] = append(r.failures[key], failures...)
+}
+
+// FailToken answers the next token requests with the failures, in order, before issuing tokens again
+func (r *Registry) FailToken(failures ...Failure) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures[tokenKey] = append(r.failures[tokenKey], failures...)
+}
+
+// ManifestRequests returns the number of requests for a repository reference, failed ones included
+func (r *Registry) ManifestRequests(repository, reference string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.requests[manifestKey(repository, reference)]
+}
+
+// TokenRequests returns the number of token requests, failed ones included
+func (r *Registry) TokenRequests() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.requests[tokenKey]
+}
+
+func (r *Registry) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/token" {
+		if r.fail(w, tokenKey) {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"token":%q}`, Token)
+		return
+	}
+
+	repository, reference, found := strings.Cut(strings.TrimPrefix(req.URL.Path, "/v2/"), "/manifests/")
+	if !found || !strings.HasPrefix(req.URL.Path, "/v2/") {
+		writeError(w, http.StatusNotFound, "NAME_UNKNOWN")
+		return
+	}
+	key := manifestKey(repository, reference)
+	if r.fail(w, key) {
+		return
+	}
+	if req.Header.Get("Authorization") != "Bearer "+Token {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED")
+		return
+	}
+
+	r.mu.Lock()
+	m, ok := r.manifests[key]
+	r.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "MANIFEST_UNKNOWN")
+		return
+	}
+	w.Header().Set("Content-Type", m.mediaType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(m.body)))
+	w.Header().Set("Docker-Content-Digest", fmt.Sprintf("sha256:%x", sha256.Sum256(m.body)))
+	if req.Method != http.MethodHead {
+		_, _ = w.Write(m.body)
+	}
+}
+
+// fail counts a request and answers it with the next queued failure for key, reporting whether it did
+func (r *Registry) fail(w http.ResponseWriter, key string) bool {
+	r.mu.Lock()
+	r.requests[key]++
+	queued := r.failures[key]
+	if len(queued) == 0 {
+		r.mu.Unlock()
+		return false
+	}
+	failure := queued[0]
+	r.failures[key] = queued[1:]
+	r.mu.Unlock()
+
+	if failure.RetryAfter != "" {
+		w.Header().Set("Retry-After", failure.RetryAfter)
+	}
+	code := "UNKNOWN"
+	if failure.StatusCode == http.StatusTooManyRequests {
+		code = "TOOMANYREQUESTS"
+	}
+	writeError(w, failure.StatusCode, code)
+	return true
+}
+
+// writeError writes a registry error response
+func writeError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, `{"errors":[{"code":%q,"message":%q}]}`, code, http.StatusText(status))
+}
+
+func manifestKey(repository, reference string) string {
+	return repository + "@" + reference
+}
@@ -2,16 +2,81 @@ package rekor
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/sigstore/rekor/pkg/client"
+	generatedclient "github.com/sigstore/rekor/pkg/generated/client"
+	"github.com/sigstore/rekor/pkg/generated/client/entries"
+	"github.com/sigstore/rekor/pkg/generated/client/index"
+	"github.com/sigstore/rekor/pkg/generated/client/tlog"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	"github.com/jonlimpw/chainguard-controller/internal/registry"
+)
+
+// maxEntriesToInspect bounds how many Rekor entries are fetched per digest
+const maxEntriesToInspect = 20
+
+// healthCheckTimeout bounds a HealthCheck so probes don't hang on an unreachable server
+const healthCheckTimeout = 5 * time.Second
+
+// Fulcio certificate extension OIDs carrying the OIDC issuer
+var (
+	oidIssuerV1 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+	oidIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
 )
 
+// predicateTypes maps in-toto predicate type URIs to the short attestation
+// type names used in AttestationPolicy.RequiredTypes (matching cosign's naming)
+var predicateTypes = map[string]string{
+	"https://slsa.dev/provenance/v0.1":                "slsaprovenance",
+	"https://slsa.dev/provenance/v0.2":                "slsaprovenance",
+	"https://slsa.dev/provenance/v1":                  "slsaprovenance1",
+	"https://in-toto.io/Link/v1":                      "link",
+	"https://spdx.dev/Document":                       "spdxjson",
+	"https://cyclonedx.org/bom":                       "cyclonedx",
+	"https://cosign.sigstore.dev/attestation/vuln/v1": "vuln",
+	"https://openvex.dev/ns":                          "openvex",
+}
+
+// DefaultURL is the public Sigstore Rekor instance
+const DefaultURL = "https://rekor.sigstore.dev"
+
+// ErrNoEntries is returned when Rekor has no entries for a digest
+var ErrNoEntries = errors.New("no Rekor entries found")
+
+// UnavailableError is returned when the Rekor server couldn't be queried, as opposed to having no
+// matching entries
+type UnavailableError struct {
+	URL string
+	Err error
+}
+
+func (e *UnavailableError) Error() string {
+	return fmt.Sprintf("Rekor at %s is unreachable: %v", e.URL, e.Err)
+}
+
+func (e *UnavailableError) Unwrap() error { return e.Err }
+
 // Client wraps the Rekor client with convenience methods
 type Client struct {
-	rekorClient interface{} // Using interface{} for now to avoid complex type dependencies
+	rekorClient *generatedclient.Rekor
+	url         string
+
+	// verifier checks inclusion proofs and signed entry timestamps against the log's pinned public key
+	verifier signature.Verifier
 }
 
 // AttestationResult represents the result of an attestation verification
@@ -21,72 +86,401 @@ type AttestationResult struct {
 	Issuer          string
 	LogIndex        int64
 	Timestamp       time.Time
+	SLSALevel       int32
 	Error           string
+
+	// PredicateType is the full in-toto predicate type URI, e.g. "https://slsa.dev/provenance/v1"
+	PredicateType string
+
+	// SAN is the subject alternative name of the signing certificate, e.g. the GitHub workflow that signed it
+	SAN string
+
+	// BuildConfigURI is the build config the signing certificate was issued to, e.g. a GitHub workflow at a ref
+	BuildConfigURI string
+
+	// BuilderID is the builder of SLSA provenance, empty for other predicates
+	BuilderID string
+
+	// Scanner is the scanner URI and version of vulnerability reports, empty for other predicates
+	Scanner string
+
+	// Vulnerabilities counts the findings of vulnerability reports by lowercase severity (e.g. "critical"),
+	// nil for other predicates and reports without findings
+	Vulnerabilities map[string]int32
+
+	// Unavailable is set when Rekor couldn't be queried, as opposed to having no matching attestation
+	Unavailable bool
+
+	// SubjectMismatch is set when the attestation isn't about the ExpectedSubject
+	SubjectMismatch bool
+
+	// ProofVerified is set when the entry's inclusion proof and signed entry timestamp were verified
+	// against the log's public key
+	ProofVerified bool
 }
 
-// NewClient creates a new Rekor client
-func NewClient() (*Client, error) {
-	rekorClient, err := client.GetRekorClient("https://rekor.sigstore.dev")
+// NewClient creates a new Rekor client for the given server URL, or DefaultURL when empty. Entries are verified
+// against publicKey, the log's PEM-encoded signing key; without one only DefaultURL is accepted, with its
+// embedded public-good key.
+func NewClient(rekorURL string, publicKey []byte) (*Client, error) {
+	if rekorURL == "" {
+		rekorURL = DefaultURL
+	}
+	if err := ValidateURL(rekorURL); err != nil {
+		return nil, err
+	}
+	verifier, err := logVerifier(rekorURL, publicKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Rekor client: %w", err)
+		return nil, err
+	}
+
+	rekorClient, err := client.GetRekorClient(rekorURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Rekor client for %s: %w", rekorURL, err)
 	}
 
 	return &Client{
 		rekorClient: rekorClient,
+		url:         rekorURL,
+		verifier:    verifier,
 	}, nil
 }
 
-// VerifyAttestation checks if an image has valid attestations in Rekor
-func (c *Client) VerifyAttestation(ctx context.Context, imageDigest string, allowedIssuers []string, requiredTypes []string) (*AttestationResult, error) {
-	// For now, implement a simplified version that always returns a basic result
-	// In production, this would query Rekor for actual attestations
+// ValidateConfig checks that a Rekor server URL is valid and has a usable public key, as NewClient does
+func ValidateConfig(rekorURL string, publicKey []byte) error {
+	if rekorURL == "" {
+		rekorURL = DefaultURL
+	}
+	if err := ValidateURL(rekorURL); err != nil {
+		return err
+	}
+	_, err := logVerifier(rekorURL, publicKey)
+	return err
+}
+
+// ValidateURL checks that a Rekor server URL is an absolute http(s) URL
+func ValidateURL(rekorURL string) error {
+	parsed, err := url.Parse(rekorURL)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return fmt.Errorf("invalid Rekor URL %q: expected http(s)://<host>", rekorURL)
+	}
+	return nil
+}
+
+// URL returns the Rekor server URL the client talks to
+func (c *Client) URL() string {
+	return c.url
+}
 
-	// Extract SHA256 hash from digest
-	digestParts := strings.Split(imageDigest, ":")
-	if len(digestParts) != 2 || digestParts[0] != "sha256" {
+// VerifyAttestation checks if an image has valid attestations in Rekor.
+// Entries integrated before notBefore are rejected; a zero notBefore means no age limit.
+// Entries below minSLSALevel are rejected; non-provenance attestations count as SLSA level 0.
+// Entries whose signing certificate doesn't chain to fulcioRoot are rejected; a nil fulcioRoot is the public-good
+// Fulcio instance's, since the log accepts entries signed with any certificate.
+// Entries whose statement doesn't name the expected subject are rejected; a nil expectedSubject skips the check.
+// Entries whose certificate wasn't issued to the expected identity are rejected; a nil expectedIdentity skips the check.
+// Entries whose inclusion proof or signed entry timestamp doesn't verify against the log's public key are rejected,
+// as are entries whose attestation doesn't match the payload hash recorded in their body.
+func (c *Client) VerifyAttestation(ctx context.Context, imageDigest string, allowedIssuers []string, requiredTypes []string, notBefore time.Time, minSLSALevel int32, fulcioRoot *TrustRoot, expectedSubject *ExpectedSubject, expectedIdentity *ExpectedIdentity) (*AttestationResult, error) {
+	// Rekor indexes sha256 and sha512 subject digests alike
+	if _, _, err := registry.ParseDigest(imageDigest); err != nil {
 		return &AttestationResult{
 			Verified: false,
-			Error:    fmt.Sprintf("invalid digest format: %s", imageDigest),
+			Error:    err.Error(),
 		}, nil
 	}
 
-	// For demo purposes, simulate attestation verification
-	// In production, this would make actual Rekor API calls
-	result := &AttestationResult{
-		Verified:        false,
-		AttestationType: "slsaprovenance",
-		Issuer:          "https://token.actions.githubusercontent.com",
-		LogIndex:        566466542, // Example log index from our successful attestation
-		Timestamp:       time.Now(),
-		Error:           "Rekor search not fully implemented - using mock verification",
-	}
-
-	// For demo purposes, simulate attestation verification
-	// In production, this would query Rekor API to find attestations for the given digest
-
-	// Check if this is a digest-based image (has proper SHA256 format)
-	if len(digestParts) == 2 && len(digestParts[1]) == 64 {
-		// Simulate finding an attestation in Rekor
-		// In production, this would be: entries := rekorClient.SearchBySubject(digestParts[1])
-
-		// For demo: assume any properly formatted digest has an attestation
-		// Verify against policy requirements (allowedIssuers and requiredTypes)
-		if c.matchesPolicy(result, allowedIssuers, requiredTypes) {
-			result.Verified = true
-			result.Error = ""
-			// Use a mock log index - in production this would come from the actual Rekor entry
-			result.LogIndex = 123456789 // Mock log index for demo
+	if c.rekorClient == nil {
+		return nil, &UnavailableError{URL: c.url, Err: errors.New("client not initialized")}
+	}
+
+	if fulcioRoot == nil {
+		fulcioRoot = PublicGoodTrustRoot()
+	}
+
+	// Search the Rekor index for entries whose subject matches the digest
+	searchParams := index.NewSearchIndexParamsWithContext(ctx)
+	searchParams.Query = &models.SearchIndex{Hash: imageDigest}
+	searchResp, err := c.rekorClient.Index.SearchIndex(searchParams)
+	if err != nil {
+		return nil, &UnavailableError{URL: c.url, Err: fmt.Errorf("failed to search index: %w", err)}
+	}
+
+	uuids := searchResp.GetPayload()
+	if len(uuids) == 0 {
+		return nil, fmt.Errorf("%w for digest %s", ErrNoEntries, imageDigest)
+	}
+	if len(uuids) > maxEntriesToInspect {
+		uuids = uuids[:maxEntriesToInspect]
+	}
+
+	// Inspect each entry until one matches the policy requirements
+	var lastResult *AttestationResult
+	for _, uuid := range uuids {
+		entryParams := entries.NewGetLogEntryByUUIDParamsWithContext(ctx)
+		entryParams.EntryUUID = uuid
+		entryResp, err := c.rekorClient.Entries.GetLogEntryByUUID(entryParams)
+		if err != nil {
+			return nil, &UnavailableError{URL: c.url, Err: fmt.Errorf("failed to fetch entry %s: %w", uuid, err)}
+		}
+
+		for _, entry := range entryResp.GetPayload() {
+			if err := verifyProof(ctx, &entry, c.verifier); err != nil {
+				lastResult = &AttestationResult{Error: fmt.Sprintf("Rekor entry %s failed log verification: %v", uuid, err)}
+				continue
+			}
+
+			result := parseEntry(entry, fulcioRoot, expectedSubject, expectedIdentity)
+			result.ProofVerified = true
+			if result.Error != "" {
+				lastResult = result
+				continue
+			}
+
+			if matchesPolicy(result, allowedIssuers, requiredTypes, notBefore, minSLSALevel) {
+				result.Verified = true
+				return result, nil
+			}
+			lastResult = result
+		}
+	}
+
+	if lastResult == nil {
+		return nil, fmt.Errorf("%w for digest %s", ErrNoEntries, imageDigest)
+	}
+	return lastResult, nil
+}
+
+// EvaluateStatement builds the attestation result of an in-toto statement found outside Rekor, e.g. in
+// an OCI referrer, and checks it against the policy like VerifyAttestation does for Rekor entries.
+// cert is the signing certificate (nil for key-signed attestations) and timestamp when it was signed (zero if unknown).
+// Key-signed attestations and certificates that don't chain to fulcioRoot are rejected, a nil fulcioRoot being the
+// public-good Fulcio instance's root like for VerifyAttestation; with an expectedSubject, statements about another image are, and with an expectedIdentity, certificates issued to another.
+func EvaluateStatement(statement []byte, cert *x509.Certificate, timestamp time.Time, allowedIssuers []string, requiredTypes []string, notBefore time.Time, minSLSALevel int32, fulcioRoot *TrustRoot, expectedSubject *ExpectedSubject, expectedIdentity *ExpectedIdentity) *AttestationResult {
+	statement = unwrapEnvelope(statement)
+	result := &AttestationResult{Timestamp: timestamp}
+	describeStatement(result, statement)
+	if fulcioRoot == nil {
+		fulcioRoot = PublicGoodTrustRoot()
+	}
+	if cert == nil {
+		result.Error = "attestation is not signed with a Fulcio certificate"
+		return result
+	}
+	if err := fulcioRoot.Verify(cert, timestamp); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if expectedSubject != nil {
+		if err := expectedSubject.check(statement); err != nil {
+			result.Error = err.Error()
+			result.SubjectMismatch = true
+			return result
+		}
+	}
+	if cert != nil {
+		describeCertificate(result, cert)
+	}
+	if expectedIdentity != nil {
+		if err := expectedIdentity.check(cert); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+	}
+	result.SLSALevel = slsaLevel(result.AttestationType, statement, result.Issuer != "")
+	result.Verified = matchesPolicy(result, allowedIssuers, requiredTypes, notBefore, minSLSALevel)
+	return result
+}
+
+// parseEntry extracts the log index, attestation type, signer, SLSA level and inclusion time from a Rekor log entry,
+// checking its statement is the payload the entry body records the hash of, its certificate chains to fulcioRoot
+// and was issued to expectedIdentity if set, and its statement names expectedSubject if set
+func parseEntry(entry models.LogEntryAnon, fulcioRoot *TrustRoot, expectedSubject *ExpectedSubject, expectedIdentity *ExpectedIdentity) *AttestationResult {
+	result := &AttestationResult{}
+
+	if entry.LogIndex != nil {
+		result.LogIndex = *entry.LogIndex
+	}
+	if entry.IntegratedTime != nil {
+		result.Timestamp = time.Unix(*entry.IntegratedTime, 0).UTC()
+	}
+
+	body, err := decodeEntryBody(entry.Body)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to parse Rekor entry %d: %v", result.LogIndex, err)
+		return result
+	}
+
+	// The attestation is stored beside the log, which only commits to its hash in the entry body
+	var statement []byte
+	if entry.Attestation != nil && len(entry.Attestation.Data) > 0 {
+		statement = unwrapEnvelope(entry.Attestation.Data)
+		if err := body.checkPayload(statement); err != nil {
+			result.Error = fmt.Sprintf("Rekor entry %d: %v", result.LogIndex, err)
+			return result
+		}
+		describeStatement(result, statement)
+	}
+
+	cert, err := body.certificate()
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to parse Rekor entry %d: %v", result.LogIndex, err)
+		return result
+	}
+	if err := fulcioRoot.Verify(cert, result.Timestamp); err != nil {
+		result.Error = fmt.Sprintf("Rekor entry %d: %v", result.LogIndex, err)
+		return result
+	}
+	if expectedSubject != nil {
+		if err := expectedSubject.check(statement); err != nil {
+			result.Error = fmt.Sprintf("Rekor entry %d: %v", result.LogIndex, err)
+			result.SubjectMismatch = true
+			return result
+		}
+	}
+	describeCertificate(result, cert)
+	if expectedIdentity != nil {
+		if err := expectedIdentity.check(cert); err != nil {
+			result.Error = fmt.Sprintf("Rekor entry %d: %v", result.LogIndex, err)
+			return result
+		}
+	}
+	result.SLSALevel = slsaLevel(result.AttestationType, statement, result.Issuer != "")
+
+	return result
+}
+
+// entryBody is the subset of the intoto and dsse entry kinds needed to find
+// the signing certificate and the hash of the attestation payload
+type entryBody struct {
+	Kind string `json:"kind"`
+	Spec struct {
+		// intoto v0.0.1
+		PublicKey string `json:"publicKey"`
+		// intoto v0.0.1 and v0.0.2
+		Content struct {
+			Envelope struct {
+				Signatures []struct {
+					PublicKey string `json:"publicKey"`
+				} `json:"signatures"`
+			} `json:"envelope"`
+			PayloadHash *entryHash `json:"payloadHash"`
+		} `json:"content"`
+		// dsse v0.0.1
+		Signatures []struct {
+			Verifier string `json:"verifier"`
+		} `json:"signatures"`
+		PayloadHash *entryHash `json:"payloadHash"`
+	} `json:"spec"`
+}
+
+// entryHash is a hash recorded in an entry body
+type entryHash struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"value"`
+}
+
+// decodeEntryBody decodes the base64 entry body
+func decodeEntryBody(body interface{}) (*entryBody, error) {
+	encoded, ok := body.(string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected entry body type %T", body)
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode entry body: %w", err)
+	}
+
+	var parsed entryBody
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal entry body: %w", err)
+	}
+	return &parsed, nil
+}
+
+// checkPayload checks that statement is the payload whose hash the entry body records
+func (b *entryBody) checkPayload(statement []byte) error {
+	hash := b.Spec.PayloadHash
+	if hash == nil {
+		hash = b.Spec.Content.PayloadHash
+	}
+	if hash == nil {
+		return fmt.Errorf("%s entry records no payload hash for its attestation", b.Kind)
+	}
+	if hash.Algorithm != "sha256" {
+		return fmt.Errorf("unsupported payload hash algorithm %q", hash.Algorithm)
+	}
+
+	sum := sha256.Sum256(statement)
+	if !strings.EqualFold(hash.Value, hex.EncodeToString(sum[:])) {
+		return errors.New("attestation doesn't match the payload hash in the entry body")
+	}
+	return nil
+}
+
+// certificate returns the entry's signing certificate
+func (b *entryBody) certificate() (*x509.Certificate, error) {
+	var encodedKey string
+	switch {
+	case b.Spec.PublicKey != "":
+		encodedKey = b.Spec.PublicKey
+	case len(b.Spec.Content.Envelope.Signatures) > 0:
+		encodedKey = b.Spec.Content.Envelope.Signatures[0].PublicKey
+	case len(b.Spec.Signatures) > 0:
+		encodedKey = b.Spec.Signatures[0].Verifier
+	default:
+		return nil, fmt.Errorf("no signing certificate found in %s entry", b.Kind)
+	}
+
+	pemBytes, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode signing certificate: %w", err)
+	}
+
+	block, _ := pem.Decode(pemBytes)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("entry is not signed with a certificate")
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// certificateIssuer returns the OIDC issuer recorded in a Fulcio certificate
+func certificateIssuer(cert *x509.Certificate) string {
+	for _, ext := range cert.Extensions {
+		switch {
+		case ext.Id.Equal(oidIssuerV2):
+			var issuer string
+			if _, err := asn1.Unmarshal(ext.Value, &issuer); err == nil {
+				return issuer
+			}
+		case ext.Id.Equal(oidIssuerV1):
+			return string(ext.Value)
 		}
-	} else {
-		// Invalid digest format
-		result.Error = fmt.Sprintf("Invalid digest format for Rekor verification: %s", imageDigest)
 	}
+	return ""
+}
 
-	return result, nil
+// describeCertificate records who a signing certificate was issued to
+func describeCertificate(result *AttestationResult, cert *x509.Certificate) {
+	result.Issuer = certificateIssuer(cert)
+	result.SAN = certificateSAN(cert)
+	result.BuildConfigURI = certificateBuildConfigURI(cert)
 }
 
-// matchesPolicy checks if the attestation result matches the policy requirements
-func (c *Client) matchesPolicy(result *AttestationResult, allowedIssuers []string, requiredTypes []string) bool {
+// shortDuration formats a duration rounded to the minute without trailing zero units (e.g. "36h")
+func shortDuration(d time.Duration) string {
+	s := d.Round(time.Minute).String()
+	s = strings.TrimSuffix(s, "0s")
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// matchesPolicy checks if the attestation result matches the policy requirements and was recorded after notBefore
+func matchesPolicy(result *AttestationResult, allowedIssuers []string, requiredTypes []string, notBefore time.Time, minSLSALevel int32) bool {
 	// Check issuer requirements
 	if len(allowedIssuers) > 0 {
 		issuerMatch := false
@@ -117,17 +511,40 @@ func (c *Client) matchesPolicy(result *AttestationResult, allowedIssuers []strin
 		}
 	}
 
+	// Check SLSA build level requirements
+	if result.SLSALevel < minSLSALevel {
+		result.Error = fmt.Sprintf("SLSA build level %d below required level %d", result.SLSALevel, minSLSALevel)
+		return false
+	}
+
+	// Check the attestation is recent enough
+	if !notBefore.IsZero() {
+		if result.Timestamp.IsZero() {
+			result.Error = "attestation has no signing time, cannot enforce MaxAge"
+			return false
+		}
+		if result.Timestamp.Before(notBefore) {
+			result.Error = fmt.Sprintf("attestation is %s old, exceeds MaxAge %s",
+				shortDuration(time.Since(result.Timestamp)), shortDuration(time.Since(notBefore)))
+			return false
+		}
+	}
+
 	return true
 }
 
-// HealthCheck verifies that the Rekor service is accessible
+// HealthCheck verifies that the Rekor service is accessible by fetching its log info.
+// The call is bounded by healthCheckTimeout, or by ctx's deadline if it is sooner.
 func (c *Client) HealthCheck(ctx context.Context) error {
-	// Simple health check - try to create a basic request
 	if c.rekorClient == nil {
-		return fmt.Errorf("Rekor client not initialized")
+		return &UnavailableError{URL: c.url, Err: errors.New("client not initialized")}
 	}
 
-	// For now, assume healthy if client exists
-	// In production, this would make an actual API call to verify connectivity
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	if _, err := c.rekorClient.Tlog.GetLogInfo(tlog.NewGetLogInfoParamsWithContext(ctx)); err != nil {
+		return &UnavailableError{URL: c.url, Err: err}
+	}
 	return nil
 }
@@ -0,0 +1,322 @@
+package rekor
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Client", func() {
+	It("should default to the public Sigstore instance", func() {
+		client, err := NewClient("", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.URL()).To(Equal(DefaultURL))
+	})
+
+	It("should use a private Rekor URL with its pinned public key", func() {
+		client, err := NewClient("http://rekor.sigstore-system.svc:3000", testPublicKey())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.URL()).To(Equal("http://rekor.sigstore-system.svc:3000"))
+	})
+
+	It("should require a public key for a private Rekor URL", func() {
+		_, err := NewClient("http://rekor.sigstore-system.svc:3000", nil)
+		Expect(err).To(MatchError(ErrNoPublicKey))
+		Expect(ValidateConfig("http://rekor.sigstore-system.svc:3000", nil)).To(MatchError(ErrNoPublicKey))
+
+		_, err = NewClient("http://rekor.sigstore-system.svc:3000", []byte("not a key"))
+		Expect(err).To(MatchError(ContainSubstring("invalid Rekor public key")))
+	})
+
+	It("should reject URLs without an http(s) scheme and host", func() {
+		for _, rekorURL := range []string{"rekor.sigstore.dev", "ftp://rekor.example.com", "https://"} {
+			_, err := NewClient(rekorURL, testPublicKey())
+			Expect(err).To(MatchError(ContainSubstring("invalid Rekor URL")), rekorURL)
+		}
+	})
+
+	Context("HealthCheck", func() {
+		newServer := func(handler http.HandlerFunc) *Client {
+			server := httptest.NewServer(handler)
+			DeferCleanup(server.Close)
+			client, err := NewClient(server.URL, testPublicKey())
+			Expect(err).NotTo(HaveOccurred())
+			return client
+		}
+
+		It("should succeed when the log info endpoint answers", func() {
+			client := newServer(func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.URL.Path).To(Equal("/api/v1/log"))
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"rootHash":"abc","signedTreeHead":"sth","treeID":"1","treeSize":1}`))
+			})
+
+			Expect(client.HealthCheck(context.Background())).To(Succeed())
+		})
+
+		It("should fail when the log info endpoint returns an error status", func() {
+			client := newServer(func(w http.ResponseWriter, r *http.Request) {
+				http.NotFound(w, r)
+			})
+
+			err := client.HealthCheck(context.Background())
+			Expect(err).To(MatchError(ContainSubstring("is unreachable")))
+			var unavailable *UnavailableError
+			Expect(errors.As(err, &unavailable)).To(BeTrue())
+		})
+
+		It("should report a failing index search as unavailable rather than having no entries", func() {
+			client := newServer(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			})
+
+			_, err := client.VerifyAttestation(context.Background(), "sha256:"+strings.Repeat("1", 64), nil, nil, time.Time{}, 0, nil, nil, nil)
+			var unavailable *UnavailableError
+			Expect(errors.As(err, &unavailable)).To(BeTrue())
+			Expect(unavailable.URL).To(Equal(client.URL()))
+			Expect(errors.Is(err, ErrNoEntries)).To(BeFalse())
+		})
+
+		It("should report a digest without entries as ErrNoEntries", func() {
+			client := newServer(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`[]`))
+			})
+
+			_, err := client.VerifyAttestation(context.Background(), "sha256:"+strings.Repeat("1", 64), nil, nil, time.Time{}, 0, nil, nil, nil)
+			Expect(err).To(MatchError(ErrNoEntries))
+			var unavailable *UnavailableError
+			Expect(errors.As(err, &unavailable)).To(BeFalse())
+		})
+
+		It("should search the index for sha512 digests and reject malformed ones", func() {
+			var searched string
+			client := newServer(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				searched = string(body)
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`[]`))
+			})
+
+			_, err := client.VerifyAttestation(context.Background(), "sha512:"+strings.Repeat("1", 128), nil, nil, time.Time{}, 0, nil, nil, nil)
+			Expect(err).To(MatchError(ErrNoEntries))
+			Expect(searched).To(ContainSubstring("sha512:" + strings.Repeat("1", 128)))
+
+			result, err := client.VerifyAttestation(context.Background(), "sha512:"+strings.Repeat("1", 64), nil, nil, time.Time{}, 0, nil, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Error).To(ContainSubstring("invalid digest format"))
+		})
+
+		It("should respect the context deadline", func() {
+			client := newServer(func(_ http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+			Expect(client.HealthCheck(ctx)).NotTo(Succeed())
+			Expect(time.Since(start)).To(BeNumerically("<", 2*time.Second))
+		})
+	})
+	Context("EvaluateStatement", func() {
+		statement := []byte(`{"_type":"https://in-toto.io/Statement/v1","predicateType":"https://slsa.dev/provenance/v1","predicate":{}}`)
+		var (
+			cert      *x509.Certificate
+			trustRoot *TrustRoot
+		)
+		BeforeEach(func() {
+			cert, trustRoot = testSigner()
+		})
+
+		It("should verify a statement matching the required types", func() {
+			result := EvaluateStatement(statement, cert, time.Now(), nil, []string{"slsaprovenance1"}, time.Time{}, 0, trustRoot, nil, nil)
+			Expect(result.Verified).To(BeTrue())
+			Expect(result.AttestationType).To(Equal("slsaprovenance1"))
+		})
+
+		It("should reject a statement of another type", func() {
+			result := EvaluateStatement(statement, cert, time.Now(), nil, []string{"spdxjson"}, time.Time{}, 0, trustRoot, nil, nil)
+			Expect(result.Verified).To(BeFalse())
+			Expect(result.Error).To(ContainSubstring("not in required list"))
+		})
+
+		It("should reject a statement without signing time when MaxAge is set", func() {
+			result := EvaluateStatement(statement, cert, time.Time{}, nil, nil, time.Now().Add(-time.Hour), 0, trustRoot, nil, nil)
+			Expect(result.Verified).To(BeFalse())
+			Expect(result.Error).To(ContainSubstring("cannot enforce MaxAge"))
+		})
+
+		It("should reject a statement older than MaxAge", func() {
+			result := EvaluateStatement(statement, cert, time.Now().Add(-2*time.Hour), nil, nil, time.Now().Add(-time.Hour), 0, trustRoot, nil, nil)
+			Expect(result.Verified).To(BeFalse())
+			Expect(result.Error).To(ContainSubstring("exceeds MaxAge 1h"))
+		})
+	})
+
+	Context("predicate details", func() {
+		var (
+			cert      *x509.Certificate
+			trustRoot *TrustRoot
+		)
+		BeforeEach(func() {
+			cert, trustRoot = testSigner()
+		})
+
+		envelope := func(statement string) []byte {
+			return []byte(`{"payloadType":"application/vnd.in-toto+json","payload":"` +
+				base64.StdEncoding.EncodeToString([]byte(statement)) + `","signatures":[{"sig":"c2ln"}]}`)
+		}
+
+		It("should extract the builder ID of SLSA provenance in a DSSE envelope", func() {
+			result := EvaluateStatement(envelope(`{"_type":"https://in-toto.io/Statement/v1",`+
+				`"subject":[{"name":"docker.io/jonlimpw/cg-demo","digest":{"sha256":"1111"}}],`+
+				`"predicateType":"https://slsa.dev/provenance/v1",`+
+				`"predicate":{"runDetails":{"builder":{"id":"https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml@refs/tags/v2.0.0"}}}}`),
+				cert, time.Now(), nil, nil, time.Time{}, 0, trustRoot, nil, nil)
+			Expect(result.PredicateType).To(Equal("https://slsa.dev/provenance/v1"))
+			Expect(result.AttestationType).To(Equal("slsaprovenance1"))
+			Expect(result.BuilderID).To(HavePrefix("https://github.com/slsa-framework/slsa-github-generator/"))
+			Expect(result.Scanner).To(BeEmpty())
+		})
+
+		It("should extract the builder ID of SLSA v0.2 provenance", func() {
+			result := &AttestationResult{}
+			describeStatement(result, []byte(`{"predicateType":"https://slsa.dev/provenance/v0.2","predicate":{"builder":{"id":"https://github.com/actions/runner/github-hosted"}}}`))
+			Expect(result.BuilderID).To(Equal("https://github.com/actions/runner/github-hosted"))
+		})
+
+		It("should extract the scanner of vulnerability reports", func() {
+			result := &AttestationResult{}
+			describeStatement(result, unwrapEnvelope(envelope(`{"predicateType":"https://cosign.sigstore.dev/attestation/vuln/v1",`+
+				`"predicate":{"scanner":{"uri":"pkg:github/aquasecurity/trivy","version":"0.50.0","result":{}}}}`)))
+			Expect(result.AttestationType).To(Equal("vuln"))
+			Expect(result.Scanner).To(Equal("pkg:github/aquasecurity/trivy@0.50.0"))
+			Expect(result.BuilderID).To(BeEmpty())
+			Expect(result.Vulnerabilities).To(BeNil())
+		})
+
+		It("should count the findings of trivy and grype reports by severity", func() {
+			result := &AttestationResult{}
+			describeStatement(result, []byte(`{"predicateType":"https://cosign.sigstore.dev/attestation/vuln/v1","predicate":{"scanner":{"result":`+
+				`{"Results":[{"Vulnerabilities":[{"Severity":"CRITICAL"},{"Severity":"HIGH"}]},{"Vulnerabilities":[{"Severity":"CRITICAL"}]}]}}}}`))
+			Expect(result.Vulnerabilities).To(Equal(map[string]int32{"critical": 2, "high": 1}))
+
+			describeStatement(result, []byte(`{"predicateType":"https://cosign.sigstore.dev/attestation/vuln/v1","predicate":{"scanner":{"result":`+
+				`{"matches":[{"vulnerability":{"severity":"Medium"}},{"vulnerability":{"severity":"Low"}}]}}}}`))
+			Expect(result.Vulnerabilities).To(Equal(map[string]int32{"medium": 1, "low": 1}))
+		})
+
+		It("should only report the type of unrecognized predicates", func() {
+			result := &AttestationResult{}
+			describeStatement(result, []byte(`{"predicateType":"https://example.com/custom/v1","predicate":{"builder":{"id":"ignored"}}}`))
+			Expect(result.PredicateType).To(Equal("https://example.com/custom/v1"))
+			Expect(result.AttestationType).To(Equal("custom"))
+			Expect(result.BuilderID).To(BeEmpty())
+		})
+
+		It("should leave the details empty when the payload isn't a statement", func() {
+			result := &AttestationResult{}
+			describeStatement(result, unwrapEnvelope([]byte(`not json`)))
+			Expect(*result).To(Equal(AttestationResult{}))
+		})
+	})
+
+	Context("expected subject", func() {
+		var (
+			cert      *x509.Certificate
+			trustRoot *TrustRoot
+		)
+		BeforeEach(func() {
+			cert, trustRoot = testSigner()
+		})
+
+		digest := "sha256:" + strings.Repeat("1", 64)
+		statement := []byte(`{"_type":"https://in-toto.io/Statement/v1",` +
+			`"subject":[{"name":"index.docker.io/jonlimpw/other","digest":{"sha256":"` + strings.Repeat("2", 64) + `"}},` +
+			`{"name":"index.docker.io/jonlimpw/cg-demo","digest":{"sha256":"` + strings.Repeat("1", 64) + `"}}],` +
+			`"predicateType":"https://slsa.dev/provenance/v1","predicate":{}}`)
+
+		It("should verify a statement naming the repository with the digest, however the name is written", func() {
+			for _, repository := range []string{"jonlimpw/cg-demo", "docker.io/jonlimpw/cg-demo", "index.docker.io/jonlimpw/cg-demo:v1"} {
+				result := EvaluateStatement(statement, cert, time.Now(), nil, nil, time.Time{}, 0, trustRoot,
+					&ExpectedSubject{Repository: repository, Digest: digest}, nil)
+				Expect(result.Verified).To(BeTrue(), repository)
+				Expect(result.SubjectMismatch).To(BeFalse())
+			}
+		})
+
+		It("should reject a statement about another repository with the same digest", func() {
+			result := EvaluateStatement(statement, cert, time.Now(), nil, nil, time.Time{}, 0, trustRoot,
+				&ExpectedSubject{Repository: "attacker/app", Digest: digest}, nil)
+			Expect(result.Verified).To(BeFalse())
+			Expect(result.SubjectMismatch).To(BeTrue())
+			Expect(result.Error).To(Equal("attestation subject index.docker.io/jonlimpw/cg-demo does not match expected repository attacker/app"))
+		})
+
+		It("should reject a subject naming the repository with another digest", func() {
+			result := EvaluateStatement(statement, cert, time.Now(), nil, nil, time.Time{}, 0, trustRoot,
+				&ExpectedSubject{Repository: "jonlimpw/other", Digest: digest}, nil)
+			Expect(result.Verified).To(BeFalse())
+			Expect(result.SubjectMismatch).To(BeTrue())
+		})
+
+		It("should reject a statement without subjects", func() {
+			result := EvaluateStatement([]byte(`{"predicateType":"https://slsa.dev/provenance/v1","predicate":{}}`), cert, time.Now(), nil, nil, time.Time{}, 0, trustRoot,
+				&ExpectedSubject{Repository: "jonlimpw/cg-demo"}, nil)
+			Expect(result.Verified).To(BeFalse())
+			Expect(result.SubjectMismatch).To(BeTrue())
+			Expect(result.Error).To(ContainSubstring("no subject"))
+		})
+	})
+})
+
+// testPublicKey returns the PEM public key of a fresh ECDSA key, for Rekor servers whose entries aren't verified
+func testPublicKey() []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	Expect(err).NotTo(HaveOccurred())
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+// testSigner returns a self-signed certificate and the trust root it chains to, for statements whose
+// signing certificate isn't under test
+func testSigner() (*x509.Certificate, *TrustRoot) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		NotBefore:             time.Now().Add(-24 * time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	cert, err := x509.ParseCertificate(der)
+	Expect(err).NotTo(HaveOccurred())
+	return cert, trustRootOf(cert)
+}
+
+// trustRootOf returns a trust root of the self-signed CA certificate cert
+func trustRootOf(cert *x509.Certificate) *TrustRoot {
+	trustRoot, err := ParseTrustRoot(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+	Expect(err).NotTo(HaveOccurred())
+	return trustRoot
+}
@@ -0,0 +1,79 @@
+package rekor
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"strings"
+)
+
+// oidBuildConfigURI is the Fulcio certificate extension OID carrying the build config (e.g. GitHub workflow) URI
+var oidBuildConfigURI = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 18}
+
+// ExpectedIdentity is who must have signed an attestation. The OIDC issuer only says which CI provider
+// issued the certificate, so any repository on it would pass; the SAN and build config name the workflow.
+// Entries ending in "*" match as a prefix, e.g. "https://github.com/org/repo/*"; the others must match exactly.
+type ExpectedIdentity struct {
+	// SANs are the accepted certificate subject alternative names, e.g.
+	// "https://github.com/org/repo/.github/workflows/release.yml@refs/heads/main"; any when empty
+	SANs []string
+
+	// BuildConfigURIs are the accepted build config URIs; any when empty
+	BuildConfigURIs []string
+}
+
+// check returns an error unless the certificate's SAN and build config URI are accepted
+func (i *ExpectedIdentity) check(cert *x509.Certificate) error {
+	if cert == nil {
+		return fmt.Errorf("attestation is not signed with a certificate to match the expected identity")
+	}
+	if san := certificateSAN(cert); !identityMatches(san, i.SANs) {
+		return fmt.Errorf("certificate SAN %q not in allowed list %v", san, i.SANs)
+	}
+	if uri := certificateBuildConfigURI(cert); !identityMatches(uri, i.BuildConfigURIs) {
+		return fmt.Errorf("certificate build config URI %q not in allowed list %v", uri, i.BuildConfigURIs)
+	}
+	return nil
+}
+
+// identityMatches checks if a value is accepted by the allowed entries, any value when there are none
+func identityMatches(value string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, entry := range allowed {
+		if prefix, ok := strings.CutSuffix(entry, "*"); ok && value != "" && strings.HasPrefix(value, prefix) {
+			return true
+		}
+		if value == entry {
+			return true
+		}
+	}
+	return false
+}
+
+// certificateSAN returns the subject alternative name of a Fulcio certificate: the workflow URI of CI
+// identities, or the email address of people
+func certificateSAN(cert *x509.Certificate) string {
+	if len(cert.URIs) > 0 {
+		return cert.URIs[0].String()
+	}
+	if len(cert.EmailAddresses) > 0 {
+		return cert.EmailAddresses[0]
+	}
+	return ""
+}
+
+// certificateBuildConfigURI returns the build config URI recorded in a Fulcio certificate, empty if none
+func certificateBuildConfigURI(cert *x509.Certificate) string {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidBuildConfigURI) {
+			continue
+		}
+		var uri string
+		if _, err := asn1.Unmarshal(ext.Value, &uri); err == nil {
+			return uri
+		}
+	}
+	return ""
+}
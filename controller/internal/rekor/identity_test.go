@@ -0,0 +1,90 @@
+package rekor
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"net/url"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExpectedIdentity", func() {
+	const (
+		workflow    = "https://github.com/jonlimpw/cg-demo/.github/workflows/release.yml@refs/heads/main"
+		buildConfig = "https://github.com/jonlimpw/cg-demo/.github/workflows/release.yml@refs/tags/v1.0.0"
+	)
+	statement := []byte(`{"_type":"https://in-toto.io/Statement/v1","predicateType":"https://slsa.dev/provenance/v1","predicate":{}}`)
+
+	// newCertificate creates a self-signed certificate issued to a workflow, like Fulcio's for GitHub Actions,
+	// that trustRootOf accepts
+	newCertificate := func(san, buildConfigURI string) *x509.Certificate {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		sanURI, err := url.Parse(san)
+		Expect(err).NotTo(HaveOccurred())
+		buildConfigValue, err := asn1.MarshalWithParams(buildConfigURI, "utf8")
+		Expect(err).NotTo(HaveOccurred())
+
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(time.Now().UnixNano()),
+			NotBefore:             time.Now().Add(-time.Minute),
+			NotAfter:              time.Now().Add(10 * time.Minute),
+			URIs:                  []*url.URL{sanURI},
+			ExtraExtensions:       []pkix.Extension{{Id: oidBuildConfigURI, Value: buildConfigValue}},
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		Expect(err).NotTo(HaveOccurred())
+		cert, err := x509.ParseCertificate(der)
+		Expect(err).NotTo(HaveOccurred())
+		return cert
+	}
+
+	It("should record who the certificate was issued to", func() {
+		cert := newCertificate(workflow, buildConfig)
+		result := EvaluateStatement(statement, cert, time.Now(), nil, nil, time.Time{}, 0, trustRootOf(cert), nil, nil)
+		Expect(result.Verified).To(BeTrue())
+		Expect(result.SAN).To(Equal(workflow))
+		Expect(result.BuildConfigURI).To(Equal(buildConfig))
+	})
+
+	It("should accept certificates issued to an allowed workflow, exactly or by prefix", func() {
+		cert := newCertificate(workflow, buildConfig)
+		for _, identity := range []*ExpectedIdentity{
+			{SANs: []string{workflow}},
+			{SANs: []string{"https://github.com/jonlimpw/cg-demo/*"}},
+			{BuildConfigURIs: []string{"https://github.com/jonlimpw/cg-demo/.github/workflows/release.yml@refs/tags/*"}},
+		} {
+			result := EvaluateStatement(statement, cert, time.Now(), nil, nil, time.Time{}, 0, trustRootOf(cert), nil, identity)
+			Expect(result.Verified).To(BeTrue(), "%+v", identity)
+		}
+	})
+
+	It("should reject certificates issued to another repository or build config", func() {
+		cert := newCertificate("https://github.com/attacker/fork/.github/workflows/release.yml@refs/heads/main", buildConfig)
+		result := EvaluateStatement(statement, cert, time.Now(), nil, nil, time.Time{}, 0, trustRootOf(cert), nil,
+			&ExpectedIdentity{SANs: []string{"https://github.com/jonlimpw/cg-demo/*"}})
+		Expect(result.Verified).To(BeFalse())
+		Expect(result.Error).To(ContainSubstring(`certificate SAN "https://github.com/attacker/fork/`))
+
+		cert = newCertificate(workflow, buildConfig)
+		result = EvaluateStatement(statement, cert, time.Now(), nil, nil, time.Time{}, 0, trustRootOf(cert), nil,
+			&ExpectedIdentity{BuildConfigURIs: []string{"https://github.com/jonlimpw/cg-demo/.github/workflows/release.yml@refs/heads/main"}})
+		Expect(result.Verified).To(BeFalse())
+		Expect(result.Error).To(ContainSubstring("certificate build config URI"))
+	})
+
+	It("should reject key-signed statements when an identity is expected", func() {
+		result := EvaluateStatement(statement, nil, time.Now(), nil, nil, time.Time{}, 0, nil, nil, &ExpectedIdentity{SANs: []string{workflow}})
+		Expect(result.Verified).To(BeFalse())
+		Expect(result.Error).To(ContainSubstring("not signed with a Fulcio certificate"))
+	})
+})
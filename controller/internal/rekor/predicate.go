@@ -0,0 +1,118 @@
+package rekor
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// dsseEnvelope is a DSSE envelope; the payload is base64 in JSON and decoded by encoding/json
+type dsseEnvelope struct {
+	PayloadType string `json:"payloadType"`
+	Payload     []byte `json:"payload"`
+}
+
+// statementSummary is the subset of an in-toto statement surfaced in the attestation details
+type statementSummary struct {
+	PredicateType string `json:"predicateType"`
+	Predicate     struct {
+		// SLSA provenance v0.1 and v0.2
+		Builder struct {
+			ID string `json:"id"`
+		} `json:"builder"`
+
+		// SLSA provenance v1
+		RunDetails struct {
+			Builder struct {
+				ID string `json:"id"`
+			} `json:"builder"`
+		} `json:"runDetails"`
+
+		// cosign vulnerability reports, whose result is the scanner's own report
+		Scanner struct {
+			URI     string          `json:"uri"`
+			Version string          `json:"version"`
+			Result  json.RawMessage `json:"result"`
+		} `json:"scanner"`
+	} `json:"predicate"`
+}
+
+// scanReport is the subset of trivy and grype JSON reports needed to count findings by severity
+type scanReport struct {
+	// trivy
+	Results []struct {
+		Vulnerabilities []struct {
+			Severity string `json:"Severity"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+
+	// grype
+	Matches []struct {
+		Vulnerability struct {
+			Severity string `json:"severity"`
+		} `json:"vulnerability"`
+	} `json:"matches"`
+}
+
+// vulnerabilityCounts counts the findings of a trivy or grype report by lowercase severity; a report
+// that can't be parsed or has no findings counts none
+func vulnerabilityCounts(report json.RawMessage) map[string]int32 {
+	var parsed scanReport
+	if len(report) == 0 || json.Unmarshal(report, &parsed) != nil {
+		return nil
+	}
+
+	var counts map[string]int32
+	count := func(severity string) {
+		if counts == nil {
+			counts = map[string]int32{}
+		}
+		counts[strings.ToLower(severity)]++
+	}
+	for _, result := range parsed.Results {
+		for _, vulnerability := range result.Vulnerabilities {
+			count(vulnerability.Severity)
+		}
+	}
+	for _, match := range parsed.Matches {
+		count(match.Vulnerability.Severity)
+	}
+	return counts
+}
+
+// unwrapEnvelope returns the in-toto statement of attestation data, decoding the payload of a DSSE
+// envelope; data that isn't an envelope is returned as is
+func unwrapEnvelope(data []byte) []byte {
+	var envelope dsseEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil || envelope.PayloadType == "" || len(envelope.Payload) == 0 {
+		return data
+	}
+	return envelope.Payload
+}
+
+// describeStatement fills in the predicate type, attestation type, and the builder ID of SLSA provenance
+// or scanner and findings of vulnerability reports. Unrecognized predicates only get their type; unparsable ones nothing.
+func describeStatement(result *AttestationResult, statement []byte) {
+	var summary statementSummary
+	if err := json.Unmarshal(statement, &summary); err != nil || summary.PredicateType == "" {
+		return
+	}
+
+	result.PredicateType = summary.PredicateType
+	result.AttestationType = "custom"
+	if shortType, ok := predicateTypes[summary.PredicateType]; ok {
+		result.AttestationType = shortType
+	}
+
+	switch result.AttestationType {
+	case "slsaprovenance":
+		result.BuilderID = summary.Predicate.Builder.ID
+	case "slsaprovenance1":
+		result.BuilderID = summary.Predicate.RunDetails.Builder.ID
+	case "vuln":
+		result.Scanner = summary.Predicate.Scanner.URI
+		if version := summary.Predicate.Scanner.Version; result.Scanner != "" && version != "" {
+			result.Scanner += "@" + version
+		}
+		result.Vulnerabilities = vulnerabilityCounts(summary.Predicate.Scanner.Result)
+	}
+}
@@ -0,0 +1,62 @@
+package rekor
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/verify"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+// publicGoodKey is the signing key of the public-good Rekor instance at DefaultURL, as distributed in
+// Sigstore's TUF repository (rekor.pub)
+const publicGoodKey = `-----BEGIN PUBLIC KEY-----
+MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAE2G2Y+2tabdTV5BcGiBIx0a9fAFwr
+kBbmLSGtks4L3qX6yYY0zufBnhC8Ur/iy55GhWP/9A/bY2LhC30M9+RYtw==
+-----END PUBLIC KEY-----
+`
+
+// ErrNoPublicKey is returned for a Rekor server other than DefaultURL without a pinned public key
+var ErrNoPublicKey = errors.New("a Rekor public key is required")
+
+// logVerifier returns a verifier for the log's signing key: publicKey (PEM) when set, else the embedded
+// public-good key, which is only valid for DefaultURL. The key is never fetched from the server being verified.
+func logVerifier(rekorURL string, publicKey []byte) (signature.Verifier, error) {
+	if len(publicKey) == 0 {
+		if strings.TrimSuffix(rekorURL, "/") != DefaultURL {
+			return nil, fmt.Errorf("%w to verify entries from %s", ErrNoPublicKey, rekorURL)
+		}
+		publicKey = []byte(publicGoodKey)
+	}
+
+	key, err := cryptoutils.UnmarshalPEMToPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Rekor public key for %s: %w", rekorURL, err)
+	}
+	verifier, err := signature.LoadVerifier(key, crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported Rekor public key for %s: %w", rekorURL, err)
+	}
+	return verifier, nil
+}
+
+// verifyProof checks a log entry's inclusion proof against its checkpoint, the checkpoint signature and
+// the signed entry timestamp, so an entry altered or never integrated by the log is rejected
+func verifyProof(ctx context.Context, entry *models.LogEntryAnon, verifier signature.Verifier) error {
+	if entry.LogIndex == nil || entry.IntegratedTime == nil || entry.LogID == nil {
+		return errors.New("entry is missing its log index, integrated time or log ID")
+	}
+	if entry.Verification == nil || len(entry.Verification.SignedEntryTimestamp) == 0 {
+		return errors.New("entry has no signed entry timestamp")
+	}
+	proof := entry.Verification.InclusionProof
+	if proof == nil || proof.Checkpoint == nil || proof.LogIndex == nil || proof.RootHash == nil || proof.TreeSize == nil {
+		return errors.New("entry has no inclusion proof")
+	}
+	return verify.VerifyLogEntry(ctx, entry, verifier)
+}
@@ -0,0 +1,202 @@
+package rekor
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/sigstore/rekor/pkg/util"
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Inclusion proof verification", func() {
+	const uuid = "24296fb24b8ad77a0000000000000000000000000000000000000000000000000000000000000000"
+	digest := "sha256:" + strings.Repeat("1", 64)
+	integratedTime := time.Now().Add(-time.Hour).Unix()
+	statement := []byte(`{"_type":"https://in-toto.io/Statement/v1","predicateType":"https://slsa.dev/provenance/v1","predicate":{}}`)
+
+	var (
+		logKey    *ecdsa.PrivateKey
+		logSigner signature.SignerVerifier
+		signer    *TrustRoot
+		body      string
+		entry     map[string]interface{}
+	)
+
+	BeforeEach(func() {
+		var err error
+		logKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		logSigner, err = signature.LoadECDSASignerVerifier(logKey, crypto.SHA256)
+		Expect(err).NotTo(HaveOccurred())
+
+		// A dsse entry signed with a self-signed certificate, recording the hash of its statement; tests trust
+		// the certificate as its own Fulcio root
+		certKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: "signer"},
+			NotBefore:             time.Now().Add(-2 * time.Hour),
+			NotAfter:              time.Now().Add(time.Hour),
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &certKey.PublicKey, certKey)
+		Expect(err).NotTo(HaveOccurred())
+		certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+		signer, err = ParseTrustRoot(certPEM)
+		Expect(err).NotTo(HaveOccurred())
+		payloadHash := sha256.Sum256(statement)
+		raw := []byte(`{"kind":"dsse","spec":{"payloadHash":{"algorithm":"sha256","value":"` + hex.EncodeToString(payloadHash[:]) + `"},` +
+			`"signatures":[{"verifier":"` + base64.StdEncoding.EncodeToString(certPEM) + `"}]}}`)
+		body = base64.StdEncoding.EncodeToString(raw)
+
+		// A single-entry tree, whose root hash is the entry's leaf hash
+		rootHash := sha256.Sum256(append([]byte{0}, raw...))
+		checkpoint, err := util.CreateAndSignCheckpoint(context.Background(), "rekor.test", 1, 1, rootHash[:], logSigner)
+		Expect(err).NotTo(HaveOccurred())
+
+		// The signed entry timestamp covers the canonical JSON of these fields, whose keys are already sorted
+		logID := hex.EncodeToString(rootHash[:])
+		payload, err := json.Marshal(struct {
+			Body           string `json:"body"`
+			IntegratedTime int64  `json:"integratedTime"`
+			LogID          string `json:"logID"`
+			LogIndex       int64  `json:"logIndex"`
+		}{body, integratedTime, logID, 0})
+		Expect(err).NotTo(HaveOccurred())
+		set, err := logSigner.SignMessage(bytes.NewReader(payload))
+		Expect(err).NotTo(HaveOccurred())
+
+		entry = map[string]interface{}{
+			"body":           body,
+			"integratedTime": integratedTime,
+			"logID":          logID,
+			"logIndex":       0,
+			"attestation":    map[string]interface{}{"data": base64.StdEncoding.EncodeToString(statement)},
+			"verification": map[string]interface{}{
+				"signedEntryTimestamp": set,
+				"inclusionProof": map[string]interface{}{
+					"checkpoint": string(checkpoint),
+					"hashes":     []string{},
+					"logIndex":   0,
+					"rootHash":   hex.EncodeToString(rootHash[:]),
+					"treeSize":   1,
+				},
+			},
+		}
+	})
+
+	// newClient serves the entry from a Rekor server whose key is pinned to pinnedKey, the log key when nil
+	newClient := func(pinnedKey *ecdsa.PublicKey) *Client {
+		if pinnedKey == nil {
+			pinnedKey = &logKey.PublicKey
+		}
+		publicKey, err := x509.MarshalPKIXPublicKey(pinnedKey)
+		Expect(err).NotTo(HaveOccurred())
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/v1/index/retrieve":
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode([]string{uuid})
+			case "/api/v1/log/entries/" + uuid:
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{uuid: entry})
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		DeferCleanup(server.Close)
+
+		client, err := NewClient(server.URL, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}))
+		Expect(err).NotTo(HaveOccurred())
+		return client
+	}
+
+	It("should verify an entry with a valid inclusion proof and signed entry timestamp", func() {
+		result, err := newClient(nil).VerifyAttestation(context.Background(), digest, nil, []string{"slsaprovenance1"}, time.Time{}, 0, signer, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Error).To(BeEmpty())
+		Expect(result.Verified).To(BeTrue())
+		Expect(result.ProofVerified).To(BeTrue())
+		Expect(result.AttestationType).To(Equal("slsaprovenance1"))
+	})
+
+	It("should reject a certificate that doesn't chain to the public-good Fulcio root by default", func() {
+		result, err := newClient(nil).VerifyAttestation(context.Background(), digest, nil, []string{"slsaprovenance1"}, time.Time{}, 0, nil, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Verified).To(BeFalse())
+		Expect(result.ProofVerified).To(BeTrue())
+		Expect(result.Error).To(ContainSubstring("doesn't chain to the Fulcio root"))
+	})
+
+	It("should reject an entry signed by a log other than the pinned one", func() {
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := newClient(&otherKey.PublicKey).VerifyAttestation(context.Background(), digest, nil, nil, time.Time{}, 0, nil, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Verified).To(BeFalse())
+		Expect(result.ProofVerified).To(BeFalse())
+		Expect(result.Error).To(ContainSubstring("failed log verification"))
+	})
+
+	It("should reject an attestation that doesn't match the entry's payload hash", func() {
+		swapped := []byte(`{"_type":"https://in-toto.io/Statement/v1","predicateType":"https://spdx.dev/Document","predicate":{}}`)
+		entry["attestation"] = map[string]interface{}{"data": base64.StdEncoding.EncodeToString(swapped)}
+
+		result, err := newClient(nil).VerifyAttestation(context.Background(), digest, nil, []string{"spdxjson"}, time.Time{}, 0, nil, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Verified).To(BeFalse())
+		Expect(result.AttestationType).To(BeEmpty())
+		Expect(result.Error).To(ContainSubstring("doesn't match the payload hash"))
+	})
+
+	It("should reject an entry whose signed entry timestamp doesn't verify", func() {
+		entry["integratedTime"] = integratedTime + 1
+
+		result, err := newClient(nil).VerifyAttestation(context.Background(), digest, nil, nil, time.Time{}, 0, nil, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Verified).To(BeFalse())
+		Expect(result.ProofVerified).To(BeFalse())
+		Expect(result.Error).To(ContainSubstring("failed log verification"))
+	})
+
+	It("should reject an entry whose inclusion proof doesn't match the checkpoint", func() {
+		proof := entry["verification"].(map[string]interface{})["inclusionProof"].(map[string]interface{})
+		proof["rootHash"] = strings.Repeat("0", 64)
+
+		result, err := newClient(nil).VerifyAttestation(context.Background(), digest, nil, nil, time.Time{}, 0, nil, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Verified).To(BeFalse())
+		Expect(result.Error).To(ContainSubstring("failed log verification"))
+	})
+
+	It("should reject an entry without an inclusion proof", func() {
+		delete(entry["verification"].(map[string]interface{}), "inclusionProof")
+
+		result, err := newClient(nil).VerifyAttestation(context.Background(), digest, nil, nil, time.Time{}, 0, nil, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Verified).To(BeFalse())
+		Expect(result.Error).To(ContainSubstring("no inclusion proof"))
+	})
+})
@@ -0,0 +1,14 @@
+package rekor
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRekor(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Rekor Suite")
+}
@@ -0,0 +1,105 @@
+package rekor
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// SLSA build levels derived from provenance:
+//
+//	0 - no SLSA provenance attestation
+//	1 - provenance exists
+//	2 - provenance names its builder and is signed with a Fulcio certificate (hosted, signed builds)
+//	3 - 2, produced by a builder known to run isolated builds, with every material pinned by digest
+const (
+	SLSALevelNone       = 0
+	SLSALevelProvenance = 1
+	SLSALevelSigned     = 2
+	SLSALevelHardened   = 3
+)
+
+// hardenedBuilders are builder ID prefixes known to meet SLSA build level 3 isolation requirements
+var hardenedBuilders = []string{
+	"https://github.com/slsa-framework/slsa-github-generator/",
+	"https://cloudbuild.googleapis.com/GoogleHostedWorker",
+	"https://github.com/actions/runner/github-hosted",
+}
+
+// slsaProvenance is the subset of the SLSA v0.1, v0.2 and v1 provenance predicates needed to
+// determine the build level
+type slsaProvenance struct {
+	Predicate struct {
+		// v0.1 and v0.2
+		Builder struct {
+			ID string `json:"id"`
+		} `json:"builder"`
+		Materials []slsaMaterial `json:"materials"`
+
+		// v1
+		RunDetails struct {
+			Builder struct {
+				ID string `json:"id"`
+			} `json:"builder"`
+		} `json:"runDetails"`
+		BuildDefinition struct {
+			ResolvedDependencies []slsaMaterial `json:"resolvedDependencies"`
+		} `json:"buildDefinition"`
+	} `json:"predicate"`
+}
+
+// slsaMaterial is a build input recorded in the provenance
+type slsaMaterial struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest"`
+}
+
+// slsaLevel determines the SLSA build level of an in-toto statement. Statements that aren't SLSA
+// provenance are level 0; signed reports whether the entry was signed with a Fulcio certificate.
+func slsaLevel(attestationType string, data []byte, signed bool) int32 {
+	if attestationType != "slsaprovenance" && attestationType != "slsaprovenance1" {
+		return SLSALevelNone
+	}
+
+	var provenance slsaProvenance
+	if err := json.Unmarshal(data, &provenance); err != nil {
+		return SLSALevelNone
+	}
+
+	builderID := provenance.Predicate.Builder.ID
+	materials := provenance.Predicate.Materials
+	if attestationType == "slsaprovenance1" {
+		builderID = provenance.Predicate.RunDetails.Builder.ID
+		materials = provenance.Predicate.BuildDefinition.ResolvedDependencies
+	}
+
+	if builderID == "" || !signed {
+		return SLSALevelProvenance
+	}
+	if !isHardenedBuilder(builderID) || !materialsPinned(materials) {
+		return SLSALevelSigned
+	}
+	return SLSALevelHardened
+}
+
+// isHardenedBuilder checks the builder ID against the known level 3 builders
+func isHardenedBuilder(builderID string) bool {
+	for _, prefix := range hardenedBuilders {
+		if strings.HasPrefix(builderID, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// materialsPinned checks that there is at least one material and all of them carry a digest
+func materialsPinned(materials []slsaMaterial) bool {
+	if len(materials) == 0 {
+		return false
+	}
+	for _, material := range materials {
+		if len(material.Digest) == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package rekor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/jonlimpw/chainguard-controller/internal/registry"
+)
+
+// ExpectedSubject is the image an attestation must be about. Searching Rekor by digest finds any
+// statement listing that digest, so the subject name is checked too: an attestation of an unrelated
+// image built with the same content would otherwise pass.
+type ExpectedSubject struct {
+	// Repository is the image repository, e.g. "docker.io/jonlimpw/cg-demo" or the DockerHub short form "jonlimpw/cg-demo"
+	Repository string
+
+	// Digest is the image digest the matching subject must carry; empty matches any digest
+	Digest string
+}
+
+// statementSubjects is the subject list of an in-toto statement
+type statementSubjects struct {
+	Subject []struct {
+		Name   string            `json:"name"`
+		Digest map[string]string `json:"digest"`
+	} `json:"subject"`
+}
+
+// check returns an error unless a subject of the statement names the expected repository with the expected digest
+func (s *ExpectedSubject) check(statement []byte) error {
+	var parsed statementSubjects
+	if err := json.Unmarshal(statement, &parsed); err != nil || len(parsed.Subject) == 0 {
+		return fmt.Errorf("attestation has no subject to match repository %s", s.Repository)
+	}
+
+	var algorithm, hex string
+	if s.Digest != "" {
+		var err error
+		if algorithm, hex, err = registry.ParseDigest(s.Digest); err != nil {
+			return err
+		}
+	}
+
+	var names []string
+	for _, subject := range parsed.Subject {
+		if hex != "" && subject.Digest[algorithm] != hex {
+			continue
+		}
+		if sameRepository(subject.Name, s.Repository) {
+			return nil
+		}
+		names = append(names, subject.Name)
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("attestation has no subject with digest %s", s.Digest)
+	}
+	return fmt.Errorf("attestation subject %s does not match expected repository %s", strings.Join(names, ", "), s.Repository)
+}
+
+// sameRepository checks if two image names refer to the same repository once normalized, so "nginx",
+// "docker.io/library/nginx" and "index.docker.io/library/nginx" match. A tag or digest on either name is ignored.
+func sameRepository(a, b string) bool {
+	repositoryA, errA := parseRepository(a)
+	repositoryB, errB := parseRepository(b)
+	return errA == nil && errB == nil && repositoryA.Name() == repositoryB.Name()
+}
+
+// parseRepository parses a repository name, or the repository of a tag or digest reference
+func parseRepository(image string) (name.Repository, error) {
+	if repository, err := name.NewRepository(image); err == nil {
+		return repository, nil
+	}
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return name.Repository{}, err
+	}
+	return ref.Context(), nil
+}
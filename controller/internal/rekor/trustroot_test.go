@@ -0,0 +1,109 @@
+package rekor
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TrustRoot", func() {
+	issuedAt := time.Now().Add(-time.Hour)
+
+	// newCertificate creates a certificate signed by parent (self-signed when nil), valid for ten minutes from issuedAt like Fulcio's
+	newCertificate := func(name string, ca bool, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(time.Now().UnixNano()),
+			Subject:               pkix.Name{CommonName: name},
+			NotBefore:             issuedAt,
+			NotAfter:              issuedAt.Add(10 * time.Minute),
+			IsCA:                  ca,
+			BasicConstraintsValid: true,
+			KeyUsage:              x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		}
+		if ca {
+			template.KeyUsage |= x509.KeyUsageCertSign
+			template.NotAfter = issuedAt.Add(24 * time.Hour)
+		}
+		if parent == nil {
+			parent, parentKey = template, key
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+		Expect(err).NotTo(HaveOccurred())
+		cert, err := x509.ParseCertificate(der)
+		Expect(err).NotTo(HaveOccurred())
+		return cert, key
+	}
+
+	encode := func(certs ...*x509.Certificate) []byte {
+		var bundle []byte
+		for _, cert := range certs {
+			bundle = append(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+		}
+		return bundle
+	}
+
+	root, rootKey := newCertificate("private-fulcio-root", true, nil, nil)
+	intermediate, intermediateKey := newCertificate("private-fulcio-intermediate", true, root, rootKey)
+	leaf, _ := newCertificate("signer", false, intermediate, intermediateKey)
+
+	It("should verify certificates chaining to the bundle's root through its intermediate", func() {
+		trustRoot, err := ParseTrustRoot(encode(intermediate, root))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(trustRoot.Verify(leaf, issuedAt.Add(time.Minute))).To(Succeed())
+	})
+
+	It("should check certificates at their issuance when the signing time is unknown", func() {
+		trustRoot, err := ParseTrustRoot(encode(root, intermediate))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(trustRoot.Verify(leaf, time.Time{})).To(Succeed())
+	})
+
+	It("should reject certificates used after they expired", func() {
+		trustRoot, err := ParseTrustRoot(encode(root, intermediate))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(trustRoot.Verify(leaf, time.Now())).To(MatchError(ContainSubstring("doesn't chain to the Fulcio root")))
+	})
+
+	It("should reject certificates from another root", func() {
+		otherRoot, _ := newCertificate("public-fulcio-root", true, nil, nil)
+		trustRoot, err := ParseTrustRoot(encode(otherRoot, intermediate))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(trustRoot.Verify(leaf, issuedAt.Add(time.Minute))).To(MatchError(ContainSubstring("doesn't chain to the Fulcio root")))
+	})
+
+	It("should reject private certificates against the embedded public-good root", func() {
+		Expect(PublicGoodTrustRoot().Verify(leaf, issuedAt.Add(time.Minute))).To(MatchError(ContainSubstring("doesn't chain to the Fulcio root")))
+	})
+
+	It("should reject bundles without a self-signed root", func() {
+		_, err := ParseTrustRoot(encode(intermediate))
+		Expect(err).To(MatchError(ContainSubstring("no self-signed CA certificate")))
+
+		_, err = ParseTrustRoot([]byte("not a certificate"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject key-signed statements when a Fulcio root is required", func() {
+		trustRoot, err := ParseTrustRoot(encode(root, intermediate))
+		Expect(err).NotTo(HaveOccurred())
+
+		statement := []byte(`{"_type":"https://in-toto.io/Statement/v1","predicateType":"https://slsa.dev/provenance/v1","predicate":{}}`)
+		result := EvaluateStatement(statement, nil, time.Now(), nil, nil, time.Time{}, 0, trustRoot, nil, nil)
+		Expect(result.Verified).To(BeFalse())
+		Expect(result.Error).To(ContainSubstring("not signed with a Fulcio certificate"))
+
+		result = EvaluateStatement(statement, leaf, issuedAt.Add(time.Minute), nil, nil, time.Time{}, 0, trustRoot, nil, nil)
+		Expect(result.Verified).To(BeTrue())
+	})
+})
@@ -0,0 +1,57 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+var _ = Describe("Setup", func() {
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		previous := otel.GetTracerProvider()
+		DeferCleanup(func() { otel.SetTracerProvider(previous) })
+	})
+
+	It("should leave tracing disabled without an endpoint", func() {
+		previous := otel.GetTracerProvider()
+		shutdown, err := Setup(ctx, Options{SampleRatio: 1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(otel.GetTracerProvider()).To(BeIdenticalTo(previous))
+		Expect(shutdown(ctx)).To(Succeed())
+	})
+
+	It("should install an exporting provider for an endpoint", func() {
+		shutdown, err := Setup(ctx, Options{Endpoint: "localhost:4317", Insecure: true, SampleRatio: 1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(otel.GetTracerProvider()).To(BeAssignableToTypeOf(&sdktrace.TracerProvider{}))
+
+		// Nothing was recorded, so shutting down doesn't need the collector to be running
+		Expect(shutdown(ctx)).To(Succeed())
+	})
+
+	It("should reject a sample ratio outside 0 to 1", func() {
+		_, err := Setup(ctx, Options{Endpoint: "localhost:4317", SampleRatio: 1.5})
+		Expect(err).To(MatchError(ContainSubstring("invalid trace sample ratio")))
+	})
+})
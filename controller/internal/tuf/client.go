@@ -0,0 +1,295 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tuf downloads targets from a TUF repository (https://theupdateframework.io), e.g. a mirror of a
+// Sigstore deployment's, with go-tuf's client following the TUF client workflow from a trusted root.json:
+// newer roots, then timestamp, snapshot and targets metadata are each verified against the keys of the
+// role above them before a target's length and hashes are checked.
+package tuf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/theupdateframework/go-tuf/client"
+	"github.com/theupdateframework/go-tuf/data"
+	"golang.org/x/sync/singleflight"
+)
+
+// RefreshInterval is how long verified metadata is used before the repository is checked for new versions
+const RefreshInterval = 15 * time.Minute
+
+// requestTimeout bounds each download from the repository
+const requestTimeout = 30 * time.Second
+
+// ErrVerification is returned when metadata or a target fails signature, hash, version or expiry checks
+var ErrVerification = errors.New("TUF verification failed")
+
+// ErrTargetNotFound is returned when the targets metadata has no target with the requested name
+var ErrTargetNotFound = errors.New("TUF target not found")
+
+// errUnavailable marks failures to download from the repository, as opposed to verification failures
+var errUnavailable = errors.New("TUF repository unavailable")
+
+// topLevelRoles are the roles whose metadata the local store holds, all of which must be unexpired
+var topLevelRoles = []string{"root", "timestamp", "snapshot", "targets"}
+
+// Client downloads verified targets from one TUF repository. It is safe for concurrent use: targets that
+// were verified are served from memory while newer metadata is fetched, so a slow repository only holds
+// up callers that have nothing cached.
+type Client struct {
+	url   string
+	local client.LocalStore
+
+	// downloads runs one refresh and download per target at a time
+	downloads singleflight.Group
+
+	// tufMu serializes the downloads' use of tuf, which isn't safe for concurrent use
+	tufMu sync.Mutex
+	tuf   *client.Client
+
+	mu        sync.Mutex
+	refreshed time.Time
+	expires   time.Time
+
+	// files caches the targets downloaded for the current targets metadata
+	files map[string][]byte
+}
+
+// NewClient creates a client for the repository whose metadata is served at repositoryURL and its
+// targets under repositoryURL/targets. trustedRoot is the root.json the client is bootstrapped from,
+// distributed out of band; it must be signed by its own root keys.
+func NewClient(repositoryURL string, trustedRoot []byte) (*Client, error) {
+	repositoryURL = strings.TrimSuffix(repositoryURL, "/")
+	local := client.MemoryLocalStore()
+	tufClient := client.NewClient(local, &remoteStore{url: repositoryURL, httpClient: &http.Client{Timeout: requestTimeout}})
+	if err := tufClient.Init(trustedRoot); err != nil {
+		return nil, fmt.Errorf("invalid trusted root: %w", err)
+	}
+	return &Client{url: repositoryURL, local: local, tuf: tufClient, files: map[string][]byte{}}, nil
+}
+
+// URL returns the repository URL
+func (c *Client) URL() string {
+	return c.url
+}
+
+// Target returns the contents of a target. A target downloaded for metadata that hasn't expired is
+// returned at once, refreshing the metadata in the background when it is older than RefreshInterval;
+// otherwise Target waits for the download until ctx is done. While the repository is unreachable,
+// metadata that hasn't expired keeps being used.
+func (c *Client) Target(ctx context.Context, name string) ([]byte, error) {
+	now := time.Now()
+	c.mu.Lock()
+	data, cached := c.files[name]
+	valid := now.Before(c.expires)
+	stale := now.Sub(c.refreshed) >= RefreshInterval
+	c.mu.Unlock()
+
+	if cached && valid {
+		if stale {
+			c.downloads.DoChan(name, func() (any, error) { return c.download(name) })
+		}
+		return data, nil
+	}
+
+	select {
+	case result := <-c.downloads.DoChan(name, func() (any, error) { return c.download(name) }):
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.([]byte), nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for TUF repository %s: %w", c.url, ctx.Err())
+	}
+}
+
+// download refreshes the metadata when it is older than RefreshInterval or has expired, then returns
+// the target, downloading it unless it is cached for the current targets metadata
+func (c *Client) download(name string) ([]byte, error) {
+	c.tufMu.Lock()
+	defer c.tufMu.Unlock()
+
+	now := time.Now()
+	c.mu.Lock()
+	refresh := now.Sub(c.refreshed) >= RefreshInterval || !now.Before(c.expires)
+	c.mu.Unlock()
+	if refresh {
+		if err := c.refresh(now); err != nil {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			if data, ok := c.files[name]; ok && now.Before(c.expires) {
+				return data, nil
+			}
+			return nil, err
+		}
+	}
+
+	c.mu.Lock()
+	data, ok := c.files[name]
+	c.mu.Unlock()
+	if ok {
+		return data, nil
+	}
+
+	if _, err := c.tuf.Target(name); err != nil {
+		if client.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %s", ErrTargetNotFound, name)
+		}
+		return nil, classify(err)
+	}
+	var dest destination
+	if err := c.tuf.Download(name, &dest); err != nil {
+		return nil, fmt.Errorf("target %s: %w", name, classify(err))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.files[name] = dest.Bytes()
+	return dest.Bytes(), nil
+}
+
+// refresh updates the metadata and drops the cached targets it changed
+func (c *Client) refresh(now time.Time) error {
+	updated, err := c.tuf.Update()
+	if err != nil {
+		return classify(err)
+	}
+	expires, err := c.expiry()
+	if err != nil {
+		return err
+	}
+	if !now.Before(expires) {
+		return fmt.Errorf("%w: repository metadata expired at %s", ErrVerification, expires)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refreshed, c.expires = now, expires
+	for name := range updated {
+		delete(c.files, name)
+	}
+	return nil
+}
+
+// expiry returns when the first of the trusted metadata expires. The update doesn't check the snapshot
+// and targets metadata again when the timestamp is unchanged, so their expiry is read from the local store.
+func (c *Client) expiry() (time.Time, error) {
+	meta, err := c.local.GetMeta()
+	if err != nil {
+		return time.Time{}, err
+	}
+	var expires time.Time
+	for _, role := range topLevelRoles {
+		raw, ok := meta[role+".json"]
+		if !ok {
+			return time.Time{}, fmt.Errorf("%w: no trusted %s metadata", ErrVerification, role)
+		}
+		var signed data.Signed
+		var header struct {
+			Expires time.Time `json:"expires"`
+		}
+		if err := json.Unmarshal(raw, &signed); err != nil {
+			return time.Time{}, fmt.Errorf("%w: %s metadata: %w", ErrVerification, role, err)
+		}
+		if err := json.Unmarshal(signed.Signed, &header); err != nil {
+			return time.Time{}, fmt.Errorf("%w: %s metadata: %w", ErrVerification, role, err)
+		}
+		if expires.IsZero() || header.Expires.Before(expires) {
+			expires = header.Expires
+		}
+	}
+	return expires, nil
+}
+
+// classify wraps the errors of metadata or targets that failed verification in ErrVerification, leaving
+// failures to reach the repository as they are
+func classify(err error) error {
+	cause := err
+	if failed, ok := err.(client.ErrDownloadFailed); ok {
+		cause = failed.Err
+	}
+	if _, ok := err.(client.ErrMissingRemoteMetadata); ok || errors.Is(cause, errUnavailable) || client.IsNotFound(err) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrVerification, err)
+}
+
+// destination collects a downloaded target in memory
+type destination struct {
+	bytes.Buffer
+}
+
+// Delete discards a target that failed verification
+func (d *destination) Delete() error {
+	d.Reset()
+	return nil
+}
+
+// remoteStore downloads metadata from a repository's URL and targets under its /targets, like go-tuf's
+// HTTPRemoteStore but treating 403 as a missing file, since object stores commonly answer 403 rather
+// than 404 for missing objects, e.g. the next root version
+type remoteStore struct {
+	url        string
+	httpClient *http.Client
+}
+
+func (s *remoteStore) GetMeta(name string) (io.ReadCloser, int64, error) {
+	return s.get(name)
+}
+
+func (s *remoteStore) GetTarget(name string) (io.ReadCloser, int64, error) {
+	return s.get("targets/" + strings.TrimPrefix(name, "/"))
+}
+
+func (s *remoteStore) get(name string) (io.ReadCloser, int64, error) {
+	resp, err := s.httpClient.Get(s.url + "/" + name)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: failed to fetch %s: %w", errUnavailable, name, err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden:
+		_ = resp.Body.Close()
+		return nil, 0, client.ErrNotFound{File: name}
+	case resp.StatusCode != http.StatusOK:
+		_ = resp.Body.Close()
+		return nil, 0, fmt.Errorf("%w: failed to fetch %s: %s", errUnavailable, name, resp.Status)
+	}
+	return responseBody{resp.Body, name}, resp.ContentLength, nil
+}
+
+// responseBody marks errors reading a download as the repository being unavailable
+type responseBody struct {
+	io.ReadCloser
+	name string
+}
+
+func (b responseBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		err = fmt.Errorf("%w: failed to read %s: %w", errUnavailable, b.name, err)
+	}
+	return n, err
+}
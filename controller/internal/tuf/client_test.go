@@ -0,0 +1,182 @@
+package tuf
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jonlimpw/chainguard-controller/internal/tuf/tuftest"
+)
+
+var _ = Describe("Client", func() {
+	const target = "fulcio.crt.pem"
+	ctx := context.Background()
+
+	var repository *tuftest.Repository
+
+	BeforeEach(func() {
+		repository = tuftest.NewRepository(map[string][]byte{target: []byte("first bundle")})
+		DeferCleanup(repository.Close)
+	})
+
+	newClient := func() *Client {
+		client, err := NewClient(repository.URL, repository.TrustedRoot())
+		Expect(err).NotTo(HaveOccurred())
+		return client
+	}
+
+	// refreshedClient returns a client that verified the first bundle, and is due to refresh its metadata
+	refreshedClient := func() *Client {
+		client := newClient()
+		Expect(client.Target(ctx, target)).To(BeEquivalentTo("first bundle"))
+		client.mu.Lock()
+		defer client.mu.Unlock()
+		client.refreshed = time.Time{}
+		return client
+	}
+
+	It("should download a target verified from the trusted root", func() {
+		data, err := newClient().Target(ctx, target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("first bundle"))
+	})
+
+	It("should report targets the repository doesn't have", func() {
+		_, err := newClient().Target(ctx, "rekor.pub")
+		Expect(err).To(MatchError(ErrTargetNotFound))
+	})
+
+	It("should follow root rotations to the new keys", func() {
+		client := newClient()
+		_, err := client.Target(ctx, target)
+		Expect(err).NotTo(HaveOccurred())
+
+		repository.RotateKeys()
+		repository.SetTarget(target, []byte("second bundle"))
+		client.mu.Lock()
+		client.refreshed = time.Time{}
+		client.mu.Unlock()
+
+		// The cached bundle is served while the rotation is followed in the background
+		Eventually(func() (string, error) {
+			data, err := client.Target(ctx, target)
+			return string(data), err
+		}).Should(Equal("second bundle"))
+
+		// A client bootstrapped from the first root gets there too
+		data, err := newClient().Target(ctx, target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("second bundle"))
+	})
+
+	It("should reject a target that doesn't match its hash", func() {
+		repository.Serve(repository.TargetPath(target), []byte("other bundle"))
+
+		_, err := newClient().Target(ctx, target)
+		Expect(err).To(MatchError(ErrVerification))
+	})
+
+	It("should reject metadata signed by keys the root doesn't trust", func() {
+		other := tuftest.NewRepository(map[string][]byte{target: []byte("other bundle")})
+		DeferCleanup(other.Close)
+
+		client, err := NewClient(other.URL, repository.TrustedRoot())
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.Target(ctx, target)
+		Expect(err).To(MatchError(ErrVerification))
+	})
+
+	It("should reject expired metadata", func() {
+		repository.Expire()
+
+		_, err := newClient().Target(ctx, target)
+		Expect(err).To(MatchError(ErrVerification))
+	})
+
+	It("should reject a trusted root that isn't signed by its own keys", func() {
+		_, err := NewClient(repository.URL, []byte(`{"signed":{"_type":"root","version":1},"signatures":[]}`))
+		Expect(err).To(HaveOccurred())
+	})
+
+	DescribeTable("should reject rollbacks to older metadata versions",
+		func(role string) {
+			client := refreshedClient()
+			repository.SetTarget(target, []byte("second bundle"))
+			Expect(client.refresh(time.Now())).To(Succeed())
+
+			repository.RollBack(role)
+			Expect(client.refresh(time.Now())).To(MatchError(ErrVerification))
+		},
+		Entry("of the timestamp", "timestamp"),
+		Entry("of the snapshot", "snapshot"),
+		Entry("of the targets", "targets"),
+	)
+
+	It("should require the threshold of a role's keys to sign its metadata", func() {
+		repository.SetRoleKeys("timestamp", 3, 2, 1)
+		_, err := newClient().Target(ctx, target)
+		Expect(err).To(MatchError(ErrVerification))
+
+		repository.SetRoleKeys("timestamp", 3, 2, 2)
+		data, err := newClient().Target(ctx, target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("first bundle"))
+	})
+
+	It("should count a key listed under two key IDs once towards the threshold", func() {
+		// Only the first of the two keys signs, under both of its IDs
+		repository.SetRoleKeys("timestamp", 2, 2, 1)
+		repository.AliasKey("timestamp")
+
+		_, err := newClient().Target(ctx, target)
+		Expect(err).To(MatchError(ErrVerification))
+	})
+
+	It("should keep using unexpired metadata while the repository is unreachable", func() {
+		client := refreshedClient()
+		repository.Close()
+
+		data, err := client.Target(ctx, target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("first bundle"))
+
+		// Refreshing fails, in the background and in the next download, which keeps the cached bundle
+		data, err = client.download(target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("first bundle"))
+	})
+
+	It("should serve cached targets without waiting for a slow repository", func() {
+		release := make(chan struct{})
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+			http.NotFound(w, r)
+		}))
+		DeferCleanup(slow.Close)
+		DeferCleanup(func() { close(release) })
+
+		client, err := NewClient(slow.URL, repository.TrustedRoot())
+		Expect(err).NotTo(HaveOccurred())
+		client.files[target] = []byte("cached bundle")
+		client.expires = time.Now().Add(time.Hour)
+
+		// The refresh this starts blocks on the repository, but doesn't hold up this call or the next
+		start := time.Now()
+		for range 2 {
+			data, err := client.Target(ctx, target)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal("cached bundle"))
+		}
+
+		// Callers with nothing cached give up when their context is done
+		timeout, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		_, err = client.Target(timeout, "rekor.pub")
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		Expect(time.Since(start)).To(BeNumerically("<", 2*time.Second))
+	})
+})
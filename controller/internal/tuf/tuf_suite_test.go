@@ -0,0 +1,14 @@
+package tuf
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTUF(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "TUF Suite")
+}
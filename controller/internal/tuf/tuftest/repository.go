@@ -0,0 +1,333 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tuftest provides a fake TUF repository with consistent snapshots, signed with ed25519 keys
+// generated per role, so TUF clients can be tested without Sigstore's repository. Its metadata is
+// written independently of go-tuf, and can be made to roll back, miss a signature threshold or list a
+// key twice.
+package tuftest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/secure-systems-lab/go-securesystemslib/cjson"
+)
+
+// roles are the top-level roles, each signed by its own keys
+var roles = []string{"root", "timestamp", "snapshot", "targets"}
+
+// roleKeys are the keys of a role and the number of them that must sign its metadata
+type roleKeys struct {
+	keys      []ed25519.PrivateKey
+	threshold int
+
+	// signers is how many of the keys sign the role's metadata
+	signers int
+
+	// aliases are additional key IDs the root lists the first key under, each of which signs too
+	aliases []string
+}
+
+// Repository is a TUF repository served from memory by an httptest.Server: metadata at its URL and
+// targets under /targets. Every change publishes new snapshot, targets and timestamp versions.
+type Repository struct {
+	*httptest.Server
+
+	mu    sync.Mutex
+	files map[string][]byte
+	keys  map[string]*roleKeys
+
+	// trustedRoot is the first root.json, the one clients are bootstrapped from
+	trustedRoot []byte
+
+	rootVersion      int64
+	targetsVersion   int64
+	snapshotVersion  int64
+	timestampVersion int64
+	expires          time.Time
+	targets          map[string][]byte
+}
+
+// NewRepository starts a repository publishing version 1 of each role's metadata and the given targets.
+// Close it when done.
+func NewRepository(targets map[string][]byte) *Repository {
+	r := &Repository{
+		files:   map[string][]byte{},
+		keys:    map[string]*roleKeys{},
+		expires: time.Now().Add(24 * time.Hour),
+		targets: map[string][]byte{},
+	}
+	for _, role := range roles {
+		r.keys[role] = newRoleKeys(1, 1)
+	}
+	for name, data := range targets {
+		r.targets[name] = data
+	}
+	r.trustedRoot = r.publishRoot(nil)
+	r.publish()
+
+	r.Server = httptest.NewServer(http.HandlerFunc(r.serve))
+	return r
+}
+
+// TrustedRoot returns the first root.json, distributed to clients out of band
+func (r *Repository) TrustedRoot() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.trustedRoot
+}
+
+// SetTarget publishes a new version of a target
+func (r *Repository) SetTarget(name string, data []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.targets[name] = data
+	r.publish()
+}
+
+// RotateKeys publishes a new root, signed by the current and new root keys, that replaces every role's key
+func (r *Repository) RotateKeys() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	previous := r.keys["root"]
+	for _, role := range roles {
+		r.keys[role] = newRoleKeys(1, 1)
+	}
+	r.publishRoot(previous)
+	r.publish()
+}
+
+// SetRoleKeys publishes a new root giving a role other than root count new keys and a threshold, and
+// metadata signed by the first signers of them
+func (r *Repository) SetRoleKeys(role string, count, threshold, signers int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys[role] = newRoleKeys(count, threshold)
+	r.keys[role].signers = signers
+	r.publishRoot(nil)
+	r.publish()
+}
+
+// AliasKey publishes a new root listing the first key of a role other than root under a second key ID
+// as well, and metadata it signs under both IDs
+func (r *Repository) AliasKey(role string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	keys := r.keys[role]
+	keys.aliases = append(keys.aliases, fmt.Sprintf("%064x", len(keys.aliases)+1))
+	r.publishRoot(nil)
+	r.publish()
+}
+
+// RollBack publishes metadata for a role other than root with a lower version than the client has seen:
+// the timestamp is republished with its previous version, while a snapshot or targets rollback is
+// published by the role above it pointing at the previous version of the role
+func (r *Repository) RollBack(role string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch role {
+	case "timestamp":
+		r.timestampVersion -= 2
+		r.publishTimestamp()
+	case "snapshot":
+		r.snapshotVersion--
+		r.publishTimestamp()
+	case "targets":
+		r.targetsVersion--
+		r.publishSnapshot()
+		r.publishTimestamp()
+	}
+}
+
+// Expire republishes the timestamp, snapshot and targets metadata with an expiry in the past
+func (r *Repository) Expire() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expires = time.Now().Add(-time.Hour)
+	r.publish()
+}
+
+// Serve replaces the file at path, e.g. to tamper with a target or metadata file
+func (r *Repository) Serve(path string, data []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.files[path] = data
+}
+
+// TargetPath returns the path a target is currently served at under /targets, prefixed with its hash
+func (r *Repository) TargetPath(name string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sum := sha256.Sum256(r.targets[name])
+	return "targets/" + hex.EncodeToString(sum[:]) + "." + name
+}
+
+func (r *Repository) serve(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	data, ok := r.files[strings.TrimPrefix(req.URL.Path, "/")]
+	r.mu.Unlock()
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	_, _ = w.Write(data)
+}
+
+// publishRoot publishes the next root version listing the current keys, signed by them and by previous
+// when rotating, and returns it
+func (r *Repository) publishRoot(previous *roleKeys) []byte {
+	r.rootVersion++
+	keys := map[string]any{}
+	roleEntries := map[string]any{}
+	for _, role := range roles {
+		var keyIDs []string
+		for i, key := range r.keys[role].keys {
+			ids := []string{keyID(key)}
+			if i == 0 {
+				ids = append(ids, r.keys[role].aliases...)
+			}
+			for _, id := range ids {
+				keys[id] = publicKey(key)
+				keyIDs = append(keyIDs, id)
+			}
+		}
+		roleEntries[role] = map[string]any{"keyids": keyIDs, "threshold": r.keys[role].threshold}
+	}
+
+	signers := []*roleKeys{r.keys["root"]}
+	if previous != nil {
+		signers = append(signers, previous)
+	}
+	root := sign(map[string]any{
+		"_type":               "root",
+		"spec_version":        "1.0.31",
+		"version":             r.rootVersion,
+		"expires":             time.Now().Add(365 * 24 * time.Hour).UTC().Format(time.RFC3339),
+		"consistent_snapshot": true,
+		"keys":                keys,
+		"roles":               roleEntries,
+	}, signers...)
+	r.files[fmt.Sprintf("%d.root.json", r.rootVersion)] = root
+	return root
+}
+
+// publish publishes the targets and new versions of the targets, snapshot and timestamp metadata
+func (r *Repository) publish() {
+	targets := map[string]any{}
+	for name, data := range r.targets {
+		sum := sha256.Sum256(data)
+		hash := hex.EncodeToString(sum[:])
+		targets[name] = map[string]any{"length": len(data), "hashes": map[string]string{"sha256": hash}}
+		r.files["targets/"+hash+"."+name] = data
+	}
+	r.targetsVersion++
+	r.files[fmt.Sprintf("%d.targets.json", r.targetsVersion)] = sign(map[string]any{"_type": "targets",
+		"version": r.targetsVersion, "expires": r.expiresAt(), "targets": targets}, r.keys["targets"])
+
+	r.publishSnapshot()
+	r.publishTimestamp()
+}
+
+// publishSnapshot publishes a new snapshot version listing the current targets version
+func (r *Repository) publishSnapshot() {
+	r.snapshotVersion++
+	r.files[fmt.Sprintf("%d.snapshot.json", r.snapshotVersion)] = sign(map[string]any{"_type": "snapshot",
+		"version": r.snapshotVersion, "expires": r.expiresAt(),
+		"meta": map[string]any{"targets.json": map[string]any{"version": r.targetsVersion}}}, r.keys["snapshot"])
+}
+
+// publishTimestamp publishes a new timestamp version pointing at the current snapshot version
+func (r *Repository) publishTimestamp() {
+	r.timestampVersion++
+	snapshotJSON := r.files[fmt.Sprintf("%d.snapshot.json", r.snapshotVersion)]
+	sum := sha256.Sum256(snapshotJSON)
+	r.files["timestamp.json"] = sign(map[string]any{"_type": "timestamp", "version": r.timestampVersion, "expires": r.expiresAt(),
+		"meta": map[string]any{"snapshot.json": map[string]any{
+			"version": r.snapshotVersion, "length": len(snapshotJSON), "hashes": map[string]string{"sha256": hex.EncodeToString(sum[:])},
+		}}}, r.keys["timestamp"])
+}
+
+func (r *Repository) expiresAt() string {
+	return r.expires.UTC().Format(time.RFC3339)
+}
+
+// sign wraps signed metadata in an envelope with the signatures of each role's signers over its canonical JSON
+func sign(signed map[string]any, signers ...*roleKeys) []byte {
+	message, err := cjson.EncodeCanonical(signed)
+	if err != nil {
+		panic(err)
+	}
+	var signatures []map[string]string
+	for _, keys := range signers {
+		for i, key := range keys.keys[:keys.signers] {
+			sig := hex.EncodeToString(ed25519.Sign(key, message))
+			ids := []string{keyID(key)}
+			if i == 0 {
+				ids = append(ids, keys.aliases...)
+			}
+			for _, id := range ids {
+				signatures = append(signatures, map[string]string{"keyid": id, "sig": sig})
+			}
+		}
+	}
+	envelope, err := json.Marshal(map[string]any{"signed": signed, "signatures": signatures})
+	if err != nil {
+		panic(err)
+	}
+	return envelope
+}
+
+// publicKey returns the TUF representation of an ed25519 key
+func publicKey(key ed25519.PrivateKey) map[string]any {
+	return map[string]any{
+		"keytype": "ed25519",
+		"scheme":  "ed25519",
+		"keyval":  map[string]string{"public": hex.EncodeToString(key.Public().(ed25519.PublicKey))},
+	}
+}
+
+// keyID identifies a key by the hash of the canonical JSON of its TUF representation
+func keyID(key ed25519.PrivateKey) string {
+	canonical, err := cjson.EncodeCanonical(publicKey(key))
+	if err != nil {
+		panic(err)
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:])
+}
+
+// newRoleKeys generates count keys, all of which sign
+func newRoleKeys(count, threshold int) *roleKeys {
+	keys := &roleKeys{threshold: threshold, signers: count}
+	for range count {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			panic(err)
+		}
+		keys.keys = append(keys.keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/jonlimpw/chainguard-controller/internal/controller"
+)
+
+// log is for logging in this package.
+var deploymentlog = logf.Log.WithName("deployment-resource")
+
+// SetupDeploymentWebhookWithManager registers the webhook for Deployment in the manager.
+// The reconciler is shared so admission reuses its policy evaluation and digest cache.
+func SetupDeploymentWebhookWithManager(mgr ctrl.Manager, reconciler *controller.ImagePolicyReconciler) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&appsv1.Deployment{}).
+		WithValidator(&DeploymentCustomValidator{Reconciler: reconciler}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-apps-v1-deployment,mutating=false,failurePolicy=ignore,sideEffects=None,groups=apps,resources=deployments,verbs=create;update,versions=v1,name=vdeployment-v1.kb.io,admissionReviewVersions=v1
+
+// DeploymentCustomValidator struct is responsible for validating the Deployment resource
+// when it is created or updated, rejecting images blocked by an ImagePolicy with BlockOnAdmission.
+//
+// NOTE: The +kubebuilder:object:generate=false marker prevents controller-gen from generating DeepCopy methods,
+// as this struct is used only for temporary operations and does not need to be deeply copied.
+// +kubebuilder:object:generate=false
+type DeploymentCustomValidator struct {
+	// Reconciler evaluates workloads against ImagePolicies
+	Reconciler *controller.ImagePolicyReconciler
+}
+
+var _ webhook.CustomValidator = &DeploymentCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type Deployment.
+func (v *DeploymentCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	deployment, ok := obj.(*appsv1.Deployment)
+	if !ok {
+		return nil, fmt.Errorf("expected a Deployment object but got %T", obj)
+	}
+	deploymentlog.V(1).Info("Validation for Deployment upon creation", "name", deployment.GetName(), "namespace", deployment.GetNamespace())
+
+	return nil, v.validateDeployment(ctx, deployment)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type Deployment.
+func (v *DeploymentCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	deployment, ok := newObj.(*appsv1.Deployment)
+	if !ok {
+		return nil, fmt.Errorf("expected a Deployment object for the newObj but got %T", newObj)
+	}
+	oldDeployment, ok := oldObj.(*appsv1.Deployment)
+	if !ok {
+		return nil, fmt.Errorf("expected a Deployment object for the oldObj but got %T", oldObj)
+	}
+	deploymentlog.V(1).Info("Validation for Deployment upon update", "name", deployment.GetName(), "namespace", deployment.GetNamespace())
+
+	// Only image changes are checked, so scaling a deployment that already runs a non-compliant image isn't blocked
+	denials, err := v.Reconciler.ValidateWorkloadUpdate(ctx, oldDeployment, deployment)
+	return nil, v.denyDeployment(deployment, denials, err)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type Deployment.
+func (v *DeploymentCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	// Deletes are never blocked; the webhook is only registered for create and update
+	return nil, nil
+}
+
+// validateDeployment rejects the deployment if any blocking ImagePolicy finds it non-compliant
+func (v *DeploymentCustomValidator) validateDeployment(ctx context.Context, deployment *appsv1.Deployment) error {
+	denials, err := v.Reconciler.ValidateWorkloadAdmission(ctx, deployment)
+	return v.denyDeployment(deployment, denials, err)
+}
+
+// denyDeployment turns the admission denials for deployment into the error rejecting it
+func (v *DeploymentCustomValidator) denyDeployment(deployment *appsv1.Deployment, denials []string, err error) error {
+	if err != nil {
+		// Fail open, matching the webhook's failurePolicy, rather than blocking deploys on our own errors
+		deploymentlog.Error(err, "Failed to evaluate ImagePolicies, allowing admission", "name", deployment.GetName(), "namespace", deployment.GetNamespace())
+		return nil
+	}
+	if len(denials) == 0 {
+		return nil
+	}
+	return fmt.Errorf("deployment %s/%s is not compliant: %s", deployment.GetNamespace(), deployment.GetName(), strings.Join(denials, "; "))
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	securityv1 "github.com/jonlimpw/chainguard-controller/api/v1"
+	"github.com/jonlimpw/chainguard-controller/internal/controller"
+)
+
+// log is for logging in this package.
+var imagepolicylog = logf.Log.WithName("imagepolicy-resource")
+
+// SetupImagePolicyWebhookWithManager registers the webhook for ImagePolicy in the manager.
+func SetupImagePolicyWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&securityv1.ImagePolicy{}).
+		WithValidator(&ImagePolicyCustomValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-security-chainguard-dev-v1-imagepolicy,mutating=false,failurePolicy=ignore,sideEffects=None,groups=security.chainguard.dev,resources=imagepolicies,verbs=create;update,versions=v1,name=vimagepolicy-v1.kb.io,admissionReviewVersions=v1
+
+// ImagePolicyCustomValidator struct is responsible for validating the ImagePolicy resource
+// when it is created or updated, rejecting specs reconciles would only report as Degraded.
+//
+// NOTE: The +kubebuilder:object:generate=false marker prevents controller-gen from generating DeepCopy methods,
+// as this struct is used only for temporary operations and does not need to be deeply copied.
+// +kubebuilder:object:generate=false
+type ImagePolicyCustomValidator struct{}
+
+var _ webhook.CustomValidator = &ImagePolicyCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type ImagePolicy.
+func (v *ImagePolicyCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	policy, ok := obj.(*securityv1.ImagePolicy)
+	if !ok {
+		return nil, fmt.Errorf("expected an ImagePolicy object but got %T", obj)
+	}
+	imagepolicylog.V(1).Info("Validation for ImagePolicy upon creation", "name", policy.GetName(), "namespace", policy.GetNamespace())
+
+	return nil, validateImagePolicy(policy)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type ImagePolicy.
+func (v *ImagePolicyCustomValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	policy, ok := newObj.(*securityv1.ImagePolicy)
+	if !ok {
+		return nil, fmt.Errorf("expected an ImagePolicy object for the newObj but got %T", newObj)
+	}
+	oldPolicy, ok := oldObj.(*securityv1.ImagePolicy)
+	if !ok {
+		return nil, fmt.Errorf("expected an ImagePolicy object for the oldObj but got %T", oldObj)
+	}
+	imagepolicylog.V(1).Info("Validation for ImagePolicy upon update", "name", policy.GetName(), "namespace", policy.GetNamespace())
+
+	// Updates leaving the spec alone (finalizers, annotations such as pausing) are allowed on policies
+	// created before the webhook, so the controller and users can still manage them
+	if equality.Semantic.DeepEqual(oldPolicy.Spec, policy.Spec) {
+		return nil, nil
+	}
+	return nil, validateImagePolicy(policy)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type ImagePolicy.
+func (v *ImagePolicyCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	// Deletes are never blocked; the webhook is only registered for create and update
+	return nil, nil
+}
+
+// validateImagePolicy rejects the policy with every invalid field at once, so a single apply shows them all
+func validateImagePolicy(policy *securityv1.ImagePolicy) error {
+	errs := controller.ValidateImagePolicy(policy)
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(securityv1.GroupVersion.WithKind("ImagePolicy").GroupKind(), policy.GetName(), errs)
+}